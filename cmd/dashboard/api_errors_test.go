@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+// decodeAPIError checks a response is a JSON error envelope with the given status
+// and code and returns it
+func decodeAPIError(t *testing.T, w *httptest.ResponseRecorder, status int, code string) APIError {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("Expected status %d, got %d: %s", status, w.Code, w.Body.String())
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected a JSON error, got Content-Type %q", contentType)
+	}
+
+	var apiError APIError
+	if err := json.NewDecoder(w.Body).Decode(&apiError); err != nil {
+		t.Fatalf("Failed to decode error envelope: %v", err)
+	}
+	if apiError.Code != code || apiError.Error == "" {
+		t.Errorf("Expected code %q with a message, got %+v", code, apiError)
+	}
+	return apiError
+}
+
+func TestDashboard_APIErrors_NotFound(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		handler http.HandlerFunc
+	}{
+		{name: "graph", method: "GET", target: "/api/graph/missing", handler: dashboard.handleGraphAPI},
+		{name: "export", method: "GET", target: "/api/export/graphml/missing", handler: dashboard.handleExportGraphML},
+		{name: "schema", method: "GET", target: "/api/schema/Spaceship", handler: dashboard.handleEntitySchema},
+		{name: "delete", method: "DELETE", target: "/api/project/delete/missing", handler: dashboard.handleDeleteProject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler(w, httptest.NewRequest(tt.method, tt.target, nil))
+			decodeAPIError(t, w, http.StatusNotFound, "not_found")
+		})
+	}
+}
+
+func TestDashboard_APIErrors_Validation(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+	setupEntityProject(t, dashboard)
+
+	w := httptest.NewRecorder()
+	dashboard.handleCreateEntity(w, httptest.NewRequest("POST", "/api/entities/forms", strings.NewReader(`{"entity_type": "Scene"}`)))
+	decodeAPIError(t, w, http.StatusBadRequest, "bad_request")
+
+	w = httptest.NewRecorder()
+	dashboard.handleCreateEntity(w, httptest.NewRequest("POST", "/api/entities/forms", strings.NewReader(`{"entity_type": "Scene", "entity_id": "scene-1", "fields": {"sequence": "first"}}`)))
+	apiError := decodeAPIError(t, w, http.StatusUnprocessableEntity, "validation_failed")
+	if fields, ok := apiError.Details.([]any); !ok || len(fields) != 1 {
+		t.Errorf("Expected the mismatching field in the details, got %v", apiError.Details)
+	}
+
+	w = httptest.NewRecorder()
+	dashboard.handleCreateEntity(w, httptest.NewRequest("GET", "/api/entities/forms", nil))
+	decodeAPIError(t, w, http.StatusMethodNotAllowed, "method_not_allowed")
+}
+
+func TestServiceErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{err: fmt.Errorf("lookup: %w", graphwrite.ErrEntityNotFound), status: http.StatusNotFound, code: "not_found"},
+		{err: graphwrite.ErrNoWorkingSet, status: http.StatusNotFound, code: "not_found"},
+		{err: fmt.Errorf("%w: name is required", graphwrite.ErrInvalidEntityData), status: http.StatusUnprocessableEntity, code: "validation_failed"},
+		{err: graphwrite.ErrVersionNotInProject, status: http.StatusBadRequest, code: "bad_request"},
+		{err: graphwrite.ErrConcurrentModification, status: http.StatusConflict, code: "conflict"},
+		{err: graphwrite.ErrProjectNotOwned, status: http.StatusForbidden, code: "forbidden"},
+		{err: errors.New("disk full"), status: 0, code: ""},
+	}
+
+	for _, tt := range tests {
+		if status, code := serviceErrorStatus(tt.err); status != tt.status || code != tt.code {
+			t.Errorf("serviceErrorStatus(%v) = %d %q, expected %d %q", tt.err, status, code, tt.status, tt.code)
+		}
+	}
+}
@@ -312,18 +312,19 @@ func TestDeleteProject_WithSharedEntities(t *testing.T) {
 	}
 
 	// Parse response
-	var result map[string]any
+	var result APIError
 	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
 		t.Fatalf("Failed to decode response: %v", err)
 	}
 
 	// Verify response indicates shared entities conflict
-	if success, ok := result["success"].(bool); !ok || success {
-		t.Errorf("Expected success=false, got %v", result["success"])
+	if result.Code != "shared_entities" || result.Error == "" {
+		t.Errorf("Expected a shared_entities error, got %+v", result)
 	}
 
-	if sharedEntities, ok := result["sharedEntities"].([]any); !ok || len(sharedEntities) == 0 {
-		t.Errorf("Expected sharedEntities list, got %v", result["sharedEntities"])
+	details, _ := result.Details.(map[string]any)
+	if sharedEntities, ok := details["sharedEntities"].([]any); !ok || len(sharedEntities) == 0 {
+		t.Errorf("Expected sharedEntities list in the details, got %v", result.Details)
 	}
 
 	// Verify project still exists (wasn't deleted)
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/barrynorthern/libretto/internal/db"
@@ -215,4 +216,88 @@ func TestDashboard_DemoPageRenders(t *testing.T) {
 	if !bytes.Contains([]byte(body), []byte("Create Story")) {
 		t.Error("Expected demo page to contain Create Story button")
 	}
-}
\ No newline at end of file
+}
+func TestDashboard_UpdateSceneDemo_IfMatch(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+
+	req1 := httptest.NewRequest("POST", "/api/demo/create-story", nil)
+	w1 := httptest.NewRecorder()
+	dashboard.handleCreateStoryDemo(w1, req1)
+
+	var createResult map[string]any
+	if err := json.NewDecoder(w1.Body).Decode(&createResult); err != nil {
+		t.Fatalf("Failed to decode create story response: %v", err)
+	}
+
+	sceneType := "Scene"
+	scenes, err := dashboard.graphService.ListEntities(context.Background(), createResult["versionId"].(string), graphwrite.EntityFilter{EntityType: &sceneType})
+	if err != nil || len(scenes) != 1 {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+
+	update := func(ifMatch string) int {
+		bodyBytes, _ := json.Marshal(map[string]any{
+			"projectId":       createResult["projectId"],
+			"parentVersionId": createResult["versionId"],
+			"sceneId":         createResult["sceneId"],
+		})
+		req := httptest.NewRequest("POST", "/api/demo/update-scene", bytes.NewReader(bodyBytes))
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		dashboard.handleUpdateSceneDemo(w, req)
+		return w.Code
+	}
+
+	if code := update(`"stale-hash"`); code != http.StatusConflict {
+		t.Errorf("Expected 409 for a stale If-Match, got %d", code)
+	}
+	if code := update(`"` + scenes[0].ContentHash + `"`); code != http.StatusOK {
+		t.Errorf("Expected 200 for a fresh If-Match, got %d", code)
+	}
+}
+
+func TestDashboard_ConcurrentElenaSagaDemos(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+
+	// Each run wipes the database before building the saga; overlapping runs must
+	// not delete each other's books halfway through
+	const runs = 2
+	codes := make([]int, runs)
+	bodies := make([]string, runs)
+	var wg sync.WaitGroup
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest("POST", "/api/demo/create-elena-saga", nil)
+			w := httptest.NewRecorder()
+			dashboard.handleCreateElenaSagaDemo(w, req)
+			codes[i] = w.Code
+			bodies[i] = w.Body.String()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < runs; i++ {
+		if codes[i] != http.StatusOK {
+			t.Fatalf("Run %d failed with status %d: %s", i, codes[i], bodies[i])
+		}
+	}
+
+	// The last run's saga is left intact: three books sharing Elena
+	projects, err := dashboard.queries.ListProjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+	if len(projects) != 3 {
+		t.Fatalf("Expected exactly one saga of 3 books, got %d projects", len(projects))
+	}
+
+	history, err := dashboard.graphService.GetEntityHistory(context.Background(), "elena-stormwind-protagonist")
+	if err != nil {
+		t.Fatalf("GetEntityHistory failed: %v", err)
+	}
+	if len(history) != 3 {
+		t.Errorf("Expected Elena in all 3 books, got %d", len(history))
+	}
+}
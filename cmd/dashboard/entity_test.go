@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// setupEntityProject creates a project with an empty working set for the create endpoint
+func setupEntityProject(t *testing.T, dashboard *Dashboard) {
+	ctx := context.Background()
+	if _, err := dashboard.queries.CreateProject(ctx, db.CreateProjectParams{ID: "forms", Name: "Forms"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := dashboard.queries.CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: "forms-root", ProjectID: "forms", IsWorkingSet: true}); err != nil {
+		t.Fatalf("Failed to create version: %v", err)
+	}
+}
+
+func TestDashboard_CreateEntityRejectsSchemaViolation(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+	setupEntityProject(t, dashboard)
+
+	body := `{"entity_type": "Scene", "entity_id": "scene-1", "fields": {"name": "Opening", "sequence": "first"}}`
+	req := httptest.NewRequest("POST", "/api/entities/forms", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	dashboard.handleCreateEntity(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Code   string             `json:"code"`
+		Errors []types.FieldError `json:"details"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Code != "validation_failed" {
+		t.Errorf("Expected code validation_failed, got %q", response.Code)
+	}
+	if len(response.Errors) != 1 || response.Errors[0].Field != "sequence" {
+		t.Fatalf("Expected one error for sequence, got %+v", response.Errors)
+	}
+	if response.Errors[0].Message != "expected integer, got string" {
+		t.Errorf("Unexpected message: %q", response.Errors[0].Message)
+	}
+
+	workingSet, err := dashboard.queries.GetWorkingSetVersion(context.Background(), "forms")
+	if err != nil {
+		t.Fatalf("GetWorkingSetVersion failed: %v", err)
+	}
+	if workingSet.ID != "forms-root" {
+		t.Errorf("Expected the rejected payload to leave the working set alone, got %s", workingSet.ID)
+	}
+}
+
+func TestDashboard_CreateEntityAppliesValidPayload(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+	setupEntityProject(t, dashboard)
+
+	body := `{"entity_type": "Scene", "entity_id": "scene-1", "fields": {"name": "Opening", "sequence": 1, "themes": ["hope"]}}`
+	req := httptest.NewRequest("POST", "/api/entities/forms", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	dashboard.handleCreateEntity(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	ctx := context.Background()
+	workingSet, err := dashboard.queries.GetWorkingSetVersion(ctx, "forms")
+	if err != nil {
+		t.Fatalf("GetWorkingSetVersion failed: %v", err)
+	}
+	entities, err := dashboard.graphService.ListEntities(ctx, workingSet.ID, graphwrite.EntityFilter{})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	if len(entities) != 1 || entities[0].ID != "scene-1" || entities[0].Name != "Opening" {
+		t.Errorf("Expected the created scene, got %+v", entities)
+	}
+}
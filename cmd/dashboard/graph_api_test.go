@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/barrynorthern/libretto/internal/db"
@@ -188,4 +190,256 @@ func findNodeByID(nodes []Node, id string) *Node {
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+func TestGraphAPI_FocusedView(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+
+	req := httptest.NewRequest("POST", "/api/demo/create-story", nil)
+	w := httptest.NewRecorder()
+	dashboard.handleCreateStoryDemo(w, req)
+
+	var story map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&story); err != nil {
+		t.Fatalf("Failed to decode create story response: %v", err)
+	}
+	projectID, _ := story["projectId"].(string)
+	sceneID, _ := story["sceneId"].(string)
+	characterID, _ := story["characterId"].(string)
+
+	fetch := func(focus string) GraphVisualization {
+		req := httptest.NewRequest("GET", "/api/graph/"+projectID+"?focus="+focus, nil)
+		w := httptest.NewRecorder()
+		dashboard.handleGraphAPI(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var graph GraphVisualization
+		if err := json.NewDecoder(w.Body).Decode(&graph); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return graph
+	}
+
+	both := fetch(sceneID + "," + characterID)
+	if len(both.Nodes) != 2 || len(both.Links) != 1 {
+		t.Errorf("Expected 2 nodes and 1 link, got %d nodes and %d links", len(both.Nodes), len(both.Links))
+	}
+
+	// The features edge leaves the selection, so it is dropped
+	single := fetch(characterID)
+	if len(single.Nodes) != 1 || len(single.Links) != 0 {
+		t.Errorf("Expected 1 node and no links, got %d nodes and %d links", len(single.Nodes), len(single.Links))
+	}
+	if len(single.Nodes) == 1 && single.Nodes[0].Size != 0 {
+		t.Errorf("Expected focused node size to count only intra-set edges, got %d", single.Nodes[0].Size)
+	}
+}
+
+func TestGraphAPI_NodeDegrees(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+	ctx := context.Background()
+
+	if _, err := dashboard.queries.CreateProject(ctx, db.CreateProjectParams{ID: "degrees", Name: "Degrees"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := dashboard.queries.CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: "degrees-root", ProjectID: "degrees", IsWorkingSet: true}); err != nil {
+		t.Fatalf("Failed to create version: %v", err)
+	}
+	if _, err := dashboard.graphService.ApplyAndAdvance(ctx, &graphwrite.ApplyRequest{
+		ParentVersionID: "degrees-root",
+		Deltas: []*graphwrite.Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}},
+			{
+				Operation:  "create",
+				EntityType: "Location",
+				EntityID:   "harbor",
+				Fields:     map[string]any{"name": "Harbor"},
+				Relationships: []*graphwrite.RelationshipDelta{
+					{Operation: "create", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "allies_with", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "elena", ToEntityID: "harbor", RelationshipType: "located_at", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "marcus", ToEntityID: "harbor", RelationshipType: "located_at", Properties: map[string]any{}},
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/graph/degrees", nil)
+	w := httptest.NewRecorder()
+	dashboard.handleGraphAPI(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var graph GraphVisualization
+	if err := json.NewDecoder(w.Body).Decode(&graph); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	expected := map[string][2]int{ // in, out
+		"elena":  {0, 2},
+		"marcus": {1, 1},
+		"harbor": {2, 0},
+	}
+	for id, degrees := range expected {
+		node := findNodeByID(graph.Nodes, id)
+		if node == nil {
+			t.Errorf("Node %s not found", id)
+			continue
+		}
+		if node.InDegree != degrees[0] || node.OutDegree != degrees[1] || node.Degree != degrees[0]+degrees[1] {
+			t.Errorf("Expected %s in/out/total %d/%d/%d, got %d/%d/%d", id,
+				degrees[0], degrees[1], degrees[0]+degrees[1], node.InDegree, node.OutDegree, node.Degree)
+		}
+	}
+
+	if len(graph.RelationshipTypes) != 2 || graph.RelationshipTypes[0] != "allies_with" || graph.RelationshipTypes[1] != "located_at" {
+		t.Errorf("Expected relationship types [allies_with located_at], got %v", graph.RelationshipTypes)
+	}
+
+	// ?type= narrows the links to one relationship type
+	req = httptest.NewRequest("GET", "/api/graph/degrees?type=located_at", nil)
+	w = httptest.NewRecorder()
+	dashboard.handleGraphAPI(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var located GraphVisualization
+	if err := json.NewDecoder(w.Body).Decode(&located); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(located.Nodes) != 3 || len(located.Links) != 2 {
+		t.Errorf("Expected 3 nodes and 2 links, got %d nodes and %d links", len(located.Nodes), len(located.Links))
+	}
+	for _, link := range located.Links {
+		if link.Type != "located_at" {
+			t.Errorf("Expected only located_at links, got %+v", link)
+		}
+	}
+}
+
+func TestExportGraphML(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+	ctx := context.Background()
+
+	if _, err := dashboard.queries.CreateProject(ctx, db.CreateProjectParams{ID: "export", Name: "Export"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := dashboard.queries.CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: "export-root", ProjectID: "export", IsWorkingSet: true}); err != nil {
+		t.Fatalf("Failed to create version: %v", err)
+	}
+	if _, err := dashboard.graphService.ApplyAndAdvance(ctx, &graphwrite.ApplyRequest{
+		ParentVersionID: "export-root",
+		Deltas: []*graphwrite.Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{
+				Operation:  "create",
+				EntityType: "Location",
+				EntityID:   "harbor",
+				Fields:     map[string]any{"name": "Harbor"},
+				Relationships: []*graphwrite.RelationshipDelta{
+					{Operation: "create", FromEntityID: "elena", ToEntityID: "harbor", RelationshipType: "located_at", Properties: map[string]any{}},
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/export/graphml/export", nil)
+	w := httptest.NewRecorder()
+	dashboard.handleExportGraphML(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/xml" {
+		t.Errorf("Expected application/xml, got %q", contentType)
+	}
+
+	var document struct {
+		Nodes []struct{} `xml:"graph>node"`
+		Edges []struct{} `xml:"graph>edge"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &document); err != nil {
+		t.Fatalf("Expected well-formed GraphML: %v", err)
+	}
+	if len(document.Nodes) != 2 || len(document.Edges) != 1 {
+		t.Errorf("Expected 2 nodes and 1 edge, got %d and %d", len(document.Nodes), len(document.Edges))
+	}
+}
+
+func TestSaveLayout_SeedsGraphPositions(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+	ctx := context.Background()
+
+	if _, err := dashboard.queries.CreateProject(ctx, db.CreateProjectParams{ID: "layout", Name: "Layout"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := dashboard.queries.CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: "layout-root", ProjectID: "layout", IsWorkingSet: true}); err != nil {
+		t.Fatalf("Failed to create version: %v", err)
+	}
+	if _, err := dashboard.graphService.ApplyAndAdvance(ctx, &graphwrite.ApplyRequest{
+		ParentVersionID: "layout-root",
+		Deltas: []*graphwrite.Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}},
+		},
+	}); err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/api/layout/layout", strings.NewReader(`{"elena": {"x": 120, "y": 80}}`))
+	w := httptest.NewRecorder()
+	dashboard.handleSaveLayout(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/graph/layout", nil)
+	w = httptest.NewRecorder()
+	dashboard.handleGraphAPI(w, req)
+
+	var graph GraphVisualization
+	if err := json.NewDecoder(w.Body).Decode(&graph); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	elena, marcus := findNodeByID(graph.Nodes, "elena"), findNodeByID(graph.Nodes, "marcus")
+	if elena == nil || elena.X == nil || *elena.X != 120 || *elena.Y != 80 {
+		t.Errorf("Expected elena seeded at (120, 80), got %+v", elena)
+	}
+	if marcus == nil || marcus.X != nil {
+		t.Errorf("Expected marcus to have no saved position, got %+v", marcus)
+	}
+}
+
+func TestGraphAPI_PrettyJSON(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+	setupEntityProject(t, dashboard)
+
+	get := func(target string) string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		dashboard.handleGraphAPI(w, httptest.NewRequest("GET", target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for %s, got %d: %s", target, w.Code, w.Body.String())
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("Expected JSON for %s, got %q", target, contentType)
+		}
+		return w.Body.String()
+	}
+
+	if compact := get("/api/graph/forms"); strings.Count(compact, "\n") != 1 {
+		t.Errorf("Expected compact JSON by default, got %q", compact)
+	}
+	if pretty := get("/api/graph/forms?pretty=1"); !strings.Contains(pretty, "{\n  \"nodes\": []") {
+		t.Errorf("Expected indented JSON with ?pretty=1, got %q", pretty)
+	}
+
+	dashboard.prettyJSON = true
+	if pretty := get("/api/graph/forms"); !strings.Contains(pretty, "\n  \"links\": []") {
+		t.Errorf("Expected indented JSON with -pretty-json, got %q", pretty)
+	}
+}
@@ -4,15 +4,22 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/barrynorthern/libretto/internal/db"
 	"github.com/barrynorthern/libretto/internal/graphwrite"
+	"github.com/barrynorthern/libretto/internal/monitoring"
+	"github.com/barrynorthern/libretto/internal/types"
 	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -21,25 +28,29 @@ type Dashboard struct {
 	queries      *db.Queries
 	database     *db.Database
 	graphService graphwrite.GraphWriteService
-}
 
-type ProjectSummary struct {
-	Project   db.Project
-	Versions  []db.GraphVersion
-	Stats     ProjectStats
+	// prettyJSON indents every JSON API response, as ?pretty=1 does for one request
+	prettyJSON bool
+
+	// demoMu serializes the demo handlers: the saga demo wipes every project before
+	// rebuilding, which would otherwise tear out data another demo is still writing
+	demoMu sync.Mutex
 }
 
-type ProjectStats struct {
-	TotalEntities     int64
-	TotalRelationships int
-	TotalAnnotations  int
-	EntityCounts      map[string]int64
-	RelationshipCounts map[string]int
+// SeriesGroup collects the projects belonging to one series on the home page.
+// Projects without a series are grouped under an empty Series.
+type SeriesGroup struct {
+	Series   string
+	Projects []graphwrite.ProjectSummaryStats
 }
 
 type GraphVisualization struct {
 	Nodes []Node `json:"nodes"`
 	Links []Link `json:"links"`
+
+	// RelationshipTypes lists the distinct relationship types in the version, for
+	// populating the view's filter controls
+	RelationshipTypes []string `json:"relationshipTypes,omitempty"`
 }
 
 type Node struct {
@@ -48,6 +59,16 @@ type Node struct {
 	Type     string `json:"type"`
 	Group    int    `json:"group"`
 	Size     int    `json:"size"`
+
+	// Degree counts every relationship touching the node; InDegree and OutDegree
+	// split it by direction
+	Degree    int `json:"degree"`
+	InDegree  int `json:"inDegree"`
+	OutDegree int `json:"outDegree"`
+
+	// X and Y hold the node's saved layout position, if any, to seed the simulation
+	X *float64 `json:"x,omitempty"`
+	Y *float64 `json:"y,omitempty"`
 }
 
 type Link struct {
@@ -59,8 +80,12 @@ type Link struct {
 
 func main() {
 	var (
-		dbPath = flag.String("db", "libretto.db", "Path to SQLite database")
-		port   = flag.String("port", "9000", "Port to serve on")
+		dbPath           = flag.String("db", "libretto.db", "Path to SQLite database")
+		port             = flag.String("port", "9000", "Port to serve on")
+		countQueries     = flag.Bool("count-queries", false, "Log the number of database queries each request issues")
+		snapshotEdits    = flag.Int("snapshot-every", 0, "Checkpoint the working set after this many edits (0 disables)")
+		snapshotInterval = flag.Duration("snapshot-interval", 0, "Checkpoint the working set on the first edit after this long (0 disables)")
+		prettyJSON       = flag.Bool("pretty-json", false, "Indent JSON API responses (per request with ?pretty=1)")
 	)
 	flag.Parse()
 
@@ -77,66 +102,188 @@ func main() {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
+	// Counting only adds a context lookup per statement, but stays opt-in for debugging
+	handle := http.HandleFunc
+	if *countQueries {
+		database = database.WithQueryCounting()
+		logger := monitoring.NewLogger("dashboard")
+		handle = func(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+			http.HandleFunc(pattern, withQueryCount(logger, handler))
+		}
+	}
+
 	// Initialize GraphWrite service
-	graphService := graphwrite.NewService(database)
+	graphService := graphwrite.NewServiceWithOptions(database, graphwrite.ServiceOptions{
+		AutoSnapshot: graphwrite.AutoSnapshotPolicy{EveryEdits: *snapshotEdits, Interval: *snapshotInterval},
+	})
 
 	dashboard := &Dashboard{
 		queries:      database.Queries(),
 		database:     database,
 		graphService: graphService,
-	}
-
-	http.HandleFunc("/", dashboard.handleHome)
-	http.HandleFunc("/project/", dashboard.handleProject)
-	http.HandleFunc("/graph/", dashboard.handleGraph)
-	http.HandleFunc("/api/graph/", dashboard.handleGraphAPI)
-	http.HandleFunc("/api/project/delete/", dashboard.handleDeleteProject)
-	http.HandleFunc("/demo", dashboard.handleDemo)
-	http.HandleFunc("/api/demo/create-story", dashboard.handleCreateStoryDemo)
-	http.HandleFunc("/api/demo/add-character", dashboard.handleAddCharacterDemo)
-	http.HandleFunc("/api/demo/update-scene", dashboard.handleUpdateSceneDemo)
-	http.HandleFunc("/api/demo/create-elena-saga", dashboard.handleCreateElenaSagaDemo)
-	http.HandleFunc("/static/", dashboard.handleStatic)
+		prettyJSON:   *prettyJSON,
+	}
+
+	handle("/", dashboard.handleHome)
+	handle("/project/", dashboard.handleProject)
+	handle("/graph/", dashboard.handleGraph)
+	handle("/api/graph/", dashboard.handleGraphAPI)
+	handle("/api/export/graphml/", dashboard.handleExportGraphML)
+	handle("/api/layout/", dashboard.handleSaveLayout)
+	handle("/api/schema/", dashboard.handleEntitySchema)
+	handle("/api/entities/", dashboard.handleCreateEntity)
+	handle("/api/project/delete/", dashboard.handleDeleteProject)
+	handle("/api/project/impact/", dashboard.handleDeletionImpact)
+	handle("/api/compare-characters/", dashboard.handleCompareCharacters)
+	handle("/demo", dashboard.handleDemo)
+	handle("/api/demo/create-story", dashboard.handleCreateStoryDemo)
+	handle("/api/demo/add-character", dashboard.handleAddCharacterDemo)
+	handle("/api/demo/update-scene", dashboard.handleUpdateSceneDemo)
+	handle("/api/demo/create-elena-saga", dashboard.handleCreateElenaSagaDemo)
+	handle("/static/", dashboard.handleStatic)
 
 	fmt.Printf("Dashboard server starting on http://localhost:%s\n", *port)
 	fmt.Printf("GraphWrite Demo available at: http://localhost:%s/demo\n", *port)
 	log.Fatal(http.ListenAndServe(":"+*port, nil))
 }
 
+// withQueryCount wraps a handler to log the number of database statements each request
+// issues, for spotting N+1 query patterns. The database must come from WithQueryCounting.
+func withQueryCount(logger *monitoring.Logger, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, counter := db.WithQueryCounter(r.Context())
+		handler(w, r.WithContext(ctx))
+		logger.Info(ctx, "Request completed",
+			monitoring.String("method", r.Method),
+			monitoring.String("path", r.URL.Path),
+			monitoring.Int64("queries", counter.Count()),
+		)
+	}
+}
+
+// writeJSON writes a JSON API response with the given status. Output is compact unless
+// the dashboard runs with -pretty-json or the request asks for ?pretty=1, which indents
+// it for reading with curl.
+func (d *Dashboard) writeJSON(w http.ResponseWriter, r *http.Request, status int, value any) {
+	encoder := json.NewEncoder(w)
+	if d.prettyJSON || r.URL.Query().Get("pretty") == "1" {
+		encoder.SetIndent("", "  ")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder.Encode(value)
+}
+
+// APIError is the body of every error response from the /api/ endpoints. Code is a
+// stable machine-readable name for the failure; Details carries extra context such as
+// the fields that failed validation.
+type APIError struct {
+	Error   string `json:"error"`
+	Code    string `json:"code"`
+	Details any    `json:"details,omitempty"`
+}
+
+// Error codes used in APIError responses
+const (
+	codeBadRequest       = "bad_request"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeNotFound         = "not_found"
+	codeValidationFailed = "validation_failed"
+	codeConflict         = "conflict"
+	codeSharedEntities   = "shared_entities"
+	codeForbidden        = "forbidden"
+	codeInternal         = "internal"
+)
+
+// writeError writes an APIError with the code implied by the status
+func (d *Dashboard) writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	d.writeJSON(w, r, status, APIError{Error: message, Code: statusCode(status)})
+}
+
+// writeErrorDetails writes an APIError with an explicit code and details
+func (d *Dashboard) writeErrorDetails(w http.ResponseWriter, r *http.Request, status int, code, message string, details any) {
+	d.writeJSON(w, r, status, APIError{Error: message, Code: code, Details: details})
+}
+
+// writeServiceError writes an APIError for a failed service or database call. Typed
+// service errors choose the status; anything else is reported with fallback.
+func (d *Dashboard) writeServiceError(w http.ResponseWriter, r *http.Request, fallback int, message string, err error) {
+	status, code := serviceErrorStatus(err)
+	if status == 0 {
+		status, code = fallback, statusCode(fallback)
+	}
+	d.writeJSON(w, r, status, APIError{Error: fmt.Sprintf("%s: %v", message, err), Code: code})
+}
+
+// serviceErrorStatus maps the typed graphwrite errors to an HTTP status and error
+// code, returning 0 for errors it does not recognise
+func serviceErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows),
+		errors.Is(err, graphwrite.ErrVersionNotFound),
+		errors.Is(err, graphwrite.ErrEntityNotFound),
+		errors.Is(err, graphwrite.ErrRelationshipNotFound),
+		errors.Is(err, graphwrite.ErrTagNotFound),
+		errors.Is(err, graphwrite.ErrNoWorkingSet),
+		errors.Is(err, graphwrite.ErrNoVersionAsOf),
+		errors.Is(err, graphwrite.ErrNoEmbedding):
+		return http.StatusNotFound, codeNotFound
+	case errors.Is(err, graphwrite.ErrNoDeltas),
+		errors.Is(err, graphwrite.ErrUnknownOperation),
+		errors.Is(err, graphwrite.ErrSelfRelationship),
+		errors.Is(err, graphwrite.ErrCrossVersionRelationship),
+		errors.Is(err, graphwrite.ErrInvalidRelationshipEndpoints),
+		errors.Is(err, graphwrite.ErrInvalidEntityData),
+		errors.Is(err, graphwrite.ErrInvalidProjectStatus),
+		errors.Is(err, graphwrite.ErrInvalidProjectName):
+		return http.StatusUnprocessableEntity, codeValidationFailed
+	case errors.Is(err, graphwrite.ErrVersionNotInProject),
+		errors.Is(err, graphwrite.ErrNotAncestor),
+		errors.Is(err, graphwrite.ErrMixedSeries),
+		errors.Is(err, graphwrite.ErrAmbiguousEntity),
+		errors.Is(err, graphwrite.ErrEntityNotDeleted):
+		return http.StatusBadRequest, codeBadRequest
+	case errors.Is(err, graphwrite.ErrConcurrentModification),
+		errors.Is(err, graphwrite.ErrEntityModified),
+		errors.Is(err, graphwrite.ErrTagExists),
+		errors.Is(err, graphwrite.ErrDuplicateProjectName):
+		return http.StatusConflict, codeConflict
+	case errors.Is(err, graphwrite.ErrProjectNotOwned):
+		return http.StatusForbidden, codeForbidden
+	}
+	return 0, ""
+}
+
+// statusCode returns the default error code for an HTTP status
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return codeBadRequest
+	case http.StatusMethodNotAllowed:
+		return codeMethodNotAllowed
+	case http.StatusNotFound:
+		return codeNotFound
+	case http.StatusUnprocessableEntity:
+		return codeValidationFailed
+	case http.StatusConflict:
+		return codeConflict
+	case http.StatusForbidden:
+		return codeForbidden
+	}
+	return codeInternal
+}
+
 func (d *Dashboard) handleHome(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
 	
-	projects, err := d.queries.ListProjects(ctx)
+	projectSummaries, err := d.graphService.ListProjectsWithStats(ctx)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to list projects: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	var projectSummaries []ProjectSummary
-	for _, project := range projects {
-		versions, err := d.queries.ListGraphVersionsByProject(ctx, project.ID)
-		if err != nil {
-			log.Printf("Failed to get versions for project %s: %v", project.ID, err)
-			continue
-		}
-
-		var stats ProjectStats
-		if len(versions) > 0 {
-			// Get stats for working set version
-			for _, version := range versions {
-				if version.IsWorkingSet {
-					stats = d.getProjectStats(ctx, version.ID)
-					break
-				}
-			}
-		}
-
-		projectSummaries = append(projectSummaries, ProjectSummary{
-			Project:  project,
-			Versions: versions,
-			Stats:    stats,
-		})
-	}
+	groups := groupProjectsBySeries(projectSummaries)
 
 	tmpl := `
 <!DOCTYPE html>
@@ -162,6 +309,11 @@ func (d *Dashboard) handleHome(w http.ResponseWriter, r *http.Request) {
         .delete-confirm { display: none; background: #f8d7da; border: 1px solid #f5c6cb; color: #721c24; padding: 10px; border-radius: 4px; margin-top: 10px; }
         .delete-confirm.show { display: block; }
         .no-projects { text-align: center; color: #7f8c8d; padding: 40px; }
+        .series-title { color: #34495e; border-bottom: 2px solid #bdc3c7; padding-bottom: 5px; margin: 30px 0 15px; }
+        .status-badge { display: inline-block; font-size: 12px; padding: 3px 8px; border-radius: 10px; margin-left: 10px; vertical-align: middle; color: white; text-transform: uppercase; }
+        .status-draft { background: #95a5a6; }
+        .status-revising { background: #f39c12; }
+        .status-complete { background: #27ae60; }
     </style>
 </head>
 <body>
@@ -176,28 +328,31 @@ func (d *Dashboard) handleHome(w http.ResponseWriter, r *http.Request) {
 
         {{if .}}
             {{range .}}
+            <h2 class="series-title">{{if .Series}}Series: {{.Series}}{{else}}Standalone{{end}}</h2>
+            {{range .Projects}}
             <div class="project-card">
-                <h2 class="project-title">{{.Project.Name}}</h2>
+                <h2 class="project-title">{{.Project.Name}}<span class="status-badge status-{{.Project.Status}}">{{.Project.Status}}</span></h2>
                 <div class="project-meta">
-                    <strong>Theme:</strong> {{if .Project.Theme.Valid}}{{.Project.Theme.String}}{{else}}Not set{{end}} | 
-                    <strong>Genre:</strong> {{if .Project.Genre.Valid}}{{.Project.Genre.String}}{{else}}Not set{{end}} | 
-                    <strong>Versions:</strong> {{len .Versions}}
+                    {{with .Project.Author}}<strong>Author:</strong> {{.}} | {{end}}
+                    <strong>Theme:</strong> {{with .Project.Theme}}{{.}}{{else}}Not set{{end}} | 
+                    <strong>Genre:</strong> {{with .Project.Genre}}{{.}}{{else}}Not set{{end}} | 
+                    <strong>Versions:</strong> {{.VersionCount}}
                 </div>
-                {{if .Project.Description.Valid}}
-                <p>{{.Project.Description.String}}</p>
+                {{with .Project.Description}}
+                <p>{{.}}</p>
                 {{end}}
                 
                 <div class="stats">
                     <div class="stat">
-                        <div class="stat-value">{{.Stats.TotalEntities}}</div>
+                        <div class="stat-value">{{.EntityCount}}</div>
                         <div class="stat-label">Entities</div>
                     </div>
                     <div class="stat">
-                        <div class="stat-value">{{.Stats.TotalRelationships}}</div>
+                        <div class="stat-value">{{.RelationshipCount}}</div>
                         <div class="stat-label">Relationships</div>
                     </div>
                     <div class="stat">
-                        <div class="stat-value">{{.Stats.TotalAnnotations}}</div>
+                        <div class="stat-value">{{.AnnotationCount}}</div>
                         <div class="stat-label">Annotations</div>
                     </div>
                 </div>
@@ -210,11 +365,13 @@ func (d *Dashboard) handleHome(w http.ResponseWriter, r *http.Request) {
                 
                 <div id="delete-confirm-{{.Project.ID}}" class="delete-confirm">
                     <p><strong>⚠️ Warning:</strong> This will permanently delete the project "{{.Project.Name}}" and all its data.</p>
+                    <div id="delete-impact-{{.Project.ID}}"></div>
                     <button onclick="deleteProject('{{.Project.ID}}')" class="btn btn-danger">Confirm Delete</button>
                     <button onclick="cancelDelete('{{.Project.ID}}')" class="btn">Cancel</button>
                 </div>
             </div>
             {{end}}
+            {{end}}
         {{else}}
             <div class="no-projects">
                 <h3>No projects found</h3>
@@ -224,9 +381,39 @@ func (d *Dashboard) handleHome(w http.ResponseWriter, r *http.Request) {
     </div>
 
     <script>
-        function confirmDelete(projectId, projectName) {
+        async function confirmDelete(projectId, projectName) {
             const confirmDiv = document.getElementById('delete-confirm-' + projectId);
+            const impactDiv = document.getElementById('delete-impact-' + projectId);
+            impactDiv.textContent = 'Checking impact...';
             confirmDiv.classList.add('show');
+
+            try {
+                const response = await fetch('/api/project/impact/' + projectId);
+                const impact = await response.json();
+                if (!response.ok) {
+                    throw new Error(impact.error);
+                }
+                impactDiv.innerHTML = '';
+                if (impact.Safe) {
+                    impactDiv.textContent = 'No other project shares entities with this one.';
+                    return;
+                }
+
+                const list = document.createElement('ul');
+                impact.SharedEntities.forEach(entity => {
+                    const item = document.createElement('li');
+                    item.textContent = entity.Name + ' (' + entity.EntityType + ') would lose this project; still in: ' + entity.RemainingProjects.join(', ');
+                    list.appendChild(item);
+                });
+                impact.BrokenRelationships.forEach(rel => {
+                    const item = document.createElement('li');
+                    item.textContent = rel.ProjectName + ': ' + rel.FromEntityID + ' ' + rel.RelationshipType + ' ' + rel.ToEntityID + ' would lose its source';
+                    list.appendChild(item);
+                });
+                impactDiv.appendChild(list);
+            } catch (error) {
+                impactDiv.textContent = 'Could not analyse deletion impact: ' + error.message;
+            }
         }
 
         function cancelDelete(projectId) {
@@ -252,10 +439,10 @@ func (d *Dashboard) handleHome(w http.ResponseWriter, r *http.Request) {
                     window.location.reload();
                 } else {
                     // Handle specific error cases
-                    if (response.status === 409 && result.sharedEntities) {
+                    if (result.code === 'shared_entities') {
                         // Shared entities conflict
-                        let message = result.message + '\n\nShared entities:\n';
-                        result.sharedEntities.forEach(entity => {
+                        let message = result.details.message + '\n\nShared entities:\n';
+                        result.details.sharedEntities.forEach(entity => {
                             message += '• ' + entity + '\n';
                         });
                         alert(message);
@@ -279,12 +466,39 @@ func (d *Dashboard) handleHome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := t.Execute(w, projectSummaries); err != nil {
+	if err := t.Execute(w, groups); err != nil {
 		http.Error(w, fmt.Sprintf("Template execution error: %v", err), http.StatusInternalServerError)
 		return
 	}
 }
 
+// groupProjectsBySeries groups summaries by series name in alphabetical order,
+// with standalone projects last. Project order within a group is preserved.
+func groupProjectsBySeries(summaries []graphwrite.ProjectSummaryStats) []SeriesGroup {
+	bySeries := make(map[string][]graphwrite.ProjectSummaryStats)
+	var names []string
+	for _, summary := range summaries {
+		series := ""
+		if summary.Project.Series != nil {
+			series = *summary.Project.Series
+		}
+		if _, seen := bySeries[series]; !seen && series != "" {
+			names = append(names, series)
+		}
+		bySeries[series] = append(bySeries[series], summary)
+	}
+	sort.Strings(names)
+
+	var groups []SeriesGroup
+	for _, name := range names {
+		groups = append(groups, SeriesGroup{Series: name, Projects: bySeries[name]})
+	}
+	if standalone := bySeries[""]; len(standalone) > 0 {
+		groups = append(groups, SeriesGroup{Projects: standalone})
+	}
+	return groups
+}
+
 func (d *Dashboard) handleProject(w http.ResponseWriter, r *http.Request) {
 	projectID := r.URL.Path[len("/project/"):]
 	if projectID == "" {
@@ -292,34 +506,25 @@ func (d *Dashboard) handleProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := context.Background()
-	
-	project, err := d.queries.GetProject(ctx, projectID)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get project: %v", err), http.StatusInternalServerError)
-		return
-	}
+	ctx := r.Context()
 
-	versions, err := d.queries.ListGraphVersionsByProject(ctx, projectID)
+	overview, err := d.graphService.GetProjectOverview(ctx, projectID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get versions: %v", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to get project: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Get working set version details
-	var workingSetVersion *db.GraphVersion
-	for _, version := range versions {
-		if version.IsWorkingSet {
-			workingSetVersion = &version
-			break
-		}
+	relationshipType := r.URL.Query().Get("rel_type")
+	relationshipPage := 1
+	if page, err := strconv.Atoi(r.URL.Query().Get("rel_page")); err == nil && page > 1 {
+		relationshipPage = page
 	}
 
 	var entities []db.Entity
-	var relationships []db.Relationship
-	var entityCounts map[string]int64
-	
-	if workingSetVersion != nil {
+	relationships := &graphwrite.ListResult[*graphwrite.Relationship]{}
+	var relationshipTypes []string
+
+	if workingSetVersion := overview.WorkingSetVersion; workingSetVersion != nil {
 		// Use GraphWrite service to get entities with logical IDs
 		graphEntities, err := d.graphService.ListEntities(ctx, workingSetVersion.ID, graphwrite.EntityFilter{})
 		if err != nil {
@@ -347,30 +552,32 @@ func (d *Dashboard) handleProject(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		relationships, err = d.queries.ListRelationshipsByVersion(ctx, workingSetVersion.ID)
+		page, err := d.graphService.ListRelationshipsOfType(ctx, workingSetVersion.ID, relationshipType, graphwrite.ListOptions{
+			Limit:  relationshipPageSize,
+			Offset: (relationshipPage - 1) * relationshipPageSize,
+		})
 		if err != nil {
 			log.Printf("Failed to get relationships: %v", err)
+		} else {
+			relationships = page
 		}
 
-		entityCounts = make(map[string]int64)
-		entityTypes := []string{"Scene", "Character", "Location", "Theme", "PlotPoint", "Arc"}
-		for _, entityType := range entityTypes {
-			params := db.CountEntitiesByTypeParams{
-				VersionID:  workingSetVersion.ID,
-				EntityType: entityType,
-			}
-			count, err := d.queries.CountEntitiesByType(ctx, params)
-			if err == nil {
-				entityCounts[entityType] = count
-			}
+		relationshipTypes, err = d.queries.ListRelationshipTypesByVersion(ctx, workingSetVersion.ID)
+		if err != nil {
+			log.Printf("Failed to get relationship types: %v", err)
 		}
 	}
 
+	relationshipPages := (relationships.Total + relationshipPageSize - 1) / relationshipPageSize
+	if relationshipPages == 0 {
+		relationshipPages = 1
+	}
+
 	tmpl := `
 <!DOCTYPE html>
 <html>
 <head>
-    <title>{{.Project.Name}} - Libretto Dashboard</title>
+    <title>{{.Overview.Project.Name}} - Libretto Dashboard</title>
     <style>
         body { font-family: Arial, sans-serif; margin: 40px; background: #f5f5f5; }
         .container { max-width: 1200px; margin: 0 auto; }
@@ -381,6 +588,7 @@ func (d *Dashboard) handleProject(w http.ResponseWriter, r *http.Request) {
         .entity-type { background: #3498db; color: white; padding: 4px 8px; border-radius: 4px; font-size: 12px; margin-bottom: 10px; display: inline-block; }
         .relationship-list { list-style: none; padding: 0; }
         .relationship-list li { padding: 8px; border-bottom: 1px solid #eee; }
+        .pager { display: flex; align-items: center; gap: 10px; margin-top: 15px; }
         .btn { background: #3498db; color: white; padding: 8px 16px; text-decoration: none; border-radius: 4px; margin-right: 10px; }
         .btn:hover { background: #2980b9; }
         .stats { display: flex; gap: 20px; margin-bottom: 20px; }
@@ -392,26 +600,34 @@ func (d *Dashboard) handleProject(w http.ResponseWriter, r *http.Request) {
 <body>
     <div class="container">
         <div class="header">
-            <h1>{{.Project.Name}}</h1>
-            <p>{{if .Project.Description.Valid}}{{.Project.Description.String}}{{end}}</p>
+            <h1>{{.Overview.Project.Name}}</h1>
+            <p>{{with .Overview.Project.Description}}{{.}}{{end}}</p>
             <a href="/" class="btn">← Back to Dashboard</a>
-            <a href="/graph/{{.Project.ID}}" class="btn">Visualize Graph</a>
+            <a href="/graph/{{.Overview.Project.ID}}" class="btn">Visualize Graph</a>
         </div>
 
-        {{if .WorkingSetVersion}}
+        {{if .Overview.WorkingSetVersion}}
         <div class="section">
             <h2>Statistics</h2>
             <div class="stats">
-                {{range $type, $count := .EntityCounts}}
+                {{range $type, $count := .Overview.EntityCounts}}
                 <div class="stat">
                     <div class="stat-value">{{$count}}</div>
                     <div class="stat-label">{{$type}}</div>
                 </div>
                 {{end}}
                 <div class="stat">
-                    <div class="stat-value">{{len .Relationships}}</div>
+                    <div class="stat-value">{{.Overview.RelationshipCount}}</div>
                     <div class="stat-label">Relationships</div>
                 </div>
+                <div class="stat">
+                    <div class="stat-value">{{.Overview.AnnotationCount}}</div>
+                    <div class="stat-label">Annotations</div>
+                </div>
+                <div class="stat">
+                    <div class="stat-value">{{.Overview.OrphanCount}}</div>
+                    <div class="stat-label">Orphans</div>
+                </div>
             </div>
         </div>
 
@@ -429,17 +645,47 @@ func (d *Dashboard) handleProject(w http.ResponseWriter, r *http.Request) {
             </div>
         </div>
 
+        {{if gt (index .Overview.EntityCounts "Character") 1}}
+        <div class="section">
+            <h2>Compare Characters</h2>
+            <select id="compare-a">{{range .Entities}}{{if eq .EntityType "Character"}}<option value="{{.ID}}">{{.Name}}</option>{{end}}{{end}}</select>
+            <select id="compare-b">{{range .Entities}}{{if eq .EntityType "Character"}}<option value="{{.ID}}">{{.Name}}</option>{{end}}{{end}}</select>
+            <a href="#" class="btn" onclick="compareCharacters(); return false;">Compare</a>
+            <pre id="compare-result"></pre>
+            <script>
+                function compareCharacters() {
+                    const a = document.getElementById('compare-a').value;
+                    const b = document.getElementById('compare-b').value;
+                    fetch('/api/compare-characters/{{.Overview.Project.ID}}?a=' + encodeURIComponent(a) + '&b=' + encodeURIComponent(b))
+                        .then(response => response.text())
+                        .then(text => { document.getElementById('compare-result').textContent = text; });
+                }
+            </script>
+        </div>
+        {{end}}
+
         <div class="section">
-            <h2>Relationships ({{len .Relationships}})</h2>
+            <h2>Relationships ({{.Relationships.Total}})</h2>
+            <form method="get">
+                <select name="rel_type" onchange="this.form.submit()">
+                    <option value="">All types</option>
+                    {{range .RelationshipTypes}}<option value="{{.}}"{{if eq . $.RelationshipType}} selected{{end}}>{{.}}</option>{{end}}
+                </select>
+            </form>
             <ul class="relationship-list">
-                {{range .Relationships}}
+                {{range .Relationships.Items}}
                 <li>
                     <strong>{{.RelationshipType}}</strong>: 
                     {{.FromEntityID}} → {{.ToEntityID}}
-                    <small>({{.CreatedAt.Format "2006-01-02 15:04"}})</small>
+                    <small>({{.CreatedAt}})</small>
                 </li>
                 {{end}}
             </ul>
+            <div class="pager">
+                {{if gt .RelationshipPage 1}}<a href="?rel_type={{.RelationshipType}}&rel_page={{.PreviousPage}}" class="btn">← Previous</a>{{end}}
+                <span>Page {{.RelationshipPage}} of {{.RelationshipPages}}</span>
+                {{if lt .RelationshipPage .RelationshipPages}}<a href="?rel_type={{.RelationshipType}}&rel_page={{.NextPage}}" class="btn">Next →</a>{{end}}
+            </div>
         </div>
         {{else}}
         <div class="section">
@@ -449,14 +695,17 @@ func (d *Dashboard) handleProject(w http.ResponseWriter, r *http.Request) {
         {{end}}
 
         <div class="section">
-            <h2>Versions ({{len .Versions}})</h2>
-            {{range .Versions}}
+            <h2>Versions ({{.Overview.VersionCount}})</h2>
+            {{if gt .Overview.VersionCount (len .Overview.RecentVersions)}}
+            <p><small>Showing the {{len .Overview.RecentVersions}} most recent of {{.Overview.VersionCount}} versions.</small></p>
+            {{end}}
+            {{range .Overview.RecentVersions}}
             <div style="padding: 10px; border: 1px solid #ddd; margin-bottom: 10px; border-radius: 4px;">
-                <h4>{{if .Name.Valid}}{{.Name.String}}{{else}}Unnamed Version{{end}} 
+                <h4>{{with .Name}}{{.}}{{else}}Unnamed Version{{end}} 
                     {{if .IsWorkingSet}}<span style="background: #27ae60; color: white; padding: 2px 6px; border-radius: 3px; font-size: 10px;">WORKING SET</span>{{end}}
                 </h4>
-                <p>{{if .Description.Valid}}{{.Description.String}}{{end}}</p>
-                <small>Created: {{.CreatedAt.Format "2006-01-02 15:04"}}</small>
+                <p>{{with .Description}}{{.}}{{end}}</p>
+                <small>Created: {{.CreatedAt}}</small>
             </div>
             {{end}}
         </div>
@@ -466,19 +715,25 @@ func (d *Dashboard) handleProject(w http.ResponseWriter, r *http.Request) {
 `
 
 	data := struct {
-		Project           db.Project
-		Versions          []db.GraphVersion
-		WorkingSetVersion *db.GraphVersion
+		Overview          *graphwrite.ProjectOverview
 		Entities          []db.Entity
-		Relationships     []db.Relationship
-		EntityCounts      map[string]int64
+		Relationships     *graphwrite.ListResult[*graphwrite.Relationship]
+		RelationshipTypes []string
+		RelationshipType  string
+		RelationshipPage  int
+		RelationshipPages int
+		PreviousPage      int
+		NextPage          int
 	}{
-		Project:           project,
-		Versions:          versions,
-		WorkingSetVersion: workingSetVersion,
+		Overview:          overview,
 		Entities:          entities,
 		Relationships:     relationships,
-		EntityCounts:      entityCounts,
+		RelationshipTypes: relationshipTypes,
+		RelationshipType:  relationshipType,
+		RelationshipPage:  relationshipPage,
+		RelationshipPages: relationshipPages,
+		PreviousPage:      relationshipPage - 1,
+		NextPage:          relationshipPage + 1,
 	}
 
 	t, err := template.New("project").Parse(tmpl)
@@ -500,7 +755,7 @@ func (d *Dashboard) handleGraph(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := r.Context()
 	
 	project, err := d.queries.GetProject(ctx, projectID)
 	if err != nil {
@@ -610,7 +865,7 @@ func (d *Dashboard) handleGraph(w http.ResponseWriter, r *http.Request) {
             });
 
         function createGraph(data) {
-            // Create force simulation
+            // Create force simulation, seeded with any saved x/y positions on the nodes
             const simulation = d3.forceSimulation(data.nodes)
                 .force("link", d3.forceLink(data.links).id(d => d.id).distance(100))
                 .force("charge", d3.forceManyBody().strength(-300))
@@ -683,9 +938,21 @@ func (d *Dashboard) handleGraph(w http.ResponseWriter, r *http.Request) {
                 if (!event.active) simulation.alphaTarget(0);
                 d.fx = null;
                 d.fy = null;
+                saveNodePosition(d);
             }
         }
 
+        // Persist a dragged node's position so the next load starts from it
+        function saveNodePosition(d) {
+            fetch('/api/layout/' + projectId, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ [d.id]: { x: d.x, y: d.y } })
+            }).catch(error => {
+                console.error('Error saving layout:', error);
+            });
+        }
+
         function showNodeInfo(node) {
             const infoDiv = document.getElementById('node-info');
             infoDiv.innerHTML = ` + "`" + `
@@ -715,37 +982,52 @@ func (d *Dashboard) handleGraph(w http.ResponseWriter, r *http.Request) {
 func (d *Dashboard) handleGraphAPI(w http.ResponseWriter, r *http.Request) {
 	projectID := r.URL.Path[len("/api/graph/"):]
 	if projectID == "" {
-		http.Error(w, "Project ID required", http.StatusBadRequest)
+		d.writeError(w, r, http.StatusBadRequest, "Project ID required")
 		return
 	}
 
-	ctx := context.Background()
+	ctx := r.Context()
 	
 	// Get working set version
 	workingSet, err := d.queries.GetWorkingSetVersion(ctx, projectID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get working set: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to get working set", err)
+		return
+	}
+
+	// A focused view, e.g. ?focus=elena,marcus, renders only the selected nodes
+	if focus := r.URL.Query().Get("focus"); focus != "" {
+		d.writeFocusedGraph(w, r, workingSet.ID, strings.Split(focus, ","))
 		return
 	}
 
 	// Use GraphWrite service to get entities with logical IDs
 	entities, err := d.graphService.ListEntities(ctx, workingSet.ID, graphwrite.EntityFilter{})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get entities: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to get entities", err)
 		return
 	}
 
-	// Get relationships using database queries but map to logical IDs
-	dbRelationships, err := d.queries.ListRelationshipsByVersion(ctx, workingSet.ID)
+	// Get relationships using database queries but map to logical IDs; ?type=allies_with
+	// loads only the links of one relationship type
+	var dbRelationships []db.Relationship
+	if relationshipType := r.URL.Query().Get("type"); relationshipType != "" {
+		dbRelationships, err = d.queries.ListRelationshipsByVersionAndType(ctx, db.ListRelationshipsByVersionAndTypeParams{
+			VersionID:        workingSet.ID,
+			RelationshipType: relationshipType,
+		})
+	} else {
+		dbRelationships, err = d.queries.ListRelationshipsByVersion(ctx, workingSet.ID)
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get relationships: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to get relationships", err)
 		return
 	}
 
 	// Get database entities to create mapping from database ID to logical ID
 	dbEntities, err := d.queries.ListEntitiesByVersion(ctx, workingSet.ID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get database entities: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to get database entities", err)
 		return
 	}
 
@@ -765,41 +1047,43 @@ func (d *Dashboard) handleGraphAPI(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	relationshipTypes, err := d.graphService.ListRelationshipTypes(ctx, workingSet.ID)
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to get relationship types", err)
+		return
+	}
+
 	// Convert to graph visualization format
 	graph := GraphVisualization{
-		Nodes: make([]Node, len(entities)),
-		Links: []Link{},
+		Nodes:             make([]Node, len(entities)),
+		Links:             []Link{},
+		RelationshipTypes: relationshipTypes,
 	}
 
-	// Count connections for each logical entity ID
-	connectionCounts := make(map[string]int)
+	// Count incoming and outgoing connections for each logical entity ID
+	inDegrees := make(map[string]int)
+	outDegrees := make(map[string]int)
 	for _, rel := range dbRelationships {
 		fromLogicalID := dbToLogicalID[rel.FromEntityID]
 		toLogicalID := dbToLogicalID[rel.ToEntityID]
 		
 		if fromLogicalID != "" && toLogicalID != "" {
-			connectionCounts[fromLogicalID]++
-			connectionCounts[toLogicalID]++
+			outDegrees[fromLogicalID]++
+			inDegrees[toLogicalID]++
 		}
 	}
 
-	// Create nodes using logical IDs
-	typeGroups := map[string]int{
-		"Scene":     1,
-		"Character": 2,
-		"Location":  3,
-		"Theme":     4,
-		"PlotPoint": 5,
-		"Arc":       6,
+	layout, err := d.graphService.GetLayout(ctx, workingSet.ID)
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to get layout", err)
+		return
 	}
 
+	// Create nodes using logical IDs
 	for i, entity := range entities {
-		graph.Nodes[i] = Node{
-			ID:    entity.ID, // This is now the logical ID
-			Name:  entity.Name,
-			Type:  entity.EntityType,
-			Group: typeGroups[entity.EntityType],
-			Size:  connectionCounts[entity.ID],
+		graph.Nodes[i] = newNode(entity, inDegrees[entity.ID], outDegrees[entity.ID])
+		if position, ok := layout[entity.ID]; ok {
+			graph.Nodes[i].X, graph.Nodes[i].Y = &position.X, &position.Y
 		}
 	}
 
@@ -818,56 +1102,256 @@ func (d *Dashboard) handleGraphAPI(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(graph)
+	d.writeJSON(w, r, http.StatusOK, graph)
 }
 
-func (d *Dashboard) handleStatic(w http.ResponseWriter, r *http.Request) {
-	// Serve static files if needed
-	http.NotFound(w, r)
+// relationshipPageSize is how many relationships the project page shows at a time
+const relationshipPageSize = 25
+
+// entityTypeGroups assigns each entity type a color group in the graph view
+var entityTypeGroups = map[string]int{
+	"Scene":     1,
+	"Character": 2,
+	"Location":  3,
+	"Theme":     4,
+	"PlotPoint": 5,
+	"Arc":       6,
 }
 
-func (d *Dashboard) getProjectStats(ctx context.Context, versionID string) ProjectStats {
-	stats := ProjectStats{
-		EntityCounts:       make(map[string]int64),
-		RelationshipCounts: make(map[string]int),
+// writeFocusedGraph writes the sub-graph of the selected entities and the edges among them
+func (d *Dashboard) writeFocusedGraph(w http.ResponseWriter, r *http.Request, versionID string, focus []string) {
+	entities, err := d.graphService.GetEntities(r.Context(), versionID, focus)
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to get entities", err)
+		return
+	}
+	relationships, err := d.graphService.RelationshipsAmong(r.Context(), versionID, focus)
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to get relationships", err)
+		return
 	}
 
-	// Get entity counts
-	entityTypes := []string{"Scene", "Character", "Location", "Theme", "PlotPoint", "Arc"}
-	for _, entityType := range entityTypes {
-		params := db.CountEntitiesByTypeParams{
-			VersionID:  versionID,
-			EntityType: entityType,
-		}
-		count, err := d.queries.CountEntitiesByType(ctx, params)
-		if err == nil {
-			stats.EntityCounts[entityType] = count
-			stats.TotalEntities += count
+	graph := GraphVisualization{Nodes: []Node{}, Links: []Link{}}
+	inDegrees := make(map[string]int)
+	outDegrees := make(map[string]int)
+	for _, rel := range relationships {
+		outDegrees[rel.FromEntityID]++
+		inDegrees[rel.ToEntityID]++
+		graph.Links = append(graph.Links, Link{
+			Source: rel.FromEntityID,
+			Target: rel.ToEntityID,
+			Type:   rel.RelationshipType,
+			Value:  1,
+		})
+	}
+	added := make(map[string]bool, len(entities))
+	for _, entity := range entities {
+		if entity == nil || added[entity.ID] {
+			continue // not in the working set, or selected twice
 		}
+		added[entity.ID] = true
+		graph.Nodes = append(graph.Nodes, newNode(entity, inDegrees[entity.ID], outDegrees[entity.ID]))
 	}
 
-	// Get relationship counts
-	relationships, err := d.queries.ListRelationshipsByVersion(ctx, versionID)
-	if err == nil {
-		stats.TotalRelationships = len(relationships)
-		for _, rel := range relationships {
-			stats.RelationshipCounts[rel.RelationshipType]++
-		}
+	d.writeJSON(w, r, http.StatusOK, graph)
+}
+
+// newNode builds a graph node for an entity with its relationship counts
+func newNode(entity *graphwrite.Entity, inDegree, outDegree int) Node {
+	return Node{
+		ID:        entity.ID, // logical ID
+		Name:      entity.Name,
+		Type:      entity.EntityType,
+		Group:     entityTypeGroups[entity.EntityType],
+		Size:      inDegree + outDegree,
+		Degree:    inDegree + outDegree,
+		InDegree:  inDegree,
+		OutDegree: outDegree,
 	}
+}
 
-	// Get annotation count (approximate - would need to query all entities)
-	entities, err := d.queries.ListEntitiesByVersion(ctx, versionID)
-	if err == nil {
-		for _, entity := range entities {
-			annotations, err := d.queries.ListAnnotationsByEntity(ctx, entity.ID)
-			if err == nil {
-				stats.TotalAnnotations += len(annotations)
-			}
-		}
+// handleSaveLayout stores dragged node positions for a project's working set,
+// e.g. POST /api/layout/{projectID} with {"elena": {"x": 120, "y": 80}}
+func (d *Dashboard) handleSaveLayout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		d.writeError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
+		return
 	}
 
-	return stats
+	projectID := r.URL.Path[len("/api/layout/"):]
+	if projectID == "" {
+		d.writeError(w, r, http.StatusBadRequest, "Project ID required")
+		return
+	}
+
+	var positions map[string]graphwrite.NodePosition
+	if err := json.NewDecoder(r.Body).Decode(&positions); err != nil {
+		d.writeError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	ctx := r.Context()
+
+	workingSet, err := d.queries.GetWorkingSetVersion(ctx, projectID)
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to get working set", err)
+		return
+	}
+
+	if err := d.graphService.SaveLayout(ctx, workingSet.ID, positions); err != nil {
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to save layout", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEntitySchema serves the JSON Schema for an entity type's data,
+// e.g. /api/schema/Scene
+func (d *Dashboard) handleEntitySchema(w http.ResponseWriter, r *http.Request) {
+	entityType := r.URL.Path[len("/api/schema/"):]
+	schema, err := types.EntitySchema(types.EntityType(entityType))
+	if err != nil {
+		d.writeError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+
+	d.writeJSON(w, r, http.StatusOK, schema)
+}
+
+// CreateEntityRequest is the body of a create entity request
+type CreateEntityRequest struct {
+	EntityType string         `json:"entity_type"`
+	EntityID   string         `json:"entity_id"`
+	Fields     map[string]any `json:"fields"`
+}
+
+// handleCreateEntity creates an entity in a project's working set once its fields
+// match the entity type's schema, e.g. POST /api/entities/{projectID}. Payloads that
+// do not match are rejected with 422 and the mismatching fields as the error details.
+func (d *Dashboard) handleCreateEntity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		d.writeError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	projectID := r.URL.Path[len("/api/entities/"):]
+	if projectID == "" {
+		d.writeError(w, r, http.StatusBadRequest, "Project ID required")
+		return
+	}
+
+	var req CreateEntityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		d.writeError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.EntityType == "" || req.EntityID == "" {
+		d.writeError(w, r, http.StatusBadRequest, "entity_type and entity_id required")
+		return
+	}
+	if _, err := types.EntitySchema(types.EntityType(req.EntityType)); err != nil {
+		d.writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if fieldErrors := types.ValidateEntityFields(types.EntityType(req.EntityType), req.Fields); len(fieldErrors) > 0 {
+		d.writeErrorDetails(w, r, http.StatusUnprocessableEntity, codeValidationFailed, "Entity fields do not match the schema", fieldErrors)
+		return
+	}
+
+	response, err := d.graphService.ApplyToProject(r.Context(), projectID, []*graphwrite.Delta{{
+		Operation:  "create",
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		Fields:     req.Fields,
+	}})
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to create entity", err)
+		return
+	}
+
+	d.writeJSON(w, r, http.StatusCreated, map[string]string{
+		"entity_id":        req.EntityID,
+		"graph_version_id": response.GraphVersionID,
+	})
+}
+
+// handleExportGraphML downloads a project's working set as GraphML,
+// e.g. /api/export/graphml/{projectID}
+func (d *Dashboard) handleExportGraphML(w http.ResponseWriter, r *http.Request) {
+	projectID := r.URL.Path[len("/api/export/graphml/"):]
+	if projectID == "" {
+		d.writeError(w, r, http.StatusBadRequest, "Project ID required")
+		return
+	}
+
+	ctx := r.Context()
+
+	workingSet, err := d.queries.GetWorkingSetVersion(ctx, projectID)
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to get working set", err)
+		return
+	}
+
+	output, err := d.graphService.ExportGraphML(ctx, workingSet.ID)
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to export graph", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", projectID+".graphml"))
+	w.Write(output)
+}
+
+// handleCompareCharacters compares two characters in a project's working set,
+// e.g. /api/compare-characters/{projectID}?a=elena&b=marcus
+func (d *Dashboard) handleCompareCharacters(w http.ResponseWriter, r *http.Request) {
+	projectID := r.URL.Path[len("/api/compare-characters/"):]
+	idA := r.URL.Query().Get("a")
+	idB := r.URL.Query().Get("b")
+	if projectID == "" || idA == "" || idB == "" {
+		d.writeError(w, r, http.StatusBadRequest, "Project ID and characters a and b required")
+		return
+	}
+
+	ctx := r.Context()
+
+	workingSet, err := d.queries.GetWorkingSetVersion(ctx, projectID)
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to get working set", err)
+		return
+	}
+
+	comparison, err := d.graphService.CompareCharacters(ctx, workingSet.ID, idA, idB)
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusBadRequest, "Failed to compare characters", err)
+		return
+	}
+
+	d.writeJSON(w, r, http.StatusOK, comparison)
+}
+
+func (d *Dashboard) handleDeletionImpact(w http.ResponseWriter, r *http.Request) {
+	projectID := r.URL.Path[len("/api/project/impact/"):]
+	if projectID == "" {
+		d.writeError(w, r, http.StatusBadRequest, "Project ID required")
+		return
+	}
+
+	impact, err := d.graphService.DeletionImpact(r.Context(), projectID)
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusNotFound, "Failed to analyse deletion impact", err)
+		return
+	}
+
+	d.writeJSON(w, r, http.StatusOK, impact)
+}
+
+func (d *Dashboard) handleStatic(w http.ResponseWriter, r *http.Request) {
+	// Serve static files if needed
+	http.NotFound(w, r)
 }
 
 // Demo handlers to showcase GraphWrite service functionality
@@ -1222,23 +1706,32 @@ func (d *Dashboard) handleDemo(w http.ResponseWriter, r *http.Request) {
 
 func (d *Dashboard) handleCreateStoryDemo(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		d.writeError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	ctx := context.Background()
+	d.demoMu.Lock()
+	defer d.demoMu.Unlock()
+
+	ctx := r.Context()
+
+	// Create a new project, numbering repeat demos so project names stay unique
+	name, err := d.graphService.UniqueProjectName(ctx, "GraphWrite Demo Story")
+	if err != nil {
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to name project", err)
+		return
+	}
 
-	// Create a new project
 	projectID := uuid.New().String()
-	_, err := d.queries.CreateProject(ctx, db.CreateProjectParams{
+	_, err = d.queries.CreateProject(ctx, db.CreateProjectParams{
 		ID:          projectID,
-		Name:        "GraphWrite Demo Story",
+		Name:        name,
 		Theme:       sql.NullString{String: "Adventure", Valid: true},
 		Genre:       sql.NullString{String: "Fantasy", Valid: true},
 		Description: sql.NullString{String: "A story created to demonstrate the GraphWrite service", Valid: true},
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create project: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to create project", err)
 		return
 	}
 
@@ -1252,7 +1745,7 @@ func (d *Dashboard) handleCreateStoryDemo(w http.ResponseWriter, r *http.Request
 		IsWorkingSet: true,
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create initial version: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to create initial version", err)
 		return
 	}
 
@@ -1300,7 +1793,7 @@ func (d *Dashboard) handleCreateStoryDemo(w http.ResponseWriter, r *http.Request
 		},
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to apply deltas: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to apply deltas", err)
 		return
 	}
 
@@ -1310,14 +1803,14 @@ func (d *Dashboard) handleCreateStoryDemo(w http.ResponseWriter, r *http.Request
 		ProjectID: projectID,
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update working set: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to update working set", err)
 		return
 	}
 
 	// Get the created entities for response
 	entities, err := d.graphService.ListEntities(ctx, response.GraphVersionID, graphwrite.EntityFilter{})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to list entities: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to list entities", err)
 		return
 	}
 
@@ -1342,16 +1835,18 @@ func (d *Dashboard) handleCreateStoryDemo(w http.ResponseWriter, r *http.Request
 		"applied":       response.Applied,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	d.writeJSON(w, r, http.StatusOK, result)
 }
 
 func (d *Dashboard) handleAddCharacterDemo(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		d.writeError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
+	d.demoMu.Lock()
+	defer d.demoMu.Unlock()
+
 	var req struct {
 		ProjectID       string `json:"projectId"`
 		ParentVersionID string `json:"parentVersionId"`
@@ -1359,11 +1854,11 @@ func (d *Dashboard) handleAddCharacterDemo(w http.ResponseWriter, r *http.Reques
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		d.writeError(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	ctx := context.Background()
+	ctx := r.Context()
 
 	// Add a new character and create relationships
 	villainID := uuid.New().String()
@@ -1420,7 +1915,7 @@ func (d *Dashboard) handleAddCharacterDemo(w http.ResponseWriter, r *http.Reques
 		},
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to apply deltas: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to apply deltas", err)
 		return
 	}
 
@@ -1430,14 +1925,14 @@ func (d *Dashboard) handleAddCharacterDemo(w http.ResponseWriter, r *http.Reques
 		ProjectID: req.ProjectID,
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update working set: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to update working set", err)
 		return
 	}
 
 	// Get updated entities
 	entities, err := d.graphService.ListEntities(ctx, response.GraphVersionID, graphwrite.EntityFilter{})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to list entities: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to list entities", err)
 		return
 	}
 
@@ -1466,16 +1961,18 @@ func (d *Dashboard) handleAddCharacterDemo(w http.ResponseWriter, r *http.Reques
 		"applied":       response.Applied,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	d.writeJSON(w, r, http.StatusOK, result)
 }
 
 func (d *Dashboard) handleUpdateSceneDemo(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		d.writeError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
+	d.demoMu.Lock()
+	defer d.demoMu.Unlock()
+
 	var req struct {
 		ProjectID       string `json:"projectId"`
 		ParentVersionID string `json:"parentVersionId"`
@@ -1483,14 +1980,18 @@ func (d *Dashboard) handleUpdateSceneDemo(w http.ResponseWriter, r *http.Request
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		d.writeError(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	ctx := context.Background()
+	ctx := r.Context()
 
-	// Update the scene with more detailed content
-	response, err := d.graphService.Apply(ctx, &graphwrite.ApplyRequest{
+	// An If-Match header carries the scene's ContentHash as last read; a scene
+	// changed since then is rejected rather than overwritten
+	expectedHash := strings.Trim(r.Header.Get("If-Match"), `"`)
+
+	// Update the scene with more detailed content, editing the working set directly
+	response, err := d.graphService.ApplyAndAdvance(ctx, &graphwrite.ApplyRequest{
 		ParentVersionID: req.ParentVersionID,
 		Deltas: []*graphwrite.Delta{
 			{
@@ -1512,28 +2013,23 @@ Elena's hand instinctively moved to the leather satchel containing her research
 					"conflict":  "Elena vs Mordak - competing for the same artifact",
 					"revision":  2,
 				},
+				ExpectedContentHash: expectedHash,
 			},
 		},
 	})
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to apply deltas: %v", err), http.StatusInternalServerError)
+	if errors.Is(err, graphwrite.ErrEntityModified) {
+		d.writeServiceError(w, r, http.StatusConflict, "Scene was modified by someone else", err)
 		return
 	}
-
-	// Update the working set to point to the new version
-	err = d.queries.SetWorkingSet(ctx, db.SetWorkingSetParams{
-		ID:        response.GraphVersionID,
-		ProjectID: req.ProjectID,
-	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update working set: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to apply deltas", err)
 		return
 	}
 
 	// Get updated entities
 	entities, err := d.graphService.ListEntities(ctx, response.GraphVersionID, graphwrite.EntityFilter{})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to list entities: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to list entities", err)
 		return
 	}
 
@@ -1562,21 +2058,23 @@ Elena's hand instinctively moved to the leather satchel containing her research
 		"applied":       response.Applied,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	d.writeJSON(w, r, http.StatusOK, result)
 }
 
 func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		d.writeError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	ctx := context.Background()
+	d.demoMu.Lock()
+	defer d.demoMu.Unlock()
+
+	ctx := r.Context()
 
 	// Clean existing demo data first
 	if err := d.cleanDemoData(ctx); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to clean existing data: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to clean existing data", err)
 		return
 	}
 
@@ -1601,7 +2099,7 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		Description: sql.NullString{String: "Elena begins her journey as a young archaeologist discovering ancient mysteries", Valid: true},
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create Book 1 project: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to create Book 1 project", err)
 		return
 	}
 
@@ -1619,7 +2117,7 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		IsWorkingSet: true,
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create Book 1 version: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to create Book 1 version", err)
 		return
 	}
 
@@ -1684,7 +2182,7 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		},
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create Book 1 characters: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to create Book 1 characters", err)
 		return
 	}
 
@@ -1701,7 +2199,7 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		ProjectID: book1ID,
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update Book 1 working set: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to update Book 1 working set", err)
 		return
 	}
 
@@ -1717,7 +2215,7 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		Description: sql.NullString{String: "Elena faces the growing darkness and becomes a war leader", Valid: true},
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create Book 2 project: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to create Book 2 project", err)
 		return
 	}
 
@@ -1735,27 +2233,27 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		IsWorkingSet: true,
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create Book 2 version: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to create Book 2 version", err)
 		return
 	}
 
 	// Import Elena from Book 1
 	_, err = service.ImportEntity(ctx, book2VersionID, book1ID, elenaID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to import Elena to Book 2: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to import Elena to Book 2", err)
 		return
 	}
 
 	// Import Marcus and location
 	_, err = service.ImportEntity(ctx, book2VersionID, book1ID, marcusID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to import Marcus to Book 2: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to import Marcus to Book 2", err)
 		return
 	}
 
 	_, err = service.ImportEntity(ctx, book2VersionID, book1ID, "ancient-temple-of-echoes")
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to import temple to Book 2: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to import temple to Book 2", err)
 		return
 	}
 
@@ -1781,7 +2279,7 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		},
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to evolve Elena in Book 2: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to evolve Elena in Book 2", err)
 		return
 	}
 
@@ -1798,7 +2296,7 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		ProjectID: book2ID,
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update Book 2 working set: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to update Book 2 working set", err)
 		return
 	}
 
@@ -1814,7 +2312,7 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		Description: sql.NullString{String: "Elena fulfills her destiny as the Lightbringer of the Seven Realms", Valid: true},
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create Book 3 project: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to create Book 3 project", err)
 		return
 	}
 
@@ -1832,14 +2330,14 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		IsWorkingSet: true,
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create Book 3 version: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to create Book 3 version", err)
 		return
 	}
 
 	// Import Elena from Book 2 (she carries her evolution)
 	_, err = service.ImportEntity(ctx, book3VersionID, book2ID, elenaID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to import Elena to Book 3: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to import Elena to Book 3", err)
 		return
 	}
 
@@ -1879,7 +2377,7 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		},
 	})
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to complete Elena's arc in Book 3: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to complete Elena's arc in Book 3", err)
 		return
 	}
 
@@ -1893,7 +2391,7 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 	// Get shared entities count
 	sharedEntities, err := service.ListSharedEntities(ctx)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to list shared entities: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to list shared entities", err)
 		return
 	}
 
@@ -1907,8 +2405,7 @@ func (d *Dashboard) handleCreateElenaSagaDemo(w http.ResponseWriter, r *http.Req
 		"message":         "Elena Stormwind's saga created successfully! Elena's identity preserved across all 3 books.",
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	d.writeJSON(w, r, http.StatusOK, result)
 }
 
 func (d *Dashboard) cleanDemoData(ctx context.Context) error {
@@ -1945,57 +2442,45 @@ func (d *Dashboard) cleanDemoData(ctx context.Context) error {
 // handleDeleteProject handles project deletion requests
 func (d *Dashboard) handleDeleteProject(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "DELETE" && r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		d.writeError(w, r, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	projectID := r.URL.Path[len("/api/project/delete/"):]
 	if projectID == "" {
-		http.Error(w, "Project ID required", http.StatusBadRequest)
+		d.writeError(w, r, http.StatusBadRequest, "Project ID required")
 		return
 	}
 
-	ctx := context.Background()
+	ctx := r.Context()
 
 	// Verify project exists
 	project, err := d.queries.GetProject(ctx, projectID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Project not found: %v", err), http.StatusNotFound)
+		d.writeServiceError(w, r, http.StatusNotFound, "Project not found", err)
 		return
 	}
 
-	// Check if project has shared entities (optional safety check)
-	sharedEntities, err := d.graphService.ListSharedEntities(ctx)
-	if err == nil {
+	// Refuse to delete projects whose entities live on in other projects
+	impact, err := d.graphService.DeletionImpact(ctx, projectID)
+	if err == nil && !impact.Safe {
 		var sharedInThisProject []string
-		for _, entity := range sharedEntities {
-			for _, projectName := range entity.Projects {
-				if projectName == project.Name {
-					sharedInThisProject = append(sharedInThisProject, entity.Name)
-					break
-				}
-			}
-		}
-		
-		// If there are shared entities, include a warning in the response
-		if len(sharedInThisProject) > 0 {
-			response := map[string]any{
-				"success": false,
-				"error":   "Cannot delete project with shared entities",
-				"message": fmt.Sprintf("Project '%s' contains %d shared entities that appear in other projects. Delete those projects first or remove the shared entities.", project.Name, len(sharedInThisProject)),
-				"sharedEntities": sharedInThisProject,
-			}
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusConflict)
-			json.NewEncoder(w).Encode(response)
-			return
+		for _, entity := range impact.SharedEntities {
+			sharedInThisProject = append(sharedInThisProject, entity.Name)
 		}
+
+		d.writeErrorDetails(w, r, http.StatusConflict, codeSharedEntities, "Cannot delete project with shared entities", map[string]any{
+			"message":        fmt.Sprintf("Project '%s' contains %d shared entities that appear in other projects. Delete those projects first or remove the shared entities.", project.Name, len(sharedInThisProject)),
+			"sharedEntities": sharedInThisProject,
+			"impact":         impact,
+		})
+		return
 	}
 
 	// Delete the project (CASCADE will handle related data)
 	err = d.queries.DeleteProject(ctx, projectID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to delete project: %v", err), http.StatusInternalServerError)
+		d.writeServiceError(w, r, http.StatusInternalServerError, "Failed to delete project", err)
 		return
 	}
 
@@ -2007,6 +2492,5 @@ func (d *Dashboard) handleDeleteProject(w http.ResponseWriter, r *http.Request)
 		"projectName": project.Name,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	d.writeJSON(w, r, http.StatusOK, response)
 }
\ No newline at end of file
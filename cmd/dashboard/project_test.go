@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+func TestDashboard_ProjectPageRendersOverview(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+
+	// Populate a project through the demo endpoint
+	createReq := httptest.NewRequest("POST", "/api/demo/create-story", nil)
+	createW := httptest.NewRecorder()
+	dashboard.handleCreateStoryDemo(createW, createReq)
+
+	var result map[string]any
+	if err := json.NewDecoder(createW.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	projectID := result["projectId"].(string)
+
+	req := httptest.NewRequest("GET", "/project/"+projectID, nil)
+	w := httptest.NewRecorder()
+	dashboard.handleProject(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{"Statistics", "Annotations", "Orphans", "WORKING SET"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected project page to contain %q", want)
+		}
+	}
+}
+
+func TestDashboard_ProjectPageLabelsRecentVersions(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+	setupEntityProject(t, dashboard)
+
+	for i := 0; i < 11; i++ {
+		if _, err := dashboard.graphService.ApplyToProject(context.Background(), "forms", []*graphwrite.Delta{
+			{Operation: "create", EntityType: "Character", EntityID: fmt.Sprintf("character-%d", i), Fields: map[string]any{"name": "Character"}},
+		}); err != nil {
+			t.Fatalf("ApplyToProject failed: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/project/forms", nil)
+	w := httptest.NewRecorder()
+	dashboard.handleProject(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Versions (12)") || !strings.Contains(body, "Showing the 10 most recent of 12 versions.") {
+		t.Errorf("Expected the capped version list to be labelled, got %s", body)
+	}
+}
+
+func TestDashboard_ProjectPageNotFound(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+
+	req := httptest.NewRequest("GET", "/project/missing", nil)
+	w := httptest.NewRecorder()
+	dashboard.handleProject(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+func TestDashboard_CompareCharactersAPI(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+
+	createReq := httptest.NewRequest("POST", "/api/demo/create-elena-saga", nil)
+	createW := httptest.NewRecorder()
+	dashboard.handleCreateElenaSagaDemo(createW, createReq)
+	if createW.Code != http.StatusOK {
+		t.Fatalf("Failed to create saga: %s", createW.Body.String())
+	}
+
+	projects, err := dashboard.queries.ListProjects(context.Background())
+	if err != nil || len(projects) == 0 {
+		t.Fatalf("Expected saga projects, got %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/compare-characters/"+projects[0].ID+"?a=elena-stormwind-protagonist&b=marcus-ironforge-companion", nil)
+	w := httptest.NewRecorder()
+	dashboard.handleCompareCharacters(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var comparison map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&comparison); err != nil {
+		t.Fatalf("Failed to decode comparison: %v", err)
+	}
+	if comparison["CharacterA"] == nil || comparison["CharacterB"] == nil {
+		t.Errorf("Expected both characters in comparison, got %v", comparison)
+	}
+
+	// Missing parameters are rejected
+	req = httptest.NewRequest("GET", "/api/compare-characters/"+projects[0].ID+"?a=elena-stormwind-protagonist", nil)
+	w = httptest.NewRecorder()
+	dashboard.handleCompareCharacters(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestDashboard_HomeGroupsProjectsBySeries(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+	ctx := context.Background()
+
+	series := "The Elena Saga"
+	author := "A. Writer"
+	projects := []struct {
+		id       string
+		name     string
+		metadata graphwrite.ProjectMetadata
+	}{
+		{"book-one", "Book One", graphwrite.ProjectMetadata{Status: graphwrite.ProjectStatusComplete, Author: &author, Series: &series}},
+		{"standalone", "Standalone Novella", graphwrite.ProjectMetadata{Status: graphwrite.ProjectStatusDraft}},
+		{"book-two", "Book Two", graphwrite.ProjectMetadata{Status: graphwrite.ProjectStatusRevising, Author: &author, Series: &series}},
+	}
+	for _, p := range projects {
+		if _, err := dashboard.queries.CreateProject(ctx, db.CreateProjectParams{ID: p.id, Name: p.name}); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+		if _, err := dashboard.graphService.UpdateProjectMetadata(ctx, p.id, p.metadata); err != nil {
+			t.Fatalf("UpdateProjectMetadata failed: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	dashboard.handleHome(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body := w.Body.String()
+	seriesHeading := strings.Index(body, "Series: The Elena Saga")
+	standaloneHeading := strings.Index(body, "Standalone</h2>")
+	if seriesHeading < 0 || standaloneHeading < 0 {
+		t.Fatalf("Expected series and standalone headings in home page")
+	}
+
+	// Both series books sit under the series heading, before the standalone group
+	for _, name := range []string{"Book One", "Book Two"} {
+		idx := strings.Index(body, name)
+		if idx < seriesHeading || idx > standaloneHeading {
+			t.Errorf("Expected %q to be grouped under its series", name)
+		}
+	}
+	if idx := strings.Index(body, "Standalone Novella"); idx < standaloneHeading {
+		t.Errorf("Expected the standalone project after the standalone heading")
+	}
+
+	for _, badge := range []string{"status-complete", "status-revising", "status-draft"} {
+		if !strings.Contains(body, badge) {
+			t.Errorf("Expected status badge %q", badge)
+		}
+	}
+}
+
+func TestDashboard_DeletionImpactOnElenaSaga(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+
+	createReq := httptest.NewRequest("POST", "/api/demo/create-elena-saga", nil)
+	createW := httptest.NewRecorder()
+	dashboard.handleCreateElenaSagaDemo(createW, createReq)
+	if createW.Code != http.StatusOK {
+		t.Fatalf("Failed to create saga: %s", createW.Body.String())
+	}
+
+	projects, err := dashboard.queries.ListProjects(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list projects: %v", err)
+	}
+	var book1ID string
+	for _, project := range projects {
+		if project.Name == "Book 1: The Lost Artifact" {
+			book1ID = project.ID
+		}
+	}
+	if book1ID == "" {
+		t.Fatal("Expected Book 1 in the saga")
+	}
+
+	req := httptest.NewRequest("GET", "/api/project/impact/"+book1ID, nil)
+	w := httptest.NewRecorder()
+	dashboard.handleDeletionImpact(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var impact graphwrite.DeletionImpact
+	if err := json.NewDecoder(w.Body).Decode(&impact); err != nil {
+		t.Fatalf("Failed to decode impact: %v", err)
+	}
+	if impact.Safe {
+		t.Error("Expected deleting Book 1 to be unsafe")
+	}
+
+	impacted := make(map[string]bool)
+	for _, entity := range impact.SharedEntities {
+		impacted[entity.LogicalID] = true
+	}
+	for _, want := range []string{"elena-stormwind-protagonist", "marcus-ironforge-companion"} {
+		if !impacted[want] {
+			t.Errorf("Expected %s to be reported as impacted", want)
+		}
+	}
+
+	// The delete endpoint refuses and carries the same impact
+	deleteReq := httptest.NewRequest("DELETE", "/api/project/delete/"+book1ID, nil)
+	deleteW := httptest.NewRecorder()
+	dashboard.handleDeleteProject(deleteW, deleteReq)
+	if deleteW.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", deleteW.Code)
+	}
+}
+
+func TestDashboard_QueryCountForHome(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+	counted := dashboard.database.WithQueryCounting()
+	dashboard.queries = counted.Queries()
+	dashboard.graphService = graphwrite.NewService(counted)
+
+	for _, id := range []string{"book-one", "book-two", "book-three"} {
+		if _, err := dashboard.queries.CreateProject(context.Background(), db.CreateProjectParams{ID: id, Name: id}); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+	}
+
+	ctx, counter := db.WithQueryCounter(context.Background())
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	dashboard.handleHome(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if counter.Count() != 2 {
+		t.Errorf("Expected the home page to issue 2 queries regardless of project count, got %d", counter.Count())
+	}
+}
+
+func TestDashboard_ProjectPagePaginatesRelationships(t *testing.T) {
+	dashboard := setupTestDashboard(t)
+	ctx := context.Background()
+
+	if _, err := dashboard.queries.CreateProject(ctx, db.CreateProjectParams{ID: "dense", Name: "Dense Story"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := dashboard.queries.CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: "dense-root", ProjectID: "dense", IsWorkingSet: true}); err != nil {
+		t.Fatalf("Failed to create version: %v", err)
+	}
+
+	// One scene featuring more characters than fit on a page, plus one location edge
+	deltas := []*graphwrite.Delta{
+		{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}},
+		{
+			Operation:  "create",
+			EntityType: "Scene",
+			EntityID:   "scene-1",
+			Fields:     map[string]any{"name": "Crowded Tavern"},
+			Relationships: []*graphwrite.RelationshipDelta{
+				{Operation: "create", FromEntityID: "scene-1", ToEntityID: "tavern", RelationshipType: "occurs_at", Properties: map[string]any{}},
+			},
+		},
+	}
+	for i := 0; i < relationshipPageSize+5; i++ {
+		characterID := fmt.Sprintf("character-%d", i)
+		deltas = append(deltas, &graphwrite.Delta{
+			Operation:  "create",
+			EntityType: "Character",
+			EntityID:   characterID,
+			Fields:     map[string]any{"name": fmt.Sprintf("Character %d", i)},
+			Relationships: []*graphwrite.RelationshipDelta{
+				{Operation: "create", FromEntityID: "scene-1", ToEntityID: characterID, RelationshipType: "features", Properties: map[string]any{}},
+			},
+		})
+	}
+	if _, err := dashboard.graphService.ApplyAndAdvance(ctx, &graphwrite.ApplyRequest{ParentVersionID: "dense-root", Deltas: deltas}); err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	render := func(query string) string {
+		req := httptest.NewRequest("GET", "/project/dense"+query, nil)
+		w := httptest.NewRecorder()
+		dashboard.handleProject(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		return w.Body.String()
+	}
+
+	body := render("")
+	if listed := strings.Count(body, "scene-1 → "); listed != relationshipPageSize {
+		t.Errorf("Expected %d relationships on the first page, got %d", relationshipPageSize, listed)
+	}
+	if !strings.Contains(body, "Relationships (31)") || !strings.Contains(body, "Page 1 of 2") || !strings.Contains(body, "rel_page=2") {
+		t.Errorf("Expected the total and a link to page 2")
+	}
+
+	body = render("?rel_page=2")
+	if listed := strings.Count(body, "scene-1 → "); listed != 6 {
+		t.Errorf("Expected the 6 remaining relationships on page 2, got %d", listed)
+	}
+
+	body = render("?rel_type=occurs_at")
+	if listed := strings.Count(body, "scene-1 → "); listed != 1 || !strings.Contains(body, "scene-1 → tavern") {
+		t.Errorf("Expected only the occurs_at edge, got %d relationships", listed)
+	}
+	if !strings.Contains(body, "Page 1 of 1") {
+		t.Errorf("Expected a single page for the filtered list")
+	}
+}
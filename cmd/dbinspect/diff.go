@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+// resolveDiffVersions picks the two versions to compare. Explicit versions win; with
+// a project, a missing second version defaults to the working set and a missing first
+// version to the working set's parent.
+func resolveDiffVersions(ctx context.Context, queries *db.Queries, projectID, fromVersionID, toVersionID string) (string, string, error) {
+	if fromVersionID != "" && toVersionID != "" {
+		return fromVersionID, toVersionID, nil
+	}
+	if projectID == "" {
+		return "", "", fmt.Errorf("diff needs -version and -version2, or -project")
+	}
+
+	if toVersionID == "" {
+		workingSet, err := queries.GetWorkingSetVersion(ctx, projectID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get working set: %w", err)
+		}
+		toVersionID = workingSet.ID
+	}
+	if fromVersionID == "" {
+		version, err := queries.GetGraphVersion(ctx, toVersionID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get version %s: %w", toVersionID, err)
+		}
+		if !version.ParentVersionID.Valid {
+			return "", "", fmt.Errorf("version %s has no parent to compare with", toVersionID)
+		}
+		fromVersionID = version.ParentVersionID.String
+	}
+
+	return fromVersionID, toVersionID, nil
+}
+
+func showDiff(ctx context.Context, dbPath, projectID, fromVersionID, toVersionID string) {
+	database, err := db.NewDatabase(dbPath)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	fromVersionID, toVersionID, err = resolveDiffVersions(ctx, database.Queries(), projectID, fromVersionID, toVersionID)
+	if err != nil {
+		log.Fatalf("Failed to choose versions: %v", err)
+	}
+
+	diff, err := graphwrite.NewService(database).Diff(ctx, fromVersionID, toVersionID)
+	if err != nil {
+		log.Fatalf("Failed to diff versions: %v", err)
+	}
+	printDiff(os.Stdout, diff)
+}
+
+// printDiff writes one tab-separated line per change, entities before relationships,
+// so the output can be filtered with standard tools
+func printDiff(out io.Writer, diff *graphwrite.GraphDiff) {
+	fmt.Fprintln(out, "=== DIFF ===")
+	fmt.Fprintf(out, "From: %s\nTo:   %s\n\n", diff.FromVersionID, diff.ToVersionID)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Change\tKind\tKey\tDetails")
+	for _, entity := range diff.AddedEntities {
+		fmt.Fprintf(w, "added\tentity\t%s\t%s %q\n", entity.ID, entity.EntityType, entity.Name)
+	}
+	for _, entity := range diff.RemovedEntities {
+		fmt.Fprintf(w, "removed\tentity\t%s\t%s %q\n", entity.ID, entity.EntityType, entity.Name)
+	}
+	for _, change := range diff.ModifiedEntities {
+		fmt.Fprintf(w, "modified\tentity\t%s\t%s\n", change.LogicalID, changedFields(change.Fields))
+	}
+	for _, rel := range diff.AddedRelationships {
+		fmt.Fprintf(w, "added\trelationship\t%s -%s-> %s\t\n", rel.FromEntityID, rel.RelationshipType, rel.ToEntityID)
+	}
+	for _, rel := range diff.RemovedRelationships {
+		fmt.Fprintf(w, "removed\trelationship\t%s -%s-> %s\t\n", rel.FromEntityID, rel.RelationshipType, rel.ToEntityID)
+	}
+	for _, change := range diff.ModifiedRelationships {
+		fmt.Fprintf(w, "modified\trelationship\t%s\t%s\n", change.Key, changedFields(change.Properties))
+	}
+	w.Flush()
+
+	fmt.Fprintf(out, "\nEntities: %d added, %d removed, %d modified\n",
+		len(diff.AddedEntities), len(diff.RemovedEntities), len(diff.ModifiedEntities))
+	fmt.Fprintf(out, "Relationships: %d added, %d removed, %d modified\n",
+		len(diff.AddedRelationships), len(diff.RemovedRelationships), len(diff.ModifiedRelationships))
+}
+
+// changedFields lists the names of the changed fields, already sorted by the diff
+func changedFields(changes []graphwrite.FieldChange) string {
+	names := make([]string, len(changes))
+	for i, change := range changes {
+		names[i] = change.Field
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+func TestDiffCommand(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "diff.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	service := graphwrite.NewService(database)
+	projectID, rootID, err := service.CreateProject(ctx, db.CreateProjectParams{Name: "Saga"}, "")
+	if err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+	first, err := service.ApplyAndAdvance(ctx, &graphwrite.ApplyRequest{
+		ParentVersionID: rootID,
+		Deltas: []*graphwrite.Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{Operation: "create", EntityType: "Character", EntityID: "villain", Fields: map[string]any{"name": "Villain"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+	second, err := service.ApplyAndAdvance(ctx, &graphwrite.ApplyRequest{
+		ParentVersionID: first.GraphVersionID,
+		Deltas: []*graphwrite.Delta{
+			{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "role": "protagonist"}},
+			{Operation: "delete", EntityType: "Character", EntityID: "villain"},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}, Relationships: []*graphwrite.RelationshipDelta{
+				{Operation: "create", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "allies_with", Properties: map[string]any{}},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	// The project defaults to the working set against its parent
+	fromID, toID, err := resolveDiffVersions(ctx, database.Queries(), projectID, "", "")
+	if err != nil {
+		t.Fatalf("resolveDiffVersions failed: %v", err)
+	}
+	if fromID != first.GraphVersionID || toID != second.GraphVersionID {
+		t.Fatalf("Expected %s..%s, got %s..%s", first.GraphVersionID, second.GraphVersionID, fromID, toID)
+	}
+	if _, _, err := resolveDiffVersions(ctx, database.Queries(), "", "", toID); err == nil {
+		t.Error("Expected an error without a project or both versions")
+	}
+
+	diff, err := service.Diff(ctx, fromID, toID)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	var out bytes.Buffer
+	printDiff(&out, diff)
+	output := out.String()
+
+	for _, want := range []string{
+		`added     entity        marcus                       Character "Marcus"`,
+		`removed   entity        villain                      Character "Villain"`,
+		`modified  entity        elena                        role`,
+		`added     relationship  elena -allies_with-> marcus`,
+		"Entities: 1 added, 1 removed, 1 modified",
+		"Relationships: 1 added, 0 removed, 0 modified",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "\x1b[") {
+		t.Error("Expected plain output without color codes")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// healthReport is the status summary printed by -cmd health
+type healthReport struct {
+	SizeBytes int64
+	TableRows map[string]int64
+	Projects  int64
+	Versions  int64
+
+	// LargestProject is the project whose working set holds the most entities, empty
+	// when no project has a working set
+	LargestProject         string
+	LargestProjectEntities int64
+
+	// ProjectsWithoutWorkingSet and VersionsWithOrphanRelationships are IDs needing
+	// attention: a project nothing is being edited in, and versions holding an edge
+	// whose endpoint is not part of the same version
+	ProjectsWithoutWorkingSet       []string
+	VersionsWithOrphanRelationships []string
+}
+
+// collectHealth gathers the health report in read-only queries
+func collectHealth(ctx context.Context, database *sql.DB) (*healthReport, error) {
+	report := &healthReport{TableRows: make(map[string]int64)}
+
+	var pageCount, pageSize int64
+	if err := database.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount); err != nil {
+		return nil, fmt.Errorf("failed to read page count: %w", err)
+	}
+	if err := database.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return nil, fmt.Errorf("failed to read page size: %w", err)
+	}
+	report.SizeBytes = pageCount * pageSize
+
+	tables, err := queryStrings(ctx, database, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	for _, table := range tables {
+		var count int64
+		if err := database.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %q", table)).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		report.TableRows[table] = count
+	}
+	report.Projects = report.TableRows["projects"]
+	report.Versions = report.TableRows["graph_versions"]
+
+	err = database.QueryRowContext(ctx, `
+		SELECT projects.name, COUNT(version_entities.entity_id) AS entities FROM projects
+		JOIN graph_versions ON graph_versions.project_id = projects.id AND graph_versions.is_working_set
+		JOIN version_entities ON version_entities.version_id = graph_versions.id
+		GROUP BY projects.id
+		ORDER BY entities DESC, projects.name ASC
+		LIMIT 1`).Scan(&report.LargestProject, &report.LargestProjectEntities)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to find the largest project: %w", err)
+	}
+
+	if report.ProjectsWithoutWorkingSet, err = queryStrings(ctx, database, `
+		SELECT id FROM projects
+		WHERE NOT EXISTS (SELECT 1 FROM graph_versions WHERE graph_versions.project_id = projects.id AND graph_versions.is_working_set)
+		ORDER BY id`); err != nil {
+		return nil, fmt.Errorf("failed to find projects without working sets: %w", err)
+	}
+
+	if report.VersionsWithOrphanRelationships, err = queryStrings(ctx, database, `
+		SELECT DISTINCT version_relationships.version_id FROM version_relationships
+		JOIN relationships ON relationships.id = version_relationships.relationship_id
+		WHERE NOT EXISTS (SELECT 1 FROM version_entities WHERE version_entities.version_id = version_relationships.version_id AND version_entities.entity_id = relationships.from_entity_id)
+		   OR NOT EXISTS (SELECT 1 FROM version_entities WHERE version_entities.version_id = version_relationships.version_id AND version_entities.entity_id = relationships.to_entity_id)
+		ORDER BY version_relationships.version_id`); err != nil {
+		return nil, fmt.Errorf("failed to find versions with orphan relationships: %w", err)
+	}
+
+	return report, nil
+}
+
+// queryStrings runs a query returning one text column
+func queryStrings(ctx context.Context, database *sql.DB, query string) ([]string, error) {
+	rows, err := database.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func showHealth(ctx context.Context, database *sql.DB) {
+	report, err := collectHealth(ctx, database)
+	if err != nil {
+		log.Fatalf("Failed to check health: %v", err)
+	}
+	printHealth(os.Stdout, report)
+}
+
+// printHealth writes the report with any flags needing attention last
+func printHealth(out io.Writer, report *healthReport) {
+	fmt.Fprintln(out, "=== HEALTH ===")
+	fmt.Fprintf(out, "Database size: %.1f KiB\n", float64(report.SizeBytes)/1024)
+	fmt.Fprintf(out, "Projects: %d, Versions: %d\n", report.Projects, report.Versions)
+	if report.LargestProject != "" {
+		fmt.Fprintf(out, "Largest project: %s (%d entities in its working set)\n", report.LargestProject, report.LargestProjectEntities)
+	}
+
+	tables := make([]string, 0, len(report.TableRows))
+	for table := range report.TableRows {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	fmt.Fprintln(out, "\nTable Rows:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Table\tRows")
+	for _, table := range tables {
+		fmt.Fprintf(w, "%s\t%d\n", table, report.TableRows[table])
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\nFlags:")
+	flagged := false
+	for _, projectID := range report.ProjectsWithoutWorkingSet {
+		fmt.Fprintf(out, "  project without a working set: %s\n", projectID)
+		flagged = true
+	}
+	for _, versionID := range report.VersionsWithOrphanRelationships {
+		fmt.Fprintf(out, "  version with orphan relationships: %s\n", versionID)
+		flagged = true
+	}
+	if !flagged {
+		fmt.Fprintln(out, "  none")
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+func TestCollectHealth(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "health.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	queries := database.Queries()
+	service := graphwrite.NewService(database)
+
+	// Two projects with a working set holding 3 and 1 entities
+	seedProject := func(projectID, name string, deltas []*graphwrite.Delta) string {
+		if _, err := queries.CreateProject(ctx, db.CreateProjectParams{ID: projectID, Name: name}); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+		rootID := projectID + "-root"
+		if _, err := queries.CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: rootID, ProjectID: projectID, IsWorkingSet: true}); err != nil {
+			t.Fatalf("Failed to create version: %v", err)
+		}
+		response, err := service.ApplyAndAdvance(ctx, &graphwrite.ApplyRequest{ParentVersionID: rootID, Deltas: deltas})
+		if err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+		return response.GraphVersionID
+	}
+	sagaVersionID := seedProject("saga", "Saga", []*graphwrite.Delta{
+		{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+		{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}, Relationships: []*graphwrite.RelationshipDelta{
+			{Operation: "create", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "allies_with", Properties: map[string]any{"bond_strength": "growing"}},
+		}},
+		{Operation: "create", EntityType: "Location", EntityID: "citadel", Fields: map[string]any{"name": "Citadel"}},
+	})
+	seedProject("short", "Short Story", []*graphwrite.Delta{
+		{Operation: "create", EntityType: "Character", EntityID: "ada", Fields: map[string]any{"name": "Ada"}},
+	})
+
+	// A project with no versions at all
+	if _, err := queries.CreateProject(ctx, db.CreateProjectParams{ID: "empty", Name: "Empty"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	// Dropping marcus from the saga's working set leaves its edge orphaned
+	marcus, err := queries.ListEntitiesByLogicalIDs(ctx, db.ListEntitiesByLogicalIDsParams{LogicalIds: `["marcus"]`, VersionID: sagaVersionID})
+	if err != nil || len(marcus) != 1 {
+		t.Fatalf("Failed to find marcus: %v", err)
+	}
+	if err := queries.RemoveVersionEntity(ctx, db.RemoveVersionEntityParams{VersionID: sagaVersionID, EntityID: marcus[0].ID}); err != nil {
+		t.Fatalf("Failed to remove marcus: %v", err)
+	}
+
+	report, err := collectHealth(ctx, database.DB())
+	if err != nil {
+		t.Fatalf("collectHealth failed: %v", err)
+	}
+
+	if report.SizeBytes <= 0 {
+		t.Errorf("Expected a positive database size, got %d", report.SizeBytes)
+	}
+	if report.Projects != 3 || report.Versions != 4 {
+		t.Errorf("Expected 3 projects and 4 versions, got %d and %d", report.Projects, report.Versions)
+	}
+	if report.TableRows["entities"] != 4 || report.TableRows["relationships"] != 1 {
+		t.Errorf("Expected 4 entity rows and 1 relationship row, got %v", report.TableRows)
+	}
+	if report.LargestProject != "Saga" || report.LargestProjectEntities != 2 {
+		t.Errorf("Expected Saga with 2 working set entities to be largest, got %s with %d", report.LargestProject, report.LargestProjectEntities)
+	}
+	if len(report.ProjectsWithoutWorkingSet) != 1 || report.ProjectsWithoutWorkingSet[0] != "empty" {
+		t.Errorf("Expected only the empty project to lack a working set, got %v", report.ProjectsWithoutWorkingSet)
+	}
+	if len(report.VersionsWithOrphanRelationships) != 1 || report.VersionsWithOrphanRelationships[0] != sagaVersionID {
+		t.Errorf("Expected only %s to have orphan relationships, got %v", sagaVersionID, report.VersionsWithOrphanRelationships)
+	}
+
+	var out bytes.Buffer
+	printHealth(&out, report)
+	for _, want := range []string{"Projects: 3, Versions: 4", "Largest project: Saga", "project without a working set: empty", "version with orphan relationships: " + sagaVersionID} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
@@ -8,9 +8,12 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"text/tabwriter"
+	"time"
 
 	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/graphwrite"
 	"github.com/barrynorthern/libretto/internal/types"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -18,11 +21,16 @@ import (
 func main() {
 	var (
 		dbPath    = flag.String("db", "libretto.db", "Path to SQLite database")
-		command   = flag.String("cmd", "schema", "Command: schema, projects, entities, relationships, annotations, graph")
+		command   = flag.String("cmd", "schema", "Command: schema, projects, entities, relationships, annotations, graph, stats, repair, health, diff")
 		projectID = flag.String("project", "", "Project ID for filtering")
 		versionID = flag.String("version", "", "Version ID for filtering")
+		version2  = flag.String("version2", "", "With -cmd diff, the version to compare -version against")
 		entityID  = flag.String("entity", "", "Entity ID for filtering")
 		verbose   = flag.Bool("v", false, "Verbose output")
+		watch     = flag.Bool("watch", false, "Re-render graph or stats whenever the project's working set changes")
+		interval  = flag.Duration("interval", 2*time.Second, "Polling interval for -watch")
+		apply     = flag.Bool("apply", false, "With -cmd repair, delete the dangling rows instead of only reporting them")
+		relType   = flag.String("type", "", "With -cmd stats, count only relationships of this type")
 	)
 	flag.Parse()
 
@@ -35,6 +43,29 @@ func main() {
 	queries := db.New(database)
 	ctx := context.Background()
 
+	if *watch {
+		if *projectID == "" || (*command != "graph" && *command != "stats") {
+			log.Fatalf("-watch requires -project and -cmd graph or stats")
+		}
+
+		watchCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+
+		err := watchWorkingSet(watchCtx, queries, *projectID, *interval, func(workingSetID string) {
+			fmt.Print(clearScreen)
+			if *command == "graph" {
+				showGraph(ctx, queries, "", workingSetID)
+			} else {
+				showStats(ctx, queries, "", workingSetID, *relType)
+			}
+			fmt.Printf("\nWatching project %s (updated %s, Ctrl+C to stop)\n", *projectID, time.Now().Format("15:04:05"))
+		})
+		if err != nil {
+			log.Fatalf("Watch failed: %v", err)
+		}
+		return
+	}
+
 	switch *command {
 	case "schema":
 		showSchema(database)
@@ -49,10 +80,16 @@ func main() {
 	case "graph":
 		showGraph(ctx, queries, *projectID, *versionID)
 	case "stats":
-		showStats(ctx, queries, *projectID, *versionID)
+		showStats(ctx, queries, *projectID, *versionID, *relType)
+	case "repair":
+		repairIntegrity(ctx, *dbPath, *apply)
+	case "health":
+		showHealth(ctx, database)
+	case "diff":
+		showDiff(ctx, *dbPath, *projectID, *versionID, *version2)
 	default:
 		fmt.Printf("Unknown command: %s\n", *command)
-		fmt.Println("Available commands: schema, projects, entities, relationships, annotations, graph, stats")
+		fmt.Println("Available commands: schema, projects, entities, relationships, annotations, graph, stats, repair, health, diff")
 	}
 }
 
@@ -139,7 +176,7 @@ func showProjects(ctx context.Context, queries *db.Queries, verbose bool) {
 			}
 			desc := "N/A"
 			if p.Description.Valid {
-				desc = truncate(p.Description.String, 30)
+				desc = types.Preview(p.Description.String, 30)
 			}
 			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", 
 				p.ID, p.Name, theme, genre, desc, p.CreatedAt.Format("2006-01-02 15:04"))
@@ -263,7 +300,7 @@ func showRelationships(ctx context.Context, queries *db.Queries, versionID, enti
 			for _, r := range typeRels {
 				props := "N/A"
 				if len(r.Properties) > 0 {
-					props = truncate(string(r.Properties), 30)
+					props = types.Preview(string(r.Properties), 30)
 				}
 				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", 
 					r.FromEntityID, r.ToEntityID, props, r.CreatedAt.Format("2006-01-02 15:04"))
@@ -313,8 +350,8 @@ func showAnnotations(ctx context.Context, queries *db.Queries, entityID string,
 				if a.AgentName.Valid {
 					agent = a.AgentName.String
 				}
-				content := truncate(a.Content, 40)
-				metadata := truncate(string(a.Metadata), 30)
+				content := types.Preview(a.Content, 40)
+				metadata := types.Preview(string(a.Metadata), 30)
 				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", 
 					agent, content, metadata, a.CreatedAt.Format("2006-01-02 15:04"))
 			}
@@ -325,7 +362,7 @@ func showAnnotations(ctx context.Context, queries *db.Queries, entityID string,
 				if a.AgentName.Valid {
 					agent = a.AgentName.String
 				}
-				content := truncate(a.Content, 60)
+				content := types.Preview(a.Content, 60)
 				fmt.Fprintf(w, "%s\t%s\n", agent, content)
 			}
 		}
@@ -381,7 +418,9 @@ func showGraph(ctx context.Context, queries *db.Queries, projectID, versionID st
 	}
 }
 
-func showStats(ctx context.Context, queries *db.Queries, projectID, versionID string) {
+// showStats prints entity and relationship counts by type. A relationship type
+// narrows the relationship counts to that type, loading only its rows.
+func showStats(ctx context.Context, queries *db.Queries, projectID, versionID, relationshipType string) {
 	fmt.Println("=== STATISTICS ===")
 	
 	if versionID == "" && projectID != "" {
@@ -422,7 +461,16 @@ func showStats(ctx context.Context, queries *db.Queries, projectID, versionID st
 	w.Flush()
 
 	// Relationship counts by type
-	relationships, err := queries.ListRelationshipsByVersion(ctx, versionID)
+	var relationships []db.Relationship
+	var err error
+	if relationshipType != "" {
+		relationships, err = queries.ListRelationshipsByVersionAndType(ctx, db.ListRelationshipsByVersionAndTypeParams{
+			VersionID:        versionID,
+			RelationshipType: relationshipType,
+		})
+	} else {
+		relationships, err = queries.ListRelationshipsByVersion(ctx, versionID)
+	}
 	if err != nil {
 		log.Printf("Failed to list relationships: %v", err)
 		return
@@ -446,6 +494,46 @@ func showStats(ctx context.Context, queries *db.Queries, projectID, versionID st
 	w2.Flush()
 }
 
+// repairIntegrity reports relationships and annotations pointing at missing entities,
+// deleting them when apply is set
+func repairIntegrity(ctx context.Context, dbPath string, apply bool) {
+	fmt.Println("=== REFERENTIAL INTEGRITY ===")
+
+	// Deletions need foreign keys on, as the service's own connection sets them
+	database, err := db.NewDatabase(dbPath)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	report, err := graphwrite.NewService(database).RepairIntegrity(ctx, graphwrite.RepairOptions{Repair: apply})
+	if err != nil {
+		log.Fatalf("Failed to check integrity: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Kind\tID")
+	for _, id := range report.DanglingRelationships {
+		fmt.Fprintf(w, "relationship\t%s\n", id)
+	}
+	for _, id := range report.DanglingAnnotations {
+		fmt.Fprintf(w, "annotation\t%s\n", id)
+	}
+	w.Flush()
+
+	fmt.Printf("\nDangling relationships: %d\n", len(report.DanglingRelationships))
+	fmt.Printf("Dangling annotations: %d\n", len(report.DanglingAnnotations))
+
+	switch {
+	case len(report.DanglingRelationships)+len(report.DanglingAnnotations) == 0:
+		fmt.Println("No dangling rows found")
+	case report.Repaired:
+		fmt.Println("Deleted all dangling rows")
+	default:
+		fmt.Println("Run again with -apply to delete them")
+	}
+}
+
 func getDataPreview(data json.RawMessage, entityType string) string {
 	switch entityType {
 	case "Scene":
@@ -464,13 +552,14 @@ func getDataPreview(data json.RawMessage, entityType string) string {
 		if themeData, err := types.UnmarshalThemeData(data); err == nil {
 			return fmt.Sprintf("Relevance: %.2f", themeData.Relevance)
 		}
+	case "PlotPoint":
+		if plotData, err := types.UnmarshalPlotPointData(data); err == nil {
+			return fmt.Sprintf("Seq: %d, Tension: %.2f", plotData.Sequence, plotData.TensionLevel)
+		}
+	case "Arc":
+		if arcData, err := types.UnmarshalArcData(data); err == nil {
+			return fmt.Sprintf("Character: %s, Milestones: %d", arcData.CharacterID, len(arcData.Milestones))
+		}
 	}
-	return truncate(string(data), 30)
+	return types.Preview(string(data), 30)
 }
-
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
-}
\ No newline at end of file
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// watchWorkingSet polls a project's working set every interval and calls render with
+// the working set version ID whenever it changes, starting with the current one.
+// It returns nil once ctx is cancelled.
+func watchWorkingSet(ctx context.Context, queries *db.Queries, projectID string, interval time.Duration, render func(versionID string)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastVersionID := ""
+	for {
+		workingSet, err := queries.GetWorkingSetVersion(ctx, projectID)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			// No working set yet; keep polling
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to get working set for project %s: %w", projectID, err)
+		case workingSet.ID != lastVersionID:
+			lastVersionID = workingSet.ID
+			render(workingSet.ID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
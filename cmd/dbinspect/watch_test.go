@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/google/uuid"
+)
+
+func TestWatchWorkingSet_RerendersOnChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "watch.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	queries := database.Queries()
+	projectID := uuid.New().String()
+	if _, err := queries.CreateProject(ctx, db.CreateProjectParams{ID: projectID, Name: "Watched"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	createVersion := func(isWorkingSet bool) string {
+		versionID := uuid.New().String()
+		if _, err := queries.CreateGraphVersion(ctx, db.CreateGraphVersionParams{
+			ID:           versionID,
+			ProjectID:    projectID,
+			Name:         sql.NullString{String: "Draft", Valid: true},
+			IsWorkingSet: isWorkingSet,
+		}); err != nil {
+			t.Fatalf("Failed to create version: %v", err)
+		}
+		return versionID
+	}
+	firstVersionID := createVersion(true)
+
+	rendered := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- watchWorkingSet(ctx, queries, projectID, 10*time.Millisecond, func(versionID string) {
+			rendered <- versionID
+		})
+	}()
+
+	expectRender := func(want string) {
+		t.Helper()
+		select {
+		case got := <-rendered:
+			if got != want {
+				t.Fatalf("Expected render of %s, got %s", want, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for render of %s", want)
+		}
+	}
+
+	expectRender(firstVersionID)
+
+	// One working set change triggers exactly one re-render
+	secondVersionID := createVersion(false)
+	if err := queries.SetWorkingSet(ctx, db.SetWorkingSetParams{ID: secondVersionID, ProjectID: projectID}); err != nil {
+		t.Fatalf("Failed to set working set: %v", err)
+	}
+	expectRender(secondVersionID)
+
+	select {
+	case got := <-rendered:
+		t.Errorf("Unexpected extra render of %s", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected watch to stop cleanly, got %v", err)
+	}
+}
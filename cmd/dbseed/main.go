@@ -123,6 +123,32 @@ func applyMigrations(database *sql.DB) error {
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS version_entities (
+			version_id TEXT NOT NULL,
+			entity_id TEXT NOT NULL,
+			PRIMARY KEY (version_id, entity_id),
+			FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE,
+			FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS version_relationships (
+			version_id TEXT NOT NULL,
+			relationship_id TEXT NOT NULL,
+			PRIMARY KEY (version_id, relationship_id),
+			FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE,
+			FOREIGN KEY (relationship_id) REFERENCES relationships(id) ON DELETE CASCADE
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS add_entity_to_version
+			AFTER INSERT ON entities
+			FOR EACH ROW
+		BEGIN
+			INSERT INTO version_entities (version_id, entity_id) VALUES (NEW.version_id, NEW.id);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS add_relationship_to_version
+			AFTER INSERT ON relationships
+			FOR EACH ROW
+		BEGIN
+			INSERT INTO version_relationships (version_id, relationship_id) VALUES (NEW.version_id, NEW.id);
+		END`,
 	}
 
 	for _, migration := range migrations {
@@ -135,7 +161,7 @@ func applyMigrations(database *sql.DB) error {
 }
 
 func cleanDatabase(database *sql.DB) error {
-	tables := []string{"annotations", "relationships", "entities", "graph_versions", "projects", "scenes"}
+	tables := []string{"annotations", "version_relationships", "relationships", "version_entities", "entities", "graph_versions", "projects", "scenes"}
 	
 	for _, table := range tables {
 		if _, err := database.Exec(fmt.Sprintf("DELETE FROM %s", table)); err != nil {
@@ -55,30 +55,16 @@ func main() {
 	// ==========================================
 	fmt.Printf("📖 BOOK 1: THE LOST ARTIFACT\n")
 	
-	book1ID := uuid.New().String()
-	_, err = database.Queries().CreateProject(ctx, db.CreateProjectParams{
-		ID:          book1ID,
+	book1ID, book1VersionID, err := service.CreateProject(ctx, db.CreateProjectParams{
 		Name:        "Book 1: The Lost Artifact",
 		Theme:       sql.NullString{String: "Discovery", Valid: true},
 		Genre:       sql.NullString{String: "Fantasy Adventure", Valid: true},
 		Description: sql.NullString{String: "Elena begins her journey as a young archaeologist discovering ancient mysteries", Valid: true},
-	})
+	}, "Final Draft")
 	if err != nil {
 		log.Fatalf("Failed to create Book 1 project: %v", err)
 	}
 
-	book1VersionID := uuid.New().String()
-	_, err = database.Queries().CreateGraphVersion(ctx, db.CreateGraphVersionParams{
-		ID:           book1VersionID,
-		ProjectID:    book1ID,
-		Name:         sql.NullString{String: "Final Draft", Valid: true},
-		Description:  sql.NullString{String: "Elena's origin story", Valid: true},
-		IsWorkingSet: true,
-	})
-	if err != nil {
-		log.Fatalf("Failed to create Book 1 version: %v", err)
-	}
-
 	// Elena starts her journey
 	book1Response, err := service.Apply(ctx, &graphwrite.ApplyRequest{
 		ParentVersionID: book1VersionID,
@@ -185,23 +171,12 @@ func main() {
 		log.Fatalf("Failed to update Book 1 working set: %v", err)
 	}
 
-	// Import Elena from Book 1
-	importedElena, err := service.ImportEntity(ctx, book2VersionID, book1ID, elenaID)
-	if err != nil {
-		log.Fatalf("Failed to import Elena to Book 2: %v", err)
-	}
-
-	// Import Marcus from Book 1
-	importedMarcus, err := service.ImportEntity(ctx, book2VersionID, book1ID, marcusID)
+	// Import Elena, Marcus and the temple location from Book 1
+	imported, err := service.ImportEntities(ctx, book2VersionID, book1ID, []string{elenaID, marcusID, "ancient-temple-of-echoes"})
 	if err != nil {
-		log.Fatalf("Failed to import Marcus to Book 2: %v", err)
-	}
-
-	// Import the temple location
-	_, err = service.ImportEntity(ctx, book2VersionID, book1ID, "ancient-temple-of-echoes")
-	if err != nil {
-		log.Fatalf("Failed to import temple to Book 2: %v", err)
+		log.Fatalf("Failed to import entities to Book 2: %v", err)
 	}
+	importedElena, importedMarcus := imported[0], imported[1]
 
 	fmt.Printf("   📥 Elena imported from Book 1 (ID: %s)\n", importedElena.ID)
 	fmt.Printf("   📥 Marcus imported from Book 1 (ID: %s)\n", importedMarcus.ID)
@@ -302,27 +277,10 @@ func main() {
 		log.Fatalf("Failed to update Book 2 working set: %v", err)
 	}
 
-	// Import Elena from Book 2 (she carries her evolution)
-	_, err = service.ImportEntity(ctx, book3VersionID, book2ID, elenaID)
-	if err != nil {
-		log.Fatalf("Failed to import Elena to Book 3: %v", err)
-	}
-
-	// Import Marcus from Book 2
-	_, err = service.ImportEntity(ctx, book3VersionID, book2ID, marcusID)
-	if err != nil {
-		log.Fatalf("Failed to import Marcus to Book 3: %v", err)
-	}
-
-	// Import locations
-	_, err = service.ImportEntity(ctx, book3VersionID, book2ID, "ancient-temple-of-echoes")
-	if err != nil {
-		log.Fatalf("Failed to import temple to Book 3: %v", err)
-	}
-
-	_, err = service.ImportEntity(ctx, book3VersionID, book2ID, "iron-pass-battlefield")
+	// Import Elena (she carries her evolution), Marcus and the locations from Book 2
+	_, err = service.ImportEntities(ctx, book3VersionID, book2ID, []string{elenaID, marcusID, "ancient-temple-of-echoes", "iron-pass-battlefield"})
 	if err != nil {
-		log.Fatalf("Failed to import battlefield to Book 3: %v", err)
+		log.Fatalf("Failed to import entities to Book 3: %v", err)
 	}
 
 	// Elena reaches her final form
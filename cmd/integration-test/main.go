@@ -11,14 +11,16 @@ import (
 	"time"
 
 	"github.com/barrynorthern/libretto/internal/db"
+	gwpkg "github.com/barrynorthern/libretto/internal/graphwrite"
 	"github.com/barrynorthern/libretto/internal/monitoring"
+	"github.com/barrynorthern/libretto/internal/selftest"
 	"github.com/barrynorthern/libretto/internal/types"
 	"github.com/google/uuid"
-	_ "github.com/mattn/go-sqlite3"
 )
 
 type TestSuite struct {
 	queries   *db.Queries
+	service   gwpkg.GraphWriteService
 	logger    *monitoring.Logger
 	metrics   *monitoring.DatabaseMetrics
 	database  *sql.DB
@@ -49,31 +51,28 @@ func main() {
 	)
 	flag.Parse()
 
+	ctx := context.Background()
+
 	// Setup database
-	database, err := sql.Open("sqlite3", *dbPath)
+	database, err := selftest.OpenDatabase(ctx, *dbPath)
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		log.Fatalf("Failed to set up database: %v", err)
 	}
 	defer database.Close()
 
-	// Apply migrations
-	if err := applyMigrations(database); err != nil {
-		log.Fatalf("Failed to apply migrations: %v", err)
-	}
-
 	// Setup monitoring
 	logger := monitoring.NewLogger("integration-test")
 	metrics := monitoring.NewDatabaseMetrics(logger)
 
 	suite := &TestSuite{
-		queries:  db.New(database),
+		queries:  database.Queries(),
+		service:  gwpkg.NewService(database),
 		logger:   logger,
 		metrics:  metrics,
-		database: database,
+		database: database.DB(),
 	}
 
-	ctx := context.Background()
-	
+
 	// Run test suite
 	report := suite.RunAllTests(ctx, *verbose)
 	
@@ -109,6 +108,7 @@ func (ts *TestSuite) RunAllTests(ctx context.Context, verbose bool) *TestReport
 		{"Performance Benchmarks", ts.testPerformance},
 		{"Data Integrity", ts.testDataIntegrity},
 		{"Concurrent Operations", ts.testConcurrentOperations},
+		{"Self-Test Round Trip", ts.testSelfTestRoundTrip},
 	}
 	
 	var results []TestResult
@@ -1429,41 +1429,35 @@ func (ts *TestSuite) testConcurrentOperations(ctx context.Context) TestResult {
 	}
 }
 
+func (ts *TestSuite) testSelfTestRoundTrip(ctx context.Context) TestResult {
+	start := time.Now()
+
+	// The same round trip libretto -selftest runs
+	if err := selftest.RoundTrip(ctx, ts.service); err != nil {
+		return TestResult{
+			Name:     "Self-Test Round Trip",
+			Passed:   false,
+			Duration: time.Since(start),
+			Error:    err.Error(),
+		}
+	}
+
+	return TestResult{
+		Name:     "Self-Test Round Trip",
+		Passed:   true,
+		Duration: time.Since(start),
+	}
+}
+
 // Helper methods
 
 func (ts *TestSuite) setupProjectAndVersion(ctx context.Context) (string, string, error) {
-	// Create project
-	projectID := uuid.New().String()
-	projectParams := db.CreateProjectParams{
-		ID:          projectID,
+	return selftest.SetupProject(ctx, ts.service, db.CreateProjectParams{
 		Name:        "Test Project",
 		Theme:       sql.NullString{String: "Adventure", Valid: true},
 		Genre:       sql.NullString{String: "Fantasy", Valid: true},
 		Description: sql.NullString{String: "Integration test project", Valid: true},
-	}
-	
-	_, err := ts.queries.CreateProject(ctx, projectParams)
-	if err != nil {
-		return "", "", err
-	}
-	
-	// Create version
-	versionID := uuid.New().String()
-	versionParams := db.CreateGraphVersionParams{
-		ID:            versionID,
-		ProjectID:     projectID,
-		ParentVersionID: sql.NullString{},
-		Name:          sql.NullString{String: "Test Version", Valid: true},
-		Description:   sql.NullString{String: "Test version", Valid: true},
-		IsWorkingSet:  true,
-	}
-	
-	_, err = ts.queries.CreateGraphVersion(ctx, versionParams)
-	if err != nil {
-		return "", "", err
-	}
-	
-	return projectID, versionID, nil
+	})
 }
 
 func (ts *TestSuite) SaveReport(report *TestReport, filename string) error {
@@ -1505,112 +1499,3 @@ func (ts *TestSuite) PrintSummary(report *TestReport) {
 		}
 	}
 }
-
-func applyMigrations(database *sql.DB) error {
-	// Enable foreign key constraints
-	if _, err := database.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		return fmt.Errorf("failed to enable foreign keys: %v", err)
-	}
-	migrations := []string{
-		// Initial schema
-		`CREATE TABLE IF NOT EXISTS scenes (
-			id TEXT PRIMARY KEY,
-			title TEXT NOT NULL,
-			summary TEXT NOT NULL DEFAULT '',
-			content TEXT NOT NULL DEFAULT '',
-			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-		)`,
-		// Living Narrative schema
-		`CREATE TABLE IF NOT EXISTS projects (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			theme TEXT,
-			genre TEXT,
-			description TEXT DEFAULT '',
-			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS graph_versions (
-			id TEXT PRIMARY KEY,
-			project_id TEXT NOT NULL,
-			parent_version_id TEXT,
-			name TEXT DEFAULT '',
-			description TEXT DEFAULT '',
-			is_working_set BOOLEAN NOT NULL DEFAULT FALSE,
-			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
-			FOREIGN KEY (parent_version_id) REFERENCES graph_versions(id)
-		)`,
-		`CREATE TABLE IF NOT EXISTS entities (
-			id TEXT PRIMARY KEY,
-			version_id TEXT NOT NULL,
-			entity_type TEXT NOT NULL,
-			name TEXT NOT NULL DEFAULT '',
-			data JSON NOT NULL,
-			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE
-		)`,
-		`CREATE TABLE IF NOT EXISTS relationships (
-			id TEXT PRIMARY KEY,
-			version_id TEXT NOT NULL,
-			from_entity_id TEXT NOT NULL,
-			to_entity_id TEXT NOT NULL,
-			relationship_type TEXT NOT NULL,
-			properties JSON,
-			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE,
-			FOREIGN KEY (from_entity_id) REFERENCES entities(id) ON DELETE CASCADE,
-			FOREIGN KEY (to_entity_id) REFERENCES entities(id) ON DELETE CASCADE,
-			UNIQUE(version_id, from_entity_id, to_entity_id, relationship_type)
-		)`,
-		`CREATE TABLE IF NOT EXISTS annotations (
-			id TEXT PRIMARY KEY,
-			entity_id TEXT NOT NULL,
-			annotation_type TEXT NOT NULL,
-			content TEXT NOT NULL,
-			metadata JSON,
-			agent_name TEXT,
-			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
-		)`,
-		// Indexes
-		`CREATE INDEX IF NOT EXISTS idx_projects_created_at ON projects(created_at DESC)`,
-		`CREATE INDEX IF NOT EXISTS idx_graph_versions_project_id ON graph_versions(project_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_graph_versions_working_set ON graph_versions(project_id, is_working_set) WHERE is_working_set = TRUE`,
-		`CREATE INDEX IF NOT EXISTS idx_entities_version_id ON entities(version_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_entities_type ON entities(version_id, entity_type)`,
-		`CREATE INDEX IF NOT EXISTS idx_relationships_version_id ON relationships(version_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_relationships_from_entity ON relationships(from_entity_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_relationships_to_entity ON relationships(to_entity_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_relationships_type ON relationships(version_id, relationship_type)`,
-		`CREATE INDEX IF NOT EXISTS idx_annotations_entity_id ON annotations(entity_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_annotations_type ON annotations(entity_id, annotation_type)`,
-		// Triggers
-		`CREATE TRIGGER IF NOT EXISTS update_projects_updated_at 
-			AFTER UPDATE ON projects
-			FOR EACH ROW
-		BEGIN
-			UPDATE projects SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
-		END`,
-		`CREATE TRIGGER IF NOT EXISTS update_entities_updated_at 
-			AFTER UPDATE ON entities
-			FOR EACH ROW
-		BEGIN
-			UPDATE entities SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
-		END`,
-		// Unique constraint for working set
-		`CREATE UNIQUE INDEX IF NOT EXISTS idx_unique_working_set_per_project 
-		ON graph_versions(project_id) 
-		WHERE is_working_set = TRUE`,
-	}
-
-	for _, migration := range migrations {
-		if _, err := database.Exec(migration); err != nil {
-			return fmt.Errorf("failed to apply migration: %v", err)
-		}
-	}
-
-	return nil
-}
\ No newline at end of file
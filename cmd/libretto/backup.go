@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// backupTimeFormat names snapshot files so they sort oldest first
+const backupTimeFormat = "20060102T150405.000000000Z"
+
+// backupPrefix starts every snapshot file name, so pruning leaves other files alone
+const backupPrefix = "libretto-"
+
+// backupConfig controls the periodic disaster-recovery job. An empty Dir disables it.
+// Each cycle writes a snapshot of the whole database, holding every project, which
+// restores by replacing the database file with it while the server is stopped.
+type backupConfig struct {
+	Dir      string
+	Interval time.Duration
+
+	// Retain is how many snapshots to keep, and so how many restore points each
+	// project has; older ones are deleted
+	Retain int
+}
+
+// backupConfigFromEnv reads BACKUP_DIR, BACKUP_INTERVAL (a Go duration, default 24h)
+// and BACKUP_RETAIN (default 7)
+func backupConfigFromEnv() (backupConfig, error) {
+	config := backupConfig{Dir: os.Getenv("BACKUP_DIR"), Interval: 24 * time.Hour, Retain: 7}
+
+	if v := os.Getenv("BACKUP_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			return config, fmt.Errorf("invalid BACKUP_INTERVAL %q", v)
+		}
+		config.Interval = interval
+	}
+	if v := os.Getenv("BACKUP_RETAIN"); v != "" {
+		retain, err := strconv.Atoi(v)
+		if err != nil || retain < 1 {
+			return config, fmt.Errorf("invalid BACKUP_RETAIN %q", v)
+		}
+		config.Retain = retain
+	}
+
+	return config, nil
+}
+
+// runBackups snapshots the database once at startup and then each interval until ctx
+// is done, logging failures rather than stopping so one bad cycle does not end the job
+func runBackups(ctx context.Context, database *db.Database, config backupConfig) {
+	if err := backupOnce(ctx, database, config, time.Now()); err != nil {
+		log.Printf("Backup failed: %v", err)
+	}
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := backupOnce(ctx, database, config, now); err != nil {
+				log.Printf("Backup failed: %v", err)
+			}
+		}
+	}
+}
+
+// backupOnce snapshots the database to <Dir>/libretto-<time>.db, then prunes the
+// snapshots beyond Retain
+func backupOnce(ctx context.Context, database *db.Database, config backupConfig, now time.Time) error {
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	path := filepath.Join(config.Dir, backupPrefix+now.UTC().Format(backupTimeFormat)+".db")
+	if err := database.Snapshot(ctx, path); err != nil {
+		return err
+	}
+
+	return pruneBackups(config.Dir, config.Retain)
+}
+
+// pruneBackups deletes all but the newest retain snapshots in the backup directory
+func pruneBackups(dir string, retain int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var snapshots []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), backupPrefix) && strings.HasSuffix(entry.Name(), ".db") {
+			snapshots = append(snapshots, entry.Name())
+		}
+	}
+	sort.Strings(snapshots)
+
+	for len(snapshots) > retain {
+		if err := os.Remove(filepath.Join(dir, snapshots[0])); err != nil {
+			return fmt.Errorf("failed to prune backup: %w", err)
+		}
+		snapshots = snapshots[1:]
+	}
+
+	return nil
+}
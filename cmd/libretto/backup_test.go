@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	gwpkg "github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+// setupBackupProjects creates a database holding one project with a working set
+// for each ID
+func setupBackupProjects(t *testing.T, dir string, projectIDs ...string) (*db.Database, gwpkg.GraphWriteService) {
+	ctx := context.Background()
+
+	database, err := db.NewDatabase(filepath.Join(dir, "backup.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	service := gwpkg.NewService(database)
+	for _, projectID := range projectIDs {
+		if _, _, err := service.CreateProject(ctx, db.CreateProjectParams{ID: projectID, Name: projectID}, ""); err != nil {
+			t.Fatalf("CreateProject failed: %v", err)
+		}
+	}
+	return database, service
+}
+
+func TestBackupOnce_SnapshotsAndPrunes(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	database, service := setupBackupProjects(t, dir, "saga")
+
+	if _, err := service.ApplyToProject(ctx, "saga", []*gwpkg.Delta{
+		{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+	}); err != nil {
+		t.Fatalf("ApplyToProject failed: %v", err)
+	}
+
+	config := backupConfig{Dir: filepath.Join(dir, "backups"), Interval: time.Hour, Retain: 2}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for cycle := 0; cycle < 3; cycle++ {
+		if err := backupOnce(ctx, database, config, start.Add(time.Duration(cycle)*time.Hour)); err != nil {
+			t.Fatalf("backupOnce failed: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(config.Dir)
+	if err != nil {
+		t.Fatalf("Failed to read backups: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	want := []string{
+		backupPrefix + start.Add(time.Hour).Format(backupTimeFormat) + ".db",
+		backupPrefix + start.Add(2*time.Hour).Format(backupTimeFormat) + ".db",
+	}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected the two newest snapshots %v, got %v", want, names)
+	}
+
+	// A snapshot restores as a working database holding the project
+	restored, err := db.NewDatabase(filepath.Join(config.Dir, want[1]))
+	if err != nil {
+		t.Fatalf("Failed to open snapshot: %v", err)
+	}
+	defer restored.Close()
+
+	workingSet, err := restored.Queries().GetWorkingSetVersion(ctx, "saga")
+	if err != nil {
+		t.Fatalf("Failed to get the working set from the snapshot: %v", err)
+	}
+	entities, err := gwpkg.NewService(restored).ListEntities(ctx, workingSet.ID, gwpkg.EntityFilter{})
+	if err != nil {
+		t.Fatalf("ListEntities failed on the snapshot: %v", err)
+	}
+	if len(entities) != 1 || entities[0].Name != "Elena" {
+		t.Errorf("Expected the snapshot to hold the working set, got %+v", entities)
+	}
+}
+
+func TestRunBackups_SnapshotsAtStartup(t *testing.T) {
+	dir := t.TempDir()
+	database, _ := setupBackupProjects(t, dir, "saga")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	config := backupConfig{Dir: filepath.Join(dir, "backups"), Interval: time.Hour, Retain: 2}
+	go func() {
+		runBackups(ctx, database, config)
+		close(done)
+	}()
+
+	// The first snapshot does not wait for the interval
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		entries, _ := os.ReadDir(config.Dir)
+		if len(entries) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected a snapshot at startup")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestBackupConfigFromEnv(t *testing.T) {
+	t.Setenv("BACKUP_DIR", "/var/backups/libretto")
+	t.Setenv("BACKUP_INTERVAL", "6h")
+	t.Setenv("BACKUP_RETAIN", "3")
+
+	config, err := backupConfigFromEnv()
+	if err != nil {
+		t.Fatalf("backupConfigFromEnv failed: %v", err)
+	}
+	if config.Dir != "/var/backups/libretto" || config.Interval != 6*time.Hour || config.Retain != 3 {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	t.Setenv("BACKUP_RETAIN", "0")
+	if _, err := backupConfigFromEnv(); err == nil {
+		t.Error("Expected an error for a retention below 1")
+	}
+}
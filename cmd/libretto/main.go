@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
@@ -14,6 +15,17 @@ import (
 )
 
 func main() {
+	selfTest := flag.Bool("selftest", false, "Run a database round-trip self-test and exit")
+	flag.Parse()
+
+	if *selfTest {
+		if err := runSelfTest(context.Background()); err != nil {
+			log.Fatalf("Self-test failed: %v", err)
+		}
+		log.Printf("Self-test passed")
+		return
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -39,11 +51,21 @@ func main() {
 	// Initialize GraphWrite service
 	service := gwpkg.NewService(database)
 
+	backups, err := backupConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure backups: %v", err)
+	}
+	if backups.Dir != "" {
+		go runBackups(ctx, database, backups)
+		log.Printf("Snapshotting the database to %s every %s, keeping %d", backups.Dir, backups.Interval, backups.Retain)
+	}
+
 	mux := http.NewServeMux()
 
 	// Wire orchestrated Baton service
 	orchestrator := app.NewOrchestrator(service, versionID)
 	mux.Handle(batonv1connect.NewBatonServiceHandler(orchestrator))
+	mux.HandleFunc("/api/directives", orchestrator.HandleApplyDirectives)
 
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	gwpkg "github.com/barrynorthern/libretto/internal/graphwrite"
+	"github.com/barrynorthern/libretto/internal/selftest"
+)
+
+// runSelfTest performs the selftest package's create-project/apply-delta/read-back
+// round trip against a throwaway database
+func runSelfTest(ctx context.Context) error {
+	tmpDir, err := os.MkdirTemp("", "libretto-selftest-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	database, err := selftest.OpenDatabase(ctx, filepath.Join(tmpDir, "selftest.db"))
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	return selftest.RoundTrip(ctx, gwpkg.NewService(database))
+}
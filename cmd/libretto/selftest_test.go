@@ -0,0 +1,12 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	if err := runSelfTest(context.Background()); err != nil {
+		t.Fatalf("Self-test failed on a healthy build: %v", err)
+	}
+}
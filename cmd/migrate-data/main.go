@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+func main() {
+	var (
+		dbPath = flag.String("db", "libretto.db", "Path to SQLite database")
+		dryRun = flag.Bool("dry-run", false, "Report what would be upgraded without writing")
+	)
+	flag.Parse()
+
+	database, err := db.NewDatabase(*dbPath)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	report, err := migrateData(context.Background(), database, *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to migrate entity data: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Entity Type\tUpgraded")
+	for _, entityType := range report.entityTypes() {
+		fmt.Fprintf(w, "%s\t%d\n", entityType, report.UpgradedByType[entityType])
+	}
+	w.Flush()
+
+	fmt.Printf("\nEntities scanned: %d\n", report.Scanned)
+	fmt.Printf("Entities upgraded: %d\n", report.Upgraded)
+	if report.Unreadable > 0 {
+		fmt.Printf("Entities with unreadable data: %d\n", report.Unreadable)
+	}
+	if *dryRun && report.Upgraded > 0 {
+		fmt.Println("Run again without -dry-run to write the upgrades")
+	}
+}
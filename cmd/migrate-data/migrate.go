@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// migrationReport counts the entity rows a data migration scanned and upgraded
+type migrationReport struct {
+	Scanned        int
+	Upgraded       int
+	Unreadable     int
+	UpgradedByType map[string]int
+}
+
+// entityTypes returns the entity types with upgraded rows, sorted
+func (r *migrationReport) entityTypes() []string {
+	entityTypes := make([]string, 0, len(r.UpgradedByType))
+	for entityType := range r.UpgradedByType {
+		entityTypes = append(entityTypes, entityType)
+	}
+	sort.Strings(entityTypes)
+	return entityTypes
+}
+
+// migrateData runs types.MigrateEntityData over every entity row and rewrites the
+// upgraded ones in a single transaction. Versions share unchanged rows, so each row
+// is visited once however many versions hold it. Timestamps are left alone since the
+// story content does not change. With dryRun the transaction is rolled back.
+func migrateData(ctx context.Context, database *db.Database, dryRun bool) (*migrationReport, error) {
+	tx, err := database.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, entity_type, data FROM entities ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	type upgrade struct {
+		id   string
+		data []byte
+	}
+	report := &migrationReport{UpgradedByType: make(map[string]int)}
+	var upgrades []upgrade
+	for rows.Next() {
+		var id, entityType string
+		var raw []byte
+		if err := rows.Scan(&id, &entityType, &raw); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan entity: %w", err)
+		}
+		report.Scanned++
+
+		var data map[string]any
+		if err := json.Unmarshal(raw, &data); err != nil || data == nil {
+			report.Unreadable++
+			continue
+		}
+		if !types.MigrateEntityData(types.EntityType(entityType), data) {
+			continue
+		}
+
+		upgraded, err := json.Marshal(data)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to marshal entity %s: %w", id, err)
+		}
+		upgrades = append(upgrades, upgrade{id: id, data: upgraded})
+		report.Upgraded++
+		report.UpgradedByType[entityType]++
+	}
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	for _, upgrade := range upgrades {
+		if _, err := tx.ExecContext(ctx, "UPDATE entities SET data = ? WHERE id = ?", upgrade.data, upgrade.id); err != nil {
+			return nil, fmt.Errorf("failed to rewrite entity %s: %w", upgrade.id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit migration: %w", err)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+func TestMigrateData(t *testing.T) {
+	ctx := context.Background()
+
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer database.Close()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	queries := database.Queries()
+	if _, err := queries.CreateProject(ctx, db.CreateProjectParams{ID: "saga", Name: "Saga"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := queries.CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: "root", ProjectID: "saga", IsWorkingSet: true}); err != nil {
+		t.Fatalf("Failed to create version: %v", err)
+	}
+
+	// Current-shape entities written through the service
+	response, err := graphwrite.NewService(database).ApplyAndAdvance(ctx, &graphwrite.ApplyRequest{
+		ParentVersionID: "root",
+		Deltas: []*graphwrite.Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{Operation: "create", EntityType: "Scene", EntityID: "opening", Fields: map[string]any{"title": "Opening", "act": "1", "sequence": 1, "characters": []any{"elena"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	// Legacy-shaped rows as older builds stored them
+	legacy := map[string]string{
+		"legacy-scene": `{"title": "Ambush", "act": 2, "sequence": "4", "themes": "betrayal", "logical_id": "ambush"}`,
+		"legacy-plot":  `{"name": "Twist", "act": 3, "logical_id": "twist"}`,
+	}
+	for id, data := range legacy {
+		entityType := "Scene"
+		if id == "legacy-plot" {
+			entityType = "PlotPoint"
+		}
+		if _, err := queries.CreateEntity(ctx, db.CreateEntityParams{ID: id, VersionID: response.GraphVersionID, EntityType: entityType, Data: json.RawMessage(data)}); err != nil {
+			t.Fatalf("CreateEntity failed: %v", err)
+		}
+	}
+
+	before, err := queries.ListEntitiesByVersion(ctx, response.GraphVersionID)
+	if err != nil {
+		t.Fatalf("ListEntitiesByVersion failed: %v", err)
+	}
+
+	dryRun, err := migrateData(ctx, database, true)
+	if err != nil {
+		t.Fatalf("migrateData failed: %v", err)
+	}
+	if dryRun.Scanned != 4 || dryRun.Upgraded != 2 {
+		t.Errorf("Expected a dry run to find 2 of 4 entities to upgrade, got %+v", dryRun)
+	}
+	if stored, _ := queries.GetEntity(ctx, "legacy-scene"); string(stored.Data) != legacy["legacy-scene"] {
+		t.Errorf("Expected a dry run to write nothing, got %s", stored.Data)
+	}
+
+	report, err := migrateData(ctx, database, false)
+	if err != nil {
+		t.Fatalf("migrateData failed: %v", err)
+	}
+	if report.Scanned != 4 || report.Upgraded != 2 || report.UpgradedByType["Scene"] != 1 || report.UpgradedByType["PlotPoint"] != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	wants := map[string]map[string]any{
+		"legacy-scene": {"title": "Ambush", "act": "2", "sequence": float64(4), "themes": []any{"betrayal"}, "logical_id": "ambush"},
+		"legacy-plot":  {"name": "Twist", "act": "3", "logical_id": "twist"},
+	}
+	for id, want := range wants {
+		stored, err := queries.GetEntity(ctx, id)
+		if err != nil {
+			t.Fatalf("GetEntity failed: %v", err)
+		}
+		var data map[string]any
+		if err := json.Unmarshal(stored.Data, &data); err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v", id, err)
+		}
+		if !reflect.DeepEqual(data, want) {
+			t.Errorf("Expected %s to be upgraded to %v, got %v", id, want, data)
+		}
+	}
+
+	for _, entity := range before {
+		if _, isLegacy := legacy[entity.ID]; isLegacy {
+			continue
+		}
+		stored, err := queries.GetEntity(ctx, entity.ID)
+		if err != nil {
+			t.Fatalf("GetEntity failed: %v", err)
+		}
+		if string(stored.Data) != string(entity.Data) || !stored.UpdatedAt.Equal(entity.UpdatedAt) {
+			t.Errorf("Expected current entity %s to be untouched, got %s", entity.ID, stored.Data)
+		}
+	}
+
+	again, err := migrateData(ctx, database, false)
+	if err != nil {
+		t.Fatalf("migrateData failed: %v", err)
+	}
+	if again.Upgraded != 0 {
+		t.Errorf("Expected a second run to upgrade nothing, got %+v", again)
+	}
+}
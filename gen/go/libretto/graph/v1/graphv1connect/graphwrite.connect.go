@@ -35,11 +35,23 @@ const (
 const (
 	// GraphWriteServiceApplyProcedure is the fully-qualified name of the GraphWriteService's Apply RPC.
 	GraphWriteServiceApplyProcedure = "/libretto.graph.v1.GraphWriteService/Apply"
+	// GraphWriteServiceSearchEntitiesProcedure is the fully-qualified name of the GraphWriteService's
+	// SearchEntities RPC.
+	GraphWriteServiceSearchEntitiesProcedure = "/libretto.graph.v1.GraphWriteService/SearchEntities"
+	// GraphWriteServiceImportEntityProcedure is the fully-qualified name of the GraphWriteService's
+	// ImportEntity RPC.
+	GraphWriteServiceImportEntityProcedure = "/libretto.graph.v1.GraphWriteService/ImportEntity"
+	// GraphWriteServiceImportEntitiesProcedure is the fully-qualified name of the GraphWriteService's
+	// ImportEntities RPC.
+	GraphWriteServiceImportEntitiesProcedure = "/libretto.graph.v1.GraphWriteService/ImportEntities"
 )
 
 // GraphWriteServiceClient is a client for the libretto.graph.v1.GraphWriteService service.
 type GraphWriteServiceClient interface {
 	Apply(context.Context, *connect.Request[v1.ApplyRequest]) (*connect.Response[v1.ApplyResponse], error)
+	SearchEntities(context.Context, *connect.Request[v1.SearchEntitiesRequest]) (*connect.Response[v1.SearchEntitiesResponse], error)
+	ImportEntity(context.Context, *connect.Request[v1.ImportEntityRequest]) (*connect.Response[v1.ImportEntityResponse], error)
+	ImportEntities(context.Context, *connect.Request[v1.ImportEntitiesRequest]) (*connect.Response[v1.ImportEntitiesResponse], error)
 }
 
 // NewGraphWriteServiceClient constructs a client for the libretto.graph.v1.GraphWriteService
@@ -59,12 +71,33 @@ func NewGraphWriteServiceClient(httpClient connect.HTTPClient, baseURL string, o
 			connect.WithSchema(graphWriteServiceMethods.ByName("Apply")),
 			connect.WithClientOptions(opts...),
 		),
+		searchEntities: connect.NewClient[v1.SearchEntitiesRequest, v1.SearchEntitiesResponse](
+			httpClient,
+			baseURL+GraphWriteServiceSearchEntitiesProcedure,
+			connect.WithSchema(graphWriteServiceMethods.ByName("SearchEntities")),
+			connect.WithClientOptions(opts...),
+		),
+		importEntity: connect.NewClient[v1.ImportEntityRequest, v1.ImportEntityResponse](
+			httpClient,
+			baseURL+GraphWriteServiceImportEntityProcedure,
+			connect.WithSchema(graphWriteServiceMethods.ByName("ImportEntity")),
+			connect.WithClientOptions(opts...),
+		),
+		importEntities: connect.NewClient[v1.ImportEntitiesRequest, v1.ImportEntitiesResponse](
+			httpClient,
+			baseURL+GraphWriteServiceImportEntitiesProcedure,
+			connect.WithSchema(graphWriteServiceMethods.ByName("ImportEntities")),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // graphWriteServiceClient implements GraphWriteServiceClient.
 type graphWriteServiceClient struct {
-	apply *connect.Client[v1.ApplyRequest, v1.ApplyResponse]
+	apply          *connect.Client[v1.ApplyRequest, v1.ApplyResponse]
+	searchEntities *connect.Client[v1.SearchEntitiesRequest, v1.SearchEntitiesResponse]
+	importEntity   *connect.Client[v1.ImportEntityRequest, v1.ImportEntityResponse]
+	importEntities *connect.Client[v1.ImportEntitiesRequest, v1.ImportEntitiesResponse]
 }
 
 // Apply calls libretto.graph.v1.GraphWriteService.Apply.
@@ -72,9 +105,27 @@ func (c *graphWriteServiceClient) Apply(ctx context.Context, req *connect.Reques
 	return c.apply.CallUnary(ctx, req)
 }
 
+// SearchEntities calls libretto.graph.v1.GraphWriteService.SearchEntities.
+func (c *graphWriteServiceClient) SearchEntities(ctx context.Context, req *connect.Request[v1.SearchEntitiesRequest]) (*connect.Response[v1.SearchEntitiesResponse], error) {
+	return c.searchEntities.CallUnary(ctx, req)
+}
+
+// ImportEntity calls libretto.graph.v1.GraphWriteService.ImportEntity.
+func (c *graphWriteServiceClient) ImportEntity(ctx context.Context, req *connect.Request[v1.ImportEntityRequest]) (*connect.Response[v1.ImportEntityResponse], error) {
+	return c.importEntity.CallUnary(ctx, req)
+}
+
+// ImportEntities calls libretto.graph.v1.GraphWriteService.ImportEntities.
+func (c *graphWriteServiceClient) ImportEntities(ctx context.Context, req *connect.Request[v1.ImportEntitiesRequest]) (*connect.Response[v1.ImportEntitiesResponse], error) {
+	return c.importEntities.CallUnary(ctx, req)
+}
+
 // GraphWriteServiceHandler is an implementation of the libretto.graph.v1.GraphWriteService service.
 type GraphWriteServiceHandler interface {
 	Apply(context.Context, *connect.Request[v1.ApplyRequest]) (*connect.Response[v1.ApplyResponse], error)
+	SearchEntities(context.Context, *connect.Request[v1.SearchEntitiesRequest]) (*connect.Response[v1.SearchEntitiesResponse], error)
+	ImportEntity(context.Context, *connect.Request[v1.ImportEntityRequest]) (*connect.Response[v1.ImportEntityResponse], error)
+	ImportEntities(context.Context, *connect.Request[v1.ImportEntitiesRequest]) (*connect.Response[v1.ImportEntitiesResponse], error)
 }
 
 // NewGraphWriteServiceHandler builds an HTTP handler from the service implementation. It returns
@@ -90,10 +141,34 @@ func NewGraphWriteServiceHandler(svc GraphWriteServiceHandler, opts ...connect.H
 		connect.WithSchema(graphWriteServiceMethods.ByName("Apply")),
 		connect.WithHandlerOptions(opts...),
 	)
+	graphWriteServiceSearchEntitiesHandler := connect.NewUnaryHandler(
+		GraphWriteServiceSearchEntitiesProcedure,
+		svc.SearchEntities,
+		connect.WithSchema(graphWriteServiceMethods.ByName("SearchEntities")),
+		connect.WithHandlerOptions(opts...),
+	)
+	graphWriteServiceImportEntityHandler := connect.NewUnaryHandler(
+		GraphWriteServiceImportEntityProcedure,
+		svc.ImportEntity,
+		connect.WithSchema(graphWriteServiceMethods.ByName("ImportEntity")),
+		connect.WithHandlerOptions(opts...),
+	)
+	graphWriteServiceImportEntitiesHandler := connect.NewUnaryHandler(
+		GraphWriteServiceImportEntitiesProcedure,
+		svc.ImportEntities,
+		connect.WithSchema(graphWriteServiceMethods.ByName("ImportEntities")),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/libretto.graph.v1.GraphWriteService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case GraphWriteServiceApplyProcedure:
 			graphWriteServiceApplyHandler.ServeHTTP(w, r)
+		case GraphWriteServiceSearchEntitiesProcedure:
+			graphWriteServiceSearchEntitiesHandler.ServeHTTP(w, r)
+		case GraphWriteServiceImportEntityProcedure:
+			graphWriteServiceImportEntityHandler.ServeHTTP(w, r)
+		case GraphWriteServiceImportEntitiesProcedure:
+			graphWriteServiceImportEntitiesHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -106,3 +181,15 @@ type UnimplementedGraphWriteServiceHandler struct{}
 func (UnimplementedGraphWriteServiceHandler) Apply(context.Context, *connect.Request[v1.ApplyRequest]) (*connect.Response[v1.ApplyResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("libretto.graph.v1.GraphWriteService.Apply is not implemented"))
 }
+
+func (UnimplementedGraphWriteServiceHandler) SearchEntities(context.Context, *connect.Request[v1.SearchEntitiesRequest]) (*connect.Response[v1.SearchEntitiesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("libretto.graph.v1.GraphWriteService.SearchEntities is not implemented"))
+}
+
+func (UnimplementedGraphWriteServiceHandler) ImportEntity(context.Context, *connect.Request[v1.ImportEntityRequest]) (*connect.Response[v1.ImportEntityResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("libretto.graph.v1.GraphWriteService.ImportEntity is not implemented"))
+}
+
+func (UnimplementedGraphWriteServiceHandler) ImportEntities(context.Context, *connect.Request[v1.ImportEntitiesRequest]) (*connect.Response[v1.ImportEntitiesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("libretto.graph.v1.GraphWriteService.ImportEntities is not implemented"))
+}
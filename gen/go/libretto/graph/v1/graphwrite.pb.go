@@ -193,6 +193,402 @@ func (x *ApplyResponse) GetApplied() int32 {
 	return 0
 }
 
+type Entity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // logical ID
+	VersionId     string                 `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	EntityType    string                 `protobuf:"bytes,3,opt,name=entity_type,json=entityType,proto3" json:"entity_type,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Fields        map[string]string      `protobuf:"bytes,5,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // non-string values are JSON-encoded
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entity) Reset() {
+	*x = Entity{}
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entity) ProtoMessage() {}
+
+func (x *Entity) ProtoReflect() protoreflect.Message {
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entity.ProtoReflect.Descriptor instead.
+func (*Entity) Descriptor() ([]byte, []int) {
+	return file_libretto_graph_v1_graphwrite_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Entity) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Entity) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *Entity) GetEntityType() string {
+	if x != nil {
+		return x.EntityType
+	}
+	return ""
+}
+
+func (x *Entity) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Entity) GetFields() map[string]string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type SearchEntitiesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VersionId     string                 `protobuf:"bytes,1,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"` // space-separated terms; "field:value" scopes a term to one field
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchEntitiesRequest) Reset() {
+	*x = SearchEntitiesRequest{}
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchEntitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchEntitiesRequest) ProtoMessage() {}
+
+func (x *SearchEntitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchEntitiesRequest.ProtoReflect.Descriptor instead.
+func (*SearchEntitiesRequest) Descriptor() ([]byte, []int) {
+	return file_libretto_graph_v1_graphwrite_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SearchEntitiesRequest) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *SearchEntitiesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+type SearchEntitiesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entities      []*Entity              `protobuf:"bytes,1,rep,name=entities,proto3" json:"entities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchEntitiesResponse) Reset() {
+	*x = SearchEntitiesResponse{}
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchEntitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchEntitiesResponse) ProtoMessage() {}
+
+func (x *SearchEntitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchEntitiesResponse.ProtoReflect.Descriptor instead.
+func (*SearchEntitiesResponse) Descriptor() ([]byte, []int) {
+	return file_libretto_graph_v1_graphwrite_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SearchEntitiesResponse) GetEntities() []*Entity {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+type ImportEntityRequest struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	TargetVersionId      string                 `protobuf:"bytes,1,opt,name=target_version_id,json=targetVersionId,proto3" json:"target_version_id,omitempty"`
+	SourceProjectId      string                 `protobuf:"bytes,2,opt,name=source_project_id,json=sourceProjectId,proto3" json:"source_project_id,omitempty"`
+	EntityId             string                 `protobuf:"bytes,3,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`                                      // logical ID, kept in the target version
+	IncludeRelationships bool                   `protobuf:"varint,4,opt,name=include_relationships,json=includeRelationships,proto3" json:"include_relationships,omitempty"` // also bring over relationships to entities already in the target
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ImportEntityRequest) Reset() {
+	*x = ImportEntityRequest{}
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportEntityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportEntityRequest) ProtoMessage() {}
+
+func (x *ImportEntityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportEntityRequest.ProtoReflect.Descriptor instead.
+func (*ImportEntityRequest) Descriptor() ([]byte, []int) {
+	return file_libretto_graph_v1_graphwrite_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ImportEntityRequest) GetTargetVersionId() string {
+	if x != nil {
+		return x.TargetVersionId
+	}
+	return ""
+}
+
+func (x *ImportEntityRequest) GetSourceProjectId() string {
+	if x != nil {
+		return x.SourceProjectId
+	}
+	return ""
+}
+
+func (x *ImportEntityRequest) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *ImportEntityRequest) GetIncludeRelationships() bool {
+	if x != nil {
+		return x.IncludeRelationships
+	}
+	return false
+}
+
+type ImportEntityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entity        *Entity                `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportEntityResponse) Reset() {
+	*x = ImportEntityResponse{}
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportEntityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportEntityResponse) ProtoMessage() {}
+
+func (x *ImportEntityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportEntityResponse.ProtoReflect.Descriptor instead.
+func (*ImportEntityResponse) Descriptor() ([]byte, []int) {
+	return file_libretto_graph_v1_graphwrite_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ImportEntityResponse) GetEntity() *Entity {
+	if x != nil {
+		return x.Entity
+	}
+	return nil
+}
+
+type ImportEntitiesRequest struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	TargetVersionId      string                 `protobuf:"bytes,1,opt,name=target_version_id,json=targetVersionId,proto3" json:"target_version_id,omitempty"`
+	SourceProjectId      string                 `protobuf:"bytes,2,opt,name=source_project_id,json=sourceProjectId,proto3" json:"source_project_id,omitempty"`
+	EntityIds            []string               `protobuf:"bytes,3,rep,name=entity_ids,json=entityIds,proto3" json:"entity_ids,omitempty"`                                   // logical IDs, imported in order
+	IncludeRelationships bool                   `protobuf:"varint,4,opt,name=include_relationships,json=includeRelationships,proto3" json:"include_relationships,omitempty"` // also bring over relationships among them and to entities already in the target
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ImportEntitiesRequest) Reset() {
+	*x = ImportEntitiesRequest{}
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportEntitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportEntitiesRequest) ProtoMessage() {}
+
+func (x *ImportEntitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportEntitiesRequest.ProtoReflect.Descriptor instead.
+func (*ImportEntitiesRequest) Descriptor() ([]byte, []int) {
+	return file_libretto_graph_v1_graphwrite_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ImportEntitiesRequest) GetTargetVersionId() string {
+	if x != nil {
+		return x.TargetVersionId
+	}
+	return ""
+}
+
+func (x *ImportEntitiesRequest) GetSourceProjectId() string {
+	if x != nil {
+		return x.SourceProjectId
+	}
+	return ""
+}
+
+func (x *ImportEntitiesRequest) GetEntityIds() []string {
+	if x != nil {
+		return x.EntityIds
+	}
+	return nil
+}
+
+func (x *ImportEntitiesRequest) GetIncludeRelationships() bool {
+	if x != nil {
+		return x.IncludeRelationships
+	}
+	return false
+}
+
+type ImportEntitiesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entities      []*Entity              `protobuf:"bytes,1,rep,name=entities,proto3" json:"entities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportEntitiesResponse) Reset() {
+	*x = ImportEntitiesResponse{}
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportEntitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportEntitiesResponse) ProtoMessage() {}
+
+func (x *ImportEntitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libretto_graph_v1_graphwrite_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportEntitiesResponse.ProtoReflect.Descriptor instead.
+func (*ImportEntitiesResponse) Descriptor() ([]byte, []int) {
+	return file_libretto_graph_v1_graphwrite_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ImportEntitiesResponse) GetEntities() []*Entity {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
 var File_libretto_graph_v1_graphwrite_proto protoreflect.FileDescriptor
 
 const file_libretto_graph_v1_graphwrite_proto_rawDesc = "" +
@@ -212,9 +608,44 @@ const file_libretto_graph_v1_graphwrite_proto_rawDesc = "" +
 	"\x06deltas\x18\x02 \x03(\v2\x18.libretto.graph.v1.DeltaR\x06deltas\"S\n" +
 	"\rApplyResponse\x12(\n" +
 	"\x10graph_version_id\x18\x01 \x01(\tR\x0egraphVersionId\x12\x18\n" +
-	"\aapplied\x18\x02 \x01(\x05R\aapplied2_\n" +
+	"\aapplied\x18\x02 \x01(\x05R\aapplied\"\xe6\x01\n" +
+	"\x06Entity\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\tR\tversionId\x12\x1f\n" +
+	"\ventity_type\x18\x03 \x01(\tR\n" +
+	"entityType\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12=\n" +
+	"\x06fields\x18\x05 \x03(\v2%.libretto.graph.v1.Entity.FieldsEntryR\x06fields\x1a9\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"L\n" +
+	"\x15SearchEntitiesRequest\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x01 \x01(\tR\tversionId\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\"O\n" +
+	"\x16SearchEntitiesResponse\x125\n" +
+	"\bentities\x18\x01 \x03(\v2\x19.libretto.graph.v1.EntityR\bentities\"\xbf\x01\n" +
+	"\x13ImportEntityRequest\x12*\n" +
+	"\x11target_version_id\x18\x01 \x01(\tR\x0ftargetVersionId\x12*\n" +
+	"\x11source_project_id\x18\x02 \x01(\tR\x0fsourceProjectId\x12\x1b\n" +
+	"\tentity_id\x18\x03 \x01(\tR\bentityId\x123\n" +
+	"\x15include_relationships\x18\x04 \x01(\bR\x14includeRelationships\"I\n" +
+	"\x14ImportEntityResponse\x121\n" +
+	"\x06entity\x18\x01 \x01(\v2\x19.libretto.graph.v1.EntityR\x06entity\"\xc3\x01\n" +
+	"\x15ImportEntitiesRequest\x12*\n" +
+	"\x11target_version_id\x18\x01 \x01(\tR\x0ftargetVersionId\x12*\n" +
+	"\x11source_project_id\x18\x02 \x01(\tR\x0fsourceProjectId\x12\x1d\n" +
+	"\n" +
+	"entity_ids\x18\x03 \x03(\tR\tentityIds\x123\n" +
+	"\x15include_relationships\x18\x04 \x01(\bR\x14includeRelationships\"O\n" +
+	"\x16ImportEntitiesResponse\x125\n" +
+	"\bentities\x18\x01 \x03(\v2\x19.libretto.graph.v1.EntityR\bentities2\x8e\x03\n" +
 	"\x11GraphWriteService\x12J\n" +
-	"\x05Apply\x12\x1f.libretto.graph.v1.ApplyRequest\x1a .libretto.graph.v1.ApplyResponseBDZBgithub.com/barrynorthern/libretto/gen/go/libretto/graph/v1;graphv1b\x06proto3"
+	"\x05Apply\x12\x1f.libretto.graph.v1.ApplyRequest\x1a .libretto.graph.v1.ApplyResponse\x12e\n" +
+	"\x0eSearchEntities\x12(.libretto.graph.v1.SearchEntitiesRequest\x1a).libretto.graph.v1.SearchEntitiesResponse\x12_\n" +
+	"\fImportEntity\x12&.libretto.graph.v1.ImportEntityRequest\x1a'.libretto.graph.v1.ImportEntityResponse\x12e\n" +
+	"\x0eImportEntities\x12(.libretto.graph.v1.ImportEntitiesRequest\x1a).libretto.graph.v1.ImportEntitiesResponseBDZBgithub.com/barrynorthern/libretto/gen/go/libretto/graph/v1;graphv1b\x06proto3"
 
 var (
 	file_libretto_graph_v1_graphwrite_proto_rawDescOnce sync.Once
@@ -228,23 +659,41 @@ func file_libretto_graph_v1_graphwrite_proto_rawDescGZIP() []byte {
 	return file_libretto_graph_v1_graphwrite_proto_rawDescData
 }
 
-var file_libretto_graph_v1_graphwrite_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_libretto_graph_v1_graphwrite_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_libretto_graph_v1_graphwrite_proto_goTypes = []any{
-	(*Delta)(nil),         // 0: libretto.graph.v1.Delta
-	(*ApplyRequest)(nil),  // 1: libretto.graph.v1.ApplyRequest
-	(*ApplyResponse)(nil), // 2: libretto.graph.v1.ApplyResponse
-	nil,                   // 3: libretto.graph.v1.Delta.FieldsEntry
+	(*Delta)(nil),                  // 0: libretto.graph.v1.Delta
+	(*ApplyRequest)(nil),           // 1: libretto.graph.v1.ApplyRequest
+	(*ApplyResponse)(nil),          // 2: libretto.graph.v1.ApplyResponse
+	(*Entity)(nil),                 // 3: libretto.graph.v1.Entity
+	(*SearchEntitiesRequest)(nil),  // 4: libretto.graph.v1.SearchEntitiesRequest
+	(*SearchEntitiesResponse)(nil), // 5: libretto.graph.v1.SearchEntitiesResponse
+	(*ImportEntityRequest)(nil),    // 6: libretto.graph.v1.ImportEntityRequest
+	(*ImportEntityResponse)(nil),   // 7: libretto.graph.v1.ImportEntityResponse
+	(*ImportEntitiesRequest)(nil),  // 8: libretto.graph.v1.ImportEntitiesRequest
+	(*ImportEntitiesResponse)(nil), // 9: libretto.graph.v1.ImportEntitiesResponse
+	nil,                            // 10: libretto.graph.v1.Delta.FieldsEntry
+	nil,                            // 11: libretto.graph.v1.Entity.FieldsEntry
 }
 var file_libretto_graph_v1_graphwrite_proto_depIdxs = []int32{
-	3, // 0: libretto.graph.v1.Delta.fields:type_name -> libretto.graph.v1.Delta.FieldsEntry
-	0, // 1: libretto.graph.v1.ApplyRequest.deltas:type_name -> libretto.graph.v1.Delta
-	1, // 2: libretto.graph.v1.GraphWriteService.Apply:input_type -> libretto.graph.v1.ApplyRequest
-	2, // 3: libretto.graph.v1.GraphWriteService.Apply:output_type -> libretto.graph.v1.ApplyResponse
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	10, // 0: libretto.graph.v1.Delta.fields:type_name -> libretto.graph.v1.Delta.FieldsEntry
+	0,  // 1: libretto.graph.v1.ApplyRequest.deltas:type_name -> libretto.graph.v1.Delta
+	11, // 2: libretto.graph.v1.Entity.fields:type_name -> libretto.graph.v1.Entity.FieldsEntry
+	3,  // 3: libretto.graph.v1.SearchEntitiesResponse.entities:type_name -> libretto.graph.v1.Entity
+	3,  // 4: libretto.graph.v1.ImportEntityResponse.entity:type_name -> libretto.graph.v1.Entity
+	3,  // 5: libretto.graph.v1.ImportEntitiesResponse.entities:type_name -> libretto.graph.v1.Entity
+	1,  // 6: libretto.graph.v1.GraphWriteService.Apply:input_type -> libretto.graph.v1.ApplyRequest
+	4,  // 7: libretto.graph.v1.GraphWriteService.SearchEntities:input_type -> libretto.graph.v1.SearchEntitiesRequest
+	6,  // 8: libretto.graph.v1.GraphWriteService.ImportEntity:input_type -> libretto.graph.v1.ImportEntityRequest
+	8,  // 9: libretto.graph.v1.GraphWriteService.ImportEntities:input_type -> libretto.graph.v1.ImportEntitiesRequest
+	2,  // 10: libretto.graph.v1.GraphWriteService.Apply:output_type -> libretto.graph.v1.ApplyResponse
+	5,  // 11: libretto.graph.v1.GraphWriteService.SearchEntities:output_type -> libretto.graph.v1.SearchEntitiesResponse
+	7,  // 12: libretto.graph.v1.GraphWriteService.ImportEntity:output_type -> libretto.graph.v1.ImportEntityResponse
+	9,  // 13: libretto.graph.v1.GraphWriteService.ImportEntities:output_type -> libretto.graph.v1.ImportEntitiesResponse
+	10, // [10:14] is the sub-list for method output_type
+	6,  // [6:10] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_libretto_graph_v1_graphwrite_proto_init() }
@@ -258,7 +707,7 @@ func file_libretto_graph_v1_graphwrite_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_libretto_graph_v1_graphwrite_proto_rawDesc), len(file_libretto_graph_v1_graphwrite_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -8,4 +8,4 @@ require (
 	google.golang.org/protobuf v1.33.0
 )
 
-require github.com/mattn/go-sqlite3 v1.14.32 // indirect
+require github.com/mattn/go-sqlite3 v1.14.32
@@ -0,0 +1,136 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// ErrDuplicateAnalyzer is returned when an analyzer name is registered twice
+var ErrDuplicateAnalyzer = errors.New("analyzer already registered")
+
+// AnnotationInput is an annotation an Analyzer proposes for the entity it was given
+type AnnotationInput struct {
+	AnnotationType types.AnnotationType
+	Content        string
+	Metadata       map[string]any
+}
+
+// Analyzer inspects a single entity and proposes annotations for it. Analyzers
+// must not write to the graph themselves; the orchestrator persists their output
+// with the analyzer's Name as the annotation's agent.
+type Analyzer interface {
+	Name() string
+	Analyze(ctx context.Context, entity *graphwrite.Entity) ([]AnnotationInput, error)
+}
+
+// Registry holds analyzers in registration order
+type Registry struct {
+	mu        sync.RWMutex
+	analyzers []Analyzer
+	names     map[string]bool
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// Register adds an analyzer. Names must be unique within a registry.
+func (r *Registry) Register(analyzer Analyzer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.names[analyzer.Name()] {
+		return fmt.Errorf("%w: %s", ErrDuplicateAnalyzer, analyzer.Name())
+	}
+	r.names[analyzer.Name()] = true
+	r.analyzers = append(r.analyzers, analyzer)
+	return nil
+}
+
+// Analyzers returns the registered analyzers in registration order
+func (r *Registry) Analyzers() []Analyzer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Analyzer(nil), r.analyzers...)
+}
+
+// Ordered returns the registered analyzers with the named ones first, in the given
+// order, followed by the rest in registration order. Unknown names are ignored so that
+// a saved pipeline order survives an analyzer being removed.
+func (r *Registry) Ordered(names []string) []Analyzer {
+	analyzers := r.Analyzers()
+	byName := make(map[string]Analyzer, len(analyzers))
+	for _, analyzer := range analyzers {
+		byName[analyzer.Name()] = analyzer
+	}
+
+	ordered := make([]Analyzer, 0, len(analyzers))
+	placed := make(map[string]bool, len(names))
+	for _, name := range names {
+		if analyzer, ok := byName[name]; ok && !placed[name] {
+			ordered = append(ordered, analyzer)
+			placed[name] = true
+		}
+	}
+	for _, analyzer := range analyzers {
+		if !placed[analyzer.Name()] {
+			ordered = append(ordered, analyzer)
+		}
+	}
+	return ordered
+}
+
+// Pipeline returns the enabled analyzers in the given order, as Ordered does. A nil
+// enabled list enables every registered analyzer; unknown names are ignored.
+func (r *Registry) Pipeline(enabled, order []string) []Analyzer {
+	ordered := r.Ordered(order)
+	if enabled == nil {
+		return ordered
+	}
+
+	isEnabled := make(map[string]bool, len(enabled))
+	for _, name := range enabled {
+		isEnabled[name] = true
+	}
+	pipeline := make([]Analyzer, 0, len(enabled))
+	for _, analyzer := range ordered {
+		if isEnabled[analyzer.Name()] {
+			pipeline = append(pipeline, analyzer)
+		}
+	}
+	return pipeline
+}
+
+// Default is the process-wide registry. The built-in analyzers register
+// themselves here, and the orchestrator uses it unless given another.
+var Default = NewRegistry()
+
+// Register adds an analyzer to the Default registry. It panics on a duplicate
+// name, so it is intended to be called from init functions.
+func Register(analyzer Analyzer) {
+	if err := Default.Register(analyzer); err != nil {
+		panic(err)
+	}
+}
+
+// toMetadata converts a typed annotation payload to the generic metadata map
+func toMetadata(payload any) (map[string]any, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal annotation metadata: %w", err)
+	}
+
+	var metadata map[string]any
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal annotation metadata: %w", err)
+	}
+	return metadata, nil
+}
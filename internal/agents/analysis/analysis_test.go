@@ -0,0 +1,150 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+type namedAnalyzer string
+
+func (n namedAnalyzer) Name() string { return string(n) }
+
+func (n namedAnalyzer) Analyze(context.Context, *graphwrite.Entity) ([]AnnotationInput, error) {
+	return nil, nil
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+
+	for _, name := range []string{"first", "second"} {
+		if err := registry.Register(namedAnalyzer(name)); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+	if err := registry.Register(namedAnalyzer("first")); !errors.Is(err, ErrDuplicateAnalyzer) {
+		t.Errorf("Expected ErrDuplicateAnalyzer, got %v", err)
+	}
+
+	analyzers := registry.Analyzers()
+	if len(analyzers) != 2 || analyzers[0].Name() != "first" || analyzers[1].Name() != "second" {
+		t.Errorf("Expected analyzers in registration order, got %v", analyzers)
+	}
+}
+
+func TestRegistry_Ordered(t *testing.T) {
+	registry := NewRegistry()
+	for _, name := range []string{"empath", "thematic", "continuity"} {
+		if err := registry.Register(namedAnalyzer(name)); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	ordered := registry.Ordered([]string{"continuity", "removed", "empath", "continuity"})
+	var names []string
+	for _, analyzer := range ordered {
+		names = append(names, analyzer.Name())
+	}
+	expected := []string{"continuity", "empath", "thematic"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, names)
+		}
+	}
+}
+
+func TestDefault_RegistersBuiltins(t *testing.T) {
+	registered := make(map[string]bool)
+	for _, analyzer := range Default.Analyzers() {
+		registered[analyzer.Name()] = true
+	}
+	for _, name := range []string{"empath", "thematic", "continuity", "clues"} {
+		if !registered[name] {
+			t.Errorf("Expected built-in analyzer %q in the default registry", name)
+		}
+	}
+}
+
+func TestBuiltins_ProduceValidMetadata(t *testing.T) {
+	scene := &graphwrite.Entity{
+		ID:         "scene-1",
+		EntityType: "Scene",
+		Data: map[string]any{
+			"emotional_tone": "tense",
+			"themes":         []any{"betrayal", "loyalty"},
+			"characters":     []any{"elena"},
+		},
+	}
+
+	for _, analyzer := range []Analyzer{empath{}, thematic{}, continuity{}} {
+		inputs, err := analyzer.Analyze(context.Background(), scene)
+		if err != nil {
+			t.Fatalf("%s failed: %v", analyzer.Name(), err)
+		}
+		if len(inputs) != 1 {
+			t.Fatalf("Expected one annotation from %s, got %d", analyzer.Name(), len(inputs))
+		}
+
+		raw, err := json.Marshal(inputs[0].Metadata)
+		if err != nil {
+			t.Fatalf("Failed to marshal metadata: %v", err)
+		}
+		if err := types.ValidateAnnotationMetadata(inputs[0].AnnotationType, raw); err != nil {
+			t.Errorf("%s produced invalid metadata: %v", analyzer.Name(), err)
+		}
+	}
+
+	// The scene has no location, so continuity flags it
+	inputs, _ := (continuity{}).Analyze(context.Background(), scene)
+	if inputs[0].Metadata["is_consistent"] != false {
+		t.Errorf("Expected continuity to flag the missing location, got %v", inputs[0].Metadata)
+	}
+
+	// Non-scene entities are ignored by the scene analyzers
+	character := &graphwrite.Entity{ID: "elena", EntityType: "Character", Data: map[string]any{"name": "Elena"}}
+	if inputs, _ := (empath{}).Analyze(context.Background(), character); len(inputs) != 0 {
+		t.Errorf("Expected empath to skip characters, got %v", inputs)
+	}
+}
+
+func TestClues_FlagsUnpaidClues(t *testing.T) {
+	for _, tt := range []struct {
+		data       map[string]any
+		consistent bool
+	}{
+		{data: map[string]any{"type": "clue"}, consistent: false},
+		{data: map[string]any{"type": "clue", "sets_up": []any{"reveal"}}, consistent: true},
+	} {
+		clue := &graphwrite.Entity{ID: "muddy-boots", EntityType: "PlotPoint", Data: tt.data}
+		inputs, err := (clues{}).Analyze(context.Background(), clue)
+		if err != nil {
+			t.Fatalf("Analyze failed: %v", err)
+		}
+		if len(inputs) != 1 {
+			t.Fatalf("Expected one annotation, got %d", len(inputs))
+		}
+		raw, err := json.Marshal(inputs[0].Metadata)
+		if err != nil {
+			t.Fatalf("Failed to marshal metadata: %v", err)
+		}
+		if err := types.ValidateAnnotationMetadata(inputs[0].AnnotationType, raw); err != nil {
+			t.Errorf("clues produced invalid metadata: %v", err)
+		}
+		if inputs[0].Metadata["is_consistent"] != tt.consistent {
+			t.Errorf("Expected is_consistent %v for %v, got %v", tt.consistent, tt.data, inputs[0].Metadata)
+		}
+	}
+
+	// Plot points that are not clues are ignored
+	twist := &graphwrite.Entity{ID: "twist", EntityType: "PlotPoint", Data: map[string]any{"type": "plot_twist"}}
+	if inputs, _ := (clues{}).Analyze(context.Background(), twist); len(inputs) != 0 {
+		t.Errorf("Expected clues to skip other plot points, got %v", inputs)
+	}
+}
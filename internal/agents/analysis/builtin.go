@@ -0,0 +1,209 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+func init() {
+	Register(empath{})
+	Register(thematic{})
+	Register(continuity{})
+	Register(clues{})
+}
+
+// Tone words recognised by the empath analyzer
+var (
+	positiveTones = map[string]bool{"hopeful": true, "joyful": true, "triumphant": true, "warm": true, "tender": true}
+	negativeTones = map[string]bool{"tense": true, "dark": true, "tragic": true, "grim": true, "fearful": true, "melancholic": true}
+)
+
+// empath scores the emotional tone of scenes
+type empath struct{}
+
+func (empath) Name() string { return "empath" }
+
+func (empath) Analyze(_ context.Context, entity *graphwrite.Entity) ([]AnnotationInput, error) {
+	if entity.EntityType != string(types.EntityTypeScene) {
+		return nil, nil
+	}
+	tone := strings.ToLower(stringField(entity.Data, "emotional_tone"))
+	if tone == "" {
+		return nil, nil
+	}
+
+	sentiment := 0.0
+	switch {
+	case positiveTones[tone]:
+		sentiment = 0.5
+	case negativeTones[tone]:
+		sentiment = -0.5
+	}
+
+	metadata, err := toMetadata(types.EmotionalAnalysisData{
+		Sentiment:    sentiment,
+		Emotions:     map[string]float64{tone: 1.0},
+		EmotionalArc: "stable",
+		ImpactScore:  0.5,
+		AnalyzedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []AnnotationInput{{
+		AnnotationType: types.AnnotationEmotionalAnalysis,
+		Content:        fmt.Sprintf("Emotional tone: %s", tone),
+		Metadata:       metadata,
+	}}, nil
+}
+
+// thematic scores how strongly scenes and plot points engage their themes
+type thematic struct{}
+
+func (thematic) Name() string { return "thematic" }
+
+func (thematic) Analyze(_ context.Context, entity *graphwrite.Entity) ([]AnnotationInput, error) {
+	if entity.EntityType != string(types.EntityTypeScene) && entity.EntityType != string(types.EntityTypePlotPoint) {
+		return nil, nil
+	}
+	themes := stringSliceField(entity.Data, "themes")
+	if len(themes) == 0 {
+		return nil, nil
+	}
+
+	alignment := make(map[string]float64, len(themes))
+	for _, theme := range themes {
+		alignment[theme] = 1.0 / float64(len(themes))
+	}
+
+	metadata, err := toMetadata(types.ThematicScoreData{
+		RelevanceScore: min(1.0, float64(len(themes))/3),
+		ThemeAlignment: alignment,
+		AnalyzedAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []AnnotationInput{{
+		AnnotationType: types.AnnotationThematicScore,
+		Content:        fmt.Sprintf("Engages %d theme(s): %s", len(themes), strings.Join(themes, ", ")),
+		Metadata:       metadata,
+	}}, nil
+}
+
+// continuity checks that scenes are anchored to a location and characters
+type continuity struct{}
+
+func (continuity) Name() string { return "continuity" }
+
+func (continuity) Analyze(_ context.Context, entity *graphwrite.Entity) ([]AnnotationInput, error) {
+	if entity.EntityType != string(types.EntityTypeScene) {
+		return nil, nil
+	}
+
+	var violations []types.ContinuityViolation
+	if stringField(entity.Data, "location") == "" {
+		violations = append(violations, types.ContinuityViolation{
+			Type:        "physical",
+			Description: "Scene has no location",
+			Severity:    "low",
+		})
+	}
+	if len(stringSliceField(entity.Data, "characters")) == 0 {
+		violations = append(violations, types.ContinuityViolation{
+			Type:        "character_presence",
+			Description: "Scene has no characters",
+			Severity:    "low",
+		})
+	}
+
+	metadata, err := toMetadata(types.ContinuityCheckData{
+		IsConsistent: len(violations) == 0,
+		Violations:   violations,
+		CheckedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content := "Scene is anchored to a location and characters"
+	if len(violations) > 0 {
+		content = fmt.Sprintf("Found %d continuity issue(s)", len(violations))
+	}
+
+	return []AnnotationInput{{
+		AnnotationType: types.AnnotationContinuityCheck,
+		Content:        content,
+		Metadata:       metadata,
+	}}, nil
+}
+
+// clues checks that every clue plot point foreshadows something, so that a mystery
+// plays fair with its reader
+type clues struct{}
+
+func (clues) Name() string { return "clues" }
+
+func (clues) Analyze(_ context.Context, entity *graphwrite.Entity) ([]AnnotationInput, error) {
+	if entity.EntityType != string(types.EntityTypePlotPoint) || stringField(entity.Data, "type") != "clue" {
+		return nil, nil
+	}
+
+	var violations []types.ContinuityViolation
+	if len(stringSliceField(entity.Data, "sets_up")) == 0 {
+		violations = append(violations, types.ContinuityViolation{
+			Type:        "foreshadowing",
+			Description: "Clue sets up no later plot point",
+			Severity:    "medium",
+		})
+	}
+
+	metadata, err := toMetadata(types.ContinuityCheckData{
+		IsConsistent: len(violations) == 0,
+		Violations:   violations,
+		CheckedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content := "Clue is paid off later in the story"
+	if len(violations) > 0 {
+		content = "Clue is never paid off"
+	}
+
+	return []AnnotationInput{{
+		AnnotationType: types.AnnotationContinuityCheck,
+		Content:        content,
+		Metadata:       metadata,
+	}}, nil
+}
+
+// stringField reads a string value from entity data, returning "" when absent
+func stringField(data map[string]any, key string) string {
+	value, _ := data[key].(string)
+	return value
+}
+
+// stringSliceField reads a list of strings from entity data, skipping non-string items
+func stringSliceField(data map[string]any, key string) []string {
+	var values []string
+	switch list := data[key].(type) {
+	case []string:
+		values = append(values, list...)
+	case []any:
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
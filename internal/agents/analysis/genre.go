@@ -0,0 +1,30 @@
+package analysis
+
+import (
+	"strings"
+	"unicode"
+)
+
+// GenreAnalyzers maps lowercase genre words to the analyzers a project of that genre
+// runs by default. Project settings can replace the set per project.
+var GenreAnalyzers = map[string][]string{
+	"mystery":  {"clues", "continuity", "empath"},
+	"thriller": {"clues", "continuity", "empath"},
+	"fantasy":  {"thematic", "empath", "continuity"},
+	"romance":  {"empath", "thematic"},
+}
+
+// AnalyzersForGenre returns the default analyzer set for a genre, matching each word
+// of it in turn so that "Cozy Mystery" runs the mystery set. It returns nil, meaning
+// every registered analyzer, for an empty or unknown genre.
+func AnalyzersForGenre(genre string) []string {
+	words := strings.FieldsFunc(strings.ToLower(genre), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		if analyzers, ok := GenreAnalyzers[word]; ok {
+			return append([]string(nil), analyzers...)
+		}
+	}
+	return nil
+}
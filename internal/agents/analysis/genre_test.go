@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzersForGenre(t *testing.T) {
+	tests := []struct {
+		genre    string
+		expected []string
+	}{
+		{genre: "Mystery", expected: []string{"clues", "continuity", "empath"}},
+		{genre: "Cozy Mystery", expected: []string{"clues", "continuity", "empath"}},
+		{genre: "epic-fantasy", expected: []string{"thematic", "empath", "continuity"}},
+		{genre: "Literary", expected: nil},
+		{genre: "", expected: nil},
+	}
+
+	for _, tt := range tests {
+		if got := AnalyzersForGenre(tt.genre); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("AnalyzersForGenre(%q) = %v, expected %v", tt.genre, got, tt.expected)
+		}
+	}
+}
+
+func TestDefault_RegistersGenreAnalyzers(t *testing.T) {
+	registered := make(map[string]bool)
+	for _, analyzer := range Default.Analyzers() {
+		registered[analyzer.Name()] = true
+	}
+	for genre, names := range GenreAnalyzers {
+		for _, name := range names {
+			if !registered[name] {
+				t.Errorf("Genre %q defaults to unregistered analyzer %q", genre, name)
+			}
+		}
+	}
+}
+
+func TestRegistry_Pipeline(t *testing.T) {
+	registry := NewRegistry()
+	for _, analyzer := range []Analyzer{empath{}, thematic{}, continuity{}, clues{}} {
+		if err := registry.Register(analyzer); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	names := func(analyzers []Analyzer) []string {
+		result := make([]string, 0, len(analyzers))
+		for _, analyzer := range analyzers {
+			result = append(result, analyzer.Name())
+		}
+		return result
+	}
+
+	if got := names(registry.Pipeline(nil, []string{"clues"})); !reflect.DeepEqual(got, []string{"clues", "empath", "thematic", "continuity"}) {
+		t.Errorf("Expected every analyzer with clues first, got %v", got)
+	}
+	if got := names(registry.Pipeline([]string{"empath", "clues", "removed"}, []string{"clues"})); !reflect.DeepEqual(got, []string{"clues", "empath"}) {
+		t.Errorf("Expected only the enabled analyzers, got %v", got)
+	}
+	if got := registry.Pipeline([]string{}, nil); len(got) != 0 {
+		t.Errorf("Expected an empty set to enable nothing, got %v", names(got))
+	}
+}
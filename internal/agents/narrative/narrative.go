@@ -18,7 +18,19 @@ type impl struct{}
 
 func (i *impl) ApplySceneProposal(ctx context.Context, service graphwrite.GraphWriteService, versionID string, p plotweaver.SceneProposal) error {
 	// Map proposal to a graph delta using the current GraphWrite service
-	req := &graphwrite.ApplyRequest{
+	_, err := service.Apply(ctx, SceneProposalRequest(versionID, p))
+	if err != nil {
+		return err
+	}
+	
+	log.Printf("narrative: applied Scene %s title=%q", p.SceneID, p.Title)
+	return nil
+}
+
+// SceneProposalRequest translates a scene proposal into the deltas that create its
+// scene on top of versionID
+func SceneProposalRequest(versionID string, p plotweaver.SceneProposal) *graphwrite.ApplyRequest {
+	return &graphwrite.ApplyRequest{
 		ParentVersionID: versionID,
 		Deltas: []*graphwrite.Delta{
 			{
@@ -32,13 +44,4 @@ func (i *impl) ApplySceneProposal(ctx context.Context, service graphwrite.GraphW
 			},
 		},
 	}
-	
-	_, err := service.Apply(ctx, req)
-	if err != nil {
-		return err
-	}
-	
-	log.Printf("narrative: applied Scene %s title=%q", p.SceneID, p.Title)
-	return nil
 }
-
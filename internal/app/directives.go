@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/barrynorthern/libretto/internal/agents/narrative"
+	gwpkg "github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+// Directive is one instruction of a batch, with the fields IssueDirective takes
+type Directive struct {
+	Text   string `json:"text"`
+	Act    string `json:"act"`
+	Target string `json:"target"`
+}
+
+// DirectiveResult summarizes what one directive of a batch did
+type DirectiveResult struct {
+	CorrelationID string   `json:"correlation_id"`
+	VersionID     string   `json:"version_id"`
+	Created       []string `json:"created"`     // logical IDs of the entities it created
+	Annotations   int      `json:"annotations"` // annotations the analyzers added to them
+}
+
+// DirectiveBatchResult is the outcome of ApplyDirectives
+type DirectiveBatchResult struct {
+	WorkingSetVersionID string            `json:"working_set_version_id"`
+	Results             []DirectiveResult `json:"results"`
+}
+
+// ApplyDirectives runs an ordered batch of directives, such as a whole outline, on top
+// of parentVersionID. Each directive is translated into deltas, applied as a new version
+// that becomes the working set and the next directive's parent, and its new entities are
+// run through the analyzer pipeline. A failing directive stops the batch; the versions
+// of the directives before it are kept, and the error comes with the partial result
+// describing them and the working set they left behind.
+func (o *Orchestrator) ApplyDirectives(ctx context.Context, parentVersionID string, directives []Directive) (*DirectiveBatchResult, error) {
+	if len(directives) == 0 {
+		return nil, fmt.Errorf("no directives provided")
+	}
+
+	result := &DirectiveBatchResult{WorkingSetVersionID: parentVersionID}
+	for i, directive := range directives {
+		proposal := o.plot.ProcessDirective(ctx, directive.Text, directive.Act, directive.Target, o.producer)
+		req := narrative.SceneProposalRequest(result.WorkingSetVersionID, proposal)
+
+		response, err := o.gw.ApplyAndAdvance(ctx, req)
+		if err != nil {
+			return result, fmt.Errorf("directive %d: %w", i, err)
+		}
+		result.WorkingSetVersionID = response.GraphVersionID
+
+		created := make(map[string]bool)
+		summary := DirectiveResult{CorrelationID: proposal.CorrelationId, VersionID: response.GraphVersionID}
+		for _, delta := range req.Deltas {
+			if delta.Operation == "create" {
+				created[delta.EntityID] = true
+				summary.Created = append(summary.Created, delta.EntityID)
+			}
+		}
+
+		summary.Annotations, err = o.analyze(ctx, response.GraphVersionID, created)
+		result.Results = append(result.Results, summary)
+		if err != nil {
+			return result, fmt.Errorf("directive %d: %w", i, err)
+		}
+	}
+
+	return result, nil
+}
+
+// applyDirectivesRequest is the body of a bulk directives request
+type applyDirectivesRequest struct {
+	ParentVersionID string      `json:"parent_version_id"`
+	Directives      []Directive `json:"directives"`
+}
+
+// directivesError is the body of a failed bulk directives request. Result holds what
+// the directives before the failing one did, when any of them were applied.
+type directivesError struct {
+	Error  string                `json:"error"`
+	Code   string                `json:"code"`
+	Result *DirectiveBatchResult `json:"result,omitempty"`
+}
+
+// HandleApplyDirectives serves ApplyDirectives as JSON, e.g. POST /api/directives with
+// {"parent_version_id": "...", "directives": [{"text": "...", "act": "I"}]}. A
+// directive rejected by validation fails with 422 and any other failure with 500.
+func (o *Orchestrator) HandleApplyDirectives(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeDirectivesJSON(w, http.StatusMethodNotAllowed, directivesError{Error: "method not allowed", Code: "method_not_allowed"})
+		return
+	}
+
+	var req applyDirectivesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDirectivesJSON(w, http.StatusBadRequest, directivesError{Error: "invalid request body", Code: "bad_request"})
+		return
+	}
+	if req.ParentVersionID == "" || len(req.Directives) == 0 {
+		writeDirectivesJSON(w, http.StatusBadRequest, directivesError{Error: "parent_version_id and directives are required", Code: "bad_request"})
+		return
+	}
+
+	result, err := o.ApplyDirectives(r.Context(), req.ParentVersionID, req.Directives)
+	if err != nil {
+		body := directivesError{Error: err.Error(), Code: "internal", Result: result}
+		status := http.StatusInternalServerError
+		if isValidationError(err) {
+			body.Code, status = "validation_failed", http.StatusUnprocessableEntity
+		}
+		writeDirectivesJSON(w, status, body)
+		return
+	}
+
+	writeDirectivesJSON(w, http.StatusOK, result)
+}
+
+// isValidationError reports whether err is graphwrite rejecting the deltas themselves
+// rather than failing to store them
+func isValidationError(err error) bool {
+	return errors.Is(err, gwpkg.ErrNoDeltas) ||
+		errors.Is(err, gwpkg.ErrUnknownOperation) ||
+		errors.Is(err, gwpkg.ErrSelfRelationship) ||
+		errors.Is(err, gwpkg.ErrCrossVersionRelationship) ||
+		errors.Is(err, gwpkg.ErrInvalidRelationshipEndpoints) ||
+		errors.Is(err, gwpkg.ErrInvalidEntityData)
+}
+
+// writeDirectivesJSON writes value as the JSON response with the given status
+func writeDirectivesJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
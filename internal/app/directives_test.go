@@ -0,0 +1,190 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/agents/analysis"
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+func TestOrchestrator_ApplyDirectivesChainsVersions(t *testing.T) {
+	service, versionID := setupAnalysisVersion(t)
+	ctx := context.Background()
+
+	registry := analysis.NewRegistry()
+	if err := registry.Register(pacingAnalyzer{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	orchestrator := NewOrchestratorWithAnalyzers(service, versionID, registry)
+
+	result, err := orchestrator.ApplyDirectives(ctx, versionID, []Directive{
+		{Text: "introduce the heist", Act: "I", Target: "scene"},
+		{Text: "raise the stakes", Act: "II", Target: "scene"},
+		{Text: "resolve the betrayal", Act: "III", Target: "scene"},
+	})
+	if err != nil {
+		t.Fatalf("ApplyDirectives failed: %v", err)
+	}
+
+	if len(result.Results) != 3 {
+		t.Fatalf("Expected 3 directive results, got %d", len(result.Results))
+	}
+	parentVersionID := versionID
+	var created []string
+	for i, summary := range result.Results {
+		if len(summary.Created) != 1 {
+			t.Errorf("Expected directive %d to create one scene, got %v", i, summary.Created)
+		}
+		if summary.Annotations != 1 {
+			t.Errorf("Expected directive %d's scene alone to be analyzed, got %d annotations", i, summary.Annotations)
+		}
+		if summary.CorrelationID == "" {
+			t.Errorf("Expected directive %d to carry a correlation ID", i)
+		}
+
+		version, err := service.GetVersion(ctx, summary.VersionID)
+		if err != nil {
+			t.Fatalf("GetVersion failed: %v", err)
+		}
+		if version.ParentVersionID == nil || *version.ParentVersionID != parentVersionID {
+			t.Errorf("Expected directive %d to build on %s, got %v", i, parentVersionID, version.ParentVersionID)
+		}
+		parentVersionID = summary.VersionID
+		created = append(created, summary.Created...)
+	}
+
+	if result.WorkingSetVersionID != result.Results[2].VersionID {
+		t.Errorf("Expected the last directive's version as the working set, got %s", result.WorkingSetVersionID)
+	}
+	workingSet, err := service.GetVersion(ctx, result.WorkingSetVersionID)
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if !workingSet.IsWorkingSet {
+		t.Error("Expected the final version to be the project's working set")
+	}
+
+	sceneType := "Scene"
+	scenes, err := service.ListEntities(ctx, result.WorkingSetVersionID, graphwrite.EntityFilter{EntityType: &sceneType})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	ids := make(map[string]bool, len(scenes))
+	for _, scene := range scenes {
+		ids[scene.ID] = true
+	}
+	if len(scenes) != 4 {
+		t.Errorf("Expected the opening scene plus 3 generated ones, got %d", len(scenes))
+	}
+	for _, id := range created {
+		if !ids[id] {
+			t.Errorf("Expected scene %s in the final version", id)
+		}
+	}
+}
+
+func TestOrchestrator_HandleApplyDirectives(t *testing.T) {
+	service, versionID := setupAnalysisVersion(t)
+	orchestrator := NewOrchestratorWithAnalyzers(service, versionID, analysis.NewRegistry())
+
+	body := `{"parent_version_id": "` + versionID + `", "directives": [{"text": "open"}, {"text": "close"}]}`
+	w := httptest.NewRecorder()
+	orchestrator.HandleApplyDirectives(w, httptest.NewRequest(http.MethodPost, "/api/directives", strings.NewReader(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result DirectiveBatchResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(result.Results) != 2 || result.WorkingSetVersionID != result.Results[1].VersionID {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	w = httptest.NewRecorder()
+	orchestrator.HandleApplyDirectives(w, httptest.NewRequest(http.MethodPost, "/api/directives", strings.NewReader(`{"parent_version_id": "x"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without directives, got %d", w.Code)
+	}
+}
+
+// failingApplyService fails every ApplyAndAdvance after the first `succeed` calls
+type failingApplyService struct {
+	graphwrite.GraphWriteService
+	succeed int
+	err     error
+}
+
+func (s *failingApplyService) ApplyAndAdvance(ctx context.Context, req *graphwrite.ApplyRequest) (*graphwrite.ApplyResponse, error) {
+	if s.succeed == 0 {
+		return nil, s.err
+	}
+	s.succeed--
+	return s.GraphWriteService.ApplyAndAdvance(ctx, req)
+}
+
+func TestOrchestrator_ApplyDirectivesReturnsPartialResult(t *testing.T) {
+	service, versionID := setupAnalysisVersion(t)
+	failing := &failingApplyService{GraphWriteService: service, succeed: 1, err: errors.New("disk full")}
+	orchestrator := NewOrchestratorWithAnalyzers(failing, versionID, analysis.NewRegistry())
+
+	result, err := orchestrator.ApplyDirectives(context.Background(), versionID, []Directive{{Text: "open"}, {Text: "close"}})
+	if err == nil {
+		t.Fatal("Expected the second directive to fail")
+	}
+	if result == nil || len(result.Results) != 1 {
+		t.Fatalf("Expected the first directive's result alongside the error, got %+v", result)
+	}
+	if result.WorkingSetVersionID != result.Results[0].VersionID {
+		t.Errorf("Expected the first directive's version as the working set, got %s", result.WorkingSetVersionID)
+	}
+}
+
+func TestOrchestrator_HandleApplyDirectivesErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"validation", fmt.Errorf("%w: Scene s1: title: required", graphwrite.ErrInvalidEntityData), http.StatusUnprocessableEntity, "validation_failed"},
+		{"storage", errors.New("disk full"), http.StatusInternalServerError, "internal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, versionID := setupAnalysisVersion(t)
+			failing := &failingApplyService{GraphWriteService: service, succeed: 1, err: tt.err}
+			orchestrator := NewOrchestratorWithAnalyzers(failing, versionID, analysis.NewRegistry())
+
+			body := `{"parent_version_id": "` + versionID + `", "directives": [{"text": "open"}, {"text": "close"}]}`
+			w := httptest.NewRecorder()
+			orchestrator.HandleApplyDirectives(w, httptest.NewRequest(http.MethodPost, "/api/directives", strings.NewReader(body)))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Expected a JSON error, got %q", contentType)
+			}
+			var response directivesError
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Code != tt.wantCode {
+				t.Errorf("Expected code %q, got %q", tt.wantCode, response.Code)
+			}
+			if response.Result == nil || len(response.Result.Results) != 1 {
+				t.Errorf("Expected the first directive's result in the error, got %+v", response.Result)
+			}
+		})
+	}
+}
@@ -2,10 +2,12 @@ package app
 
 import (
 	"context"
+	"fmt"
 
 	"connectrpc.com/connect"
 	batonv1 "github.com/barrynorthern/libretto/gen/go/libretto/baton/v1"
 	"github.com/barrynorthern/libretto/gen/go/libretto/baton/v1/batonv1connect"
+	"github.com/barrynorthern/libretto/internal/agents/analysis"
 	"github.com/barrynorthern/libretto/internal/agents/narrative"
 	"github.com/barrynorthern/libretto/internal/agents/plotweaver"
 	gwpkg "github.com/barrynorthern/libretto/internal/graphwrite"
@@ -15,15 +17,23 @@ import (
 type Orchestrator struct {
 	plot      plotweaver.Module
 	narr      narrative.Module
+	analyzers *analysis.Registry
 	gw        gwpkg.GraphWriteService
 	versionID string
 	producer  string
 }
 
 func NewOrchestrator(service gwpkg.GraphWriteService, versionID string) *Orchestrator {
+	return NewOrchestratorWithAnalyzers(service, versionID, analysis.Default)
+}
+
+// NewOrchestratorWithAnalyzers creates an orchestrator that runs the analyzers in
+// the given registry instead of the default one.
+func NewOrchestratorWithAnalyzers(service gwpkg.GraphWriteService, versionID string, analyzers *analysis.Registry) *Orchestrator {
 	return &Orchestrator{
 		plot:      plotweaver.New(),
 		narr:      narrative.New(),
+		analyzers: analyzers,
 		gw:        service,
 		versionID: versionID,
 		producer:  "monolith",
@@ -39,3 +49,81 @@ func (o *Orchestrator) IssueDirective(ctx context.Context, req *connect.Request[
 	_ = o.narr.ApplySceneProposal(ctx, o.gw, o.versionID, proposal)
 	return connect.NewResponse(&batonv1.IssueDirectiveResponse{CorrelationId: proposal.CorrelationId}), nil
 }
+
+// Analyze runs the registered analyzers over the entities in a version as a pipeline
+// and persists the annotations they propose. The project's settings choose which
+// analyzers run, defaulting to the set for the project's genre, and their order. Each
+// analyzer sees the annotations earlier analyzers produced during the run on the
+// entity's Annotations. Returns the number of annotations created.
+func (o *Orchestrator) Analyze(ctx context.Context, versionID string) (int, error) {
+	return o.analyze(ctx, versionID, nil)
+}
+
+// analyze runs the analyzer pipeline as Analyze does, over only the entities whose
+// logical IDs are in only, or over every entity when only is nil
+func (o *Orchestrator) analyze(ctx context.Context, versionID string, only map[string]bool) (int, error) {
+	version, err := o.gw.GetVersion(ctx, versionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get version: %w", err)
+	}
+	settings, err := o.gw.GetProjectSettings(ctx, version.ProjectID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get project settings: %w", err)
+	}
+	enabled := settings.Analyzers
+	if enabled == nil {
+		project, err := o.gw.GetProject(ctx, version.ProjectID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get project: %w", err)
+		}
+		if project.Genre != nil {
+			enabled = analysis.AnalyzersForGenre(*project.Genre)
+		}
+	}
+
+	entities, err := o.gw.ListEntities(ctx, versionID, gwpkg.EntityFilter{IncludeAnnotations: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	created := 0
+	for _, analyzer := range o.analyzers.Pipeline(enabled, settings.AnalyzerOrder) {
+		for _, entity := range entities {
+			if only != nil && !only[entity.ID] {
+				continue
+			}
+			inputs, err := analyzer.Analyze(ctx, entity)
+			if err != nil {
+				return created, fmt.Errorf("analyzer %s failed on %s: %w", analyzer.Name(), entity.ID, err)
+			}
+
+			for _, input := range inputs {
+				annotation, err := o.gw.CreateAnnotation(ctx, versionID, &gwpkg.Annotation{
+					EntityID:       entity.ID,
+					AnnotationType: string(input.AnnotationType),
+					Content:        input.Content,
+					Metadata:       input.Metadata,
+					AgentName:      analyzer.Name(),
+				})
+				if err != nil {
+					return created, fmt.Errorf("failed to persist %s annotation: %w", analyzer.Name(), err)
+				}
+				entity.Annotations = withLatestAnnotation(entity.Annotations, annotation)
+				created++
+			}
+		}
+	}
+
+	return created, nil
+}
+
+// withLatestAnnotation replaces the annotation of the same type, keeping one per type
+func withLatestAnnotation(annotations []*gwpkg.Annotation, latest *gwpkg.Annotation) []*gwpkg.Annotation {
+	for i, annotation := range annotations {
+		if annotation.AnnotationType == latest.AnnotationType {
+			annotations[i] = latest
+			return annotations
+		}
+	}
+	return append(annotations, latest)
+}
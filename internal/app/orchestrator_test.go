@@ -0,0 +1,224 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/agents/analysis"
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// pacingAnalyzer is a user-defined analyzer flagging every scene's pacing
+type pacingAnalyzer struct{}
+
+func (pacingAnalyzer) Name() string { return "pacer" }
+
+func (pacingAnalyzer) Analyze(_ context.Context, entity *graphwrite.Entity) ([]analysis.AnnotationInput, error) {
+	if entity.EntityType != "Scene" {
+		return nil, nil
+	}
+	return []analysis.AnnotationInput{{
+		AnnotationType: types.AnnotationPacingAnalysis,
+		Content:        "Pacing looks brisk",
+		Metadata:       map[string]any{"pace": "brisk"},
+	}}, nil
+}
+
+// pacingAwareAnalyzer reports on scenes only once it can see the pacer's annotation
+type pacingAwareAnalyzer struct{}
+
+func (pacingAwareAnalyzer) Name() string { return "pacing-aware" }
+
+func (pacingAwareAnalyzer) Analyze(_ context.Context, entity *graphwrite.Entity) ([]analysis.AnnotationInput, error) {
+	for _, annotation := range entity.Annotations {
+		if annotation.AnnotationType == string(types.AnnotationPacingAnalysis) {
+			return []analysis.AnnotationInput{{
+				AnnotationType: types.AnnotationContinuityCheck,
+				Content:        "Saw pace " + fmt.Sprint(annotation.Metadata["pace"]),
+				Metadata:       map[string]any{"is_consistent": true},
+			}}, nil
+		}
+	}
+	return nil, nil
+}
+
+// setupAnalysisVersion creates a project with a scene and a character and returns
+// the service and the version holding them
+func setupAnalysisVersion(t *testing.T) (graphwrite.GraphWriteService, string) {
+	return setupGenreAnalysisVersion(t, "")
+}
+
+// setupGenreAnalysisVersion is setupAnalysisVersion for a project of the given genre,
+// with a clue plot point added to the story
+func setupGenreAnalysisVersion(t *testing.T, genre string) (graphwrite.GraphWriteService, string) {
+	tmpFile, err := os.CreateTemp("", "libretto_orchestrator_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	tmpFile.Close()
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	database, err := db.NewDatabase(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	ctx := context.Background()
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	if _, err := database.Queries().CreateProject(ctx, db.CreateProjectParams{ID: "project-1", Name: "Analysed", Genre: sql.NullString{String: genre, Valid: genre != ""}}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := database.Queries().CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: "root", ProjectID: "project-1", IsWorkingSet: true}); err != nil {
+		t.Fatalf("Failed to create version: %v", err)
+	}
+
+	service := graphwrite.NewService(database)
+	request := &graphwrite.ApplyRequest{
+		ParentVersionID: "root",
+		Deltas: []*graphwrite.Delta{
+			{Operation: "create", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": "Opening"}},
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+		},
+	}
+	if genre != "" {
+		request.Deltas = append(request.Deltas, &graphwrite.Delta{Operation: "create", EntityType: "PlotPoint", EntityID: "muddy-boots", Fields: map[string]any{"name": "Muddy boots", "type": "clue"}})
+	}
+	response, err := service.Apply(ctx, request)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	return service, response.GraphVersionID
+}
+
+func TestOrchestrator_AnalyzeRunsCustomAnalyzer(t *testing.T) {
+	service, versionID := setupAnalysisVersion(t)
+	ctx := context.Background()
+
+	registry := analysis.NewRegistry()
+	if err := registry.Register(pacingAnalyzer{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	orchestrator := NewOrchestratorWithAnalyzers(service, versionID, registry)
+	created, err := orchestrator.Analyze(ctx, versionID)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if created != 1 {
+		t.Fatalf("Expected 1 annotation, got %d", created)
+	}
+
+	annotations, err := service.AnnotationsForVersion(ctx, versionID)
+	if err != nil {
+		t.Fatalf("AnnotationsForVersion failed: %v", err)
+	}
+	if len(annotations) != 1 {
+		t.Fatalf("Expected 1 persisted annotation, got %d", len(annotations))
+	}
+	annotation := annotations[0]
+	if annotation.EntityID != "scene-1" || annotation.AgentName != "pacer" || annotation.AnnotationType != "pacing_analysis" {
+		t.Errorf("Unexpected annotation: %+v", annotation)
+	}
+}
+
+func TestOrchestrator_AnalyzeFollowsProjectPipelineOrder(t *testing.T) {
+	service, versionID := setupAnalysisVersion(t)
+	ctx := context.Background()
+
+	// Registered in the wrong order: the pacing-aware analyzer depends on the pacer
+	registry := analysis.NewRegistry()
+	for _, analyzer := range []analysis.Analyzer{pacingAwareAnalyzer{}, pacingAnalyzer{}} {
+		if err := registry.Register(analyzer); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+	}
+
+	if _, err := service.UpdateProjectSettings(ctx, "project-1", graphwrite.ProjectSettings{AnalyzerOrder: []string{"pacer", "pacing-aware"}}); err != nil {
+		t.Fatalf("UpdateProjectSettings failed: %v", err)
+	}
+
+	orchestrator := NewOrchestratorWithAnalyzers(service, versionID, registry)
+	created, err := orchestrator.Analyze(ctx, versionID)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if created != 2 {
+		t.Fatalf("Expected the pacing-aware analyzer to see the pacer's annotation, got %d annotations", created)
+	}
+
+	annotations, err := service.AnnotationsForVersion(ctx, versionID)
+	if err != nil {
+		t.Fatalf("AnnotationsForVersion failed: %v", err)
+	}
+	var found bool
+	for _, annotation := range annotations {
+		if annotation.AgentName == "pacing-aware" {
+			found = true
+			if annotation.Content != "Saw pace brisk" {
+				t.Errorf("Expected the pacer's metadata to be passed forward, got %q", annotation.Content)
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected an annotation from the pacing-aware analyzer")
+	}
+}
+
+// agentNames lists the analyzers that annotated a version
+func agentNames(t *testing.T, service graphwrite.GraphWriteService, versionID string) map[string]bool {
+	annotations, err := service.AnnotationsForVersion(context.Background(), versionID)
+	if err != nil {
+		t.Fatalf("AnnotationsForVersion failed: %v", err)
+	}
+	agents := make(map[string]bool)
+	for _, annotation := range annotations {
+		agents[annotation.AgentName] = true
+	}
+	return agents
+}
+
+func TestOrchestrator_AnalyzeUsesGenreDefaults(t *testing.T) {
+	ctx := context.Background()
+
+	mysteryService, mysteryVersionID := setupGenreAnalysisVersion(t, "Mystery")
+	if _, err := NewOrchestrator(mysteryService, mysteryVersionID).Analyze(ctx, mysteryVersionID); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if agents := agentNames(t, mysteryService, mysteryVersionID); !agents["clues"] || agents["thematic"] {
+		t.Errorf("Expected a mystery to run the clues analyzer and not thematic, got %v", agents)
+	}
+
+	fantasyService, fantasyVersionID := setupGenreAnalysisVersion(t, "Fantasy")
+	if _, err := NewOrchestrator(fantasyService, fantasyVersionID).Analyze(ctx, fantasyVersionID); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if agents := agentNames(t, fantasyService, fantasyVersionID); agents["clues"] || !agents["continuity"] {
+		t.Errorf("Expected a fantasy to run continuity but not the clues analyzer, got %v", agents)
+	}
+}
+
+func TestOrchestrator_AnalyzeProjectAnalyzersOverrideGenre(t *testing.T) {
+	service, versionID := setupGenreAnalysisVersion(t, "Fantasy")
+	ctx := context.Background()
+
+	if _, err := service.UpdateProjectSettings(ctx, "project-1", graphwrite.ProjectSettings{Analyzers: []string{"clues"}}); err != nil {
+		t.Fatalf("UpdateProjectSettings failed: %v", err)
+	}
+
+	created, err := NewOrchestrator(service, versionID).Analyze(ctx, versionID)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if agents := agentNames(t, service, versionID); created != 1 || len(agents) != 1 || !agents["clues"] {
+		t.Errorf("Expected only the clues analyzer to run, got %d annotations from %v", created, agents)
+	}
+}
@@ -11,6 +11,19 @@ import (
 	"encoding/json"
 )
 
+const countAnnotationsByVersion = `-- name: CountAnnotationsByVersion :one
+SELECT COUNT(*) FROM annotations
+JOIN version_entities ON version_entities.entity_id = annotations.entity_id
+WHERE version_entities.version_id = ?
+`
+
+func (q *Queries) CountAnnotationsByVersion(ctx context.Context, versionID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAnnotationsByVersion, versionID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAnnotation = `-- name: CreateAnnotation :one
 
 INSERT INTO annotations (id, entity_id, annotation_type, content, metadata, agent_name)
@@ -93,7 +106,7 @@ func (q *Queries) GetAnnotation(ctx context.Context, id string) (Annotation, err
 const listAnnotationsByAgent = `-- name: ListAnnotationsByAgent :many
 SELECT id, entity_id, annotation_type, content, metadata, agent_name, created_at FROM annotations
 WHERE agent_name = ?
-ORDER BY created_at DESC
+ORDER BY created_at DESC, rowid DESC
 `
 
 func (q *Queries) ListAnnotationsByAgent(ctx context.Context, agentName sql.NullString) ([]Annotation, error) {
@@ -130,7 +143,7 @@ func (q *Queries) ListAnnotationsByAgent(ctx context.Context, agentName sql.Null
 const listAnnotationsByEntity = `-- name: ListAnnotationsByEntity :many
 SELECT id, entity_id, annotation_type, content, metadata, agent_name, created_at FROM annotations
 WHERE entity_id = ?
-ORDER BY created_at DESC
+ORDER BY created_at DESC, rowid DESC
 `
 
 func (q *Queries) ListAnnotationsByEntity(ctx context.Context, entityID string) ([]Annotation, error) {
@@ -167,7 +180,7 @@ func (q *Queries) ListAnnotationsByEntity(ctx context.Context, entityID string)
 const listAnnotationsByType = `-- name: ListAnnotationsByType :many
 SELECT id, entity_id, annotation_type, content, metadata, agent_name, created_at FROM annotations
 WHERE entity_id = ? AND annotation_type = ?
-ORDER BY created_at DESC
+ORDER BY created_at DESC, rowid DESC
 `
 
 type ListAnnotationsByTypeParams struct {
@@ -206,6 +219,141 @@ func (q *Queries) ListAnnotationsByType(ctx context.Context, arg ListAnnotations
 	return items, nil
 }
 
+const listAnnotationsByVersion = `-- name: ListAnnotationsByVersion :many
+SELECT annotations.id, annotations.entity_id, annotations.annotation_type, annotations.content, annotations.metadata, annotations.agent_name, annotations.created_at FROM annotations
+JOIN version_entities ON version_entities.entity_id = annotations.entity_id
+WHERE version_entities.version_id = ?
+ORDER BY annotations.created_at DESC, annotations.rowid DESC
+`
+
+func (q *Queries) ListAnnotationsByVersion(ctx context.Context, versionID string) ([]Annotation, error) {
+	rows, err := q.db.QueryContext(ctx, listAnnotationsByVersion, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Annotation{}
+	for rows.Next() {
+		var i Annotation
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityID,
+			&i.AnnotationType,
+			&i.Content,
+			&i.Metadata,
+			&i.AgentName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDanglingAnnotations = `-- name: ListDanglingAnnotations :many
+SELECT id, entity_id, annotation_type, content, metadata, agent_name, created_at FROM annotations
+WHERE NOT EXISTS (SELECT 1 FROM entities WHERE entities.id = annotations.entity_id)
+ORDER BY annotations.created_at ASC
+`
+
+// Annotations whose entity row no longer exists
+func (q *Queries) ListDanglingAnnotations(ctx context.Context) ([]Annotation, error) {
+	rows, err := q.db.QueryContext(ctx, listDanglingAnnotations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Annotation{}
+	for rows.Next() {
+		var i Annotation
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityID,
+			&i.AnnotationType,
+			&i.Content,
+			&i.Metadata,
+			&i.AgentName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrphanAnnotations = `-- name: ListOrphanAnnotations :many
+SELECT annotations.id, annotations.entity_id, annotations.annotation_type, annotations.content, annotations.metadata, annotations.agent_name, annotations.created_at FROM annotations
+LEFT JOIN entities ON entities.id = annotations.entity_id
+LEFT JOIN graph_versions ON graph_versions.id = entities.version_id
+WHERE entities.id IS NULL
+   OR (graph_versions.is_working_set = 0
+       AND EXISTS (
+           SELECT 1 FROM entities AS current
+           JOIN version_entities AS membership ON membership.entity_id = current.id
+           JOIN graph_versions AS working_set ON working_set.id = membership.version_id
+           WHERE working_set.project_id = graph_versions.project_id
+             AND working_set.is_working_set = 1
+             AND json_extract(current.data, '$.logical_id') = json_extract(entities.data, '$.logical_id'))
+       AND NOT EXISTS (
+           SELECT 1 FROM annotations AS carried
+           JOIN entities AS current ON current.id = carried.entity_id
+           JOIN version_entities AS membership ON membership.entity_id = current.id
+           JOIN graph_versions AS working_set ON working_set.id = membership.version_id
+           WHERE working_set.project_id = graph_versions.project_id
+             AND working_set.is_working_set = 1
+             AND json_extract(current.data, '$.logical_id') = json_extract(entities.data, '$.logical_id')
+             AND carried.annotation_type = annotations.annotation_type
+             AND carried.agent_name IS annotations.agent_name))
+ORDER BY annotations.created_at ASC
+`
+
+// Annotations whose entity row is gone, or which sit on an old version's entity
+// that is still in the project's working set without a carried-forward copy
+func (q *Queries) ListOrphanAnnotations(ctx context.Context) ([]Annotation, error) {
+	rows, err := q.db.QueryContext(ctx, listOrphanAnnotations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Annotation{}
+	for rows.Next() {
+		var i Annotation
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityID,
+			&i.AnnotationType,
+			&i.Content,
+			&i.Metadata,
+			&i.AgentName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateAnnotation = `-- name: UpdateAnnotation :one
 UPDATE annotations
 SET content = ?, metadata = ?
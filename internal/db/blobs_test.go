@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCreateBlob_IgnoresDuplicateHash(t *testing.T) {
+	queries := setupTestDB(t)
+	ctx := context.Background()
+
+	params := CreateBlobParams{Hash: "abc123", Content: "The storm broke over the harbor."}
+	if err := queries.CreateBlob(ctx, params); err != nil {
+		t.Fatalf("Failed to create blob: %v", err)
+	}
+	if err := queries.CreateBlob(ctx, params); err != nil {
+		t.Fatalf("Creating a duplicate blob should be a no-op: %v", err)
+	}
+
+	count, err := queries.CountBlobs(ctx)
+	if err != nil {
+		t.Fatalf("Failed to count blobs: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 blob, got %d", count)
+	}
+
+	blob, err := queries.GetBlob(ctx, "abc123")
+	if err != nil {
+		t.Fatalf("Failed to get blob: %v", err)
+	}
+	if blob.Content != params.Content {
+		t.Errorf("Expected content %q, got %q", params.Content, blob.Content)
+	}
+}
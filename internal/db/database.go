@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -22,18 +24,45 @@ type Database struct {
 	queries *Queries
 }
 
-// NewDatabase creates a new Database instance with SQLite connection
+// ErrEncryptionUnsupported is returned when DB_KEY is set but the binary is not linked against SQLCipher
+var ErrEncryptionUnsupported = errors.New("DB_KEY is set but this build is not linked against SQLCipher (see -tags sqlcipher)")
+
+// ErrInvalidKey is returned when an encrypted database cannot be read with the configured DB_KEY
+var ErrInvalidKey = errors.New("database cannot be decrypted with DB_KEY")
+
+// NewDatabase creates a new Database instance with SQLite connection.
+// When the DB_KEY environment variable is set the database is opened
+// encrypted at rest with that key, which requires a sqlcipher build.
 func NewDatabase(dbPath string) (*Database, error) {
-	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+	dsn := dbPath + "?_foreign_keys=on"
+	key := os.Getenv("DB_KEY")
+
+	var db *sql.DB
+	var err error
+	if key != "" {
+		db, err = openEncrypted(dsn, key)
+	} else {
+		db, err = sql.Open("sqlite3", dsn)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	// Reading the schema fails straight away for a wrong or missing key
+	if _, err := db.Exec("SELECT COUNT(*) FROM sqlite_master"); err != nil {
+		db.Close()
+		if key != "" {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+		}
+		return nil, fmt.Errorf("failed to read database (encrypted databases require DB_KEY): %w", err)
+	}
+
 	database := &Database{
 		db:      db,
 		queries: New(db),
@@ -52,6 +81,26 @@ func (d *Database) Queries() *Queries {
 	return d.queries
 }
 
+// WithDBTX returns a Database sharing this connection whose queries run through
+// dbtx, typically a wrapper around DB() used for instrumentation. Closing either
+// Database closes the shared connection.
+func (d *Database) WithDBTX(dbtx DBTX) *Database {
+	return &Database{
+		db:      d.db,
+		queries: New(dbtx),
+	}
+}
+
+// Snapshot writes a consistent copy of the whole database to path with VACUUM INTO.
+// The copy is a complete database that can replace the original file; path must not
+// exist yet.
+func (d *Database) Snapshot(ctx context.Context, path string) error {
+	if _, err := d.db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	return nil
+}
+
 // DB returns the underlying database connection
 func (d *Database) DB() *sql.DB {
 	return d.db
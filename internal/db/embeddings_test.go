@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUpsertEmbedding_ReplacesVector(t *testing.T) {
+	queries := setupTestDB(t)
+	ctx := context.Background()
+
+	if _, err := queries.CreateProject(ctx, CreateProjectParams{ID: "project-1", Name: "Test Project"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := queries.CreateGraphVersion(ctx, CreateGraphVersionParams{ID: "version-1", ProjectID: "project-1", IsWorkingSet: true}); err != nil {
+		t.Fatalf("Failed to create graph version: %v", err)
+	}
+
+	for _, params := range []UpsertEmbeddingParams{
+		{VersionID: "version-1", LogicalID: "marcus", Dimensions: 1, Vector: []byte{1, 2, 3, 4}},
+		{VersionID: "version-1", LogicalID: "elena", Dimensions: 1, Vector: []byte{5, 6, 7, 8}},
+		{VersionID: "version-1", LogicalID: "elena", Dimensions: 2, Vector: []byte{1, 1, 1, 1, 2, 2, 2, 2}},
+	} {
+		if err := queries.UpsertEmbedding(ctx, params); err != nil {
+			t.Fatalf("Failed to upsert embedding: %v", err)
+		}
+	}
+
+	embeddings, err := queries.ListEmbeddingsByVersion(ctx, "version-1")
+	if err != nil {
+		t.Fatalf("Failed to list embeddings: %v", err)
+	}
+	if len(embeddings) != 2 {
+		t.Fatalf("Expected 2 embeddings, got %d", len(embeddings))
+	}
+	if embeddings[0].LogicalID != "elena" || embeddings[0].Dimensions != 2 || len(embeddings[0].Vector) != 8 {
+		t.Errorf("Expected elena's replaced 2-dimension vector, got %+v", embeddings[0])
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !sqlcipher
+
+package db
+
+import "database/sql"
+
+func openEncrypted(dsn, key string) (*sql.DB, error) {
+	return nil, ErrEncryptionUnsupported
+}
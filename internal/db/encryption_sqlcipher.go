@@ -0,0 +1,56 @@
+//go:build sqlcipher
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// openEncrypted opens the database with PRAGMA key issued on every new connection.
+// The binary must be linked against SQLCipher instead of the bundled SQLite, e.g.
+//
+//	CGO_CFLAGS="-I/usr/include/sqlcipher" CGO_LDFLAGS="-lsqlcipher" go build -tags "sqlcipher libsqlite3"
+func openEncrypted(dsn, key string) (*sql.DB, error) {
+	sqliteDriver := &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			_, err := conn.Exec(fmt.Sprintf("PRAGMA key = %s", quoteLiteral(key)), nil)
+			return err
+		},
+	}
+
+	db := sql.OpenDB(&keyedConnector{driver: sqliteDriver, dsn: dsn})
+
+	// Plain SQLite silently ignores PRAGMA key, so refuse to run without SQLCipher
+	var cipherVersion string
+	if err := db.QueryRow("PRAGMA cipher_version").Scan(&cipherVersion); err != nil || cipherVersion == "" {
+		db.Close()
+		return nil, ErrEncryptionUnsupported
+	}
+
+	return db, nil
+}
+
+// keyedConnector opens connections through a driver carrying the key hook
+type keyedConnector struct {
+	driver *sqlite3.SQLiteDriver
+	dsn    string
+}
+
+func (c *keyedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *keyedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// quoteLiteral quotes a value as an SQL string literal
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,59 @@
+//go:build sqlcipher
+
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNewDatabase_Encrypted(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "libretto.db")
+	projectID := uuid.New().String()
+
+	t.Setenv("DB_KEY", "correct horse battery staple")
+	database, err := NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create encrypted database: %v", err)
+	}
+	if err := database.Migrate(ctx); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if _, err := database.Queries().CreateProject(ctx, CreateProjectParams{ID: projectID, Name: "Secret Draft"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	database.Close()
+
+	// Without the key the file is unreadable
+	t.Setenv("DB_KEY", "")
+	if _, err := NewDatabase(dbPath); err == nil {
+		t.Fatal("Expected opening without DB_KEY to fail")
+	}
+
+	// A wrong key is reported clearly
+	t.Setenv("DB_KEY", "wrong")
+	if _, err := NewDatabase(dbPath); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Expected ErrInvalidKey, got %v", err)
+	}
+
+	// The right key opens it again
+	t.Setenv("DB_KEY", "correct horse battery staple")
+	database, err = NewDatabase(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen encrypted database: %v", err)
+	}
+	defer database.Close()
+
+	project, err := database.Queries().GetProject(ctx, projectID)
+	if err != nil {
+		t.Fatalf("Failed to read project: %v", err)
+	}
+	if project.Name != "Secret Draft" {
+		t.Errorf("Expected Secret Draft, got %s", project.Name)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !sqlcipher
+
+package db
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewDatabase_KeyWithoutEncryptionSupport(t *testing.T) {
+	t.Setenv("DB_KEY", "secret")
+
+	_, err := NewDatabase(filepath.Join(t.TempDir(), "libretto.db"))
+	if !errors.Is(err, ErrEncryptionUnsupported) {
+		t.Errorf("Expected ErrEncryptionUnsupported, got %v", err)
+	}
+}
@@ -7,12 +7,14 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 )
 
 const countEntitiesByType = `-- name: CountEntitiesByType :one
-SELECT COUNT(*) FROM entities
-WHERE version_id = ? AND entity_type = ?
+SELECT COUNT(*) FROM version_entities
+JOIN entities ON entities.id = version_entities.entity_id
+WHERE version_entities.version_id = ? AND entities.entity_type = ?
 `
 
 type CountEntitiesByTypeParams struct {
@@ -94,10 +96,56 @@ func (q *Queries) GetEntity(ctx context.Context, id string) (Entity, error) {
 	return i, err
 }
 
+const listEntitiesByLogicalIDs = `-- name: ListEntitiesByLogicalIDs :many
+SELECT entities.id, version_entities.version_id, entities.entity_type, entities.name, entities.data, entities.created_at, entities.updated_at FROM entities
+JOIN version_entities ON version_entities.entity_id = entities.id
+WHERE json_extract(entities.data, '$.logical_id') IN (SELECT value FROM json_each(CAST(?1 AS TEXT)))
+  AND version_entities.version_id = ?2
+`
+
+type ListEntitiesByLogicalIDsParams struct {
+	LogicalIds string `json:"logical_ids"`
+	VersionID  string `json:"version_id"`
+}
+
+// Lists a version's entities whose logical IDs are in a JSON array of IDs, in no
+// particular order
+func (q *Queries) ListEntitiesByLogicalIDs(ctx context.Context, arg ListEntitiesByLogicalIDsParams) ([]Entity, error) {
+	rows, err := q.db.QueryContext(ctx, listEntitiesByLogicalIDs, arg.LogicalIds, arg.VersionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entity{}
+	for rows.Next() {
+		var i Entity
+		if err := rows.Scan(
+			&i.ID,
+			&i.VersionID,
+			&i.EntityType,
+			&i.Name,
+			&i.Data,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntitiesByType = `-- name: ListEntitiesByType :many
-SELECT id, version_id, entity_type, name, data, created_at, updated_at FROM entities
-WHERE version_id = ? AND entity_type = ?
-ORDER BY created_at DESC
+SELECT entities.id, version_entities.version_id, entities.entity_type, entities.name, entities.data, entities.created_at, entities.updated_at FROM version_entities
+JOIN entities ON entities.id = version_entities.entity_id
+WHERE version_entities.version_id = ? AND entities.entity_type = ?
+ORDER BY entities.created_at DESC, entities.entity_type ASC, entities.rowid ASC
 `
 
 type ListEntitiesByTypeParams struct {
@@ -137,9 +185,10 @@ func (q *Queries) ListEntitiesByType(ctx context.Context, arg ListEntitiesByType
 }
 
 const listEntitiesByVersion = `-- name: ListEntitiesByVersion :many
-SELECT id, version_id, entity_type, name, data, created_at, updated_at FROM entities
-WHERE version_id = ?
-ORDER BY created_at DESC
+SELECT entities.id, version_entities.version_id, entities.entity_type, entities.name, entities.data, entities.created_at, entities.updated_at FROM version_entities
+JOIN entities ON entities.id = version_entities.entity_id
+WHERE version_entities.version_id = ?
+ORDER BY entities.created_at DESC, entities.entity_type ASC, entities.rowid ASC
 `
 
 func (q *Queries) ListEntitiesByVersion(ctx context.Context, versionID string) ([]Entity, error) {
@@ -173,6 +222,76 @@ func (q *Queries) ListEntitiesByVersion(ctx context.Context, versionID string) (
 	return items, nil
 }
 
+const listEntitiesPage = `-- name: ListEntitiesPage :many
+SELECT entities.id, version_entities.version_id, entities.entity_type, entities.name, entities.data, entities.created_at, entities.updated_at FROM version_entities
+JOIN entities ON entities.id = version_entities.entity_id
+WHERE version_entities.version_id = ?1
+  AND (?2 IS NULL OR entities.entity_type = ?2)
+  AND (CAST(?3 AS BOOLEAN) OR NOT IFNULL(json_extract(entities.data, '$.deleted'), FALSE))
+ORDER BY
+  CASE WHEN CAST(?4 AS TEXT) = 'name' AND NOT CAST(?5 AS BOOLEAN) THEN entities.name END ASC,
+  CASE WHEN CAST(?4 AS TEXT) = 'name' AND CAST(?5 AS BOOLEAN) THEN entities.name END DESC,
+  CASE WHEN CAST(?4 AS TEXT) = 'entity_type' AND NOT CAST(?5 AS BOOLEAN) THEN entities.entity_type END ASC,
+  CASE WHEN CAST(?4 AS TEXT) = 'entity_type' AND CAST(?5 AS BOOLEAN) THEN entities.entity_type END DESC,
+  CASE WHEN CAST(?4 AS TEXT) = 'created_at' AND NOT CAST(?5 AS BOOLEAN) THEN entities.created_at END ASC,
+  CASE WHEN CAST(?4 AS TEXT) = 'created_at' AND CAST(?5 AS BOOLEAN) THEN entities.created_at END DESC,
+  entities.created_at DESC, entities.entity_type ASC, entities.rowid ASC
+LIMIT ?6 OFFSET ?7
+`
+
+type ListEntitiesPageParams struct {
+	VersionID      string         `json:"version_id"`
+	EntityType     sql.NullString `json:"entity_type"`
+	IncludeDeleted bool           `json:"include_deleted"`
+	OrderBy        string         `json:"order_by"`
+	Descending     bool           `json:"descending"`
+	PageLimit      int64          `json:"page_limit"`
+	PageOffset     int64          `json:"page_offset"`
+}
+
+// Lists one page of a version's entities, optionally of one type, sorted by name,
+// entity_type or created_at (the default order when order_by is empty). Ties keep the
+// default order so pages stay stable; a negative page_limit returns every row.
+// Tombstoned entities, whose data sets deleted, are left out unless include_deleted.
+func (q *Queries) ListEntitiesPage(ctx context.Context, arg ListEntitiesPageParams) ([]Entity, error) {
+	rows, err := q.db.QueryContext(ctx, listEntitiesPage,
+		arg.VersionID,
+		arg.EntityType,
+		arg.IncludeDeleted,
+		arg.OrderBy,
+		arg.Descending,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entity{}
+	for rows.Next() {
+		var i Entity
+		if err := rows.Scan(
+			&i.ID,
+			&i.VersionID,
+			&i.EntityType,
+			&i.Name,
+			&i.Data,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateEntity = `-- name: UpdateEntity :one
 UPDATE entities
 SET name = ?, data = ?
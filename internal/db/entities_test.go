@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/google/uuid"
@@ -307,6 +308,67 @@ func TestListEntitiesByType(t *testing.T) {
 	}
 }
 
+func TestListEntitiesPage(t *testing.T) {
+	queries := setupTestDB(t)
+	ctx := context.Background()
+
+	projectID := uuid.New().String()
+	versionID := uuid.New().String()
+	if _, err := queries.CreateProject(ctx, CreateProjectParams{ID: projectID, Name: "Test Project"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := queries.CreateGraphVersion(ctx, CreateGraphVersionParams{ID: versionID, ProjectID: projectID, IsWorkingSet: true}); err != nil {
+		t.Fatalf("Failed to create graph version: %v", err)
+	}
+
+	for _, entity := range []struct{ entityType, name string }{
+		{"Character", "Cleo"},
+		{"Scene", "Opening Scene"},
+		{"Character", "Aria"},
+		{"Character", "Bram"},
+	} {
+		if _, err := queries.CreateEntity(ctx, CreateEntityParams{
+			ID:         uuid.New().String(),
+			VersionID:  versionID,
+			EntityType: entity.entityType,
+			Name:       entity.name,
+			Data:       json.RawMessage(`{}`),
+		}); err != nil {
+			t.Fatalf("Failed to create entity %s: %v", entity.name, err)
+		}
+	}
+
+	names := func(params ListEntitiesPageParams) []string {
+		t.Helper()
+		entities, err := queries.ListEntitiesPage(ctx, params)
+		if err != nil {
+			t.Fatalf("Failed to list entities: %v", err)
+		}
+		result := []string{}
+		for _, entity := range entities {
+			result = append(result, entity.Name)
+		}
+		return result
+	}
+
+	characters := sql.NullString{String: "Character", Valid: true}
+	tests := []struct {
+		name   string
+		params ListEntitiesPageParams
+		want   string
+	}{
+		{"all by name", ListEntitiesPageParams{VersionID: versionID, OrderBy: "name", PageLimit: -1}, "[Aria Bram Cleo Opening Scene]"},
+		{"characters by name descending", ListEntitiesPageParams{VersionID: versionID, EntityType: characters, OrderBy: "name", Descending: true, PageLimit: -1}, "[Cleo Bram Aria]"},
+		{"second page of characters", ListEntitiesPageParams{VersionID: versionID, EntityType: characters, OrderBy: "name", PageLimit: 2, PageOffset: 2}, "[Cleo]"},
+		{"by type then default order", ListEntitiesPageParams{VersionID: versionID, OrderBy: "entity_type", Descending: true, PageLimit: 1}, "[Opening Scene]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(names(tt.params)); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
 func TestUpdateEntity(t *testing.T) {
 	queries := setupTestDB(t)
 	ctx := context.Background()
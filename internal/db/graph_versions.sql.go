@@ -10,6 +10,35 @@ import (
 	"database/sql"
 )
 
+const advanceWorkingSet = `-- name: AdvanceWorkingSet :execrows
+UPDATE graph_versions
+SET is_working_set = CASE WHEN id = ?1 THEN TRUE ELSE FALSE END
+WHERE project_id = ?2
+  AND EXISTS (
+    SELECT 1 FROM graph_versions AS current
+    WHERE current.project_id = ?2
+      AND current.id = ?3
+      AND current.is_working_set = TRUE
+  )
+`
+
+type AdvanceWorkingSetParams struct {
+	ID         string `json:"id"`
+	ProjectID  string `json:"project_id"`
+	ExpectedID string `json:"expected_id"`
+}
+
+// Moves the working set like SetWorkingSet, but only while expected_id is still the
+// working set, so the check and the move cannot interleave with another writer's.
+// Affects no rows when the working set has moved on.
+func (q *Queries) AdvanceWorkingSet(ctx context.Context, arg AdvanceWorkingSetParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, advanceWorkingSet, arg.ID, arg.ProjectID, arg.ExpectedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createGraphVersion = `-- name: CreateGraphVersion :one
 
 INSERT INTO graph_versions (id, project_id, parent_version_id, name, description, is_working_set)
@@ -136,6 +165,46 @@ func (q *Queries) ListGraphVersionsByProject(ctx context.Context, projectID stri
 	return items, nil
 }
 
+const listGraphVersionsContainingEntity = `-- name: ListGraphVersionsContainingEntity :many
+SELECT DISTINCT graph_versions.id, graph_versions.project_id, graph_versions.parent_version_id, graph_versions.name, graph_versions.description, graph_versions.is_working_set, graph_versions.created_at FROM graph_versions
+JOIN version_entities ON version_entities.version_id = graph_versions.id
+JOIN entities ON entities.id = version_entities.entity_id
+WHERE json_extract(entities.data, '$.logical_id') = ?1
+  AND json_extract(entities.data, '$.deleted') IS NOT 1
+ORDER BY graph_versions.created_at ASC
+`
+
+func (q *Queries) ListGraphVersionsContainingEntity(ctx context.Context, logicalID interface{}) ([]GraphVersion, error) {
+	rows, err := q.db.QueryContext(ctx, listGraphVersionsContainingEntity, logicalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GraphVersion{}
+	for rows.Next() {
+		var i GraphVersion
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.ParentVersionID,
+			&i.Name,
+			&i.Description,
+			&i.IsWorkingSet,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const setWorkingSet = `-- name: SetWorkingSet :exec
 UPDATE graph_versions
 SET is_working_set = CASE WHEN id = ? THEN TRUE ELSE FALSE END
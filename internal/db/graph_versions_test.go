@@ -205,6 +205,63 @@ func TestSetWorkingSet(t *testing.T) {
 	}
 }
 
+func TestAdvanceWorkingSet(t *testing.T) {
+	queries := setupTestDB(t)
+	ctx := context.Background()
+
+	projectID := uuid.New().String()
+	if _, err := queries.CreateProject(ctx, CreateProjectParams{ID: projectID, Name: "Test Project"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	var versionIDs []string
+	for i := 0; i < 3; i++ {
+		versionID := uuid.New().String()
+		if _, err := queries.CreateGraphVersion(ctx, CreateGraphVersionParams{
+			ID:           versionID,
+			ProjectID:    projectID,
+			IsWorkingSet: i == 0,
+		}); err != nil {
+			t.Fatalf("Failed to create version %d: %v", i+1, err)
+		}
+		versionIDs = append(versionIDs, versionID)
+	}
+
+	// Advancing from the current working set moves it
+	advanced, err := queries.AdvanceWorkingSet(ctx, AdvanceWorkingSetParams{
+		ID:         versionIDs[1],
+		ProjectID:  projectID,
+		ExpectedID: versionIDs[0],
+	})
+	if err != nil {
+		t.Fatalf("Failed to advance working set: %v", err)
+	}
+	if advanced == 0 {
+		t.Fatal("Expected advancing from the working set to affect rows")
+	}
+
+	// Advancing from a stale working set leaves it where it is
+	advanced, err = queries.AdvanceWorkingSet(ctx, AdvanceWorkingSetParams{
+		ID:         versionIDs[2],
+		ProjectID:  projectID,
+		ExpectedID: versionIDs[0],
+	})
+	if err != nil {
+		t.Fatalf("Failed to advance working set: %v", err)
+	}
+	if advanced != 0 {
+		t.Errorf("Expected advancing from a stale working set to affect no rows, got %d", advanced)
+	}
+
+	workingSet, err := queries.GetWorkingSetVersion(ctx, projectID)
+	if err != nil {
+		t.Fatalf("Failed to get working set version: %v", err)
+	}
+	if workingSet.ID != versionIDs[1] {
+		t.Errorf("Expected working set ID %s, got %s", versionIDs[1], workingSet.ID)
+	}
+}
+
 func TestListGraphVersionsByProject(t *testing.T) {
 	queries := setupTestDB(t)
 	ctx := context.Background()
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUpsertLayoutPosition_ReplacesPosition(t *testing.T) {
+	queries := setupTestDB(t)
+	ctx := context.Background()
+
+	if _, err := queries.CreateProject(ctx, CreateProjectParams{ID: "project-1", Name: "Test Project"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := queries.CreateGraphVersion(ctx, CreateGraphVersionParams{ID: "version-1", ProjectID: "project-1", IsWorkingSet: true}); err != nil {
+		t.Fatalf("Failed to create graph version: %v", err)
+	}
+
+	for _, params := range []UpsertLayoutPositionParams{
+		{VersionID: "version-1", LogicalID: "elena", X: 10, Y: 20},
+		{VersionID: "version-1", LogicalID: "marcus", X: 30, Y: 40},
+		{VersionID: "version-1", LogicalID: "elena", X: 15, Y: 25},
+	} {
+		if err := queries.UpsertLayoutPosition(ctx, params); err != nil {
+			t.Fatalf("Failed to upsert layout position: %v", err)
+		}
+	}
+
+	positions, err := queries.ListLayoutPositions(ctx, "version-1")
+	if err != nil {
+		t.Fatalf("Failed to list layout positions: %v", err)
+	}
+	if len(positions) != 2 {
+		t.Fatalf("Expected 2 positions, got %d", len(positions))
+	}
+	if positions[0].LogicalID != "elena" || positions[0].X != 15 || positions[0].Y != 25 {
+		t.Errorf("Expected elena at (15, 25), got %+v", positions[0])
+	}
+}
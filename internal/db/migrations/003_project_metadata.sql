@@ -0,0 +1,8 @@
+-- Structured project metadata
+-- Adds editorial status, author and series so projects can be grouped and tracked
+
+ALTER TABLE projects ADD COLUMN status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'revising', 'complete'));
+ALTER TABLE projects ADD COLUMN author TEXT;
+ALTER TABLE projects ADD COLUMN series TEXT;
+
+CREATE INDEX idx_projects_series ON projects(series);
@@ -0,0 +1,9 @@
+-- Content-addressed blob storage
+-- Large scene content can be stored once here and referenced by hash from entity
+-- data, so copying a version does not duplicate unchanged content
+
+CREATE TABLE blobs (
+    hash TEXT PRIMARY KEY, -- hex-encoded SHA-256 of content
+    content TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
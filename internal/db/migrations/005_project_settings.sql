@@ -0,0 +1,9 @@
+-- Per-project settings
+-- analyzer_order lists analyzer names in the order the orchestrator should run them
+
+CREATE TABLE project_settings (
+    project_id TEXT PRIMARY KEY,
+    analyzer_order JSON NOT NULL DEFAULT '[]',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
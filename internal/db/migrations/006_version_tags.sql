@@ -0,0 +1,12 @@
+-- Named checkpoints
+-- A tag names one version of a project, e.g. "editor-draft", so it can be found later
+
+CREATE TABLE version_tags (
+    project_id TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    version_id TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, tag),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE
+);
@@ -0,0 +1,12 @@
+-- Graph layout positions
+-- Saved node positions for the dashboard graph view, per version and logical entity
+
+CREATE TABLE layouts (
+    version_id TEXT NOT NULL,
+    logical_id TEXT NOT NULL,
+    x REAL NOT NULL,
+    y REAL NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (version_id, logical_id),
+    FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE
+);
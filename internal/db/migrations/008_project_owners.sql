@@ -0,0 +1,6 @@
+-- Project owners
+-- Optional owner so several users can share one database; projects without an owner are single-tenant
+
+ALTER TABLE projects ADD COLUMN owner_id TEXT;
+
+CREATE INDEX idx_projects_owner ON projects(owner_id);
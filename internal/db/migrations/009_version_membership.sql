@@ -0,0 +1,43 @@
+-- Version membership
+-- Lists which entity and relationship rows make up each version, so a child version
+-- can share its parent's unchanged rows instead of copying them. Every row belongs to
+-- the version it was created in; copy-on-write versions add the parent's rows too.
+
+CREATE TABLE version_entities (
+    version_id TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    PRIMARY KEY (version_id, entity_id),
+    FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE,
+    FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
+);
+
+CREATE TABLE version_relationships (
+    version_id TEXT NOT NULL,
+    relationship_id TEXT NOT NULL,
+    PRIMARY KEY (version_id, relationship_id),
+    FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE,
+    FOREIGN KEY (relationship_id) REFERENCES relationships(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_version_entities_entity_id ON version_entities(entity_id);
+CREATE INDEX idx_version_relationships_relationship_id ON version_relationships(relationship_id);
+
+INSERT INTO version_entities (version_id, entity_id)
+SELECT version_id, id FROM entities;
+
+INSERT INTO version_relationships (version_id, relationship_id)
+SELECT version_id, id FROM relationships;
+
+CREATE TRIGGER add_entity_to_version
+    AFTER INSERT ON entities
+    FOR EACH ROW
+BEGIN
+    INSERT INTO version_entities (version_id, entity_id) VALUES (NEW.version_id, NEW.id);
+END;
+
+CREATE TRIGGER add_relationship_to_version
+    AFTER INSERT ON relationships
+    FOR EACH ROW
+BEGIN
+    INSERT INTO version_relationships (version_id, relationship_id) VALUES (NEW.version_id, NEW.id);
+END;
@@ -0,0 +1,16 @@
+-- Relationship annotations
+-- Agent notes on relationship rows, such as why an alliance is strained. Like entity
+-- annotations they live on a row and are carried onto its copies in later versions.
+
+CREATE TABLE relationship_annotations (
+    id TEXT PRIMARY KEY,
+    relationship_id TEXT NOT NULL,
+    annotation_type TEXT NOT NULL,
+    content TEXT NOT NULL,
+    metadata JSON,
+    agent_name TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (relationship_id) REFERENCES relationships(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_relationship_annotations_relationship_id ON relationship_annotations(relationship_id);
@@ -0,0 +1,5 @@
+-- Entity logical ID index
+-- Entities are looked up by the logical ID in their data far more often than by row
+-- ID, so index the extracted value for batch fetches and history queries.
+
+CREATE INDEX idx_entities_logical_id ON entities(json_extract(data, '$.logical_id'));
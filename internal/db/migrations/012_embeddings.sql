@@ -0,0 +1,13 @@
+-- Entity embeddings
+-- Caller-supplied vectors for similarity search, per version and logical entity.
+-- vector holds dimensions little-endian float32 values.
+
+CREATE TABLE embeddings (
+    version_id TEXT NOT NULL,
+    logical_id TEXT NOT NULL,
+    dimensions INTEGER NOT NULL,
+    vector BLOB NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (version_id, logical_id),
+    FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+-- Project analyzer sets
+-- analyzers lists the analyzers the orchestrator runs for a project. JSON null falls
+-- back to the defaults for the project's genre.
+
+ALTER TABLE project_settings ADD COLUMN analyzers JSON NOT NULL DEFAULT 'null';
@@ -20,6 +20,20 @@ type Annotation struct {
 	CreatedAt      time.Time       `json:"created_at"`
 }
 
+type Blob struct {
+	Hash      string    `json:"hash"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Embedding struct {
+	VersionID  string    `json:"version_id"`
+	LogicalID  string    `json:"logical_id"`
+	Dimensions int64     `json:"dimensions"`
+	Vector     []byte    `json:"vector"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
 type Entity struct {
 	ID         string          `json:"id"`
 	VersionID  string          `json:"version_id"`
@@ -40,6 +54,14 @@ type GraphVersion struct {
 	CreatedAt       time.Time      `json:"created_at"`
 }
 
+type Layout struct {
+	VersionID string    `json:"version_id"`
+	LogicalID string    `json:"logical_id"`
+	X         float64   `json:"x"`
+	Y         float64   `json:"y"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type Project struct {
 	ID          string         `json:"id"`
 	Name        string         `json:"name"`
@@ -48,6 +70,17 @@ type Project struct {
 	Description sql.NullString `json:"description"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	Status      string         `json:"status"`
+	Author      sql.NullString `json:"author"`
+	Series      sql.NullString `json:"series"`
+	OwnerID     sql.NullString `json:"owner_id"`
+}
+
+type ProjectSetting struct {
+	ProjectID     string          `json:"project_id"`
+	AnalyzerOrder json.RawMessage `json:"analyzer_order"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	Analyzers     json.RawMessage `json:"analyzers"`
 }
 
 type Relationship struct {
@@ -60,6 +93,16 @@ type Relationship struct {
 	CreatedAt        time.Time       `json:"created_at"`
 }
 
+type RelationshipAnnotation struct {
+	ID             string          `json:"id"`
+	RelationshipID string          `json:"relationship_id"`
+	AnnotationType string          `json:"annotation_type"`
+	Content        string          `json:"content"`
+	Metadata       json.RawMessage `json:"metadata"`
+	AgentName      sql.NullString  `json:"agent_name"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
 type Scene struct {
 	ID        string    `json:"id"`
 	Title     string    `json:"title"`
@@ -68,3 +111,20 @@ type Scene struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+type VersionEntity struct {
+	VersionID string `json:"version_id"`
+	EntityID  string `json:"entity_id"`
+}
+
+type VersionRelationship struct {
+	VersionID      string `json:"version_id"`
+	RelationshipID string `json:"relationship_id"`
+}
+
+type VersionTag struct {
+	ProjectID string    `json:"project_id"`
+	Tag       string    `json:"tag"`
+	VersionID string    `json:"version_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -8,13 +8,14 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 const createProject = `-- name: CreateProject :one
 
 INSERT INTO projects (id, name, theme, genre, description)
 VALUES (?, ?, ?, ?, ?)
-RETURNING id, name, theme, genre, description, created_at, updated_at
+RETURNING id, name, theme, genre, description, created_at, updated_at, status, author, series, owner_id
 `
 
 type CreateProjectParams struct {
@@ -43,6 +44,10 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.Author,
+		&i.Series,
+		&i.OwnerID,
 	)
 	return i, err
 }
@@ -58,7 +63,7 @@ func (q *Queries) DeleteProject(ctx context.Context, id string) error {
 }
 
 const getProject = `-- name: GetProject :one
-SELECT id, name, theme, genre, description, created_at, updated_at FROM projects
+SELECT id, name, theme, genre, description, created_at, updated_at, status, author, series, owner_id FROM projects
 WHERE id = ?
 `
 
@@ -73,12 +78,79 @@ func (q *Queries) GetProject(ctx context.Context, id string) (Project, error) {
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.Author,
+		&i.Series,
+		&i.OwnerID,
 	)
 	return i, err
 }
 
+const listProjectVersionStats = `-- name: ListProjectVersionStats :many
+SELECT p.id, p.name, p.theme, p.genre, p.description, p.created_at, p.updated_at, p.status, p.author, p.series, p.owner_id,
+       COUNT(gv.id) AS version_count,
+       CAST(MAX(CASE WHEN gv.is_working_set THEN gv.id END) AS TEXT) AS working_set_version_id
+FROM projects p
+LEFT JOIN graph_versions gv ON gv.project_id = p.id
+GROUP BY p.id
+ORDER BY p.created_at DESC
+`
+
+type ListProjectVersionStatsRow struct {
+	ID                  string         `json:"id"`
+	Name                string         `json:"name"`
+	Theme               sql.NullString `json:"theme"`
+	Genre               sql.NullString `json:"genre"`
+	Description         sql.NullString `json:"description"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	Status              string         `json:"status"`
+	Author              sql.NullString `json:"author"`
+	Series              sql.NullString `json:"series"`
+	OwnerID             sql.NullString `json:"owner_id"`
+	VersionCount        int64          `json:"version_count"`
+	WorkingSetVersionID sql.NullString `json:"working_set_version_id"`
+}
+
+func (q *Queries) ListProjectVersionStats(ctx context.Context) ([]ListProjectVersionStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listProjectVersionStats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProjectVersionStatsRow{}
+	for rows.Next() {
+		var i ListProjectVersionStatsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Theme,
+			&i.Genre,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.Author,
+			&i.Series,
+			&i.OwnerID,
+			&i.VersionCount,
+			&i.WorkingSetVersionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listProjects = `-- name: ListProjects :many
-SELECT id, name, theme, genre, description, created_at, updated_at FROM projects
+SELECT id, name, theme, genre, description, created_at, updated_at, status, author, series, owner_id FROM projects
 ORDER BY created_at DESC
 `
 
@@ -99,6 +171,10 @@ func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Status,
+			&i.Author,
+			&i.Series,
+			&i.OwnerID,
 		); err != nil {
 			return nil, err
 		}
@@ -113,11 +189,119 @@ func (q *Queries) ListProjects(ctx context.Context) ([]Project, error) {
 	return items, nil
 }
 
+const listProjectsByOwner = `-- name: ListProjectsByOwner :many
+SELECT id, name, theme, genre, description, created_at, updated_at, status, author, series, owner_id FROM projects
+WHERE owner_id = ?
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListProjectsByOwner(ctx context.Context, ownerID sql.NullString) ([]Project, error) {
+	rows, err := q.db.QueryContext(ctx, listProjectsByOwner, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Theme,
+			&i.Genre,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.Author,
+			&i.Series,
+			&i.OwnerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkingSetCounts = `-- name: ListWorkingSetCounts :many
+SELECT gv.project_id,
+       (SELECT COUNT(*) FROM version_entities ve JOIN entities e ON e.id = ve.entity_id
+        WHERE ve.version_id = gv.id AND COALESCE(json_extract(e.data, '$.deleted'), 0) = 0) AS entity_count,
+       (SELECT COUNT(*) FROM version_relationships vr JOIN relationships r ON r.id = vr.relationship_id
+        JOIN entities ef ON ef.id = r.from_entity_id JOIN entities et ON et.id = r.to_entity_id
+        WHERE vr.version_id = gv.id
+          AND COALESCE(json_extract(ef.data, '$.deleted'), 0) = 0
+          AND COALESCE(json_extract(et.data, '$.deleted'), 0) = 0) AS relationship_count,
+       (SELECT COUNT(*) FROM annotations a JOIN version_entities ve ON ve.entity_id = a.entity_id
+        JOIN entities e ON e.id = a.entity_id
+        WHERE ve.version_id = gv.id AND COALESCE(json_extract(e.data, '$.deleted'), 0) = 0) AS annotation_count
+FROM graph_versions gv
+WHERE gv.is_working_set = TRUE
+`
+
+type ListWorkingSetCountsRow struct {
+	ProjectID         string `json:"project_id"`
+	EntityCount       int64  `json:"entity_count"`
+	RelationshipCount int64  `json:"relationship_count"`
+	AnnotationCount   int64  `json:"annotation_count"`
+}
+
+func (q *Queries) ListWorkingSetCounts(ctx context.Context) ([]ListWorkingSetCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listWorkingSetCounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListWorkingSetCountsRow{}
+	for rows.Next() {
+		var i ListWorkingSetCountsRow
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.EntityCount,
+			&i.RelationshipCount,
+			&i.AnnotationCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setProjectOwner = `-- name: SetProjectOwner :exec
+UPDATE projects
+SET owner_id = ?
+WHERE id = ?
+`
+
+type SetProjectOwnerParams struct {
+	OwnerID sql.NullString `json:"owner_id"`
+	ID      string         `json:"id"`
+}
+
+func (q *Queries) SetProjectOwner(ctx context.Context, arg SetProjectOwnerParams) error {
+	_, err := q.db.ExecContext(ctx, setProjectOwner, arg.OwnerID, arg.ID)
+	return err
+}
+
 const updateProject = `-- name: UpdateProject :one
 UPDATE projects
 SET name = ?, theme = ?, genre = ?, description = ?
 WHERE id = ?
-RETURNING id, name, theme, genre, description, created_at, updated_at
+RETURNING id, name, theme, genre, description, created_at, updated_at, status, author, series, owner_id
 `
 
 type UpdateProjectParams struct {
@@ -145,6 +329,48 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.Author,
+		&i.Series,
+		&i.OwnerID,
+	)
+	return i, err
+}
+
+const updateProjectMetadata = `-- name: UpdateProjectMetadata :one
+UPDATE projects
+SET status = ?, author = ?, series = ?
+WHERE id = ?
+RETURNING id, name, theme, genre, description, created_at, updated_at, status, author, series, owner_id
+`
+
+type UpdateProjectMetadataParams struct {
+	Status string         `json:"status"`
+	Author sql.NullString `json:"author"`
+	Series sql.NullString `json:"series"`
+	ID     string         `json:"id"`
+}
+
+func (q *Queries) UpdateProjectMetadata(ctx context.Context, arg UpdateProjectMetadataParams) (Project, error) {
+	row := q.db.QueryRowContext(ctx, updateProjectMetadata,
+		arg.Status,
+		arg.Author,
+		arg.Series,
+		arg.ID,
+	)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Theme,
+		&i.Genre,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.Author,
+		&i.Series,
+		&i.OwnerID,
 	)
 	return i, err
 }
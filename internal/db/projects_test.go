@@ -98,6 +98,84 @@ func setupTestDB(t *testing.T) *Queries {
 		`CREATE UNIQUE INDEX idx_unique_working_set_per_project 
 		ON graph_versions(project_id) 
 		WHERE is_working_set = TRUE;`,
+		// Project metadata
+		`ALTER TABLE projects ADD COLUMN status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'revising', 'complete'));`,
+		`ALTER TABLE projects ADD COLUMN author TEXT;`,
+		`ALTER TABLE projects ADD COLUMN series TEXT;`,
+		// Blobs
+		`CREATE TABLE blobs (
+			hash TEXT PRIMARY KEY,
+			content TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`,
+		// Project settings
+		`CREATE TABLE project_settings (
+			project_id TEXT PRIMARY KEY,
+			analyzer_order JSON NOT NULL DEFAULT '[]',
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+		);`,
+		// Version tags
+		`CREATE TABLE version_tags (
+			project_id TEXT NOT NULL,
+			tag TEXT NOT NULL,
+			version_id TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (project_id, tag),
+			FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+			FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE
+		);`,
+		// Layouts
+		`CREATE TABLE layouts (
+			version_id TEXT NOT NULL,
+			logical_id TEXT NOT NULL,
+			x REAL NOT NULL,
+			y REAL NOT NULL,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (version_id, logical_id),
+			FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE
+		);`,
+		// Project owners
+		`ALTER TABLE projects ADD COLUMN owner_id TEXT;`,
+		// Version membership
+		`CREATE TABLE version_entities (
+			version_id TEXT NOT NULL,
+			entity_id TEXT NOT NULL,
+			PRIMARY KEY (version_id, entity_id),
+			FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE,
+			FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
+		);`,
+		`CREATE TABLE version_relationships (
+			version_id TEXT NOT NULL,
+			relationship_id TEXT NOT NULL,
+			PRIMARY KEY (version_id, relationship_id),
+			FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE,
+			FOREIGN KEY (relationship_id) REFERENCES relationships(id) ON DELETE CASCADE
+		);`,
+		`CREATE TRIGGER add_entity_to_version
+			AFTER INSERT ON entities
+			FOR EACH ROW
+		BEGIN
+			INSERT INTO version_entities (version_id, entity_id) VALUES (NEW.version_id, NEW.id);
+		END;`,
+		`CREATE TRIGGER add_relationship_to_version
+			AFTER INSERT ON relationships
+			FOR EACH ROW
+		BEGIN
+			INSERT INTO version_relationships (version_id, relationship_id) VALUES (NEW.version_id, NEW.id);
+		END;`,
+		// Embeddings
+		`CREATE TABLE embeddings (
+			version_id TEXT NOT NULL,
+			logical_id TEXT NOT NULL,
+			dimensions INTEGER NOT NULL,
+			vector BLOB NOT NULL,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (version_id, logical_id),
+			FOREIGN KEY (version_id) REFERENCES graph_versions(id) ON DELETE CASCADE
+		);`,
+		// Project analyzer sets
+		`ALTER TABLE project_settings ADD COLUMN analyzers JSON NOT NULL DEFAULT 'null';`,
 	}
 
 	for _, migration := range migrations {
@@ -238,6 +316,43 @@ func TestListProjects(t *testing.T) {
 	}
 }
 
+func TestListProjectsByOwner(t *testing.T) {
+	queries := setupTestDB(t)
+	ctx := context.Background()
+
+	owners := []sql.NullString{
+		{String: "alice", Valid: true},
+		{String: "bob", Valid: true},
+		{},
+	}
+	for i, owner := range owners {
+		projectID := uuid.New().String()
+		if _, err := queries.CreateProject(ctx, CreateProjectParams{ID: projectID, Name: "Project " + string(rune('A'+i))}); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+		if err := queries.SetProjectOwner(ctx, SetProjectOwnerParams{OwnerID: owner, ID: projectID}); err != nil {
+			t.Fatalf("Failed to set project owner: %v", err)
+		}
+	}
+
+	projects, err := queries.ListProjectsByOwner(ctx, sql.NullString{String: "alice", Valid: true})
+	if err != nil {
+		t.Fatalf("Failed to list projects by owner: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Name != "Project A" || projects[0].OwnerID.String != "alice" {
+		t.Errorf("Expected only alice's project, got %+v", projects)
+	}
+
+	// Unowned projects are still listed by ListProjects
+	all, err := queries.ListProjects(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list projects: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected 3 projects, got %d", len(all))
+	}
+}
+
 func TestUpdateProject(t *testing.T) {
 	queries := setupTestDB(t)
 	ctx := context.Background()
@@ -285,6 +400,37 @@ func TestUpdateProject(t *testing.T) {
 	}
 }
 
+func TestUpdateProjectMetadata(t *testing.T) {
+	queries := setupTestDB(t)
+	ctx := context.Background()
+
+	projectID := uuid.New().String()
+	project, err := queries.CreateProject(ctx, CreateProjectParams{ID: projectID, Name: "Metadata Project"})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if project.Status != "draft" || project.Author.Valid || project.Series.Valid {
+		t.Errorf("Expected draft status with no author or series, got %q/%v/%v", project.Status, project.Author, project.Series)
+	}
+
+	updated, err := queries.UpdateProjectMetadata(ctx, UpdateProjectMetadataParams{
+		ID:     projectID,
+		Status: "complete",
+		Author: sql.NullString{String: "A. Writer", Valid: true},
+		Series: sql.NullString{String: "The Elena Saga", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update project metadata: %v", err)
+	}
+	if updated.Status != "complete" || updated.Author.String != "A. Writer" || updated.Series.String != "The Elena Saga" {
+		t.Errorf("Unexpected metadata after update: %+v", updated)
+	}
+
+	if _, err := queries.UpdateProjectMetadata(ctx, UpdateProjectMetadataParams{ID: projectID, Status: "abandoned"}); err == nil {
+		t.Error("Expected unknown status to violate the check constraint")
+	}
+}
+
 func TestDeleteProject(t *testing.T) {
 	queries := setupTestDB(t)
 	ctx := context.Background()
@@ -10,9 +10,20 @@ import (
 )
 
 type Querier interface {
+	// Moves the working set like SetWorkingSet, but only while expected_id is still the
+	// working set, so the check and the move cannot interleave with another writer's.
+	// Affects no rows when the working set has moved on.
+	AdvanceWorkingSet(ctx context.Context, arg AdvanceWorkingSetParams) (int64, error)
+	CountAnnotationsByVersion(ctx context.Context, versionID string) (int64, error)
+	CountBlobs(ctx context.Context) (int64, error)
 	CountEntitiesByType(ctx context.Context, arg CountEntitiesByTypeParams) (int64, error)
+	// Version membership operations
+	CountVersionEntity(ctx context.Context, arg CountVersionEntityParams) (int64, error)
+	CountVersionRelationship(ctx context.Context, arg CountVersionRelationshipParams) (int64, error)
 	// Annotations CRUD operations
 	CreateAnnotation(ctx context.Context, arg CreateAnnotationParams) (Annotation, error)
+	// Content-addressed blob operations
+	CreateBlob(ctx context.Context, arg CreateBlobParams) error
 	// Entities CRUD operations
 	CreateEntity(ctx context.Context, arg CreateEntityParams) (Entity, error)
 	// Graph versions CRUD operations
@@ -21,7 +32,11 @@ type Querier interface {
 	CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error)
 	// Relationships CRUD operations
 	CreateRelationship(ctx context.Context, arg CreateRelationshipParams) (Relationship, error)
+	// Relationship annotations
+	CreateRelationshipAnnotation(ctx context.Context, arg CreateRelationshipAnnotationParams) (RelationshipAnnotation, error)
 	CreateScene(ctx context.Context, arg CreateSceneParams) (Scene, error)
+	// Version tag operations
+	CreateVersionTag(ctx context.Context, arg CreateVersionTagParams) (VersionTag, error)
 	DeleteAnnotation(ctx context.Context, id string) error
 	DeleteAnnotationsByEntity(ctx context.Context, entityID string) error
 	DeleteEntity(ctx context.Context, id string) error
@@ -31,31 +46,79 @@ type Querier interface {
 	DeleteRelationshipsByEntity(ctx context.Context, arg DeleteRelationshipsByEntityParams) error
 	DeleteScene(ctx context.Context, id string) error
 	GetAnnotation(ctx context.Context, id string) (Annotation, error)
+	GetBlob(ctx context.Context, hash string) (Blob, error)
 	GetEntity(ctx context.Context, id string) (Entity, error)
 	GetGraphVersion(ctx context.Context, id string) (GraphVersion, error)
 	GetProject(ctx context.Context, id string) (Project, error)
+	// Project settings operations
+	GetProjectSettings(ctx context.Context, projectID string) (ProjectSetting, error)
 	GetRelationship(ctx context.Context, id string) (Relationship, error)
 	GetRelationshipsBetweenEntities(ctx context.Context, arg GetRelationshipsBetweenEntitiesParams) ([]Relationship, error)
 	GetScene(ctx context.Context, id string) (Scene, error)
+	GetVersionTag(ctx context.Context, arg GetVersionTagParams) (VersionTag, error)
 	GetWorkingSetVersion(ctx context.Context, projectID string) (GraphVersion, error)
 	ListAnnotationsByAgent(ctx context.Context, agentName sql.NullString) ([]Annotation, error)
 	ListAnnotationsByEntity(ctx context.Context, entityID string) ([]Annotation, error)
 	ListAnnotationsByType(ctx context.Context, arg ListAnnotationsByTypeParams) ([]Annotation, error)
+	ListAnnotationsByVersion(ctx context.Context, versionID string) ([]Annotation, error)
+	// Annotations whose entity row no longer exists
+	ListDanglingAnnotations(ctx context.Context) ([]Annotation, error)
+	// Relationships with an endpoint whose entity row no longer exists
+	ListDanglingRelationships(ctx context.Context) ([]Relationship, error)
+	ListEmbeddingsByVersion(ctx context.Context, versionID string) ([]Embedding, error)
+	// Lists a version's entities whose logical IDs are in a JSON array of IDs, in no
+	// particular order
+	ListEntitiesByLogicalIDs(ctx context.Context, arg ListEntitiesByLogicalIDsParams) ([]Entity, error)
 	ListEntitiesByType(ctx context.Context, arg ListEntitiesByTypeParams) ([]Entity, error)
 	ListEntitiesByVersion(ctx context.Context, versionID string) ([]Entity, error)
+	// Lists one page of a version's entities, optionally of one type, sorted by name,
+	// entity_type or created_at (the default order when order_by is empty). Ties keep the
+	// default order so pages stay stable; a negative page_limit returns every row.
+	// Tombstoned entities, whose data sets deleted, are left out unless include_deleted.
+	ListEntitiesPage(ctx context.Context, arg ListEntitiesPageParams) ([]Entity, error)
 	ListGraphVersionsByProject(ctx context.Context, projectID string) ([]GraphVersion, error)
+	// Versions in which the entity is a tombstone are left out.
+	ListGraphVersionsContainingEntity(ctx context.Context, logicalID interface{}) ([]GraphVersion, error)
+	ListLayoutPositions(ctx context.Context, versionID string) ([]Layout, error)
+	// Annotations whose entity row is gone, or which sit on an old version's entity
+	// that is still in the project's working set without a carried-forward copy
+	ListOrphanAnnotations(ctx context.Context) ([]Annotation, error)
+	ListProjectVersionStats(ctx context.Context) ([]ListProjectVersionStatsRow, error)
 	ListProjects(ctx context.Context) ([]Project, error)
+	ListProjectsByOwner(ctx context.Context, ownerID sql.NullString) ([]Project, error)
+	ListRelationshipAnnotationsByRelationship(ctx context.Context, relationshipID string) ([]RelationshipAnnotation, error)
+	// Annotations on every relationship row that is part of a version
+	ListRelationshipAnnotationsByVersion(ctx context.Context, versionID string) ([]RelationshipAnnotation, error)
+	ListRelationshipTypesByVersion(ctx context.Context, versionID string) ([]string, error)
 	ListRelationshipsByEntity(ctx context.Context, arg ListRelationshipsByEntityParams) ([]Relationship, error)
-	ListRelationshipsByType(ctx context.Context, arg ListRelationshipsByTypeParams) ([]Relationship, error)
+	// Relationships touching an entity row that are part of a version; VersionID on
+	// each row is the version that created it
+	ListRelationshipsByEntityInVersion(ctx context.Context, arg ListRelationshipsByEntityInVersionParams) ([]Relationship, error)
 	ListRelationshipsByVersion(ctx context.Context, versionID string) ([]Relationship, error)
+	ListRelationshipsByVersionAndType(ctx context.Context, arg ListRelationshipsByVersionAndTypeParams) ([]Relationship, error)
 	ListScenes(ctx context.Context) ([]Scene, error)
+	ListVersionTags(ctx context.Context, projectID string) ([]VersionTag, error)
+	ListWorkingSetCounts(ctx context.Context) ([]ListWorkingSetCountsRow, error)
+	RemoveVersionEntity(ctx context.Context, arg RemoveVersionEntityParams) error
+	RemoveVersionRelationship(ctx context.Context, arg RemoveVersionRelationshipParams) error
+	SetProjectOwner(ctx context.Context, arg SetProjectOwnerParams) error
 	SetWorkingSet(ctx context.Context, arg SetWorkingSetParams) error
+	// Adds every entity row of the parent version to a version without copying the rows
+	ShareVersionEntities(ctx context.Context, arg ShareVersionEntitiesParams) error
+	// Adds every relationship row of the parent version to a version without copying the rows
+	ShareVersionRelationships(ctx context.Context, arg ShareVersionRelationshipsParams) error
 	UpdateAnnotation(ctx context.Context, arg UpdateAnnotationParams) (Annotation, error)
 	UpdateEntity(ctx context.Context, arg UpdateEntityParams) (Entity, error)
 	UpdateGraphVersion(ctx context.Context, arg UpdateGraphVersionParams) (GraphVersion, error)
 	UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error)
+	UpdateProjectMetadata(ctx context.Context, arg UpdateProjectMetadataParams) (Project, error)
 	UpdateRelationship(ctx context.Context, arg UpdateRelationshipParams) (Relationship, error)
 	UpdateScene(ctx context.Context, arg UpdateSceneParams) (Scene, error)
+	// Entity embedding operations
+	UpsertEmbedding(ctx context.Context, arg UpsertEmbeddingParams) error
+	// Graph layout operations
+	UpsertLayoutPosition(ctx context.Context, arg UpsertLayoutPositionParams) error
+	UpsertProjectSettings(ctx context.Context, arg UpsertProjectSettingsParams) (ProjectSetting, error)
 }
 
 var _ Querier = (*Queries)(nil)
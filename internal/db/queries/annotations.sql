@@ -12,17 +12,17 @@ WHERE id = ?;
 -- name: ListAnnotationsByEntity :many
 SELECT * FROM annotations
 WHERE entity_id = ?
-ORDER BY created_at DESC;
+ORDER BY created_at DESC, rowid DESC;
 
 -- name: ListAnnotationsByType :many
 SELECT * FROM annotations
 WHERE entity_id = ? AND annotation_type = ?
-ORDER BY created_at DESC;
+ORDER BY created_at DESC, rowid DESC;
 
 -- name: ListAnnotationsByAgent :many
 SELECT * FROM annotations
 WHERE agent_name = ?
-ORDER BY created_at DESC;
+ORDER BY created_at DESC, rowid DESC;
 
 -- name: UpdateAnnotation :one
 UPDATE annotations
@@ -36,4 +36,48 @@ WHERE id = ?;
 
 -- name: DeleteAnnotationsByEntity :exec
 DELETE FROM annotations
-WHERE entity_id = ?;
\ No newline at end of file
+WHERE entity_id = ?;
+
+-- name: CountAnnotationsByVersion :one
+SELECT COUNT(*) FROM annotations
+JOIN version_entities ON version_entities.entity_id = annotations.entity_id
+WHERE version_entities.version_id = ?;
+
+-- name: ListAnnotationsByVersion :many
+SELECT annotations.* FROM annotations
+JOIN version_entities ON version_entities.entity_id = annotations.entity_id
+WHERE version_entities.version_id = ?
+ORDER BY annotations.created_at DESC, annotations.rowid DESC;
+
+-- name: ListDanglingAnnotations :many
+-- Annotations whose entity row no longer exists
+SELECT annotations.* FROM annotations
+WHERE NOT EXISTS (SELECT 1 FROM entities WHERE entities.id = annotations.entity_id)
+ORDER BY annotations.created_at ASC;
+
+-- name: ListOrphanAnnotations :many
+-- Annotations whose entity row is gone, or which sit on an old version's entity
+-- that is still in the project's working set without a carried-forward copy
+SELECT annotations.* FROM annotations
+LEFT JOIN entities ON entities.id = annotations.entity_id
+LEFT JOIN graph_versions ON graph_versions.id = entities.version_id
+WHERE entities.id IS NULL
+   OR (graph_versions.is_working_set = 0
+       AND EXISTS (
+           SELECT 1 FROM entities AS current
+           JOIN version_entities AS membership ON membership.entity_id = current.id
+           JOIN graph_versions AS working_set ON working_set.id = membership.version_id
+           WHERE working_set.project_id = graph_versions.project_id
+             AND working_set.is_working_set = 1
+             AND json_extract(current.data, '$.logical_id') = json_extract(entities.data, '$.logical_id'))
+       AND NOT EXISTS (
+           SELECT 1 FROM annotations AS carried
+           JOIN entities AS current ON current.id = carried.entity_id
+           JOIN version_entities AS membership ON membership.entity_id = current.id
+           JOIN graph_versions AS working_set ON working_set.id = membership.version_id
+           WHERE working_set.project_id = graph_versions.project_id
+             AND working_set.is_working_set = 1
+             AND json_extract(current.data, '$.logical_id') = json_extract(entities.data, '$.logical_id')
+             AND carried.annotation_type = annotations.annotation_type
+             AND carried.agent_name IS annotations.agent_name))
+ORDER BY annotations.created_at ASC;
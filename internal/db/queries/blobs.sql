@@ -0,0 +1,13 @@
+-- Content-addressed blob operations
+
+-- name: CreateBlob :exec
+INSERT INTO blobs (hash, content)
+VALUES (?, ?)
+ON CONFLICT (hash) DO NOTHING;
+
+-- name: GetBlob :one
+SELECT * FROM blobs
+WHERE hash = ?;
+
+-- name: CountBlobs :one
+SELECT COUNT(*) FROM blobs;
@@ -0,0 +1,12 @@
+-- Entity embedding operations
+
+-- name: UpsertEmbedding :exec
+INSERT INTO embeddings (version_id, logical_id, dimensions, vector)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (version_id, logical_id) DO UPDATE
+SET dimensions = excluded.dimensions, vector = excluded.vector, updated_at = CURRENT_TIMESTAMP;
+
+-- name: ListEmbeddingsByVersion :many
+SELECT * FROM embeddings
+WHERE version_id = ?
+ORDER BY logical_id ASC;
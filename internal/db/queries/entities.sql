@@ -10,14 +10,44 @@ SELECT * FROM entities
 WHERE id = ?;
 
 -- name: ListEntitiesByVersion :many
-SELECT * FROM entities
-WHERE version_id = ?
-ORDER BY created_at DESC;
+SELECT entities.id, version_entities.version_id, entities.entity_type, entities.name, entities.data, entities.created_at, entities.updated_at FROM version_entities
+JOIN entities ON entities.id = version_entities.entity_id
+WHERE version_entities.version_id = ?
+ORDER BY entities.created_at DESC, entities.entity_type ASC, entities.rowid ASC;
+
+-- name: ListEntitiesByLogicalIDs :many
+-- Lists a version's entities whose logical IDs are in a JSON array of IDs, in no
+-- particular order
+SELECT entities.id, version_entities.version_id, entities.entity_type, entities.name, entities.data, entities.created_at, entities.updated_at FROM entities
+JOIN version_entities ON version_entities.entity_id = entities.id
+WHERE json_extract(entities.data, '$.logical_id') IN (SELECT value FROM json_each(CAST(sqlc.arg(logical_ids) AS TEXT)))
+  AND version_entities.version_id = sqlc.arg(version_id);
 
 -- name: ListEntitiesByType :many
-SELECT * FROM entities
-WHERE version_id = ? AND entity_type = ?
-ORDER BY created_at DESC;
+SELECT entities.id, version_entities.version_id, entities.entity_type, entities.name, entities.data, entities.created_at, entities.updated_at FROM version_entities
+JOIN entities ON entities.id = version_entities.entity_id
+WHERE version_entities.version_id = ? AND entities.entity_type = ?
+ORDER BY entities.created_at DESC, entities.entity_type ASC, entities.rowid ASC;
+
+-- Lists one page of a version's entities, optionally of one type, sorted by name,
+-- entity_type or created_at (the default order when order_by is empty). Ties keep the
+-- default order so pages stay stable; a negative page_limit returns every row.
+-- Tombstoned entities, whose data sets deleted, are left out unless include_deleted.
+-- name: ListEntitiesPage :many
+SELECT entities.id, version_entities.version_id, entities.entity_type, entities.name, entities.data, entities.created_at, entities.updated_at FROM version_entities
+JOIN entities ON entities.id = version_entities.entity_id
+WHERE version_entities.version_id = sqlc.arg(version_id)
+  AND (sqlc.narg(entity_type) IS NULL OR entities.entity_type = sqlc.narg(entity_type))
+  AND (CAST(sqlc.arg(include_deleted) AS BOOLEAN) OR NOT IFNULL(json_extract(entities.data, '$.deleted'), FALSE))
+ORDER BY
+  CASE WHEN CAST(sqlc.arg(order_by) AS TEXT) = 'name' AND NOT CAST(sqlc.arg(descending) AS BOOLEAN) THEN entities.name END ASC,
+  CASE WHEN CAST(sqlc.arg(order_by) AS TEXT) = 'name' AND CAST(sqlc.arg(descending) AS BOOLEAN) THEN entities.name END DESC,
+  CASE WHEN CAST(sqlc.arg(order_by) AS TEXT) = 'entity_type' AND NOT CAST(sqlc.arg(descending) AS BOOLEAN) THEN entities.entity_type END ASC,
+  CASE WHEN CAST(sqlc.arg(order_by) AS TEXT) = 'entity_type' AND CAST(sqlc.arg(descending) AS BOOLEAN) THEN entities.entity_type END DESC,
+  CASE WHEN CAST(sqlc.arg(order_by) AS TEXT) = 'created_at' AND NOT CAST(sqlc.arg(descending) AS BOOLEAN) THEN entities.created_at END ASC,
+  CASE WHEN CAST(sqlc.arg(order_by) AS TEXT) = 'created_at' AND CAST(sqlc.arg(descending) AS BOOLEAN) THEN entities.created_at END DESC,
+  entities.created_at DESC, entities.entity_type ASC, entities.rowid ASC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
 
 -- name: UpdateEntity :one
 UPDATE entities
@@ -30,5 +60,6 @@ DELETE FROM entities
 WHERE id = ?;
 
 -- name: CountEntitiesByType :one
-SELECT COUNT(*) FROM entities
-WHERE version_id = ? AND entity_type = ?;
\ No newline at end of file
+SELECT COUNT(*) FROM version_entities
+JOIN entities ON entities.id = version_entities.entity_id
+WHERE version_entities.version_id = ? AND entities.entity_type = ?;
\ No newline at end of file
@@ -29,6 +29,28 @@ UPDATE graph_versions
 SET is_working_set = CASE WHEN id = ? THEN TRUE ELSE FALSE END
 WHERE project_id = ?;
 
+-- Moves the working set like SetWorkingSet, but only while expected_id is still the
+-- working set, so the check and the move cannot interleave with another writer's.
+-- Affects no rows when the working set has moved on.
+-- name: AdvanceWorkingSet :execrows
+UPDATE graph_versions
+SET is_working_set = CASE WHEN id = sqlc.arg(id) THEN TRUE ELSE FALSE END
+WHERE project_id = sqlc.arg(project_id)
+  AND EXISTS (
+    SELECT 1 FROM graph_versions AS current
+    WHERE current.project_id = sqlc.arg(project_id)
+      AND current.id = sqlc.arg(expected_id)
+      AND current.is_working_set = TRUE
+  );
+
 -- name: DeleteGraphVersion :exec
 DELETE FROM graph_versions
-WHERE id = ?;
\ No newline at end of file
+WHERE id = ?;
+
+-- name: ListGraphVersionsContainingEntity :many
+SELECT DISTINCT graph_versions.* FROM graph_versions
+JOIN version_entities ON version_entities.version_id = graph_versions.id
+JOIN entities ON entities.id = version_entities.entity_id
+WHERE json_extract(entities.data, '$.logical_id') = sqlc.arg(logical_id)
+  AND json_extract(entities.data, '$.deleted') IS NOT 1
+ORDER BY graph_versions.created_at ASC;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- Graph layout operations
+
+-- name: UpsertLayoutPosition :exec
+INSERT INTO layouts (version_id, logical_id, x, y)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (version_id, logical_id) DO UPDATE
+SET x = excluded.x, y = excluded.y, updated_at = CURRENT_TIMESTAMP;
+
+-- name: ListLayoutPositions :many
+SELECT * FROM layouts
+WHERE version_id = ?
+ORDER BY logical_id ASC;
@@ -0,0 +1,12 @@
+-- Project settings operations
+
+-- name: GetProjectSettings :one
+SELECT * FROM project_settings
+WHERE project_id = ?;
+
+-- name: UpsertProjectSettings :one
+INSERT INTO project_settings (project_id, analyzer_order, analyzers)
+VALUES (?, ?, ?)
+ON CONFLICT (project_id) DO UPDATE
+SET analyzer_order = excluded.analyzer_order, analyzers = excluded.analyzers, updated_at = CURRENT_TIMESTAMP
+RETURNING *;
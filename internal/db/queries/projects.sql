@@ -13,6 +13,16 @@ WHERE id = ?;
 SELECT * FROM projects
 ORDER BY created_at DESC;
 
+-- name: ListProjectsByOwner :many
+SELECT * FROM projects
+WHERE owner_id = ?
+ORDER BY created_at DESC;
+
+-- name: SetProjectOwner :exec
+UPDATE projects
+SET owner_id = ?
+WHERE id = ?;
+
 -- name: UpdateProject :one
 UPDATE projects
 SET name = ?, theme = ?, genre = ?, description = ?
@@ -21,4 +31,34 @@ RETURNING *;
 
 -- name: DeleteProject :exec
 DELETE FROM projects
-WHERE id = ?;
\ No newline at end of file
+WHERE id = ?;
+
+-- name: UpdateProjectMetadata :one
+UPDATE projects
+SET status = ?, author = ?, series = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: ListProjectVersionStats :many
+SELECT p.id, p.name, p.theme, p.genre, p.description, p.created_at, p.updated_at, p.status, p.author, p.series, p.owner_id,
+       COUNT(gv.id) AS version_count,
+       CAST(MAX(CASE WHEN gv.is_working_set THEN gv.id END) AS TEXT) AS working_set_version_id
+FROM projects p
+LEFT JOIN graph_versions gv ON gv.project_id = p.id
+GROUP BY p.id
+ORDER BY p.created_at DESC;
+
+-- name: ListWorkingSetCounts :many
+SELECT gv.project_id,
+       (SELECT COUNT(*) FROM version_entities ve JOIN entities e ON e.id = ve.entity_id
+        WHERE ve.version_id = gv.id AND COALESCE(json_extract(e.data, '$.deleted'), 0) = 0) AS entity_count,
+       (SELECT COUNT(*) FROM version_relationships vr JOIN relationships r ON r.id = vr.relationship_id
+        JOIN entities ef ON ef.id = r.from_entity_id JOIN entities et ON et.id = r.to_entity_id
+        WHERE vr.version_id = gv.id
+          AND COALESCE(json_extract(ef.data, '$.deleted'), 0) = 0
+          AND COALESCE(json_extract(et.data, '$.deleted'), 0) = 0) AS relationship_count,
+       (SELECT COUNT(*) FROM annotations a JOIN version_entities ve ON ve.entity_id = a.entity_id
+        JOIN entities e ON e.id = a.entity_id
+        WHERE ve.version_id = gv.id AND COALESCE(json_extract(e.data, '$.deleted'), 0) = 0) AS annotation_count
+FROM graph_versions gv
+WHERE gv.is_working_set = TRUE;
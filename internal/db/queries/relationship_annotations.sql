@@ -0,0 +1,18 @@
+-- Relationship annotations
+
+-- name: CreateRelationshipAnnotation :one
+INSERT INTO relationship_annotations (id, relationship_id, annotation_type, content, metadata, agent_name)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListRelationshipAnnotationsByRelationship :many
+SELECT * FROM relationship_annotations
+WHERE relationship_id = ?
+ORDER BY created_at DESC, rowid DESC;
+
+-- Annotations on every relationship row that is part of a version
+-- name: ListRelationshipAnnotationsByVersion :many
+SELECT relationship_annotations.* FROM relationship_annotations
+JOIN version_relationships ON version_relationships.relationship_id = relationship_annotations.relationship_id
+WHERE version_relationships.version_id = ?
+ORDER BY relationship_annotations.created_at DESC, relationship_annotations.rowid DESC;
@@ -10,19 +10,43 @@ SELECT * FROM relationships
 WHERE id = ?;
 
 -- name: ListRelationshipsByVersion :many
-SELECT * FROM relationships
-WHERE version_id = ?
-ORDER BY created_at DESC;
+SELECT relationships.id, version_relationships.version_id, relationships.from_entity_id, relationships.to_entity_id, relationships.relationship_type, relationships.properties, relationships.created_at FROM version_relationships
+JOIN relationships ON relationships.id = version_relationships.relationship_id
+WHERE version_relationships.version_id = ?
+ORDER BY relationships.created_at DESC, relationships.relationship_type ASC, relationships.rowid ASC;
+
+-- name: ListRelationshipsByVersionAndType :many
+SELECT relationships.id, version_relationships.version_id, relationships.from_entity_id, relationships.to_entity_id, relationships.relationship_type, relationships.properties, relationships.created_at FROM version_relationships
+JOIN relationships ON relationships.id = version_relationships.relationship_id
+WHERE version_relationships.version_id = ? AND relationships.relationship_type = ?
+ORDER BY relationships.created_at DESC, relationships.relationship_type ASC, relationships.rowid ASC;
 
 -- name: ListRelationshipsByEntity :many
 SELECT * FROM relationships
 WHERE (from_entity_id = ? OR to_entity_id = ?)
 ORDER BY created_at DESC;
 
--- name: ListRelationshipsByType :many
+-- name: ListRelationshipsByEntityInVersion :many
+-- Relationships touching an entity row that are part of a version; VersionID on
+-- each row is the version that created it
+SELECT relationships.* FROM relationships
+JOIN version_relationships ON version_relationships.relationship_id = relationships.id
+WHERE version_relationships.version_id = ?
+  AND (relationships.from_entity_id = ? OR relationships.to_entity_id = ?)
+ORDER BY relationships.created_at DESC, relationships.relationship_type ASC, relationships.rowid ASC;
+
+-- name: ListDanglingRelationships :many
+-- Relationships with an endpoint whose entity row no longer exists
 SELECT * FROM relationships
-WHERE version_id = ? AND relationship_type = ?
-ORDER BY created_at DESC;
+WHERE NOT EXISTS (SELECT 1 FROM entities WHERE entities.id = relationships.from_entity_id)
+   OR NOT EXISTS (SELECT 1 FROM entities WHERE entities.id = relationships.to_entity_id)
+ORDER BY created_at ASC;
+
+-- name: ListRelationshipTypesByVersion :many
+SELECT DISTINCT relationships.relationship_type FROM version_relationships
+JOIN relationships ON relationships.id = version_relationships.relationship_id
+WHERE version_relationships.version_id = ?
+ORDER BY relationships.relationship_type ASC;
 
 -- name: GetRelationshipsBetweenEntities :many
 SELECT * FROM relationships
@@ -0,0 +1,29 @@
+-- Version membership operations
+
+-- name: CountVersionEntity :one
+SELECT COUNT(*) FROM version_entities
+WHERE version_id = ? AND entity_id = ?;
+
+-- name: CountVersionRelationship :one
+SELECT COUNT(*) FROM version_relationships
+WHERE version_id = ? AND relationship_id = ?;
+
+-- name: ShareVersionEntities :exec
+-- Adds every entity row of the parent version to a version without copying the rows
+INSERT INTO version_entities (version_id, entity_id)
+SELECT sqlc.arg(version_id), entity_id FROM version_entities
+WHERE version_entities.version_id = sqlc.arg(parent_version_id);
+
+-- name: ShareVersionRelationships :exec
+-- Adds every relationship row of the parent version to a version without copying the rows
+INSERT INTO version_relationships (version_id, relationship_id)
+SELECT sqlc.arg(version_id), relationship_id FROM version_relationships
+WHERE version_relationships.version_id = sqlc.arg(parent_version_id);
+
+-- name: RemoveVersionEntity :exec
+DELETE FROM version_entities
+WHERE version_id = ? AND entity_id = ?;
+
+-- name: RemoveVersionRelationship :exec
+DELETE FROM version_relationships
+WHERE version_id = ? AND relationship_id = ?;
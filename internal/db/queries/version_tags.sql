@@ -0,0 +1,15 @@
+-- Version tag operations
+
+-- name: CreateVersionTag :one
+INSERT INTO version_tags (project_id, tag, version_id)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: GetVersionTag :one
+SELECT * FROM version_tags
+WHERE project_id = ? AND tag = ?;
+
+-- name: ListVersionTags :many
+SELECT * FROM version_tags
+WHERE project_id = ?
+ORDER BY created_at ASC, tag ASC;
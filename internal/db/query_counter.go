@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+)
+
+// QueryCounter counts the statements executed with a context that carries it.
+// It is safe for concurrent use.
+type QueryCounter struct {
+	count atomic.Int64
+}
+
+// Count returns the number of statements executed so far
+func (c *QueryCounter) Count() int64 {
+	return c.count.Load()
+}
+
+type queryCounterKey struct{}
+
+// WithQueryCounter returns a context carrying a fresh QueryCounter. Statements run
+// with the context through a Database from WithQueryCounting are counted on it.
+func WithQueryCounter(ctx context.Context) (context.Context, *QueryCounter) {
+	counter := &QueryCounter{}
+	return context.WithValue(ctx, queryCounterKey{}, counter), counter
+}
+
+// countQuery increments the counter carried by ctx, if any
+func countQuery(ctx context.Context) {
+	if counter, ok := ctx.Value(queryCounterKey{}).(*QueryCounter); ok {
+		counter.count.Add(1)
+	}
+}
+
+// countingDBTX counts every statement against the QueryCounter in its context
+type countingDBTX struct {
+	DBTX
+}
+
+func (c countingDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	countQuery(ctx)
+	return c.DBTX.ExecContext(ctx, query, args...)
+}
+
+func (c countingDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	countQuery(ctx)
+	return c.DBTX.PrepareContext(ctx, query)
+}
+
+func (c countingDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	countQuery(ctx)
+	return c.DBTX.QueryContext(ctx, query, args...)
+}
+
+func (c countingDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	countQuery(ctx)
+	return c.DBTX.QueryRowContext(ctx, query, args...)
+}
+
+// WithQueryCounting returns a Database sharing this connection that counts statements
+// against the QueryCounter in each call's context (see WithQueryCounter). Calls whose
+// context carries no counter pass straight through.
+func (d *Database) WithQueryCounting() *Database {
+	return d.WithDBTX(countingDBTX{DBTX: d.db})
+}
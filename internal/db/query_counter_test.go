@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestWithQueryCounting(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "libretto_query_counter_test_*.db")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	tmpFile.Close()
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	database, err := NewDatabase(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer database.Close()
+
+	if err := database.Migrate(context.Background()); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	counted := database.WithQueryCounting()
+	ctx, counter := WithQueryCounter(context.Background())
+
+	if _, err := counted.Queries().CreateProject(ctx, CreateProjectParams{ID: "project-1", Name: "Counted"}); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := counted.Queries().ListProjects(ctx); err != nil {
+		t.Fatalf("Failed to list projects: %v", err)
+	}
+	if _, err := counted.Queries().GetProject(ctx, "project-1"); err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	if counter.Count() != 3 {
+		t.Errorf("Expected 3 counted queries, got %d", counter.Count())
+	}
+
+	// Queries without a counter in their context, or through the plain Database, are not counted
+	if _, err := counted.Queries().ListProjects(context.Background()); err != nil {
+		t.Fatalf("Failed to list projects: %v", err)
+	}
+	if _, err := database.Queries().ListProjects(ctx); err != nil {
+		t.Fatalf("Failed to list projects: %v", err)
+	}
+	if counter.Count() != 3 {
+		t.Errorf("Expected uncounted queries to be ignored, got %d", counter.Count())
+	}
+}
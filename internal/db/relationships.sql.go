@@ -135,6 +135,75 @@ func (q *Queries) GetRelationshipsBetweenEntities(ctx context.Context, arg GetRe
 	return items, nil
 }
 
+const listDanglingRelationships = `-- name: ListDanglingRelationships :many
+SELECT id, version_id, from_entity_id, to_entity_id, relationship_type, properties, created_at FROM relationships
+WHERE NOT EXISTS (SELECT 1 FROM entities WHERE entities.id = relationships.from_entity_id)
+   OR NOT EXISTS (SELECT 1 FROM entities WHERE entities.id = relationships.to_entity_id)
+ORDER BY created_at ASC
+`
+
+// Relationships with an endpoint whose entity row no longer exists
+func (q *Queries) ListDanglingRelationships(ctx context.Context) ([]Relationship, error) {
+	rows, err := q.db.QueryContext(ctx, listDanglingRelationships)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Relationship{}
+	for rows.Next() {
+		var i Relationship
+		if err := rows.Scan(
+			&i.ID,
+			&i.VersionID,
+			&i.FromEntityID,
+			&i.ToEntityID,
+			&i.RelationshipType,
+			&i.Properties,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRelationshipTypesByVersion = `-- name: ListRelationshipTypesByVersion :many
+SELECT DISTINCT relationships.relationship_type FROM version_relationships
+JOIN relationships ON relationships.id = version_relationships.relationship_id
+WHERE version_relationships.version_id = ?
+ORDER BY relationships.relationship_type ASC
+`
+
+func (q *Queries) ListRelationshipTypesByVersion(ctx context.Context, versionID string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listRelationshipTypesByVersion, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var relationship_type string
+		if err := rows.Scan(&relationship_type); err != nil {
+			return nil, err
+		}
+		items = append(items, relationship_type)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listRelationshipsByEntity = `-- name: ListRelationshipsByEntity :many
 SELECT id, version_id, from_entity_id, to_entity_id, relationship_type, properties, created_at FROM relationships
 WHERE (from_entity_id = ? OR to_entity_id = ?)
@@ -177,19 +246,24 @@ func (q *Queries) ListRelationshipsByEntity(ctx context.Context, arg ListRelatio
 	return items, nil
 }
 
-const listRelationshipsByType = `-- name: ListRelationshipsByType :many
-SELECT id, version_id, from_entity_id, to_entity_id, relationship_type, properties, created_at FROM relationships
-WHERE version_id = ? AND relationship_type = ?
-ORDER BY created_at DESC
+const listRelationshipsByEntityInVersion = `-- name: ListRelationshipsByEntityInVersion :many
+SELECT relationships.id, relationships.version_id, relationships.from_entity_id, relationships.to_entity_id, relationships.relationship_type, relationships.properties, relationships.created_at FROM relationships
+JOIN version_relationships ON version_relationships.relationship_id = relationships.id
+WHERE version_relationships.version_id = ?
+  AND (relationships.from_entity_id = ? OR relationships.to_entity_id = ?)
+ORDER BY relationships.created_at DESC, relationships.relationship_type ASC, relationships.rowid ASC
 `
 
-type ListRelationshipsByTypeParams struct {
-	VersionID        string `json:"version_id"`
-	RelationshipType string `json:"relationship_type"`
+type ListRelationshipsByEntityInVersionParams struct {
+	VersionID    string `json:"version_id"`
+	FromEntityID string `json:"from_entity_id"`
+	ToEntityID   string `json:"to_entity_id"`
 }
 
-func (q *Queries) ListRelationshipsByType(ctx context.Context, arg ListRelationshipsByTypeParams) ([]Relationship, error) {
-	rows, err := q.db.QueryContext(ctx, listRelationshipsByType, arg.VersionID, arg.RelationshipType)
+// Relationships touching an entity row that are part of a version; VersionID on
+// each row is the version that created it
+func (q *Queries) ListRelationshipsByEntityInVersion(ctx context.Context, arg ListRelationshipsByEntityInVersionParams) ([]Relationship, error) {
+	rows, err := q.db.QueryContext(ctx, listRelationshipsByEntityInVersion, arg.VersionID, arg.FromEntityID, arg.ToEntityID)
 	if err != nil {
 		return nil, err
 	}
@@ -220,9 +294,10 @@ func (q *Queries) ListRelationshipsByType(ctx context.Context, arg ListRelations
 }
 
 const listRelationshipsByVersion = `-- name: ListRelationshipsByVersion :many
-SELECT id, version_id, from_entity_id, to_entity_id, relationship_type, properties, created_at FROM relationships
-WHERE version_id = ?
-ORDER BY created_at DESC
+SELECT relationships.id, version_relationships.version_id, relationships.from_entity_id, relationships.to_entity_id, relationships.relationship_type, relationships.properties, relationships.created_at FROM version_relationships
+JOIN relationships ON relationships.id = version_relationships.relationship_id
+WHERE version_relationships.version_id = ?
+ORDER BY relationships.created_at DESC, relationships.relationship_type ASC, relationships.rowid ASC
 `
 
 func (q *Queries) ListRelationshipsByVersion(ctx context.Context, versionID string) ([]Relationship, error) {
@@ -256,6 +331,49 @@ func (q *Queries) ListRelationshipsByVersion(ctx context.Context, versionID stri
 	return items, nil
 }
 
+const listRelationshipsByVersionAndType = `-- name: ListRelationshipsByVersionAndType :many
+SELECT relationships.id, version_relationships.version_id, relationships.from_entity_id, relationships.to_entity_id, relationships.relationship_type, relationships.properties, relationships.created_at FROM version_relationships
+JOIN relationships ON relationships.id = version_relationships.relationship_id
+WHERE version_relationships.version_id = ? AND relationships.relationship_type = ?
+ORDER BY relationships.created_at DESC, relationships.relationship_type ASC, relationships.rowid ASC
+`
+
+type ListRelationshipsByVersionAndTypeParams struct {
+	VersionID        string `json:"version_id"`
+	RelationshipType string `json:"relationship_type"`
+}
+
+func (q *Queries) ListRelationshipsByVersionAndType(ctx context.Context, arg ListRelationshipsByVersionAndTypeParams) ([]Relationship, error) {
+	rows, err := q.db.QueryContext(ctx, listRelationshipsByVersionAndType, arg.VersionID, arg.RelationshipType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Relationship{}
+	for rows.Next() {
+		var i Relationship
+		if err := rows.Scan(
+			&i.ID,
+			&i.VersionID,
+			&i.FromEntityID,
+			&i.ToEntityID,
+			&i.RelationshipType,
+			&i.Properties,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateRelationship = `-- name: UpdateRelationship :one
 UPDATE relationships
 SET properties = ?
@@ -269,7 +269,7 @@ func TestListRelationshipsByEntity(t *testing.T) {
 	}
 }
 
-func TestListRelationshipsByType(t *testing.T) {
+func TestListRelationshipsByVersionAndType(t *testing.T) {
 	queries := setupTestDB(t)
 	ctx := context.Background()
 
@@ -376,12 +376,12 @@ func TestListRelationshipsByType(t *testing.T) {
 	}
 
 	// List "features" relationships
-	listParams := ListRelationshipsByTypeParams{
+	listParams := ListRelationshipsByVersionAndTypeParams{
 		VersionID:        versionID,
 		RelationshipType: "features",
 	}
 
-	featuresRelationships, err := queries.ListRelationshipsByType(ctx, listParams)
+	featuresRelationships, err := queries.ListRelationshipsByVersionAndType(ctx, listParams)
 	if err != nil {
 		t.Fatalf("Failed to list features relationships: %v", err)
 	}
@@ -398,7 +398,7 @@ func TestListRelationshipsByType(t *testing.T) {
 
 	// List "conflicts" relationships
 	listParams.RelationshipType = "conflicts"
-	conflictsRelationships, err := queries.ListRelationshipsByType(ctx, listParams)
+	conflictsRelationships, err := queries.ListRelationshipsByVersionAndType(ctx, listParams)
 	if err != nil {
 		t.Fatalf("Failed to list conflicts relationships: %v", err)
 	}
@@ -406,6 +406,39 @@ func TestListRelationshipsByType(t *testing.T) {
 	if len(conflictsRelationships) != 1 {
 		t.Errorf("Expected 1 conflicts relationship, got %d", len(conflictsRelationships))
 	}
+
+	// Relationships of the same type in another version are left out
+	otherVersionID := uuid.New().String()
+	if _, err := queries.CreateGraphVersion(ctx, CreateGraphVersionParams{ID: otherVersionID, ProjectID: projectID}); err != nil {
+		t.Fatalf("Failed to create graph version: %v", err)
+	}
+	if _, err := queries.CreateRelationship(ctx, CreateRelationshipParams{
+		ID:               uuid.New().String(),
+		VersionID:        otherVersionID,
+		FromEntityID:     sceneID,
+		ToEntityID:       character1ID,
+		RelationshipType: "features",
+		Properties:       json.RawMessage(`{}`),
+	}); err != nil {
+		t.Fatalf("Failed to create relationship: %v", err)
+	}
+	listParams.RelationshipType = "features"
+	featuresRelationships, err = queries.ListRelationshipsByVersionAndType(ctx, listParams)
+	if err != nil {
+		t.Fatalf("Failed to list features relationships: %v", err)
+	}
+	if len(featuresRelationships) != 2 {
+		t.Errorf("Expected 2 features relationships in the first version, got %d", len(featuresRelationships))
+	}
+
+	// Distinct types, sorted
+	relationshipTypes, err := queries.ListRelationshipTypesByVersion(ctx, versionID)
+	if err != nil {
+		t.Fatalf("Failed to list relationship types: %v", err)
+	}
+	if len(relationshipTypes) != 2 || relationshipTypes[0] != "conflicts" || relationshipTypes[1] != "features" {
+		t.Errorf("Expected [conflicts features], got %v", relationshipTypes)
+	}
 }
 
 func TestGetRelationshipsBetweenEntities(t *testing.T) {
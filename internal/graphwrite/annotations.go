@@ -0,0 +1,184 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/types"
+	"github.com/google/uuid"
+)
+
+// Annotation represents agent-generated metadata attached to an entity.
+//
+// Annotations are stored against the database ID of the entity row they were
+// created on. Apply copies them onto the new rows along with their entities, so a
+// version holds every annotation made on its entities or their predecessors;
+// deleting an entity drops its annotations in that version only. Service methods
+// report the entity's logical ID in EntityID.
+type Annotation struct {
+	ID             string
+	EntityID       string
+	AnnotationType string
+	Content        string
+	Metadata       map[string]any
+	AgentName      string
+	CreatedAt      string
+}
+
+// toAnnotation converts a database annotation to its service representation.
+// entityID is the logical ID of the annotated entity.
+func toAnnotation(annotation db.Annotation, entityID string) (*Annotation, error) {
+	var metadata map[string]any
+	if len(annotation.Metadata) > 0 {
+		if err := json.Unmarshal(annotation.Metadata, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal annotation metadata: %w", err)
+		}
+	}
+
+	return &Annotation{
+		ID:             annotation.ID,
+		EntityID:       entityID,
+		AnnotationType: annotation.AnnotationType,
+		Content:        annotation.Content,
+		Metadata:       metadata,
+		AgentName:      annotation.AgentName.String,
+		CreatedAt:      annotation.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}, nil
+}
+
+// CreateAnnotation attaches an annotation to a logical entity in a version. When
+// ServiceOptions.ValidateAnnotationMetadata is set, the metadata must match the
+// typed schema for the annotation type or the annotation is rejected.
+func (s *Service) CreateAnnotation(ctx context.Context, versionID string, annotation *Annotation) (*Annotation, error) {
+	entity, err := s.findEntityInVersion(ctx, versionID, annotation.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := json.RawMessage("{}")
+	if annotation.Metadata != nil {
+		metadata, err = json.Marshal(annotation.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal annotation metadata: %w", err)
+		}
+	}
+
+	if s.options.ValidateAnnotationMetadata {
+		if err := types.ValidateAnnotationMetadata(types.AnnotationType(annotation.AnnotationType), metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	// Annotations belong to one version, so a row shared with other versions is copied first
+	entityID := entity.ID
+	if s.options.CopyOnWrite {
+		entityID, err = s.materializeEntity(ctx, versionID, annotation.EntityID, entity.ID, map[string]string{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := s.db.Queries().CreateAnnotation(ctx, db.CreateAnnotationParams{
+		ID:             uuid.New().String(),
+		EntityID:       entityID,
+		AnnotationType: annotation.AnnotationType,
+		Content:        annotation.Content,
+		Metadata:       metadata,
+		AgentName:      sql.NullString{String: annotation.AgentName, Valid: annotation.AgentName != ""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create annotation: %w", err)
+	}
+
+	return toAnnotation(created, annotation.EntityID)
+}
+
+// AnnotationsForVersion returns every annotation on the entities of a version,
+// newest first, with EntityID resolved to the entity's logical ID
+func (s *Service) AnnotationsForVersion(ctx context.Context, versionID string) ([]Annotation, error) {
+	logicalIDs, err := s.logicalIDsByDatabaseID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations, err := s.db.Queries().ListAnnotationsByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list annotations: %w", err)
+	}
+
+	result := make([]Annotation, 0, len(annotations))
+	for _, annotation := range annotations {
+		converted, err := toAnnotation(annotation, logicalIDs[annotation.EntityID])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *converted)
+	}
+
+	return result, nil
+}
+
+// copyAnnotations copies the annotations of every entity in the source version onto
+// the entities' new database IDs, given the logical-to-database ID mapping of the copy
+func (s *Service) copyAnnotations(ctx context.Context, sourceVersionID string, entityIDMapping map[string]string) error {
+	logicalIDs, err := s.logicalIDsByDatabaseID(ctx, sourceVersionID)
+	if err != nil {
+		return err
+	}
+
+	for sourceDatabaseID, logicalID := range logicalIDs {
+		targetDatabaseID, exists := entityIDMapping[logicalID]
+		if !exists {
+			continue
+		}
+
+		annotations, err := s.db.Queries().ListAnnotationsByEntity(ctx, sourceDatabaseID)
+		if err != nil {
+			return fmt.Errorf("failed to list annotations: %w", err)
+		}
+
+		for _, annotation := range annotations {
+			if _, err := s.db.Queries().CreateAnnotation(ctx, db.CreateAnnotationParams{
+				ID:             uuid.New().String(),
+				EntityID:       targetDatabaseID,
+				AnnotationType: annotation.AnnotationType,
+				Content:        annotation.Content,
+				Metadata:       annotation.Metadata,
+				AgentName:      annotation.AgentName,
+			}); err != nil {
+				return fmt.Errorf("failed to copy annotation %s: %w", annotation.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// latestAnnotationsByType returns the most recent annotation of each type for an entity
+func (s *Service) latestAnnotationsByType(ctx context.Context, databaseID string, entityID string) ([]*Annotation, error) {
+	// Annotations are returned newest first
+	annotations, err := s.db.Queries().ListAnnotationsByEntity(ctx, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list annotations: %w", err)
+	}
+
+	seenTypes := make(map[string]bool)
+	result := []*Annotation{}
+	for _, annotation := range annotations {
+		if seenTypes[annotation.AnnotationType] {
+			continue
+		}
+		seenTypes[annotation.AnnotationType] = true
+
+		converted, err := toAnnotation(annotation, entityID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, converted)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,311 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/google/uuid"
+)
+
+// databaseIDForEntity resolves the database ID of a logical entity in a version
+func databaseIDForEntity(t *testing.T, database *db.Database, versionID, logicalID string) string {
+	service := &Service{db: NewSQLiteStore(database)}
+	entity, err := service.findEntityInVersion(context.Background(), versionID, logicalID)
+	if err != nil {
+		t.Fatalf("Failed to find entity %s: %v", logicalID, err)
+	}
+	return entity.ID
+}
+
+// createTestAnnotation attaches an annotation to an entity by database ID
+func createTestAnnotation(t *testing.T, database *db.Database, entityDatabaseID, annotationType string, metadata map[string]any) string {
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatalf("Failed to marshal annotation metadata: %v", err)
+	}
+
+	annotationID := uuid.New().String()
+	_, err = database.Queries().CreateAnnotation(context.Background(), db.CreateAnnotationParams{
+		ID:             annotationID,
+		EntityID:       entityDatabaseID,
+		AnnotationType: annotationType,
+		Content:        annotationType + " content",
+		Metadata:       metadataBytes,
+		AgentName:      sql.NullString{String: "test_agent", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create annotation: %v", err)
+	}
+	return annotationID
+}
+
+func TestService_ListEntities_IncludeAnnotations(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	parentVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: parentVersionID,
+		Deltas: []*Delta{
+			{
+				Operation:  "create",
+				EntityType: "Scene",
+				EntityID:   "scene-1",
+				Fields:     map[string]any{"name": "Opening", "title": "Opening"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	sceneDatabaseID := databaseIDForEntity(t, database, response.GraphVersionID, "scene-1")
+	oldID := createTestAnnotation(t, database, sceneDatabaseID, "emotional_analysis", map[string]any{"sentiment": -0.5})
+	newID := createTestAnnotation(t, database, sceneDatabaseID, "emotional_analysis", map[string]any{"sentiment": 0.5})
+	thematicID := createTestAnnotation(t, database, sceneDatabaseID, "thematic_score", map[string]any{"relevance_score": 0.8})
+
+	// Make the ordering of the two emotional analyses unambiguous
+	if _, err := database.DB().ExecContext(ctx, "UPDATE annotations SET created_at = '2020-01-01 00:00:00' WHERE id = ?", oldID); err != nil {
+		t.Fatalf("Failed to backdate annotation: %v", err)
+	}
+
+	// Omitted by default
+	entities, err := service.ListEntities(ctx, response.GraphVersionID, EntityFilter{})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	if entities[0].Annotations != nil {
+		t.Errorf("Expected no annotations by default, got %d", len(entities[0].Annotations))
+	}
+
+	// Attached when requested
+	entities, err = service.ListEntities(ctx, response.GraphVersionID, EntityFilter{IncludeAnnotations: true})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	annotations := entities[0].Annotations
+	if len(annotations) != 2 {
+		t.Fatalf("Expected latest annotation of each of 2 types, got %d", len(annotations))
+	}
+
+	byType := make(map[string]*Annotation)
+	for _, annotation := range annotations {
+		byType[annotation.AnnotationType] = annotation
+		if annotation.EntityID != "scene-1" {
+			t.Errorf("Expected annotation entity ID scene-1, got %s", annotation.EntityID)
+		}
+	}
+	if byType["emotional_analysis"] == nil || byType["emotional_analysis"].ID != newID {
+		t.Errorf("Expected latest emotional analysis %s, got %+v", newID, byType["emotional_analysis"])
+	}
+	if byType["emotional_analysis"].Metadata["sentiment"] != 0.5 {
+		t.Errorf("Expected sentiment 0.5, got %v", byType["emotional_analysis"].Metadata["sentiment"])
+	}
+	if byType["thematic_score"] == nil || byType["thematic_score"].ID != thematicID {
+		t.Errorf("Expected thematic score %s, got %+v", thematicID, byType["thematic_score"])
+	}
+}
+
+func TestService_ListEntities_LatestAnnotationWithinTheSameSecond(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	parentVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: parentVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": "Opening", "title": "Opening"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	// Written back to back, both annotations usually share a created_at second
+	sceneDatabaseID := databaseIDForEntity(t, database, response.GraphVersionID, "scene-1")
+	createTestAnnotation(t, database, sceneDatabaseID, "emotional_analysis", map[string]any{"sentiment": -0.5})
+	newID := createTestAnnotation(t, database, sceneDatabaseID, "emotional_analysis", map[string]any{"sentiment": 0.5})
+
+	entities, err := service.ListEntities(ctx, response.GraphVersionID, EntityFilter{IncludeAnnotations: true})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	if annotations := entities[0].Annotations; len(annotations) != 1 || annotations[0].ID != newID {
+		t.Errorf("Expected the later annotation %s, got %+v", newID, annotations)
+	}
+}
+
+func TestService_CreateAnnotation_MetadataValidation(t *testing.T) {
+	ctx := context.Background()
+
+	thematicShape := map[string]any{
+		"relevance_score": 0.9,
+		"theme_alignment": map[string]any{"redemption": 0.7},
+	}
+
+	setup := func(t *testing.T, options ServiceOptions) (GraphWriteService, string) {
+		database := setupTestDB(t)
+		t.Cleanup(func() { database.Close() })
+
+		service := NewServiceWithOptions(database, options)
+		projectID := createTestProject(t, database)
+		rootVersionID := createTestGraphVersion(t, database, projectID, true)
+		return service, createFeaturedSceneVersion(t, service, rootVersionID)
+	}
+
+	t.Run("mismatched shape rejected when enabled", func(t *testing.T) {
+		service, versionID := setup(t, ServiceOptions{ValidateAnnotationMetadata: true})
+
+		_, err := service.CreateAnnotation(ctx, versionID, &Annotation{
+			EntityID:       "scene-1",
+			AnnotationType: "emotional_analysis",
+			Content:        "Tense opening",
+			Metadata:       thematicShape,
+		})
+		if err == nil {
+			t.Fatal("Expected thematic metadata on an emotional_analysis annotation to be rejected")
+		}
+
+		annotation, err := service.CreateAnnotation(ctx, versionID, &Annotation{
+			EntityID:       "scene-1",
+			AnnotationType: "emotional_analysis",
+			Content:        "Tense opening",
+			Metadata:       map[string]any{"sentiment": -0.3, "emotions": map[string]any{"dread": 0.8}},
+			AgentName:      "empath",
+		})
+		if err != nil {
+			t.Fatalf("Expected matching metadata to be accepted, got %v", err)
+		}
+		if annotation.EntityID != "scene-1" || annotation.AgentName != "empath" {
+			t.Errorf("Unexpected annotation: %+v", annotation)
+		}
+	})
+
+	t.Run("any shape accepted when disabled", func(t *testing.T) {
+		service, versionID := setup(t, ServiceOptions{})
+
+		if _, err := service.CreateAnnotation(ctx, versionID, &Annotation{
+			EntityID:       "scene-1",
+			AnnotationType: "emotional_analysis",
+			Content:        "Tense opening",
+			Metadata:       thematicShape,
+		}); err != nil {
+			t.Errorf("Expected metadata to be stored unvalidated, got %v", err)
+		}
+	})
+}
+
+func TestService_AnnotationsForVersion(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+	for _, annotation := range []*Annotation{
+		{EntityID: "scene-1", AnnotationType: "pacing_analysis", Content: "Slow burn"},
+		{EntityID: "elena", AnnotationType: "character_voice", Content: "Wry and guarded"},
+	} {
+		if _, err := service.CreateAnnotation(ctx, versionID, annotation); err != nil {
+			t.Fatalf("CreateAnnotation failed: %v", err)
+		}
+	}
+
+	annotations, err := service.AnnotationsForVersion(ctx, versionID)
+	if err != nil {
+		t.Fatalf("AnnotationsForVersion failed: %v", err)
+	}
+	if len(annotations) != 2 {
+		t.Fatalf("Expected 2 annotations, got %d", len(annotations))
+	}
+
+	byEntity := make(map[string]string)
+	for _, annotation := range annotations {
+		byEntity[annotation.EntityID] = annotation.AnnotationType
+	}
+	if byEntity["scene-1"] != "pacing_analysis" || byEntity["elena"] != "character_voice" {
+		t.Errorf("Expected annotations keyed by logical ID, got %v", byEntity)
+	}
+
+	// Other versions hold their own annotations
+	annotations, err = service.AnnotationsForVersion(ctx, rootVersionID)
+	if err != nil {
+		t.Fatalf("AnnotationsForVersion failed: %v", err)
+	}
+	if len(annotations) != 0 {
+		t.Errorf("Expected no annotations on the root version, got %d", len(annotations))
+	}
+}
+
+func TestService_Apply_AnnotationsFollowEntities(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	annotatedVersionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+	if _, err := service.CreateAnnotation(ctx, annotatedVersionID, &Annotation{
+		EntityID:       "scene-1",
+		AnnotationType: "emotional_analysis",
+		Content:        "Dread builds steadily",
+		Metadata:       map[string]any{"sentiment": -0.4},
+		AgentName:      "empath",
+	}); err != nil {
+		t.Fatalf("CreateAnnotation failed: %v", err)
+	}
+
+	// An unrelated change creates a new version with new entity IDs
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: annotatedVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	entities, err := service.ListEntities(ctx, response.GraphVersionID, EntityFilter{IncludeAnnotations: true})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+
+	for _, entity := range entities {
+		if entity.ID != "scene-1" {
+			continue
+		}
+		if len(entity.Annotations) != 1 {
+			t.Fatalf("Expected the scene to keep its annotation, got %d", len(entity.Annotations))
+		}
+		annotation := entity.Annotations[0]
+		if annotation.AnnotationType != "emotional_analysis" || annotation.Content != "Dread builds steadily" || annotation.AgentName != "empath" {
+			t.Errorf("Unexpected annotation after copy: %+v", annotation)
+		}
+		if annotation.Metadata["sentiment"] != -0.4 {
+			t.Errorf("Expected metadata to be copied, got %v", annotation.Metadata)
+		}
+		return
+	}
+	t.Fatal("Scene missing from new version")
+}
@@ -0,0 +1,32 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// EntitiesAsOf returns the entities of the latest version in the project created at or
+// before t. Versions sharing a timestamp are ordered by ancestry, so a child wins over
+// its parent.
+func (s *Service) EntitiesAsOf(ctx context.Context, projectID string, t time.Time) ([]*Entity, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	versions, err := s.db.Queries().ListGraphVersionsByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	var versionID string
+	for _, version := range versionsInHistoryOrder(versions) {
+		if !version.CreatedAt.After(t) {
+			versionID = version.ID
+		}
+	}
+	if versionID == "" {
+		return nil, fmt.Errorf("%w: project %s at %s", ErrNoVersionAsOf, projectID, t.Format(time.RFC3339))
+	}
+
+	return s.ListEntities(ctx, versionID, EntityFilter{})
+}
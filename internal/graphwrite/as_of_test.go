@@ -0,0 +1,86 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestService_EntitiesAsOf(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	monday, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	wednesday, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: monday.GraphVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	timestamps := map[string]string{
+		rootVersionID:            "2024-03-01 09:00:00",
+		monday.GraphVersionID:    "2024-03-04 09:00:00",
+		wednesday.GraphVersionID: "2024-03-06 09:00:00",
+	}
+	for versionID, createdAt := range timestamps {
+		if _, err := database.DB().ExecContext(ctx, "UPDATE graph_versions SET created_at = ? WHERE id = ?", createdAt, versionID); err != nil {
+			t.Fatalf("Failed to set created_at: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected []string
+	}{
+		{"before any edits", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), nil},
+		{"exactly at a version", time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC), []string{"elena"}},
+		{"between versions", time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC), []string{"elena"}},
+		{"after the latest version", time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), []string{"elena", "marcus"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entities, err := service.EntitiesAsOf(ctx, projectID, tt.at)
+			if err != nil {
+				t.Fatalf("EntitiesAsOf failed: %v", err)
+			}
+			if len(entities) != len(tt.expected) {
+				t.Fatalf("Expected %d entities, got %d", len(tt.expected), len(entities))
+			}
+			found := make(map[string]bool)
+			for _, entity := range entities {
+				found[entity.ID] = true
+			}
+			for _, id := range tt.expected {
+				if !found[id] {
+					t.Errorf("Expected %s in the version as of %s", id, tt.at)
+				}
+			}
+		})
+	}
+
+	if _, err := service.EntitiesAsOf(ctx, projectID, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrNoVersionAsOf) {
+		t.Errorf("Expected ErrNoVersionAsOf before the project existed, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// AutoSnapshotTagPrefix prefixes the tags of checkpoints created by auto-snapshotting
+const AutoSnapshotTagPrefix = "auto-"
+
+// AutoSnapshotPolicy configures automatic checkpoints of the working set. A zero
+// field disables its trigger; a checkpoint is taken when either trigger is reached.
+type AutoSnapshotPolicy struct {
+	// EveryEdits takes a checkpoint once this many versions have been applied to the
+	// working set since the last checkpoint
+	EveryEdits int
+
+	// Interval takes a checkpoint on the first edit at least this long after the
+	// last checkpoint, or after the oldest version if there is none yet
+	Interval time.Duration
+}
+
+func (p AutoSnapshotPolicy) enabled() bool {
+	return p.EveryEdits > 0 || p.Interval > 0
+}
+
+// autoSnapshot tags a newly advanced working set version as a checkpoint when the
+// policy's edit count or interval since the last checkpoint has been reached.
+// Checkpoints are tagged auto-1, auto-2, ... in order.
+func (s *Service) autoSnapshot(ctx context.Context, version db.GraphVersion) error {
+	policy := s.options.AutoSnapshot
+	if !policy.enabled() {
+		return nil
+	}
+
+	tags, err := s.db.Queries().ListVersionTags(ctx, version.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to list version tags: %w", err)
+	}
+
+	var last *db.VersionTag
+	checkpoints := 0
+	for i, tag := range tags {
+		if strings.HasPrefix(tag.Tag, AutoSnapshotTagPrefix) {
+			last = &tags[i]
+			checkpoints++
+		}
+	}
+
+	// Count the edits back to the last checkpoint, or to the root
+	edits := 0
+	since := version.CreatedAt
+	err = s.walkVersionChain(ctx, version.ID, func(current db.GraphVersion) (bool, error) {
+		if last != nil && current.ID == last.VersionID {
+			return false, nil
+		}
+		since = current.CreatedAt
+		if !current.ParentVersionID.Valid {
+			return false, nil
+		}
+		edits++
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		since = last.CreatedAt
+	}
+
+	due := (policy.EveryEdits > 0 && edits >= policy.EveryEdits) ||
+		(policy.Interval > 0 && time.Since(since) >= policy.Interval)
+	if !due {
+		return nil
+	}
+
+	tag := fmt.Sprintf("%s%d", AutoSnapshotTagPrefix, checkpoints+1)
+	if err := s.TagVersion(ctx, version.ID, tag); err != nil {
+		return fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	return nil
+}
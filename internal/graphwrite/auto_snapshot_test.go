@@ -0,0 +1,121 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestService_AutoSnapshot_EveryEdits(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewServiceWithOptions(database, ServiceOptions{AutoSnapshot: AutoSnapshotPolicy{EveryEdits: 3}})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	versionID := createTestGraphVersion(t, database, projectID, true)
+
+	var versions []string
+	for i := 1; i <= 7; i++ {
+		response, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+			ParentVersionID: versionID,
+			Deltas: []*Delta{
+				{Operation: "create", EntityType: "Character", EntityID: fmt.Sprintf("character-%d", i), Fields: map[string]any{"name": "Character"}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+		versionID = response.GraphVersionID
+		versions = append(versions, versionID)
+	}
+
+	tags, err := database.Queries().ListVersionTags(ctx, projectID)
+	if err != nil {
+		t.Fatalf("ListVersionTags failed: %v", err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("Expected checkpoints after the 3rd and 6th edits, got %+v", tags)
+	}
+	if tags[0].Tag != "auto-1" || tags[0].VersionID != versions[2] {
+		t.Errorf("Expected auto-1 on the 3rd edit, got %+v", tags[0])
+	}
+	if tags[1].Tag != "auto-2" || tags[1].VersionID != versions[5] {
+		t.Errorf("Expected auto-2 on the 6th edit, got %+v", tags[1])
+	}
+}
+
+func TestService_AutoSnapshot_Interval(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewServiceWithOptions(database, ServiceOptions{AutoSnapshot: AutoSnapshotPolicy{Interval: time.Hour}})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	edit := func(parentVersionID, entityID string) string {
+		response, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+			ParentVersionID: parentVersionID,
+			Deltas:          []*Delta{{Operation: "create", EntityType: "Character", EntityID: entityID, Fields: map[string]any{"name": "Character"}}},
+		})
+		if err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+		return response.GraphVersionID
+	}
+
+	versionID := edit(rootVersionID, "elena")
+	tags, _ := database.Queries().ListVersionTags(ctx, projectID)
+	if len(tags) != 0 {
+		t.Fatalf("Expected no checkpoint within the interval, got %+v", tags)
+	}
+
+	// Age the project's history past the interval
+	if _, err := database.DB().ExecContext(ctx, "UPDATE graph_versions SET created_at = ? WHERE id = ?", time.Now().Add(-2*time.Hour).UTC(), rootVersionID); err != nil {
+		t.Fatalf("Failed to backdate version: %v", err)
+	}
+
+	versionID = edit(versionID, "marcus")
+	tags, _ = database.Queries().ListVersionTags(ctx, projectID)
+	if len(tags) != 1 || tags[0].VersionID != versionID {
+		t.Errorf("Expected a checkpoint once the interval passed, got %+v", tags)
+	}
+}
+
+func TestService_AutoSnapshot_FailureDoesNotFailApply(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewServiceWithOptions(database, ServiceOptions{AutoSnapshot: AutoSnapshotPolicy{EveryEdits: 1}})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	// A hand-made tag takes the name the next checkpoint would use
+	if err := service.TagVersion(ctx, rootVersionID, "auto-2"); err != nil {
+		t.Fatalf("TagVersion failed: %v", err)
+	}
+
+	response, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected the apply to succeed despite the failed checkpoint, got %v", err)
+	}
+
+	workingSet, err := database.Queries().GetWorkingSetVersion(ctx, projectID)
+	if err != nil {
+		t.Fatalf("GetWorkingSetVersion failed: %v", err)
+	}
+	if workingSet.ID != response.GraphVersionID {
+		t.Errorf("Expected the working set to advance to %s, got %s", response.GraphVersionID, workingSet.ID)
+	}
+}
@@ -0,0 +1,56 @@
+package graphwrite
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// contentBlobField is the entity data key holding the blob hash of externalized scene content
+const contentBlobField = "content_blob"
+
+// externalizeContent moves a scene's content into the blobs table when
+// ServiceOptions.ExternalizeSceneContent is set, leaving its hash in the entity data.
+// Identical content is stored once however many versions or scenes reference it.
+func (s *Service) externalizeContent(ctx context.Context, entityType string, fields map[string]any) error {
+	if !s.options.ExternalizeSceneContent || entityType != string(types.EntityTypeScene) {
+		return nil
+	}
+	content, ok := fields["content"].(string)
+	if !ok {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+	if err := s.db.Queries().CreateBlob(ctx, db.CreateBlobParams{Hash: hash, Content: content}); err != nil {
+		return fmt.Errorf("failed to store content blob: %w", err)
+	}
+
+	delete(fields, "content")
+	fields[contentBlobField] = hash
+	return nil
+}
+
+// rehydrateContent replaces a blob reference in entity data with the content it points to.
+// It runs regardless of ServiceOptions.ExternalizeSceneContent so that data written with
+// the option on stays readable after it is turned off.
+func (s *Service) rehydrateContent(ctx context.Context, data map[string]any) error {
+	hash, ok := data[contentBlobField].(string)
+	if !ok {
+		return nil
+	}
+
+	blob, err := s.db.Queries().GetBlob(ctx, hash)
+	if err != nil {
+		return fmt.Errorf("failed to load content blob %s: %w", hash, err)
+	}
+
+	delete(data, contentBlobField)
+	data["content"] = blob.Content
+	return nil
+}
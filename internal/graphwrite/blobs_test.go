@@ -0,0 +1,173 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// sceneContentBlob returns the blob hash stored in a scene's raw entity data
+func sceneContentBlob(t *testing.T, service *Service, versionID string, logicalID string) string {
+	entities, err := service.db.Queries().ListEntitiesByVersion(context.Background(), versionID)
+	if err != nil {
+		t.Fatalf("ListEntitiesByVersion failed: %v", err)
+	}
+	for _, entity := range entities {
+		var data map[string]any
+		if err := json.Unmarshal(entity.Data, &data); err != nil {
+			t.Fatalf("Failed to unmarshal entity data: %v", err)
+		}
+		if data["logical_id"] != logicalID {
+			continue
+		}
+		if _, inline := data["content"]; inline {
+			t.Errorf("Expected content to be externalized, found it inline in %s", versionID)
+		}
+		hash, _ := data[contentBlobField].(string)
+		return hash
+	}
+	t.Fatalf("Entity %s not found in version %s", logicalID, versionID)
+	return ""
+}
+
+func TestService_ExternalizeSceneContent(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewServiceWithOptions(database, ServiceOptions{ExternalizeSceneContent: true})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	created, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": "Harbor", "content": "The storm broke over the harbor."}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	// A version that leaves the scene untouched copies only the reference
+	unchanged, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: created.GraphVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	impl := service.(*Service)
+	firstHash := sceneContentBlob(t, impl, created.GraphVersionID, "scene-1")
+	if firstHash == "" {
+		t.Fatal("Expected the scene to reference a content blob")
+	}
+	if hash := sceneContentBlob(t, impl, unchanged.GraphVersionID, "scene-1"); hash != firstHash {
+		t.Errorf("Expected both versions to reference blob %s, got %s", firstHash, hash)
+	}
+
+	edited, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: unchanged.GraphVersionID,
+		Deltas: []*Delta{
+			{Operation: "update", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": "Harbor", "content": "The storm passed; the harbor was quiet."}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if hash := sceneContentBlob(t, impl, edited.GraphVersionID, "scene-1"); hash == firstHash || hash == "" {
+		t.Errorf("Expected editing the content to reference a new blob, got %q", hash)
+	}
+
+	count, err := database.Queries().CountBlobs(ctx)
+	if err != nil {
+		t.Fatalf("CountBlobs failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 blobs, got %d", count)
+	}
+
+	// ListEntities rehydrates the content and hides the reference
+	scenes, err := service.ListEntities(ctx, edited.GraphVersionID, EntityFilter{})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	for _, entity := range scenes {
+		if entity.ID != "scene-1" {
+			continue
+		}
+		if entity.Data["content"] != "The storm passed; the harbor was quiet." {
+			t.Errorf("Expected rehydrated content, got %v", entity.Data["content"])
+		}
+		if _, exists := entity.Data[contentBlobField]; exists {
+			t.Errorf("Expected the blob reference to be hidden, got %v", entity.Data)
+		}
+	}
+}
+
+func TestService_SceneContentInlineByDefault(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	if _, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": "Harbor", "content": "The storm broke over the harbor."}},
+		},
+	}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	count, err := database.Queries().CountBlobs(ctx)
+	if err != nil {
+		t.Fatalf("CountBlobs failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no blobs without ExternalizeSceneContent, got %d", count)
+	}
+}
+
+func TestService_ExternalizeSceneContent_UpdateWithoutEntityType(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewServiceWithOptions(database, ServiceOptions{ExternalizeSceneContent: true})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	created, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": "Harbor", "content": "The storm broke over the harbor."}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	// The stored entity type decides, not the delta's
+	edited, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: created.GraphVersionID,
+		Deltas: []*Delta{
+			{Operation: "update", EntityID: "scene-1", Fields: map[string]any{"content": "The storm passed; the harbor was quiet."}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if hash := sceneContentBlob(t, service.(*Service), edited.GraphVersionID, "scene-1"); hash == "" {
+		t.Error("Expected the updated content to be stored in content_blob")
+	}
+}
@@ -0,0 +1,256 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// GetCharacterProfile retrieves a character and unmarshals its data into the typed
+// CharacterData structure, including nested voice characteristics and arc.
+// Returns an error if the entity is not a Character.
+func (s *Service) GetCharacterProfile(ctx context.Context, versionID string, logicalID string) (*types.CharacterData, error) {
+	entity, err := s.findEntityInVersion(ctx, versionID, logicalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if entity.EntityType != string(types.EntityTypeCharacter) {
+		return nil, fmt.Errorf("entity %s is a %s, not a %s", logicalID, entity.EntityType, types.EntityTypeCharacter)
+	}
+
+	profile, err := types.UnmarshalCharacterData(entity.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal character data: %w", err)
+	}
+
+	return profile, nil
+}
+
+// FirstAppearance returns the earliest scene in narrative order (act, then sequence)
+// that features a character, or nil when no scene features it. Returns an error if
+// the entity is not a Character.
+func (s *Service) FirstAppearance(ctx context.Context, versionID, characterLogicalID string) (*Entity, error) {
+	character, err := s.findEntityInVersion(ctx, versionID, characterLogicalID)
+	if err != nil {
+		return nil, err
+	}
+	if character.EntityType != string(types.EntityTypeCharacter) {
+		return nil, fmt.Errorf("entity %s is a %s, not a %s", characterLogicalID, character.EntityType, types.EntityTypeCharacter)
+	}
+
+	relations, err := s.characterRelations(ctx, versionID, characterLogicalID)
+	if err != nil {
+		return nil, err
+	}
+	featuredIn := make(map[string]bool)
+	for _, relation := range relations {
+		if relation.RelationshipType == string(types.RelationshipFeatures) && !relation.Outgoing {
+			featuredIn[relation.EntityID] = true
+		}
+	}
+	if len(featuredIn) == 0 {
+		return nil, nil
+	}
+
+	sceneType := string(types.EntityTypeScene)
+	scenes, err := s.ListEntities(ctx, versionID, EntityFilter{EntityType: &sceneType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scenes: %w", err)
+	}
+
+	var first *Entity
+	for _, scene := range scenes {
+		if !featuredIn[scene.ID] {
+			continue
+		}
+		if first == nil || sceneBefore(scene, first) {
+			first = scene
+		}
+	}
+
+	return first, nil
+}
+
+// sceneBefore reports whether scene a comes before scene b in narrative order, with
+// logical IDs breaking ties as in EmotionalArc
+func sceneBefore(a, b *Entity) bool {
+	actA, actB := arcAct(a.Data["act"]), arcAct(b.Data["act"])
+	if actA != actB {
+		return actLess(actA, actB)
+	}
+	sequenceA, sequenceB := arcSequence(a.Data["sequence"]), arcSequence(b.Data["sequence"])
+	if sequenceA != sequenceB {
+		return sequenceA < sequenceB
+	}
+	return a.ID < b.ID
+}
+
+// CharacterComparison describes how two characters overlap and differ
+type CharacterComparison struct {
+	CharacterA *types.CharacterData
+	CharacterB *types.CharacterData
+
+	SharedTraits    []string
+	DistinctTraitsA []string
+	DistinctTraitsB []string
+
+	SharedSpeechPatterns []string
+
+	// Differences lists voice and arc fields whose values differ, e.g. "voice.tone"
+	Differences []FieldDifference
+
+	SharedRelationships    []CharacterRelation
+	DistinctRelationshipsA []CharacterRelation
+	DistinctRelationshipsB []CharacterRelation
+}
+
+// FieldDifference records a typed field that differs between two characters
+type FieldDifference struct {
+	Field string
+	A     string
+	B     string
+}
+
+// CharacterRelation is a relationship seen from a character's side
+type CharacterRelation struct {
+	RelationshipType string
+	EntityID         string // Logical ID of the other endpoint
+	Outgoing         bool   // True when the character is the source
+}
+
+// CompareCharacters diffs two characters' typed data (traits, voice, arc) and
+// their relationships in a version. A relationship is shared when both characters
+// have the same type and direction of relationship to the same entity.
+func (s *Service) CompareCharacters(ctx context.Context, versionID, idA, idB string) (*CharacterComparison, error) {
+	profileA, err := s.GetCharacterProfile(ctx, versionID, idA)
+	if err != nil {
+		return nil, err
+	}
+	profileB, err := s.GetCharacterProfile(ctx, versionID, idB)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &CharacterComparison{
+		CharacterA: profileA,
+		CharacterB: profileB,
+	}
+	comparison.SharedTraits, comparison.DistinctTraitsA, comparison.DistinctTraitsB = compareStrings(profileA.PersonalityTraits, profileB.PersonalityTraits)
+	comparison.SharedSpeechPatterns, _, _ = compareStrings(profileA.VoiceCharacteristics.SpeechPatterns, profileB.VoiceCharacteristics.SpeechPatterns)
+
+	fields := []FieldDifference{
+		{"role", profileA.Role, profileB.Role},
+		{"voice.tone", profileA.VoiceCharacteristics.Tone, profileB.VoiceCharacteristics.Tone},
+		{"voice.vocabulary", profileA.VoiceCharacteristics.Vocabulary, profileB.VoiceCharacteristics.Vocabulary},
+		{"arc.starting_state", profileA.CharacterArc.StartingState, profileB.CharacterArc.StartingState},
+		{"arc.current_state", profileA.CharacterArc.CurrentState, profileB.CharacterArc.CurrentState},
+		{"arc.target_state", profileA.CharacterArc.TargetState, profileB.CharacterArc.TargetState},
+	}
+	for _, field := range fields {
+		if field.A != field.B {
+			comparison.Differences = append(comparison.Differences, field)
+		}
+	}
+
+	relationsA, err := s.characterRelations(ctx, versionID, idA)
+	if err != nil {
+		return nil, err
+	}
+	relationsB, err := s.characterRelations(ctx, versionID, idB)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, relation := range relationsA {
+		if containsRelation(relationsB, relation) {
+			comparison.SharedRelationships = append(comparison.SharedRelationships, relation)
+		} else {
+			comparison.DistinctRelationshipsA = append(comparison.DistinctRelationshipsA, relation)
+		}
+	}
+	for _, relation := range relationsB {
+		if !containsRelation(relationsA, relation) {
+			comparison.DistinctRelationshipsB = append(comparison.DistinctRelationshipsB, relation)
+		}
+	}
+
+	return comparison, nil
+}
+
+// characterRelations lists a character's relationships in a version from its own side
+func (s *Service) characterRelations(ctx context.Context, versionID, logicalID string) ([]CharacterRelation, error) {
+	entity, err := s.findEntityInVersion(ctx, versionID, logicalID)
+	if err != nil {
+		return nil, err
+	}
+
+	logicalIDs, err := s.logicalIDsByDatabaseID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	relationships, err := s.db.Queries().ListRelationshipsByEntityInVersion(ctx, db.ListRelationshipsByEntityInVersionParams{
+		VersionID:    versionID,
+		FromEntityID: entity.ID,
+		ToEntityID:   entity.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	var relations []CharacterRelation
+	for _, rel := range relationships {
+		outgoing := rel.FromEntityID == entity.ID
+		otherID := rel.ToEntityID
+		if !outgoing {
+			otherID = rel.FromEntityID
+		}
+
+		relations = append(relations, CharacterRelation{
+			RelationshipType: rel.RelationshipType,
+			EntityID:         logicalIDs[otherID],
+			Outgoing:         outgoing,
+		})
+	}
+
+	return relations, nil
+}
+
+// compareStrings splits two string lists into shared values and values unique to each side
+func compareStrings(a, b []string) (shared, onlyA, onlyB []string) {
+	inA := make(map[string]bool, len(a))
+	for _, value := range a {
+		inA[value] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, value := range b {
+		inB[value] = true
+	}
+
+	for _, value := range a {
+		if inB[value] {
+			shared = append(shared, value)
+		} else {
+			onlyA = append(onlyA, value)
+		}
+	}
+	for _, value := range b {
+		if !inA[value] {
+			onlyB = append(onlyB, value)
+		}
+	}
+
+	return shared, onlyA, onlyB
+}
+
+func containsRelation(relations []CharacterRelation, target CharacterRelation) bool {
+	for _, relation := range relations {
+		if relation == target {
+			return true
+		}
+	}
+	return false
+}
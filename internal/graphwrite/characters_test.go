@@ -0,0 +1,246 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+)
+
+func TestService_GetCharacterProfile(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	parentVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: parentVersionID,
+		Deltas: []*Delta{
+			{
+				Operation:  "create",
+				EntityType: "Character",
+				EntityID:   "elena",
+				Fields: map[string]any{
+					"name":               "Elena",
+					"role":               "protagonist",
+					"personality_traits": []string{"curious", "stubborn"},
+					"voice_characteristics": map[string]any{
+						"tone":            "wry",
+						"vocabulary":      "scholarly",
+						"speech_patterns": []string{"asks questions", "quotes texts"},
+					},
+					"character_arc": map[string]any{
+						"starting_state": "naive scholar",
+						"current_state":  "reluctant leader",
+						"target_state":   "legendary hero",
+					},
+				},
+			},
+			{
+				Operation:  "create",
+				EntityType: "Location",
+				EntityID:   "temple",
+				Fields: map[string]any{
+					"name": "Ancient Temple",
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	profile, err := service.GetCharacterProfile(ctx, response.GraphVersionID, "elena")
+	if err != nil {
+		t.Fatalf("GetCharacterProfile failed: %v", err)
+	}
+
+	if profile.Name != "Elena" || profile.Role != "protagonist" {
+		t.Errorf("Expected Elena/protagonist, got %s/%s", profile.Name, profile.Role)
+	}
+	if len(profile.PersonalityTraits) != 2 {
+		t.Errorf("Expected 2 personality traits, got %d", len(profile.PersonalityTraits))
+	}
+
+	voice := profile.VoiceCharacteristics
+	if voice.Tone != "wry" || voice.Vocabulary != "scholarly" {
+		t.Errorf("Expected voice wry/scholarly, got %s/%s", voice.Tone, voice.Vocabulary)
+	}
+	if len(voice.SpeechPatterns) != 2 || voice.SpeechPatterns[1] != "quotes texts" {
+		t.Errorf("Unexpected speech patterns: %v", voice.SpeechPatterns)
+	}
+
+	arc := profile.CharacterArc
+	if arc.StartingState != "naive scholar" || arc.CurrentState != "reluctant leader" || arc.TargetState != "legendary hero" {
+		t.Errorf("Unexpected character arc: %+v", arc)
+	}
+
+	// Non-character entities are rejected
+	if _, err := service.GetCharacterProfile(ctx, response.GraphVersionID, "temple"); err == nil {
+		t.Error("Expected error for non-character entity")
+	}
+
+	// Missing entities are rejected
+	if _, err := service.GetCharacterProfile(ctx, response.GraphVersionID, "nobody"); err == nil {
+		t.Error("Expected error for missing entity")
+	}
+}
+
+func TestService_CompareCharacters(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	parentVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: parentVersionID,
+		Deltas: []*Delta{
+			{
+				Operation:  "create",
+				EntityType: "Scene",
+				EntityID:   "council",
+				Fields:     map[string]any{"name": "The Council", "title": "The Council"},
+			},
+			{
+				Operation:  "create",
+				EntityType: "Location",
+				EntityID:   "citadel",
+				Fields:     map[string]any{"name": "Citadel"},
+			},
+			{
+				Operation:  "create",
+				EntityType: "Character",
+				EntityID:   "elena",
+				Fields: map[string]any{
+					"name":                  "Elena",
+					"personality_traits":    []string{"curious", "stubborn", "loyal"},
+					"voice_characteristics": map[string]any{"tone": "wry"},
+				},
+			},
+			{
+				Operation:  "create",
+				EntityType: "Character",
+				EntityID:   "marcus",
+				Fields: map[string]any{
+					"name":                  "Marcus",
+					"personality_traits":    []string{"loyal", "gruff"},
+					"voice_characteristics": map[string]any{"tone": "blunt"},
+				},
+				Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "council", ToEntityID: "elena", RelationshipType: "features", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "council", ToEntityID: "marcus", RelationshipType: "features", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "elena", ToEntityID: "citadel", RelationshipType: "occurs_at", Properties: map[string]any{}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	comparison, err := service.CompareCharacters(ctx, response.GraphVersionID, "elena", "marcus")
+	if err != nil {
+		t.Fatalf("CompareCharacters failed: %v", err)
+	}
+
+	if len(comparison.SharedTraits) != 1 || comparison.SharedTraits[0] != "loyal" {
+		t.Errorf("Expected shared trait loyal, got %v", comparison.SharedTraits)
+	}
+	if len(comparison.DistinctTraitsA) != 2 || len(comparison.DistinctTraitsB) != 1 || comparison.DistinctTraitsB[0] != "gruff" {
+		t.Errorf("Unexpected distinct traits: %v / %v", comparison.DistinctTraitsA, comparison.DistinctTraitsB)
+	}
+
+	foundTone := false
+	for _, difference := range comparison.Differences {
+		if difference.Field == "voice.tone" && difference.A == "wry" && difference.B == "blunt" {
+			foundTone = true
+		}
+	}
+	if !foundTone {
+		t.Errorf("Expected voice.tone difference, got %+v", comparison.Differences)
+	}
+
+	sharedFeature := CharacterRelation{RelationshipType: "features", EntityID: "council", Outgoing: false}
+	if len(comparison.SharedRelationships) != 1 || comparison.SharedRelationships[0] != sharedFeature {
+		t.Errorf("Expected shared %+v, got %+v", sharedFeature, comparison.SharedRelationships)
+	}
+
+	distinctLocation := CharacterRelation{RelationshipType: "occurs_at", EntityID: "citadel", Outgoing: true}
+	if len(comparison.DistinctRelationshipsA) != 1 || comparison.DistinctRelationshipsA[0] != distinctLocation {
+		t.Errorf("Expected distinct %+v, got %+v", distinctLocation, comparison.DistinctRelationshipsA)
+	}
+	if len(comparison.DistinctRelationshipsB) != 0 {
+		t.Errorf("Expected no distinct relationships for marcus, got %+v", comparison.DistinctRelationshipsB)
+	}
+
+	// Non-characters are rejected
+	if _, err := service.CompareCharacters(ctx, response.GraphVersionID, "elena", "citadel"); err == nil {
+		t.Error("Expected error comparing a character with a location")
+	}
+}
+
+func TestService_FirstAppearance(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	parentVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	scene := func(id string, act, sequence int, featured ...string) *Delta {
+		delta := &Delta{Operation: "create", EntityType: "Scene", EntityID: id, Fields: map[string]any{"name": id, "act": act, "sequence": sequence}}
+		for _, characterID := range featured {
+			delta.Relationships = append(delta.Relationships, &RelationshipDelta{
+				Operation: "create", FromEntityID: id, ToEntityID: characterID, RelationshipType: "features", Properties: map[string]any{},
+			})
+		}
+		return delta
+	}
+
+	// Scenes are created out of narrative order; act 10 must sort after act 2
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: parentVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}},
+			{Operation: "create", EntityType: "Character", EntityID: "aria", Fields: map[string]any{"name": "Aria"}},
+			scene("finale", 10, 1, "elena", "marcus"),
+			scene("duel", 2, 4, "elena", "marcus"),
+			scene("arrival", 2, 1, "marcus"),
+			scene("prologue", 1, 3, "elena"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	for characterID, want := range map[string]string{"elena": "prologue", "marcus": "arrival"} {
+		first, err := service.FirstAppearance(ctx, response.GraphVersionID, characterID)
+		if err != nil {
+			t.Fatalf("FirstAppearance failed: %v", err)
+		}
+		if first == nil || first.ID != want {
+			t.Errorf("Expected %s to first appear in %s, got %+v", characterID, want, first)
+		}
+	}
+
+	first, err := service.FirstAppearance(ctx, response.GraphVersionID, "aria")
+	if err != nil {
+		t.Fatalf("FirstAppearance failed: %v", err)
+	}
+	if first != nil {
+		t.Errorf("Expected no scene for aria, got %+v", first)
+	}
+
+	if _, err := service.FirstAppearance(ctx, response.GraphVersionID, "duel"); err == nil {
+		t.Error("Expected an error for a non-character")
+	}
+}
@@ -0,0 +1,215 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// Completeness check names
+const (
+	CheckActsPresent          = "acts_present"
+	CheckScenesHaveCharacters = "scenes_have_characters"
+	CheckScenesHaveLocation   = "scenes_have_location"
+	CheckMainCharactersAppear = "main_characters_appear"
+	CheckThemesCovered        = "themes_covered"
+)
+
+// CompletenessConfig sets the expectations of the completeness checklist
+type CompletenessConfig struct {
+	// RequiredActs lists the acts that must each hold at least one scene.
+	// Defaults to Act1, Act2 and Act3.
+	RequiredActs []string
+
+	// MainCharacterRoles lists the character roles treated as main characters.
+	// Defaults to protagonist and antagonist.
+	MainCharacterRoles []string
+
+	// MinScenesPerMainCharacter is how many scenes each main character must
+	// appear in. Defaults to 2.
+	MinScenesPerMainCharacter int
+}
+
+// withDefaults fills unset fields with their defaults
+func (c CompletenessConfig) withDefaults() CompletenessConfig {
+	if len(c.RequiredActs) == 0 {
+		c.RequiredActs = []string{"Act1", "Act2", "Act3"}
+	}
+	if len(c.MainCharacterRoles) == 0 {
+		c.MainCharacterRoles = []string{"protagonist", "antagonist"}
+	}
+	if c.MinScenesPerMainCharacter <= 0 {
+		c.MinScenesPerMainCharacter = 2
+	}
+	return c
+}
+
+// Completeness is the result of the completeness checklist for a version
+type Completeness struct {
+	VersionID string
+	Passed    bool
+	Checks    []CompletenessCheck
+}
+
+// CompletenessCheck is the outcome of one checklist item. OffendingIDs holds the
+// logical IDs that failed it, or the missing act names for CheckActsPresent.
+type CompletenessCheck struct {
+	Name         string
+	Passed       bool
+	Message      string
+	OffendingIDs []string
+}
+
+// CompletenessReport runs the editorial checklist over a version: the configured
+// acts each hold a scene, every scene features a character and occurs at a location,
+// every main character appears in enough scenes, and every theme is touched by a
+// scene. A scene's characters, location and themes are read from both its data
+// fields and its relationships.
+func (s *Service) CompletenessReport(ctx context.Context, versionID string) (*Completeness, error) {
+	config := s.options.Completeness.withDefaults()
+
+	entities, err := s.ListEntities(ctx, versionID, EntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	logicalIDs, err := s.logicalIDsByDatabaseID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := s.db.Queries().ListRelationshipsByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	// Links between entities in either direction, from relationships and data references
+	links := make(map[string]map[string]bool)
+	link := func(a, b string) {
+		for _, pair := range [][2]string{{a, b}, {b, a}} {
+			if links[pair[0]] == nil {
+				links[pair[0]] = make(map[string]bool)
+			}
+			links[pair[0]][pair[1]] = true
+		}
+	}
+	for _, rel := range relationships {
+		link(logicalIDs[rel.FromEntityID], logicalIDs[rel.ToEntityID])
+	}
+
+	entityTypes := make(map[string]string, len(entities))
+	var scenes, characters, themes []*Entity
+	for _, entity := range entities {
+		entityTypes[entity.ID] = entity.EntityType
+		switch types.EntityType(entity.EntityType) {
+		case types.EntityTypeScene:
+			scenes = append(scenes, entity)
+			for _, field := range []string{"characters", "location", "themes"} {
+				for _, id := range referencedIDs(entity.Data[field]) {
+					link(entity.ID, id)
+				}
+			}
+		case types.EntityTypeCharacter:
+			characters = append(characters, entity)
+		case types.EntityTypeTheme:
+			themes = append(themes, entity)
+		}
+	}
+
+	linkedOfType := func(id string, entityType types.EntityType) []string {
+		var result []string
+		for other := range links[id] {
+			if entityTypes[other] == string(entityType) {
+				result = append(result, other)
+			}
+		}
+		return result
+	}
+
+	// Acts present
+	acts := make(map[string]bool)
+	for _, scene := range scenes {
+		if act, ok := scene.Data["act"].(string); ok {
+			acts[act] = true
+		}
+	}
+	var missingActs []string
+	for _, act := range config.RequiredActs {
+		if !acts[act] {
+			missingActs = append(missingActs, act)
+		}
+	}
+
+	// Scene characters and locations
+	var scenesWithoutCharacters, scenesWithoutLocation []string
+	appearances := make(map[string]int)
+	for _, scene := range scenes {
+		sceneCharacters := linkedOfType(scene.ID, types.EntityTypeCharacter)
+		if len(sceneCharacters) == 0 {
+			scenesWithoutCharacters = append(scenesWithoutCharacters, scene.ID)
+		}
+		for _, characterID := range sceneCharacters {
+			appearances[characterID]++
+		}
+		if len(linkedOfType(scene.ID, types.EntityTypeLocation)) == 0 {
+			scenesWithoutLocation = append(scenesWithoutLocation, scene.ID)
+		}
+	}
+
+	// Main character appearances
+	mainRoles := make(map[string]bool, len(config.MainCharacterRoles))
+	for _, role := range config.MainCharacterRoles {
+		mainRoles[strings.ToLower(role)] = true
+	}
+	var underusedCharacters []string
+	for _, character := range characters {
+		role, _ := character.Data["role"].(string)
+		if mainRoles[strings.ToLower(role)] && appearances[character.ID] < config.MinScenesPerMainCharacter {
+			underusedCharacters = append(underusedCharacters, character.ID)
+		}
+	}
+
+	// Theme coverage
+	var uncoveredThemes []string
+	for _, theme := range themes {
+		if len(linkedOfType(theme.ID, types.EntityTypeScene)) == 0 {
+			uncoveredThemes = append(uncoveredThemes, theme.ID)
+		}
+	}
+
+	report := &Completeness{
+		VersionID: versionID,
+		Checks: []CompletenessCheck{
+			newCompletenessCheck(CheckActsPresent, missingActs, "every required act has a scene", "acts without scenes"),
+			newCompletenessCheck(CheckScenesHaveCharacters, scenesWithoutCharacters, "every scene features a character", "scenes without characters"),
+			newCompletenessCheck(CheckScenesHaveLocation, scenesWithoutLocation, "every scene has a location", "scenes without a location"),
+			newCompletenessCheck(CheckMainCharactersAppear, underusedCharacters,
+				fmt.Sprintf("every main character appears in at least %d scenes", config.MinScenesPerMainCharacter),
+				fmt.Sprintf("main characters in fewer than %d scenes", config.MinScenesPerMainCharacter)),
+			newCompletenessCheck(CheckThemesCovered, uncoveredThemes, "every theme is explored by a scene", "themes no scene explores"),
+		},
+	}
+
+	report.Passed = true
+	for _, check := range report.Checks {
+		report.Passed = report.Passed && check.Passed
+	}
+
+	return report, nil
+}
+
+// newCompletenessCheck builds a check that passes when nothing offended it
+func newCompletenessCheck(name string, offendingIDs []string, passMessage, failMessage string) CompletenessCheck {
+	if len(offendingIDs) == 0 {
+		return CompletenessCheck{Name: name, Passed: true, Message: passMessage, OffendingIDs: []string{}}
+	}
+
+	sort.Strings(offendingIDs)
+	return CompletenessCheck{
+		Name:         name,
+		Message:      fmt.Sprintf("%d %s", len(offendingIDs), failMessage),
+		OffendingIDs: offendingIDs,
+	}
+}
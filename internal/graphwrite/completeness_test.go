@@ -0,0 +1,108 @@
+package graphwrite
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// completenessDeltas builds a small story: a protagonist and a theme, and one scene
+// per given act at the harbor featuring the protagonist
+func completenessDeltas(acts ...string) []*Delta {
+	deltas := []*Delta{
+		{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "role": "protagonist"}},
+		{Operation: "create", EntityType: "Location", EntityID: "harbor", Fields: map[string]any{"name": "Harbor"}},
+		{Operation: "create", EntityType: "Theme", EntityID: "loyalty", Fields: map[string]any{"name": "Loyalty"}},
+	}
+	for _, act := range acts {
+		deltas = append(deltas, &Delta{
+			Operation:  "create",
+			EntityType: "Scene",
+			EntityID:   "scene-" + act,
+			Fields: map[string]any{
+				"name":       "Scene in " + act,
+				"act":        act,
+				"location":   "harbor",
+				"characters": []any{"elena"},
+				"themes":     []any{"loyalty"},
+			},
+		})
+	}
+	return deltas
+}
+
+func completenessCheck(t *testing.T, report *Completeness, name string) CompletenessCheck {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("Expected a %s check, got %+v", name, report.Checks)
+	return CompletenessCheck{}
+}
+
+func TestService_CompletenessReport_CompleteStory(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	response, err := service.Apply(ctx, &ApplyRequest{ParentVersionID: rootVersionID, Deltas: completenessDeltas("Act1", "Act2", "Act3")})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	report, err := service.CompletenessReport(ctx, response.GraphVersionID)
+	if err != nil {
+		t.Fatalf("CompletenessReport failed: %v", err)
+	}
+	if !report.Passed || len(report.Checks) != 5 {
+		t.Errorf("Expected all five checks to pass, got %+v", report.Checks)
+	}
+}
+
+func TestService_CompletenessReport_IncompleteStory(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	deltas := append(completenessDeltas("Act1", "Act2"),
+		&Delta{Operation: "create", EntityType: "Scene", EntityID: "interlude", Fields: map[string]any{"name": "Interlude", "act": "Act2"}},
+		&Delta{Operation: "create", EntityType: "Character", EntityID: "mordak", Fields: map[string]any{"name": "Mordak", "role": "antagonist"}},
+		&Delta{Operation: "create", EntityType: "Theme", EntityID: "betrayal", Fields: map[string]any{"name": "Betrayal"}},
+	)
+	response, err := service.Apply(ctx, &ApplyRequest{ParentVersionID: rootVersionID, Deltas: deltas})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	report, err := service.CompletenessReport(ctx, response.GraphVersionID)
+	if err != nil {
+		t.Fatalf("CompletenessReport failed: %v", err)
+	}
+	if report.Passed {
+		t.Fatal("Expected the incomplete story to fail")
+	}
+
+	expected := map[string][]string{
+		CheckActsPresent:          {"Act3"},
+		CheckScenesHaveCharacters: {"interlude"},
+		CheckScenesHaveLocation:   {"interlude"},
+		CheckMainCharactersAppear: {"mordak"},
+		CheckThemesCovered:        {"betrayal"},
+	}
+	for name, offending := range expected {
+		check := completenessCheck(t, report, name)
+		if check.Passed || !reflect.DeepEqual(check.OffendingIDs, offending) {
+			t.Errorf("Expected %s to fail on %v, got %+v", name, offending, check)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package graphwrite
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// entityContentHash returns a hash of an entity row's type, name and data. Data is
+// canonicalised first, so the hash does not depend on stored key order.
+func entityContentHash(entity db.Entity) (string, error) {
+	var data map[string]any
+	if err := json.Unmarshal(entity.Data, &data); err != nil {
+		return "", fmt.Errorf("failed to unmarshal entity data: %w", err)
+	}
+
+	canonicalData, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal entity data: %w", err)
+	}
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s", entity.EntityType, entity.Name, canonicalData)))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// versionContentHash returns a hash of a version's narrative content: every entity's
+// logical ID, type, name and data, and every relationship between logical IDs with
+// its properties. Database IDs, timestamps and annotations are excluded, so two
+// versions with the same story content hash identically.
+func (s *Service) versionContentHash(ctx context.Context, versionID string) (string, error) {
+	entities, err := s.db.Queries().ListEntitiesByVersion(ctx, versionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	relationships, err := s.db.Queries().ListRelationshipsByVersion(ctx, versionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	logicalIDs := make(map[string]string, len(entities))
+	lines := make([]string, 0, len(entities)+len(relationships))
+
+	for _, entity := range entities {
+		// Round-trip through a map so keys are marshaled in sorted order
+		var data map[string]any
+		if err := json.Unmarshal(entity.Data, &data); err != nil {
+			return "", fmt.Errorf("failed to unmarshal entity data: %w", err)
+		}
+
+		logicalID := entity.ID
+		if lid, exists := data["logical_id"].(string); exists {
+			logicalID = lid
+		}
+		logicalIDs[entity.ID] = logicalID
+
+		canonicalData, err := json.Marshal(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal entity data: %w", err)
+		}
+		lines = append(lines, fmt.Sprintf("entity\x00%s\x00%s\x00%s\x00%s", logicalID, entity.EntityType, entity.Name, canonicalData))
+	}
+
+	for _, rel := range relationships {
+		var properties any
+		if len(rel.Properties) > 0 {
+			if err := json.Unmarshal(rel.Properties, &properties); err != nil {
+				return "", fmt.Errorf("failed to unmarshal relationship properties: %w", err)
+			}
+		}
+
+		canonicalProperties, err := json.Marshal(properties)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal relationship properties: %w", err)
+		}
+		lines = append(lines, fmt.Sprintf("relationship\x00%s\x00%s\x00%s\x00%s",
+			logicalIDs[rel.FromEntityID], rel.RelationshipType, logicalIDs[rel.ToEntityID], canonicalProperties))
+	}
+
+	sort.Strings(lines)
+
+	hash := sha256.New()
+	for _, line := range lines {
+		hash.Write([]byte(line))
+		hash.Write([]byte{'\n'})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// sameContent reports whether two versions hold identical narrative content
+func (s *Service) sameContent(ctx context.Context, versionA, versionB string) (bool, error) {
+	hashA, err := s.versionContentHash(ctx, versionA)
+	if err != nil {
+		return false, err
+	}
+
+	hashB, err := s.versionContentHash(ctx, versionB)
+	if err != nil {
+		return false, err
+	}
+
+	return hashA == hashB, nil
+}
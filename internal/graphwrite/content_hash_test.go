@@ -0,0 +1,120 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestService_Apply_DedupIdenticalApplies(t *testing.T) {
+	ctx := context.Background()
+
+	restateScene := func(parentVersionID string) *ApplyRequest {
+		return &ApplyRequest{
+			ParentVersionID: parentVersionID,
+			Deltas: []*Delta{
+				{Operation: "update", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"title": "Opening", "name": "Opening"}},
+			},
+		}
+	}
+
+	t.Run("no-op apply reuses parent when enabled", func(t *testing.T) {
+		database := setupTestDB(t)
+		defer database.Close()
+
+		service := NewServiceWithOptions(database, ServiceOptions{DedupIdenticalApplies: true})
+		projectID := createTestProject(t, database)
+		rootVersionID := createTestGraphVersion(t, database, projectID, true)
+		parentVersionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+		response, err := service.Apply(ctx, restateScene(parentVersionID))
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if response.GraphVersionID != parentVersionID || response.Applied != 0 {
+			t.Errorf("Expected parent %s with 0 applied, got %s with %d", parentVersionID, response.GraphVersionID, response.Applied)
+		}
+
+		versions, err := database.Queries().ListGraphVersionsByProject(ctx, projectID)
+		if err != nil {
+			t.Fatalf("Failed to list versions: %v", err)
+		}
+		if len(versions) != 2 {
+			t.Errorf("Expected no new version, got %d versions", len(versions))
+		}
+
+		// A real change still creates a version
+		changed := restateScene(parentVersionID)
+		changed.Deltas[0].Fields["title"] = "A New Opening"
+		response, err = service.Apply(ctx, changed)
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if response.GraphVersionID == parentVersionID || response.Applied != 1 {
+			t.Errorf("Expected a new version for a real change, got %s with %d applied", response.GraphVersionID, response.Applied)
+		}
+	})
+
+	t.Run("no-op apply creates version when disabled", func(t *testing.T) {
+		database := setupTestDB(t)
+		defer database.Close()
+
+		service := NewService(database)
+		projectID := createTestProject(t, database)
+		rootVersionID := createTestGraphVersion(t, database, projectID, true)
+		parentVersionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+		response, err := service.Apply(ctx, restateScene(parentVersionID))
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if response.GraphVersionID == parentVersionID {
+			t.Error("Expected a new version without dedup")
+		}
+	})
+}
+
+func TestService_Apply_ExpectedContentHash(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+	sceneHash := func(versionID string) string {
+		sceneType := "Scene"
+		scenes, err := service.ListEntities(ctx, versionID, EntityFilter{EntityType: &sceneType})
+		if err != nil || len(scenes) != 1 {
+			t.Fatalf("ListEntities failed: %v (%d scenes)", err, len(scenes))
+		}
+		return scenes[0].ContentHash
+	}
+	readHash := sceneHash(versionID)
+
+	edit := func(parentVersionID, expectedHash, title string) (*ApplyResponse, error) {
+		return service.Apply(ctx, &ApplyRequest{
+			ParentVersionID: parentVersionID,
+			Deltas: []*Delta{
+				{Operation: "update", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": title}, ExpectedContentHash: expectedHash},
+			},
+		})
+	}
+
+	// A fresh edit is accepted
+	fresh, err := edit(versionID, readHash, "First Edit")
+	if err != nil {
+		t.Fatalf("Expected a fresh edit to succeed, got %v", err)
+	}
+	if sceneHash(fresh.GraphVersionID) == readHash {
+		t.Error("Expected the content hash to change after an edit")
+	}
+
+	// A second edit still holding the hash read before the first is stale
+	if _, err := edit(fresh.GraphVersionID, readHash, "Second Edit"); !errors.Is(err, ErrEntityModified) {
+		t.Errorf("Expected ErrEntityModified for a stale edit, got %v", err)
+	}
+}
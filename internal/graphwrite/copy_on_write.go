@@ -0,0 +1,259 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/google/uuid"
+)
+
+// shareRowsFromParent makes every entity and relationship row of the parent part of the
+// new version without copying them, and returns the logical to database ID mapping
+// for the new version. Rows a delta changes are materialized later by materializeEntity
+// and materializeRelationship.
+func (s *Service) shareRowsFromParent(ctx context.Context, parentVersionID, newVersionID string) (map[string]string, error) {
+	if err := s.db.Queries().ShareVersionEntities(ctx, db.ShareVersionEntitiesParams{
+		VersionID:       newVersionID,
+		ParentVersionID: parentVersionID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to share entities: %w", err)
+	}
+
+	if err := s.db.Queries().ShareVersionRelationships(ctx, db.ShareVersionRelationshipsParams{
+		VersionID:       newVersionID,
+		ParentVersionID: parentVersionID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to share relationships: %w", err)
+	}
+
+	logicalIDs, err := s.logicalIDsByDatabaseID(ctx, newVersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	entityIDMapping := make(map[string]string, len(logicalIDs))
+	for databaseID, logicalID := range logicalIDs {
+		entityIDMapping[logicalID] = databaseID
+	}
+
+	return entityIDMapping, nil
+}
+
+// materializeEntity gives a version its own copy of an entity row it shares with other
+// versions, so the row can change without affecting them. The copy takes over the row's
+// annotations and the version's relationships touching it, with their annotations. It returns the database ID
+// to write to, which is unchanged when the row already belongs to the version alone.
+func (s *Service) materializeEntity(ctx context.Context, versionID string, logicalID string, databaseID string, entityIDMapping map[string]string) (string, error) {
+	entity, err := s.db.Queries().GetEntity(ctx, databaseID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get entity: %w", err)
+	}
+	if entity.VersionID == versionID {
+		return databaseID, nil
+	}
+
+	newDatabaseID := uuid.New().String()
+	if _, err := s.db.Queries().CreateEntity(ctx, db.CreateEntityParams{
+		ID:         newDatabaseID,
+		VersionID:  versionID,
+		EntityType: entity.EntityType,
+		Name:       entity.Name,
+		Data:       entity.Data,
+	}); err != nil {
+		return "", fmt.Errorf("failed to materialize entity %s: %w", logicalID, err)
+	}
+
+	if err := s.db.Queries().RemoveVersionEntity(ctx, db.RemoveVersionEntityParams{
+		VersionID: versionID,
+		EntityID:  databaseID,
+	}); err != nil {
+		return "", fmt.Errorf("failed to unshare entity %s: %w", logicalID, err)
+	}
+
+	annotations, err := s.db.Queries().ListAnnotationsByEntity(ctx, databaseID)
+	if err != nil {
+		return "", fmt.Errorf("failed to list annotations: %w", err)
+	}
+	for _, annotation := range annotations {
+		if _, err := s.db.Queries().CreateAnnotation(ctx, db.CreateAnnotationParams{
+			ID:             uuid.New().String(),
+			EntityID:       newDatabaseID,
+			AnnotationType: annotation.AnnotationType,
+			Content:        annotation.Content,
+			Metadata:       annotation.Metadata,
+			AgentName:      annotation.AgentName,
+		}); err != nil {
+			return "", fmt.Errorf("failed to copy annotation %s: %w", annotation.ID, err)
+		}
+	}
+
+	relationships, err := s.db.Queries().ListRelationshipsByEntityInVersion(ctx, db.ListRelationshipsByEntityInVersionParams{
+		VersionID:    versionID,
+		FromEntityID: databaseID,
+		ToEntityID:   databaseID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list relationships: %w", err)
+	}
+	for _, rel := range relationships {
+		fromID, toID := rel.FromEntityID, rel.ToEntityID
+		if fromID == databaseID {
+			fromID = newDatabaseID
+		}
+		if toID == databaseID {
+			toID = newDatabaseID
+		}
+		newRelationshipID := uuid.New().String()
+		if _, err := s.db.Queries().CreateRelationship(ctx, db.CreateRelationshipParams{
+			ID:               newRelationshipID,
+			VersionID:        versionID,
+			FromEntityID:     fromID,
+			ToEntityID:       toID,
+			RelationshipType: rel.RelationshipType,
+			Properties:       rel.Properties,
+		}); err != nil {
+			return "", fmt.Errorf("failed to re-point relationship %s: %w", rel.ID, err)
+		}
+		if err := s.copyRelationshipAnnotations(ctx, rel.ID, newRelationshipID); err != nil {
+			return "", err
+		}
+		if err := s.removeRelationshipFromVersion(ctx, versionID, rel); err != nil {
+			return "", err
+		}
+	}
+
+	entityIDMapping[logicalID] = newDatabaseID
+	return newDatabaseID, nil
+}
+
+// materializeRelationship gives a version its own copy of a relationship row it shares
+// with other versions, annotations included. A row already replaced by materializeEntity resolves to its
+// replacement. It returns the database ID to write to, which is unchanged when the row
+// already belongs to the version alone or is not part of the version at all.
+func (s *Service) materializeRelationship(ctx context.Context, versionID string, relationshipID string, entityIDMapping map[string]string) (string, error) {
+	rel, err := s.db.Queries().GetRelationship(ctx, relationshipID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get relationship: %w", err)
+	}
+	if rel.VersionID == versionID {
+		return relationshipID, nil
+	}
+
+	inVersion, err := s.db.Queries().CountVersionRelationship(ctx, db.CountVersionRelationshipParams{
+		VersionID:      versionID,
+		RelationshipID: relationshipID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to check relationship membership: %w", err)
+	}
+	if inVersion == 0 {
+		return s.replacementRelationship(ctx, versionID, rel, entityIDMapping)
+	}
+
+	newRelationshipID := uuid.New().String()
+	if _, err := s.db.Queries().CreateRelationship(ctx, db.CreateRelationshipParams{
+		ID:               newRelationshipID,
+		VersionID:        versionID,
+		FromEntityID:     rel.FromEntityID,
+		ToEntityID:       rel.ToEntityID,
+		RelationshipType: rel.RelationshipType,
+		Properties:       rel.Properties,
+	}); err != nil {
+		return "", fmt.Errorf("failed to materialize relationship %s: %w", relationshipID, err)
+	}
+	if err := s.copyRelationshipAnnotations(ctx, relationshipID, newRelationshipID); err != nil {
+		return "", err
+	}
+
+	if err := s.removeRelationshipFromVersion(ctx, versionID, rel); err != nil {
+		return "", err
+	}
+
+	return newRelationshipID, nil
+}
+
+// replacementRelationship finds the version's relationship with the same logical endpoints
+// and type as a row outside the version, which is how a shared row re-pointed by
+// materializeEntity is found. Rows without a replacement are returned unchanged.
+func (s *Service) replacementRelationship(ctx context.Context, versionID string, rel db.Relationship, entityIDMapping map[string]string) (string, error) {
+	from, err := s.db.Queries().GetEntity(ctx, rel.FromEntityID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get relationship source: %w", err)
+	}
+	to, err := s.db.Queries().GetEntity(ctx, rel.ToEntityID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get relationship target: %w", err)
+	}
+
+	replacementID, found, err := s.findRelationshipByLogicalTuple(ctx, versionID, &RelationshipDelta{
+		FromEntityID:     logicalIDOf(from),
+		ToEntityID:       logicalIDOf(to),
+		RelationshipType: rel.RelationshipType,
+	}, entityIDMapping)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return rel.ID, nil
+	}
+	return replacementID, nil
+}
+
+// logicalIDOf returns an entity row's logical ID, which is its database ID for rows
+// created before logical IDs were recorded
+func logicalIDOf(entity db.Entity) string {
+	var data map[string]any
+	if err := json.Unmarshal(entity.Data, &data); err == nil {
+		if logicalID, ok := data["logical_id"].(string); ok {
+			return logicalID
+		}
+	}
+	return entity.ID
+}
+
+// removeRelationshipFromVersion drops a relationship from a version: rows the version
+// created are deleted, rows shared with other versions only leave this one
+func (s *Service) removeRelationshipFromVersion(ctx context.Context, versionID string, rel db.Relationship) error {
+	if rel.VersionID == versionID {
+		if err := s.db.Queries().DeleteRelationship(ctx, rel.ID); err != nil {
+			return fmt.Errorf("failed to delete relationship: %w", err)
+		}
+		return nil
+	}
+
+	if err := s.db.Queries().RemoveVersionRelationship(ctx, db.RemoveVersionRelationshipParams{
+		VersionID:      versionID,
+		RelationshipID: rel.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to unshare relationship: %w", err)
+	}
+	return nil
+}
+
+// removeEntityFromVersion drops a shared entity row and the version's relationships
+// touching it from a version, leaving the rows in place for the versions still using them
+func (s *Service) removeEntityFromVersion(ctx context.Context, versionID string, databaseID string) error {
+	relationships, err := s.db.Queries().ListRelationshipsByEntityInVersion(ctx, db.ListRelationshipsByEntityInVersionParams{
+		VersionID:    versionID,
+		FromEntityID: databaseID,
+		ToEntityID:   databaseID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list relationships: %w", err)
+	}
+	for _, rel := range relationships {
+		if err := s.removeRelationshipFromVersion(ctx, versionID, rel); err != nil {
+			return err
+		}
+	}
+
+	if err := s.db.Queries().RemoveVersionEntity(ctx, db.RemoveVersionEntityParams{
+		VersionID: versionID,
+		EntityID:  databaseID,
+	}); err != nil {
+		return fmt.Errorf("failed to unshare entity: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// countRows counts every row of a table regardless of version
+func countRows(t testing.TB, database *db.Database, table string) int {
+	var count int
+	if err := database.DB().QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+		t.Fatalf("Failed to count %s: %v", table, err)
+	}
+	return count
+}
+
+// entityNames maps logical IDs to names for a version's entities
+func entityNames(t *testing.T, service GraphWriteService, versionID string) map[string]string {
+	entities, err := service.ListEntities(context.Background(), versionID, EntityFilter{})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	names := make(map[string]string, len(entities))
+	for _, entity := range entities {
+		names[entity.ID] = entity.Name
+	}
+	return names
+}
+
+func TestService_CopyOnWrite_SharesUnchangedRows(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewServiceWithOptions(database, ServiceOptions{CopyOnWrite: true})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: baseVersionID,
+		Deltas: []*Delta{
+			{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena the Bold"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	// Only elena and her edge are materialized; marcus is shared with the parent
+	if entities := countRows(t, database, "entities"); entities != 3 {
+		t.Errorf("Expected 3 entity rows, got %d", entities)
+	}
+	if relationships := countRows(t, database, "relationships"); relationships != 2 {
+		t.Errorf("Expected 2 relationship rows, got %d", relationships)
+	}
+
+	if names := entityNames(t, service, baseVersionID); names["elena"] != "Elena" || names["marcus"] != "Marcus" {
+		t.Errorf("Expected the parent to keep its names, got %v", names)
+	}
+	if names := entityNames(t, service, response.GraphVersionID); names["elena"] != "Elena the Bold" || names["marcus"] != "Marcus" {
+		t.Errorf("Expected the child to see the update and the shared entity, got %v", names)
+	}
+
+	for _, versionID := range []string{baseVersionID, response.GraphVersionID} {
+		relationships, err := database.Queries().ListRelationshipsByVersion(ctx, versionID)
+		if err != nil {
+			t.Fatalf("ListRelationshipsByVersion failed: %v", err)
+		}
+		if len(relationships) != 1 {
+			t.Errorf("Expected 1 relationship in version %s, got %d", versionID, len(relationships))
+		}
+
+		neighbors, err := service.GetNeighborsInVersion(ctx, versionID, "elena", "allies_with")
+		if err != nil {
+			t.Fatalf("GetNeighborsInVersion failed: %v", err)
+		}
+		if len(neighbors) != 1 || neighbors[0].ID != "marcus" {
+			t.Errorf("Expected elena to ally with marcus in version %s, got %+v", versionID, neighbors)
+		}
+	}
+}
+
+func TestService_CopyOnWrite_DeleteLeavesParentIntact(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewServiceWithOptions(database, ServiceOptions{CopyOnWrite: true})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: baseVersionID,
+		Deltas: []*Delta{
+			{Operation: "delete", EntityType: "Character", EntityID: "marcus"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if names := entityNames(t, service, response.GraphVersionID); len(names) != 1 || names["elena"] != "Elena" {
+		t.Errorf("Expected only elena in the child, got %v", names)
+	}
+	if names := entityNames(t, service, baseVersionID); len(names) != 2 {
+		t.Errorf("Expected the parent to keep both entities, got %v", names)
+	}
+
+	child, err := database.Queries().ListRelationshipsByVersion(ctx, response.GraphVersionID)
+	if err != nil {
+		t.Fatalf("ListRelationshipsByVersion failed: %v", err)
+	}
+	if len(child) != 0 {
+		t.Errorf("Expected the edge to leave the child, got %d relationships", len(child))
+	}
+	parent, err := database.Queries().ListRelationshipsByVersion(ctx, baseVersionID)
+	if err != nil {
+		t.Fatalf("ListRelationshipsByVersion failed: %v", err)
+	}
+	if len(parent) != 1 {
+		t.Errorf("Expected the parent to keep its edge, got %d relationships", len(parent))
+	}
+}
+
+func TestService_CopyOnWrite_RelationshipChanges(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewServiceWithOptions(database, ServiceOptions{CopyOnWrite: true})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+	parentEdges, err := database.Queries().ListRelationshipsByVersion(ctx, baseVersionID)
+	if err != nil || len(parentEdges) != 1 {
+		t.Fatalf("Expected one parent edge, got %d (%v)", len(parentEdges), err)
+	}
+
+	updated, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: baseVersionID,
+		Deltas: []*Delta{{
+			Operation:  "update",
+			EntityType: "Character",
+			EntityID:   "marcus",
+			Fields:     map[string]any{"name": "Marcus"},
+			Relationships: []*RelationshipDelta{{
+				Operation:      "update",
+				RelationshipID: parentEdges[0].ID,
+				Properties:     map[string]any{"bond_strength": "strong"},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	impl := service.(*Service)
+	base, err := impl.relationshipsByLogicalKey(ctx, baseVersionID)
+	if err != nil {
+		t.Fatalf("relationshipsByLogicalKey failed: %v", err)
+	}
+	after, err := impl.relationshipsByLogicalKey(ctx, updated.GraphVersionID)
+	if err != nil {
+		t.Fatalf("relationshipsByLogicalKey failed: %v", err)
+	}
+	key := relationshipKey{From: "elena", To: "marcus", Type: "allies_with"}
+	if base[key]["bond_strength"] != "growing" {
+		t.Errorf("Expected the parent edge to stay growing, got %v", base[key])
+	}
+	if after[key]["bond_strength"] != "strong" {
+		t.Errorf("Expected the child edge to be strong, got %v", after[key])
+	}
+
+	deleted, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: baseVersionID,
+		Deltas: []*Delta{{
+			Operation:  "update",
+			EntityType: "Character",
+			EntityID:   "elena",
+			Fields:     map[string]any{"name": "Elena"},
+			Relationships: []*RelationshipDelta{{
+				Operation:        "delete",
+				FromEntityID:     "elena",
+				ToEntityID:       "marcus",
+				RelationshipType: "allies_with",
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if edges, _ := impl.relationshipsByLogicalKey(ctx, deleted.GraphVersionID); len(edges) != 0 {
+		t.Errorf("Expected the edge to be deleted in the child, got %v", edges)
+	}
+	if edges, _ := impl.relationshipsByLogicalKey(ctx, baseVersionID); len(edges) != 1 {
+		t.Errorf("Expected the parent to keep its edge, got %v", edges)
+	}
+}
+
+func TestService_CopyOnWrite_AnnotationsStayInTheirVersion(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewServiceWithOptions(database, ServiceOptions{CopyOnWrite: true})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+	// Two children sharing every row of the base
+	var childIDs []string
+	for _, locationID := range []string{"forge", "harbour"} {
+		response, err := service.Apply(ctx, &ApplyRequest{
+			ParentVersionID: baseVersionID,
+			Deltas:          []*Delta{{Operation: "create", EntityType: "Location", EntityID: locationID, Fields: map[string]any{"name": locationID}}},
+		})
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		childIDs = append(childIDs, response.GraphVersionID)
+	}
+
+	if _, err := service.CreateAnnotation(ctx, childIDs[0], &Annotation{EntityID: "marcus", AnnotationType: "continuity_check", Content: "Marcus is late"}); err != nil {
+		t.Fatalf("CreateAnnotation failed: %v", err)
+	}
+	if _, err := service.AnnotateRelationship(ctx, childIDs[0], &RelationshipAnnotation{
+		FromEntityID:     "elena",
+		ToEntityID:       "marcus",
+		RelationshipType: "allies_with",
+		AnnotationType:   "continuity_check",
+		Content:          "This alliance is strained",
+	}); err != nil {
+		t.Fatalf("AnnotateRelationship failed: %v", err)
+	}
+
+	for versionID, want := range map[string]int{baseVersionID: 0, childIDs[0]: 1, childIDs[1]: 0} {
+		annotations, err := service.AnnotationsForVersion(ctx, versionID)
+		if err != nil {
+			t.Fatalf("AnnotationsForVersion failed: %v", err)
+		}
+		if len(annotations) != want {
+			t.Errorf("Expected %d entity annotations in version %s, got %+v", want, versionID, annotations)
+		}
+		relationshipAnnotations, err := service.RelationshipAnnotationsForVersion(ctx, versionID)
+		if err != nil {
+			t.Fatalf("RelationshipAnnotationsForVersion failed: %v", err)
+		}
+		if len(relationshipAnnotations) != want {
+			t.Errorf("Expected %d relationship annotations in version %s, got %+v", want, versionID, relationshipAnnotations)
+		}
+	}
+
+	if names := entityNames(t, service, childIDs[0]); names["marcus"] != "Marcus" || len(names) != 3 {
+		t.Errorf("Expected the annotated child to keep its entities, got %v", names)
+	}
+}
+
+// BenchmarkApply_SequentialEdits applies 50 single-entity edits in a row to a
+// 20-entity story, copying every row per version versus sharing unchanged rows.
+// Each op is the whole chain of edits; entity-rows and relationship-rows report
+// the rows stored once it finishes.
+func BenchmarkApply_SequentialEdits(b *testing.B) {
+	const (
+		storySize = 20
+		edits     = 50
+	)
+
+	for _, mode := range []struct {
+		name    string
+		options ServiceOptions
+	}{
+		{name: "copy", options: ServiceOptions{}},
+		{name: "copy-on-write", options: ServiceOptions{CopyOnWrite: true}},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			ctx := context.Background()
+			var entityRows, relationshipRows int
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				database := setupTestDB(b)
+				service := NewServiceWithOptions(database, mode.options)
+				projectID := createTestProject(b, database)
+				versionID := createTestGraphVersion(b, database, projectID, true)
+
+				deltas := make([]*Delta, 0, storySize)
+				for n := 0; n < storySize; n++ {
+					delta := &Delta{Operation: "create", EntityType: "Character", EntityID: fmt.Sprintf("character-%d", n), Fields: map[string]any{"name": fmt.Sprintf("Character %d", n)}}
+					if n > 0 {
+						delta.Relationships = []*RelationshipDelta{{Operation: "create", FromEntityID: fmt.Sprintf("character-%d", n-1), ToEntityID: delta.EntityID, RelationshipType: "knows", Properties: map[string]any{"since": "chapter-1"}}}
+					}
+					deltas = append(deltas, delta)
+				}
+				response, err := service.Apply(ctx, &ApplyRequest{ParentVersionID: versionID, Deltas: deltas})
+				if err != nil {
+					b.Fatalf("Apply failed: %v", err)
+				}
+				versionID = response.GraphVersionID
+				b.StartTimer()
+
+				for edit := 0; edit < edits; edit++ {
+					response, err := service.Apply(ctx, &ApplyRequest{
+						ParentVersionID: versionID,
+						Deltas: []*Delta{{
+							Operation:  "update",
+							EntityType: "Character",
+							EntityID:   fmt.Sprintf("character-%d", edit%storySize),
+							Fields:     map[string]any{"name": fmt.Sprintf("Edit %d", edit)},
+						}},
+					})
+					if err != nil {
+						b.Fatalf("Apply failed: %v", err)
+					}
+					versionID = response.GraphVersionID
+				}
+
+				b.StopTimer()
+				entityRows = countRows(b, database, "entities")
+				relationshipRows = countRows(b, database, "relationships")
+				database.Close()
+				b.StartTimer()
+			}
+
+			b.ReportMetric(float64(entityRows), "entity-rows")
+			b.ReportMetric(float64(relationshipRows), "relationship-rows")
+		})
+	}
+}
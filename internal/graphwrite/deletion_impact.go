@@ -0,0 +1,163 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// DeletionImpact reports what deleting a project would do to the rest of the library
+type DeletionImpact struct {
+	ProjectID   string
+	ProjectName string
+
+	// SharedEntities are working set entities that also appear in other projects
+	SharedEntities []*ImpactedEntity
+
+	// BrokenRelationships are relationships in other projects that touch a shared entity
+	BrokenRelationships []*ImpactedRelationship
+
+	// Safe is true when no other project shares an entity with this one
+	Safe bool
+}
+
+// ImpactedEntity is a shared entity that would lose a project on deletion
+type ImpactedEntity struct {
+	LogicalID  string
+	Name       string
+	EntityType string
+
+	// RemainingProjectIDs identifies the other projects that still hold the entity;
+	// RemainingProjects holds their names in the same order, for display only
+	RemainingProjectIDs []string
+	RemainingProjects   []string
+}
+
+// ImpactedRelationship is a relationship in another project that involves a shared entity
+type ImpactedRelationship struct {
+	ProjectID        string
+	ProjectName      string
+	FromEntityID     string // logical ID
+	ToEntityID       string // logical ID
+	RelationshipType string
+}
+
+// DeletionImpact analyses which shared entities and cross-project relationships would be
+// affected by deleting a project. Projects are compared through their working sets.
+func (s *Service) DeletionImpact(ctx context.Context, projectID string) (*DeletionImpact, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	project, err := s.db.Queries().GetProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	impact := &DeletionImpact{
+		ProjectID:           project.ID,
+		ProjectName:         project.Name,
+		SharedEntities:      []*ImpactedEntity{},
+		BrokenRelationships: []*ImpactedRelationship{},
+		Safe:                true,
+	}
+
+	workingSet, err := s.db.Queries().GetWorkingSetVersion(ctx, projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return impact, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working set: %w", err)
+	}
+
+	entities, err := s.db.Queries().ListEntitiesByVersion(ctx, workingSet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+	logicalIDs, err := s.logicalIDsByDatabaseID(ctx, workingSet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[string]*ImpactedEntity, len(entities))
+	for _, entity := range entities {
+		logicalID := logicalIDs[entity.ID]
+		candidates[logicalID] = &ImpactedEntity{
+			LogicalID:           logicalID,
+			Name:                entity.Name,
+			EntityType:          entity.EntityType,
+			RemainingProjectIDs: []string{},
+			RemainingProjects:   []string{},
+		}
+	}
+
+	projects, err := s.listProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	for _, other := range projects {
+		if other.ID == projectID {
+			continue
+		}
+
+		otherWorkingSet, err := s.db.Queries().GetWorkingSetVersion(ctx, other.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working set for project %s: %w", other.ID, err)
+		}
+
+		otherLogicalIDs, err := s.logicalIDsByDatabaseID(ctx, otherWorkingSet.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		shared := make(map[string]bool)
+		for _, logicalID := range otherLogicalIDs {
+			if candidate, ok := candidates[logicalID]; ok && !shared[logicalID] {
+				shared[logicalID] = true
+				candidate.RemainingProjectIDs = append(candidate.RemainingProjectIDs, other.ID)
+				candidate.RemainingProjects = append(candidate.RemainingProjects, other.Name)
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+
+		relationships, err := s.db.Queries().ListRelationshipsByVersion(ctx, otherWorkingSet.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list relationships for project %s: %w", other.ID, err)
+		}
+		for _, rel := range relationships {
+			from, to := otherLogicalIDs[rel.FromEntityID], otherLogicalIDs[rel.ToEntityID]
+			if !shared[from] && !shared[to] {
+				continue
+			}
+			impact.BrokenRelationships = append(impact.BrokenRelationships, &ImpactedRelationship{
+				ProjectID:        other.ID,
+				ProjectName:      other.Name,
+				FromEntityID:     from,
+				ToEntityID:       to,
+				RelationshipType: rel.RelationshipType,
+			})
+		}
+	}
+
+	for _, candidate := range candidates {
+		if len(candidate.RemainingProjectIDs) > 0 {
+			impact.SharedEntities = append(impact.SharedEntities, candidate)
+		}
+	}
+	sort.Slice(impact.SharedEntities, func(i, j int) bool {
+		if impact.SharedEntities[i].Name != impact.SharedEntities[j].Name {
+			return impact.SharedEntities[i].Name < impact.SharedEntities[j].Name
+		}
+		return impact.SharedEntities[i].LogicalID < impact.SharedEntities[j].LogicalID
+	})
+	impact.Safe = len(impact.SharedEntities) == 0
+
+	return impact, nil
+}
@@ -0,0 +1,225 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+func TestService_DeletionImpact_ElenaSaga(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	createBook := func(id, name string) string {
+		if _, err := database.Queries().CreateProject(ctx, db.CreateProjectParams{ID: id, Name: name}); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+		return createTestGraphVersion(t, database, id, true)
+	}
+
+	// Book 1 introduces Elena and Marcus
+	book1RootID := createBook("book-1", "Book 1: The Lost Artifact")
+	if _, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+		ParentVersionID: book1RootID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena Stormwind"}},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus Ironforge"}},
+			{Operation: "create", EntityType: "Location", EntityID: "temple", Fields: map[string]any{"name": "Temple of Echoes"}},
+		},
+	}); err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	// Book 2 imports both characters and relates them
+	book2RootID := createBook("book-2", "Book 2: The Shadow War")
+	for _, logicalID := range []string{"elena", "marcus"} {
+		if _, err := service.ImportEntity(ctx, book2RootID, "book-1", logicalID); err != nil {
+			t.Fatalf("ImportEntity failed: %v", err)
+		}
+	}
+	if _, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+		ParentVersionID: book2RootID,
+		Deltas: []*Delta{
+			{
+				Operation:  "create",
+				EntityType: "Location",
+				EntityID:   "war-camp",
+				Fields:     map[string]any{"name": "War Camp"},
+				Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "allies_with", Properties: map[string]any{}},
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	impact, err := service.DeletionImpact(ctx, "book-1")
+	if err != nil {
+		t.Fatalf("DeletionImpact failed: %v", err)
+	}
+
+	if impact.Safe {
+		t.Error("Expected deleting Book 1 to be unsafe")
+	}
+	if len(impact.SharedEntities) != 2 {
+		t.Fatalf("Expected Elena and Marcus to be impacted, got %d entities", len(impact.SharedEntities))
+	}
+	for i, want := range []string{"elena", "marcus"} {
+		entity := impact.SharedEntities[i]
+		if entity.LogicalID != want {
+			t.Errorf("Expected impacted entity %q, got %q", want, entity.LogicalID)
+		}
+		if len(entity.RemainingProjects) != 1 || entity.RemainingProjects[0] != "Book 2: The Shadow War" {
+			t.Errorf("Expected %s to remain in Book 2, got %v", want, entity.RemainingProjects)
+		}
+	}
+
+	if len(impact.BrokenRelationships) != 1 {
+		t.Fatalf("Expected 1 broken relationship, got %d", len(impact.BrokenRelationships))
+	}
+	broken := impact.BrokenRelationships[0]
+	if broken.ProjectID != "book-2" || broken.FromEntityID != "elena" || broken.ToEntityID != "marcus" || broken.RelationshipType != "allies_with" {
+		t.Errorf("Unexpected broken relationship: %+v", broken)
+	}
+
+	// A project sharing nothing is safe to delete
+	createBook("book-0", "Prequel Notes")
+	impact, err = service.DeletionImpact(ctx, "book-0")
+	if err != nil {
+		t.Fatalf("DeletionImpact failed: %v", err)
+	}
+	if !impact.Safe || len(impact.SharedEntities) != 0 || len(impact.BrokenRelationships) != 0 {
+		t.Errorf("Expected an empty, safe impact, got %+v", impact)
+	}
+}
+
+func TestService_SharedEntityLogic_DistinguishesProjectsWithTheSameName(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	// Three projects, two of them called "Untitled", all holding Elena
+	projectIDs := []string{"draft-a", "draft-b", "original"}
+	names := []string{"Untitled", "Untitled", "The Original"}
+	for i, id := range projectIDs {
+		if _, err := database.Queries().CreateProject(ctx, db.CreateProjectParams{ID: id, Name: names[i]}); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+		rootVersionID := createTestGraphVersion(t, database, id, true)
+		if _, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+			ParentVersionID: rootVersionID,
+			Deltas: []*Delta{
+				{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			},
+		}); err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+	}
+
+	shared, err := service.ListSharedEntities(ctx)
+	if err != nil {
+		t.Fatalf("ListSharedEntities failed: %v", err)
+	}
+	if len(shared) != 1 {
+		t.Fatalf("Expected Elena to be the only shared entity, got %d", len(shared))
+	}
+	elena := shared[0]
+	if elena.ProjectCount != 3 || len(elena.ProjectIDs) != 3 {
+		t.Fatalf("Expected both same-named projects to count separately, got %+v", elena)
+	}
+	for i, id := range []string{"draft-a", "draft-b", "original"} {
+		if elena.ProjectIDs[i] != id {
+			t.Errorf("Expected sorted project IDs %v, got %v", projectIDs, elena.ProjectIDs)
+			break
+		}
+	}
+	if elena.Projects[0] != "Untitled (draft-a)" || elena.Projects[1] != "Untitled (draft-b)" || elena.Projects[2] != "The Original" {
+		t.Errorf("Expected distinct display names aligned with IDs, got %v", elena.Projects)
+	}
+
+	impact, err := service.DeletionImpact(ctx, "original")
+	if err != nil {
+		t.Fatalf("DeletionImpact failed: %v", err)
+	}
+	if len(impact.SharedEntities) != 1 {
+		t.Fatalf("Expected Elena to be impacted, got %d entities", len(impact.SharedEntities))
+	}
+	remaining := impact.SharedEntities[0].RemainingProjectIDs
+	if len(remaining) != 2 || remaining[0] == remaining[1] {
+		t.Errorf("Expected both Untitled projects by ID, got %v", remaining)
+	}
+	for _, id := range remaining {
+		if id != "draft-a" && id != "draft-b" {
+			t.Errorf("Unexpected remaining project %s", id)
+		}
+	}
+}
+
+func TestService_ListSharedEntities_NamesEachProjectOnce(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	// Elena is a Character and, by mistake, a Theme in every book, so her logical ID
+	// appears twice in each project
+	for _, book := range []struct{ id, name string }{
+		{"book-3", "Book 3: The Return"},
+		{"book-1", "Book 1: The Lost Artifact"},
+		{"book-2", "Book 2: The Shadow War"},
+	} {
+		if _, err := database.Queries().CreateProject(ctx, db.CreateProjectParams{ID: book.id, Name: book.name}); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+		rootVersionID := createTestGraphVersion(t, database, book.id, true)
+		response, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+			ParentVersionID: rootVersionID,
+			Deltas: []*Delta{
+				{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+				{Operation: "create", EntityType: "Character", EntityID: "aria", Fields: map[string]any{"name": "Aria"}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+		if _, err := database.Queries().CreateEntity(ctx, db.CreateEntityParams{
+			ID:         book.id + "-elena-theme",
+			VersionID:  response.GraphVersionID,
+			EntityType: "Theme",
+			Name:       "Elena",
+			Data:       []byte(`{"name": "Elena", "logical_id": "elena"}`),
+		}); err != nil {
+			t.Fatalf("CreateEntity failed: %v", err)
+		}
+	}
+
+	shared, err := service.ListSharedEntities(ctx)
+	if err != nil {
+		t.Fatalf("ListSharedEntities failed: %v", err)
+	}
+	if len(shared) != 2 || shared[0].LogicalID != "aria" || shared[1].LogicalID != "elena" {
+		t.Fatalf("Expected aria then elena, sorted by name, got %+v", shared)
+	}
+
+	want := []string{"Book 1: The Lost Artifact", "Book 2: The Shadow War", "Book 3: The Return"}
+	for _, entity := range shared {
+		if entity.ProjectCount != len(entity.Projects) || len(entity.Projects) != len(want) {
+			t.Errorf("Expected %s in 3 projects, got count %d and %v", entity.LogicalID, entity.ProjectCount, entity.Projects)
+			continue
+		}
+		for i, name := range want {
+			if entity.Projects[i] != name {
+				t.Errorf("Expected %s's projects %v, got %v", entity.LogicalID, want, entity.Projects)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// GraphDiff is the structural difference between two versions. Entities are matched by
+// logical ID and relationships by their logical endpoints and type, so entities copied
+// into a new version line up with their originals. Every list is sorted by key.
+type GraphDiff struct {
+	FromVersionID string
+	ToVersionID   string
+
+	AddedEntities   []*Entity
+	RemovedEntities []*Entity
+
+	// ModifiedEntities carry per-field changes; logical_id bookkeeping is ignored
+	ModifiedEntities []*EntityChange
+
+	AddedRelationships    []*Relationship
+	RemovedRelationships  []*Relationship
+	ModifiedRelationships []*RelationshipChange
+}
+
+// RelationshipChange describes a relationship whose properties differ between two versions
+type RelationshipChange struct {
+	// Key renders the relationship as "from -type-> to"
+	Key    string
+	Before *Relationship
+	After  *Relationship
+
+	// Properties lists the properties that differ, sorted by name
+	Properties []FieldChange
+}
+
+// ApplyWithDiff applies deltas exactly as Apply does and returns the new version's
+// diff against the parent alongside the response, so callers showing what changed
+// need no second call. When ServiceOptions.DedupIdenticalApplies returns the parent
+// itself, the diff is empty.
+func (s *Service) ApplyWithDiff(ctx context.Context, req *ApplyRequest) (*ApplyResponse, *GraphDiff, error) {
+	response, err := s.Apply(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diff, err := s.Diff(ctx, req.ParentVersionID, response.GraphVersionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to diff the applied version: %w", err)
+	}
+
+	return response, diff, nil
+}
+
+// Diff compares two versions, which need not be parent and child or even share a project
+func (s *Service) Diff(ctx context.Context, fromVersionID, toVersionID string) (*GraphDiff, error) {
+	fromEntities, err := s.ListEntities(ctx, fromVersionID, EntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	toEntities, err := s.ListEntities(ctx, toVersionID, EntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	fromRelationships, err := s.ListRelationships(ctx, fromVersionID, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	toRelationships, err := s.ListRelationships(ctx, toVersionID, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &GraphDiff{
+		FromVersionID:         fromVersionID,
+		ToVersionID:           toVersionID,
+		AddedEntities:         []*Entity{},
+		RemovedEntities:       []*Entity{},
+		ModifiedEntities:      []*EntityChange{},
+		AddedRelationships:    []*Relationship{},
+		RemovedRelationships:  []*Relationship{},
+		ModifiedRelationships: []*RelationshipChange{},
+	}
+
+	before := make(map[string]*Entity, len(fromEntities))
+	for _, entity := range fromEntities {
+		before[entity.ID] = entity
+	}
+	after := make(map[string]*Entity, len(toEntities))
+	for _, entity := range toEntities {
+		after[entity.ID] = entity
+		previous, ok := before[entity.ID]
+		if !ok {
+			diff.AddedEntities = append(diff.AddedEntities, entity)
+			continue
+		}
+		if fields := diffFields(previous.Data, entity.Data); len(fields) > 0 {
+			diff.ModifiedEntities = append(diff.ModifiedEntities, &EntityChange{
+				LogicalID:     entity.ID,
+				FromVersionID: fromVersionID,
+				ToVersionID:   toVersionID,
+				Change:        ChangeModified,
+				Before:        previous,
+				After:         entity,
+				Fields:        fields,
+			})
+		}
+	}
+	for _, entity := range fromEntities {
+		if _, ok := after[entity.ID]; !ok {
+			diff.RemovedEntities = append(diff.RemovedEntities, entity)
+		}
+	}
+
+	beforeRels := make(map[relationshipKey]*Relationship, len(fromRelationships.Items))
+	for _, rel := range fromRelationships.Items {
+		beforeRels[relationshipKeyOf(rel)] = rel
+	}
+	afterRels := make(map[relationshipKey]*Relationship, len(toRelationships.Items))
+	for _, rel := range toRelationships.Items {
+		key := relationshipKeyOf(rel)
+		afterRels[key] = rel
+		previous, ok := beforeRels[key]
+		if !ok {
+			diff.AddedRelationships = append(diff.AddedRelationships, rel)
+			continue
+		}
+		if properties := diffFields(previous.Properties, rel.Properties); len(properties) > 0 {
+			diff.ModifiedRelationships = append(diff.ModifiedRelationships, &RelationshipChange{
+				Key:        key.String(),
+				Before:     previous,
+				After:      rel,
+				Properties: properties,
+			})
+		}
+	}
+	for _, rel := range fromRelationships.Items {
+		if _, ok := afterRels[relationshipKeyOf(rel)]; !ok {
+			diff.RemovedRelationships = append(diff.RemovedRelationships, rel)
+		}
+	}
+
+	sortEntities := func(entities []*Entity) {
+		sort.Slice(entities, func(i, j int) bool { return entities[i].ID < entities[j].ID })
+	}
+	sortRelationships := func(relationships []*Relationship) {
+		sort.Slice(relationships, func(i, j int) bool {
+			return relationshipKeyOf(relationships[i]).String() < relationshipKeyOf(relationships[j]).String()
+		})
+	}
+	sortEntities(diff.AddedEntities)
+	sortEntities(diff.RemovedEntities)
+	sort.Slice(diff.ModifiedEntities, func(i, j int) bool {
+		return diff.ModifiedEntities[i].LogicalID < diff.ModifiedEntities[j].LogicalID
+	})
+	sortRelationships(diff.AddedRelationships)
+	sortRelationships(diff.RemovedRelationships)
+	sort.Slice(diff.ModifiedRelationships, func(i, j int) bool {
+		return diff.ModifiedRelationships[i].Key < diff.ModifiedRelationships[j].Key
+	})
+
+	return diff, nil
+}
+
+// relationshipKeyOf keys a service relationship by its logical endpoints and type
+func relationshipKeyOf(rel *Relationship) relationshipKey {
+	return relationshipKey{From: rel.FromEntityID, To: rel.ToEntityID, Type: rel.RelationshipType}
+}
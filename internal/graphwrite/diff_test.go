@@ -0,0 +1,208 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestService_Diff(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	parentVersionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+	apply := func(parentVersionID string, deltas ...*Delta) string {
+		t.Helper()
+		response, err := service.Apply(ctx, &ApplyRequest{ParentVersionID: parentVersionID, Deltas: deltas})
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		return response.GraphVersionID
+	}
+	diff := func(fromVersionID, toVersionID string) *GraphDiff {
+		t.Helper()
+		result, err := service.Diff(ctx, fromVersionID, toVersionID)
+		if err != nil {
+			t.Fatalf("Diff failed: %v", err)
+		}
+		return result
+	}
+
+	t.Run("create only", func(t *testing.T) {
+		childVersionID := apply(parentVersionID,
+			&Delta{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}})
+
+		result := diff(parentVersionID, childVersionID)
+		if len(result.AddedEntities) != 1 || result.AddedEntities[0].ID != "tavern" {
+			t.Errorf("Expected tavern to be added, got %+v", result.AddedEntities)
+		}
+		// The copied scene and character have new rows but the same logical IDs
+		if len(result.RemovedEntities) != 0 || len(result.ModifiedEntities) != 0 {
+			t.Errorf("Expected copied entities to line up, got %d removed and %d modified", len(result.RemovedEntities), len(result.ModifiedEntities))
+		}
+		if len(result.AddedRelationships) != 0 || len(result.RemovedRelationships) != 0 || len(result.ModifiedRelationships) != 0 {
+			t.Errorf("Expected no relationship changes, got %+v", result)
+		}
+	})
+
+	t.Run("update only", func(t *testing.T) {
+		childVersionID := apply(parentVersionID,
+			&Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena Voss"}})
+
+		result := diff(parentVersionID, childVersionID)
+		if len(result.AddedEntities) != 0 || len(result.RemovedEntities) != 0 {
+			t.Errorf("Expected no added or removed entities, got %+v", result)
+		}
+		if len(result.ModifiedEntities) != 1 {
+			t.Fatalf("Expected elena to be modified, got %d modified entities", len(result.ModifiedEntities))
+		}
+		change := result.ModifiedEntities[0]
+		if change.LogicalID != "elena" || change.Change != ChangeModified {
+			t.Errorf("Expected a modification of elena, got %+v", change)
+		}
+		if len(change.Fields) != 1 || change.Fields[0].Field != "name" || change.Fields[0].Before != "Elena" || change.Fields[0].After != "Elena Voss" {
+			t.Errorf("Expected only the name to change, got %+v", change.Fields)
+		}
+	})
+
+	t.Run("delete only", func(t *testing.T) {
+		withTavernVersionID := apply(parentVersionID,
+			&Delta{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}})
+		childVersionID := apply(withTavernVersionID,
+			&Delta{Operation: "delete", EntityType: "Location", EntityID: "tavern"})
+
+		result := diff(withTavernVersionID, childVersionID)
+		if len(result.RemovedEntities) != 1 || result.RemovedEntities[0].ID != "tavern" {
+			t.Errorf("Expected tavern to be removed, got %+v", result.RemovedEntities)
+		}
+		if len(result.AddedEntities) != 0 || len(result.ModifiedEntities) != 0 {
+			t.Errorf("Expected no other entity changes, got %+v", result)
+		}
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		childVersionID := apply(parentVersionID,
+			&Delta{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}},
+			&Delta{
+				Operation:  "update",
+				EntityType: "Scene",
+				EntityID:   "scene-1",
+				Fields:     map[string]any{"name": "Opening", "title": "The Opening"},
+				Relationships: []*RelationshipDelta{
+					{Operation: "delete", FromEntityID: "scene-1", ToEntityID: "elena", RelationshipType: "features"},
+					{Operation: "create", FromEntityID: "scene-1", ToEntityID: "tavern", RelationshipType: "occurs_at", Properties: map[string]any{}},
+				},
+			},
+			&Delta{Operation: "delete", EntityType: "Character", EntityID: "elena"})
+
+		result := diff(parentVersionID, childVersionID)
+		if len(result.AddedEntities) != 1 || result.AddedEntities[0].ID != "tavern" {
+			t.Errorf("Expected tavern to be added, got %+v", result.AddedEntities)
+		}
+		if len(result.RemovedEntities) != 1 || result.RemovedEntities[0].ID != "elena" {
+			t.Errorf("Expected elena to be removed, got %+v", result.RemovedEntities)
+		}
+		if len(result.ModifiedEntities) != 1 || result.ModifiedEntities[0].LogicalID != "scene-1" {
+			t.Fatalf("Expected scene-1 to be modified, got %+v", result.ModifiedEntities)
+		}
+		if fields := result.ModifiedEntities[0].Fields; len(fields) != 1 || fields[0].Field != "title" {
+			t.Errorf("Expected only the title to change, got %+v", fields)
+		}
+
+		if len(result.AddedRelationships) != 1 || result.AddedRelationships[0].RelationshipType != "occurs_at" {
+			t.Errorf("Expected occurs_at to be added, got %+v", result.AddedRelationships)
+		}
+		if len(result.RemovedRelationships) != 1 || result.RemovedRelationships[0].RelationshipType != "features" {
+			t.Errorf("Expected features to be removed, got %+v", result.RemovedRelationships)
+		}
+	})
+
+	t.Run("relationship properties", func(t *testing.T) {
+		baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+		childVersionID := branchWithBondStrength(t, service, database, baseVersionID, "strong")
+
+		result := diff(baseVersionID, childVersionID)
+		if len(result.ModifiedRelationships) != 1 {
+			t.Fatalf("Expected one modified relationship, got %+v", result.ModifiedRelationships)
+		}
+		change := result.ModifiedRelationships[0]
+		if change.Key != "elena -allies_with-> marcus" {
+			t.Errorf("Expected the allies_with edge, got %s", change.Key)
+		}
+		if len(change.Properties) != 1 || change.Properties[0].Field != "bond_strength" || change.Properties[0].After != "strong" {
+			t.Errorf("Expected only bond_strength to change, got %+v", change.Properties)
+		}
+	})
+}
+
+func TestService_ApplyWithDiff(t *testing.T) {
+	for _, mode := range []struct {
+		name    string
+		options ServiceOptions
+	}{
+		{name: "copy", options: ServiceOptions{}},
+		{name: "copy-on-write", options: ServiceOptions{CopyOnWrite: true}},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			database := setupTestDB(t)
+			defer database.Close()
+
+			service := NewServiceWithOptions(database, mode.options)
+			ctx := context.Background()
+
+			projectID := createTestProject(t, database)
+			rootVersionID := createTestGraphVersion(t, database, projectID, true)
+			parentVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+			response, diff, err := service.ApplyWithDiff(ctx, &ApplyRequest{
+				ParentVersionID: parentVersionID,
+				Deltas: []*Delta{
+					{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena the Bold"}},
+					{Operation: "create", EntityType: "Location", EntityID: "harbor", Fields: map[string]any{"name": "Harbor"}},
+					{Operation: "delete", EntityType: "Character", EntityID: "marcus"},
+				},
+			})
+			if err != nil {
+				t.Fatalf("ApplyWithDiff failed: %v", err)
+			}
+			if response.Applied != 3 {
+				t.Errorf("Expected 3 deltas applied, got %d", response.Applied)
+			}
+
+			independent, err := service.Diff(ctx, parentVersionID, response.GraphVersionID)
+			if err != nil {
+				t.Fatalf("Diff failed: %v", err)
+			}
+			if !reflect.DeepEqual(diff, independent) {
+				t.Errorf("Expected the returned diff to match Diff, got %+v and %+v", diff, independent)
+			}
+
+			if diff.FromVersionID != parentVersionID || diff.ToVersionID != response.GraphVersionID {
+				t.Errorf("Expected a diff from the parent to the new version, got %s to %s", diff.FromVersionID, diff.ToVersionID)
+			}
+			if len(diff.AddedEntities) != 1 || len(diff.RemovedEntities) != 1 || len(diff.ModifiedEntities) != 1 || len(diff.RemovedRelationships) != 1 {
+				t.Errorf("Expected one added, removed and modified entity and one removed edge, got %+v", diff)
+			}
+		})
+	}
+
+	t.Run("failed apply", func(t *testing.T) {
+		database := setupTestDB(t)
+		defer database.Close()
+
+		service := NewService(database)
+		response, diff, err := service.ApplyWithDiff(context.Background(), &ApplyRequest{ParentVersionID: "missing", Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+		}})
+		if !errors.Is(err, ErrVersionNotFound) || response != nil || diff != nil {
+			t.Errorf("Expected ErrVersionNotFound and nothing else, got %v, %+v, %+v", err, response, diff)
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// CharacterDossier is the document ExportCharacterDossier renders: one character as it
+// stands in every book it appears in, oldest project first
+type CharacterDossier struct {
+	CharacterID string        `json:"character_id"`
+	Name        string        `json:"name"`
+	Books       []DossierBook `json:"books"`
+}
+
+// DossierBook is a character in one project's working set
+type DossierBook struct {
+	ProjectID   string `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	Series      string `json:"series,omitempty"`
+	VersionID   string `json:"version_id"`
+
+	// Revisions counts the project's versions that contain the character
+	Revisions int `json:"revisions"`
+
+	Fields          map[string]any        `json:"fields"`
+	Arc             types.CharacterArc    `json:"arc"`
+	Relationships   []DossierRelationship `json:"relationships"`
+	FirstAppearance *DossierScene         `json:"first_appearance,omitempty"`
+}
+
+// DossierRelationship is one of the character's relationships, seen from its side
+type DossierRelationship struct {
+	RelationshipType string `json:"relationship_type"`
+	EntityID         string `json:"entity_id"`
+	EntityName       string `json:"entity_name"`
+	Outgoing         bool   `json:"outgoing"`
+}
+
+// DossierScene is the scene that introduces the character in a book
+type DossierScene struct {
+	SceneID  string `json:"scene_id"`
+	Name     string `json:"name"`
+	Act      string `json:"act,omitempty"`
+	Sequence int    `json:"sequence,omitempty"`
+}
+
+// ExportCharacterDossier renders everything known about a character across every
+// project it appears in as an indented JSON CharacterDossier: its fields and arc, its
+// relationships, the scene introducing it and how many versions of each project hold
+// it. It returns an error when no project's working set has the character.
+func (s *Service) ExportCharacterDossier(ctx context.Context, logicalID string) ([]byte, error) {
+	history, err := s.GetEntityHistory(ctx, logicalID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.VersionsContaining(ctx, logicalID)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make(map[string]int)
+	for _, version := range versions {
+		revisions[version.ProjectID]++
+	}
+
+	type book struct {
+		DossierBook
+		createdAt string
+	}
+	var books []book
+	for _, entry := range history {
+		if entry.Entity.EntityType != string(types.EntityTypeCharacter) {
+			continue
+		}
+
+		project, err := s.db.Queries().GetProject(ctx, entry.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get project: %w", err)
+		}
+
+		dossierBook, err := s.dossierBook(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+		dossierBook.Series = project.Series.String
+		dossierBook.Revisions = revisions[entry.ProjectID]
+		books = append(books, book{DossierBook: *dossierBook, createdAt: project.CreatedAt.Format("2006-01-02T15:04:05.000000000")})
+	}
+	if len(books) == 0 {
+		return nil, fmt.Errorf("character %s not found in any project", logicalID)
+	}
+
+	sort.Slice(books, func(i, j int) bool {
+		if books[i].createdAt != books[j].createdAt {
+			return books[i].createdAt < books[j].createdAt
+		}
+		if books[i].ProjectName != books[j].ProjectName {
+			return books[i].ProjectName < books[j].ProjectName
+		}
+		return books[i].ProjectID < books[j].ProjectID
+	})
+
+	dossier := CharacterDossier{CharacterID: logicalID, Books: make([]DossierBook, 0, len(books))}
+	for _, book := range books {
+		dossier.Books = append(dossier.Books, book.DossierBook)
+	}
+	dossier.Name, _ = dossier.Books[len(dossier.Books)-1].Fields["name"].(string)
+
+	output, err := json.MarshalIndent(dossier, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dossier: %w", err)
+	}
+	return output, nil
+}
+
+// dossierBook gathers a character's entry in one project's working set
+func (s *Service) dossierBook(ctx context.Context, entry *EntityVersion) (*DossierBook, error) {
+	logicalID := entry.Entity.ID
+
+	fields := make(map[string]any, len(entry.Entity.Data))
+	for name, value := range entry.Entity.Data {
+		if name != "logical_id" {
+			fields[name] = value
+		}
+	}
+
+	profile, err := s.GetCharacterProfile(ctx, entry.VersionID, logicalID)
+	if err != nil {
+		return nil, err
+	}
+
+	relations, err := s.characterRelations(ctx, entry.VersionID, logicalID)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.entityNamesInVersion(ctx, entry.VersionID)
+	if err != nil {
+		return nil, err
+	}
+	relationships := make([]DossierRelationship, 0, len(relations))
+	for _, relation := range relations {
+		relationships = append(relationships, DossierRelationship{
+			RelationshipType: relation.RelationshipType,
+			EntityID:         relation.EntityID,
+			EntityName:       names[relation.EntityID],
+			Outgoing:         relation.Outgoing,
+		})
+	}
+	sort.Slice(relationships, func(i, j int) bool {
+		if relationships[i].RelationshipType != relationships[j].RelationshipType {
+			return relationships[i].RelationshipType < relationships[j].RelationshipType
+		}
+		return relationships[i].EntityID < relationships[j].EntityID
+	})
+
+	book := &DossierBook{
+		ProjectID:     entry.ProjectID,
+		ProjectName:   entry.ProjectName,
+		VersionID:     entry.VersionID,
+		Fields:        fields,
+		Arc:           profile.CharacterArc,
+		Relationships: relationships,
+	}
+
+	scene, err := s.FirstAppearance(ctx, entry.VersionID, logicalID)
+	if err != nil {
+		return nil, err
+	}
+	if scene != nil {
+		book.FirstAppearance = &DossierScene{
+			SceneID:  scene.ID,
+			Name:     scene.Name,
+			Act:      arcAct(scene.Data["act"]),
+			Sequence: arcSequence(scene.Data["sequence"]),
+		}
+	}
+
+	return book, nil
+}
+
+// entityNamesInVersion maps a version's logical entity IDs to names
+func (s *Service) entityNamesInVersion(ctx context.Context, versionID string) (map[string]string, error) {
+	entities, err := s.ListEntities(ctx, versionID, EntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(entities))
+	for _, entity := range entities {
+		names[entity.ID] = entity.Name
+	}
+	return names, nil
+}
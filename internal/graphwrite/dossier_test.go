@@ -0,0 +1,90 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestService_ExportCharacterDossier(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	const series = "The Chronicles of Elena Stormwind"
+	opening := &Delta{Operation: "create", EntityType: "Scene", EntityID: "arrival", Fields: map[string]any{"name": "Arrival", "act": 1, "sequence": 1}, Relationships: []*RelationshipDelta{
+		{Operation: "create", FromEntityID: "arrival", ToEntityID: "elena", RelationshipType: "features", Properties: map[string]any{}},
+	}}
+	alliance := &Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{}, Relationships: []*RelationshipDelta{
+		{Operation: "create", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "allies_with", Properties: map[string]any{}},
+	}}
+
+	book1 := createSagaBook(t, service, database, series, []*Delta{sagaElena(1), opening})
+	book2 := createSagaBook(t, service, database, series, []*Delta{sagaElena(4), sagaCitadel("torchlit")})
+	book3 := createSagaBook(t, service, database, series, []*Delta{sagaElena(7), sagaMarcus(), alliance})
+
+	// A later edit to Book 3 adds a second revision holding Elena
+	workingSet := workingSetID(t, database, book3)
+	if _, err := service.ApplyAndAdvance(ctx, &ApplyRequest{ParentVersionID: workingSet, Deltas: []*Delta{
+		{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"character_arc": map[string]any{"current_state": "queen"}}},
+	}}); err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	output, err := service.ExportCharacterDossier(ctx, "elena")
+	if err != nil {
+		t.Fatalf("ExportCharacterDossier failed: %v", err)
+	}
+
+	var dossier CharacterDossier
+	if err := json.Unmarshal(output, &dossier); err != nil {
+		t.Fatalf("Failed to parse dossier: %v", err)
+	}
+	if dossier.CharacterID != "elena" || dossier.Name != "Elena Stormwind" {
+		t.Errorf("Expected Elena Stormwind's dossier, got %s (%s)", dossier.CharacterID, dossier.Name)
+	}
+	if len(dossier.Books) != 3 {
+		t.Fatalf("Expected the dossier to span 3 books, got %d", len(dossier.Books))
+	}
+
+	books := make(map[string]DossierBook, len(dossier.Books))
+	for _, book := range dossier.Books {
+		books[book.ProjectID] = book
+		if book.Series != series {
+			t.Errorf("Expected %s to be in the series, got %q", book.ProjectID, book.Series)
+		}
+	}
+
+	for projectID, level := range map[string]float64{book1: 1, book2: 4, book3: 7} {
+		if got := books[projectID].Fields["level"]; got != level {
+			t.Errorf("Expected level %v in %s, got %v", level, projectID, got)
+		}
+		if _, leaked := books[projectID].Fields["logical_id"]; leaked {
+			t.Errorf("Expected the logical ID to stay out of the fields in %s", projectID)
+		}
+	}
+
+	if first := books[book1].FirstAppearance; first == nil || first.SceneID != "arrival" || first.Act != "1" {
+		t.Errorf("Expected Elena to be introduced in the arrival scene of Book 1, got %+v", first)
+	}
+	if books[book2].FirstAppearance != nil {
+		t.Errorf("Expected no introduction in Book 2, got %+v", books[book2].FirstAppearance)
+	}
+
+	relationships := books[book3].Relationships
+	if len(relationships) != 1 || relationships[0].EntityName != "Marcus Ironforge" || !relationships[0].Outgoing {
+		t.Errorf("Expected Elena's alliance with Marcus in Book 3, got %+v", relationships)
+	}
+	if books[book3].Arc.CurrentState != "queen" || books[book3].Revisions != 2 {
+		t.Errorf("Expected Book 3's arc and 2 revisions, got %+v", books[book3])
+	}
+	if books[book1].Revisions != 1 {
+		t.Errorf("Expected 1 revision of Book 1, got %d", books[book1].Revisions)
+	}
+
+	if _, err := service.ExportCharacterDossier(ctx, "nobody"); err == nil {
+		t.Error("Expected an error for a character in no project")
+	}
+}
@@ -0,0 +1,165 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// StoreEmbedding saves an embedding vector for an entity in a version, replacing any
+// previous one. Vectors come from the caller; the service never computes them.
+func (s *Service) StoreEmbedding(ctx context.Context, versionID, logicalID string, vector []float32) error {
+	if len(vector) == 0 {
+		return fmt.Errorf("embedding for %s is empty", logicalID)
+	}
+	if _, err := s.db.Queries().GetGraphVersion(ctx, versionID); err != nil {
+		return fmt.Errorf("%w: %w", ErrVersionNotFound, err)
+	}
+	if _, err := s.findEntityInVersion(ctx, versionID, logicalID); err != nil {
+		return err
+	}
+
+	if err := s.db.Queries().UpsertEmbedding(ctx, db.UpsertEmbeddingParams{
+		VersionID:  versionID,
+		LogicalID:  logicalID,
+		Dimensions: int64(len(vector)),
+		Vector:     encodeVector(vector),
+	}); err != nil {
+		return fmt.Errorf("failed to store embedding of %s: %w", logicalID, err)
+	}
+	return nil
+}
+
+// FindSimilarEntities returns up to k entities of a version most similar to the given
+// entity by cosine similarity of their embeddings, most similar first, with logical
+// IDs breaking ties. An entity without an embedding in the version uses the one from
+// its nearest ancestor that has it, so embeddings survive later edits. Entities whose
+// vectors have a different number of dimensions, or no length, are not comparable and
+// are left out. Fails with ErrNoEmbedding when the entity itself has no embedding.
+func (s *Service) FindSimilarEntities(ctx context.Context, versionID, logicalID string, k int) ([]*Entity, error) {
+	entities, err := s.ListEntities(ctx, versionID, EntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Entity, len(entities))
+	for _, entity := range entities {
+		byID[entity.ID] = entity
+	}
+	if byID[logicalID] == nil {
+		return nil, fmt.Errorf("%w: %s is not in version %s", ErrEntityNotFound, logicalID, versionID)
+	}
+
+	vectors, err := s.embeddingsInVersion(ctx, versionID, byID)
+	if err != nil {
+		return nil, err
+	}
+	query, exists := vectors[logicalID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNoEmbedding, logicalID)
+	}
+	if k <= 0 {
+		return []*Entity{}, nil
+	}
+
+	type scored struct {
+		id    string
+		score float64
+	}
+	var candidates []scored
+	for id, vector := range vectors {
+		if id == logicalID {
+			continue
+		}
+		if score, ok := cosineSimilarity(query, vector); ok {
+			candidates = append(candidates, scored{id, score})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].id < candidates[j].id
+	})
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+
+	result := make([]*Entity, len(candidates))
+	for i, candidate := range candidates {
+		result[i] = byID[candidate.id]
+	}
+	return result, nil
+}
+
+// embeddingsInVersion collects the vectors of the given entities, walking up from the
+// version until each has the one stored nearest to it or the chain ends
+func (s *Service) embeddingsInVersion(ctx context.Context, versionID string, entities map[string]*Entity) (map[string][]float32, error) {
+	vectors := make(map[string][]float32, len(entities))
+	err := s.walkVersionChain(ctx, versionID, func(version db.GraphVersion) (bool, error) {
+		rows, err := s.db.Queries().ListEmbeddingsByVersion(ctx, version.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to list embeddings: %w", err)
+		}
+		for _, row := range rows {
+			if _, wanted := entities[row.LogicalID]; !wanted {
+				continue
+			}
+			if _, found := vectors[row.LogicalID]; found {
+				continue
+			}
+			vector, err := decodeVector(row.Vector, int(row.Dimensions))
+			if err != nil {
+				return false, fmt.Errorf("embedding of %s: %w", row.LogicalID, err)
+			}
+			vectors[row.LogicalID] = vector
+		}
+		return len(vectors) < len(entities), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// encodeVector packs a vector as little-endian float32 values
+func encodeVector(vector []float32) []byte {
+	encoded := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(encoded[4*i:], math.Float32bits(value))
+	}
+	return encoded
+}
+
+// decodeVector unpacks a vector written by encodeVector
+func decodeVector(encoded []byte, dimensions int) ([]float32, error) {
+	if len(encoded) != 4*dimensions {
+		return nil, fmt.Errorf("%d bytes do not hold %d dimensions", len(encoded), dimensions)
+	}
+	vector := make([]float32, dimensions)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(encoded[4*i:]))
+	}
+	return vector, nil
+}
+
+// cosineSimilarity compares two vectors, reporting false when they differ in length
+// or either has no magnitude
+func cosineSimilarity(a, b []float32) (float64, bool) {
+	if len(a) != len(b) {
+		return 0, false
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0, false
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB)), true
+}
@@ -0,0 +1,89 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestService_FindSimilarEntities(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createCastVersion(t, service, rootVersionID, 5)
+
+	vectors := map[string][]float32{
+		"character-00": {1, 0, 0},
+		"character-01": {0.9, 0.1, 0},   // nearly the same direction
+		"character-02": {2, 2, 0},       // 45 degrees away, magnitude ignored
+		"character-03": {0, 0, 1},       // orthogonal
+		"character-04": {-1, 0, 0},      // opposite
+		"scene-1":      {1, 0, 0, 0, 0}, // other dimensions, not comparable
+	}
+	for logicalID, vector := range vectors {
+		if err := service.StoreEmbedding(ctx, versionID, logicalID, vector); err != nil {
+			t.Fatalf("StoreEmbedding failed for %s: %v", logicalID, err)
+		}
+	}
+
+	ids := func(entities []*Entity) []string {
+		result := make([]string, len(entities))
+		for i, entity := range entities {
+			result[i] = entity.ID
+		}
+		return result
+	}
+
+	similar, err := service.FindSimilarEntities(ctx, versionID, "character-00", 10)
+	if err != nil {
+		t.Fatalf("FindSimilarEntities failed: %v", err)
+	}
+	want := []string{"character-01", "character-02", "character-03", "character-04"}
+	if got := ids(similar); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] || got[3] != want[3] {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	top, err := service.FindSimilarEntities(ctx, versionID, "character-00", 2)
+	if err != nil {
+		t.Fatalf("FindSimilarEntities failed: %v", err)
+	}
+	if got := ids(top); len(got) != 2 || got[0] != "character-01" || got[1] != "character-02" {
+		t.Errorf("Expected the 2 nearest, got %v", got)
+	}
+
+	// A later version inherits the embeddings and can override one
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: versionID,
+		Deltas:          []*Delta{{Operation: "update", EntityType: "Character", EntityID: "character-03", Fields: map[string]any{"name": "Renamed"}}},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if err := service.StoreEmbedding(ctx, response.GraphVersionID, "character-03", []float32{1, 0, 0}); err != nil {
+		t.Fatalf("StoreEmbedding failed: %v", err)
+	}
+	child, err := service.FindSimilarEntities(ctx, response.GraphVersionID, "character-00", 1)
+	if err != nil {
+		t.Fatalf("FindSimilarEntities failed: %v", err)
+	}
+	if got := ids(child); len(got) != 1 || got[0] != "character-03" {
+		t.Errorf("Expected the re-embedded character-03 to be nearest, got %v", got)
+	}
+
+	if _, err := service.FindSimilarEntities(ctx, rootVersionID, "character-00", 3); !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("Expected ErrEntityNotFound outside the version, got %v", err)
+	}
+	if err := service.StoreEmbedding(ctx, versionID, "missing", []float32{1}); !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("Expected ErrEntityNotFound storing for a missing entity, got %v", err)
+	}
+
+	unembedded := createCastVersion(t, service, rootVersionID, 1)
+	if _, err := service.FindSimilarEntities(ctx, unembedded, "character-00", 3); !errors.Is(err, ErrNoEmbedding) {
+		t.Errorf("Expected ErrNoEmbedding, got %v", err)
+	}
+}
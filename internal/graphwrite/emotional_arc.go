@@ -0,0 +1,116 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// ArcPoint is one scene's position on a story's emotional curve
+type ArcPoint struct {
+	SceneID  string // logical ID
+	Name     string
+	Act      string
+	Sequence int
+
+	// Sentiment and ImpactScore come from the scene's latest emotional analysis.
+	// Both are nil when the scene has not been analysed, leaving a gap in the curve.
+	Sentiment   *float64
+	ImpactScore *float64
+}
+
+// EmotionalArc returns one point per scene in narrative order (act, then sequence)
+// with the sentiment and impact of each scene's latest emotional analysis
+func (s *Service) EmotionalArc(ctx context.Context, versionID string) ([]ArcPoint, error) {
+	sceneType := string(types.EntityTypeScene)
+	scenes, err := s.ListEntities(ctx, versionID, EntityFilter{EntityType: &sceneType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scenes: %w", err)
+	}
+
+	entityIDMapping, err := s.logicalIDsByDatabaseID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	databaseIDs := make(map[string]string, len(entityIDMapping))
+	for databaseID, logicalID := range entityIDMapping {
+		databaseIDs[logicalID] = databaseID
+	}
+
+	points := make([]ArcPoint, 0, len(scenes))
+	for _, scene := range scenes {
+		point := ArcPoint{
+			SceneID:  scene.ID,
+			Name:     scene.Name,
+			Act:      arcAct(scene.Data["act"]),
+			Sequence: arcSequence(scene.Data["sequence"]),
+		}
+
+		annotations, err := s.latestAnnotationsByType(ctx, databaseIDs[scene.ID], scene.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, annotation := range annotations {
+			if annotation.AnnotationType != string(types.AnnotationEmotionalAnalysis) {
+				continue
+			}
+			point.Sentiment = metadataFloat(annotation.Metadata, "sentiment")
+			point.ImpactScore = metadataFloat(annotation.Metadata, "impact_score")
+		}
+
+		points = append(points, point)
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		if points[i].Act != points[j].Act {
+			return actLess(points[i].Act, points[j].Act)
+		}
+		if points[i].Sequence != points[j].Sequence {
+			return points[i].Sequence < points[j].Sequence
+		}
+		return points[i].SceneID < points[j].SceneID
+	})
+
+	return points, nil
+}
+
+// arcAct normalises a scene's act field, which may be stored as a string or a number
+func arcAct(value any) string {
+	switch act := value.(type) {
+	case string:
+		return act
+	case float64:
+		return strconv.FormatFloat(act, 'f', -1, 64)
+	}
+	return ""
+}
+
+// arcSequence reads a scene's sequence field, defaulting to zero
+func arcSequence(value any) int {
+	if sequence, ok := value.(float64); ok {
+		return int(sequence)
+	}
+	return 0
+}
+
+// actLess orders acts numerically when both are numbers and lexically otherwise
+func actLess(a, b string) bool {
+	numA, errA := strconv.Atoi(a)
+	numB, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return numA < numB
+	}
+	return a < b
+}
+
+// metadataFloat reads a numeric metadata value, returning nil when absent
+func metadataFloat(metadata map[string]any, key string) *float64 {
+	value, ok := metadata[key].(float64)
+	if !ok {
+		return nil
+	}
+	return &value
+}
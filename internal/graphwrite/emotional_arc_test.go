@@ -0,0 +1,89 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+)
+
+func TestService_EmotionalArc(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	scene := func(id string, act any, sequence int) *Delta {
+		return &Delta{
+			Operation:  "create",
+			EntityType: "Scene",
+			EntityID:   id,
+			Fields:     map[string]any{"name": id, "act": act, "sequence": sequence},
+		}
+	}
+
+	// Created out of narrative order; act 10 must sort after act 2
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			scene("finale", "10", 1),
+			scene("midpoint", "2", 1),
+			scene("inciting", "1", 2),
+			scene("opening", 1, 1),
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	versionID := response.GraphVersionID
+
+	emotion := func(sentiment, impact float64) map[string]any {
+		return map[string]any{"sentiment": sentiment, "impact_score": impact}
+	}
+	createTestAnnotation(t, database, databaseIDForEntity(t, database, versionID, "opening"), "emotional_analysis", emotion(0.2, 0.3))
+	createTestAnnotation(t, database, databaseIDForEntity(t, database, versionID, "midpoint"), "emotional_analysis", emotion(-0.6, 0.8))
+	createTestAnnotation(t, database, databaseIDForEntity(t, database, versionID, "finale"), "emotional_analysis", emotion(0.9, 1.0))
+	// A non-emotional annotation does not count
+	createTestAnnotation(t, database, databaseIDForEntity(t, database, versionID, "inciting"), "thematic_score", nil)
+
+	points, err := service.EmotionalArc(ctx, versionID)
+	if err != nil {
+		t.Fatalf("EmotionalArc failed: %v", err)
+	}
+
+	expected := []struct {
+		sceneID   string
+		sentiment *float64
+		impact    *float64
+	}{
+		{"opening", floatPtr(0.2), floatPtr(0.3)},
+		{"inciting", nil, nil},
+		{"midpoint", floatPtr(-0.6), floatPtr(0.8)},
+		{"finale", floatPtr(0.9), floatPtr(1.0)},
+	}
+	if len(points) != len(expected) {
+		t.Fatalf("Expected %d arc points, got %d", len(expected), len(points))
+	}
+	for i, want := range expected {
+		got := points[i]
+		if got.SceneID != want.sceneID {
+			t.Errorf("Point %d: expected scene %s, got %s", i, want.sceneID, got.SceneID)
+			continue
+		}
+		if !equalFloatPtr(got.Sentiment, want.sentiment) || !equalFloatPtr(got.ImpactScore, want.impact) {
+			t.Errorf("Point %s: unexpected values %v/%v", got.SceneID, got.Sentiment, got.ImpactScore)
+		}
+	}
+}
+
+func floatPtr(f float64) *float64 { return &f }
+
+func equalFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,59 @@
+package graphwrite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// DefaultEntityValidators returns validators for the built-in entity types, suitable
+// for ServiceOptions.EntityValidators: scenes need a title and every other type a
+// name, and every field must have the type its typed data structure gives it. The
+// map is new on each call, so callers may add or replace entries.
+func DefaultEntityValidators() map[string]func(fields map[string]any) error {
+	return map[string]func(fields map[string]any) error{
+		string(types.EntityTypeScene):     SchemaValidator(types.EntityTypeScene, "title"),
+		string(types.EntityTypeCharacter): SchemaValidator(types.EntityTypeCharacter, "name"),
+		string(types.EntityTypeLocation):  SchemaValidator(types.EntityTypeLocation, "name"),
+		string(types.EntityTypeTheme):     SchemaValidator(types.EntityTypeTheme, "name"),
+		string(types.EntityTypePlotPoint): SchemaValidator(types.EntityTypePlotPoint, "name"),
+		string(types.EntityTypeArc):       SchemaValidator(types.EntityTypeArc, "name"),
+	}
+}
+
+// SchemaValidator builds an entity validator requiring the given fields to be present
+// and non-empty and checking every field against the entity type's schema (see
+// types.ValidateEntityFields). The error lists every problem found.
+func SchemaValidator(entityType types.EntityType, required ...string) func(fields map[string]any) error {
+	return func(fields map[string]any) error {
+		var problems []string
+		for _, field := range required {
+			if value, exists := fields[field]; !exists || value == nil || value == "" {
+				problems = append(problems, field+": required")
+			}
+		}
+		for _, fieldError := range types.ValidateEntityFields(entityType, fields) {
+			problems = append(problems, fieldError.Error())
+		}
+
+		if len(problems) > 0 {
+			return errors.New(strings.Join(problems, "; "))
+		}
+		return nil
+	}
+}
+
+// validateEntityData runs the registered validator for an entity type, if any, on
+// the data about to be stored
+func (s *Service) validateEntityData(entityType, logicalID string, fields map[string]any) error {
+	validate, exists := s.options.EntityValidators[entityType]
+	if !exists {
+		return nil
+	}
+	if err := validate(fields); err != nil {
+		return fmt.Errorf("%w: %s %s: %w", ErrInvalidEntityData, entityType, logicalID, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestService_Apply_EntityValidation(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	validators := DefaultEntityValidators()
+	validators["Faction"] = func(fields map[string]any) error {
+		if _, exists := fields["allegiance"]; !exists {
+			return fmt.Errorf("allegiance: required")
+		}
+		return nil
+	}
+	service := NewServiceWithOptions(database, ServiceOptions{EntityValidators: validators})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	base, err := service.Apply(ctx, &ApplyRequest{ParentVersionID: rootVersionID, Deltas: []*Delta{
+		{Operation: "create", EntityType: "Scene", EntityID: "opening", Fields: map[string]any{"title": "Opening", "sequence": 1}},
+		{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "role": "protagonist"}},
+	}})
+	if err != nil {
+		t.Fatalf("Expected valid entities to be stored, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		delta   *Delta
+		mention string
+	}{
+		{
+			name:    "scene without a title",
+			delta:   &Delta{Operation: "create", EntityType: "Scene", EntityID: "untitled", Fields: map[string]any{"summary": "Something happens"}},
+			mention: "title: required",
+		},
+		{
+			name:    "character with a numeric role",
+			delta:   &Delta{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus", "role": 7}},
+			mention: "role: expected string, got integer",
+		},
+		{
+			name:    "update unsetting the scene title",
+			delta:   &Delta{Operation: "update", EntityType: "Scene", EntityID: "opening", Fields: map[string]any{}, UnsetFields: []string{"title"}},
+			mention: "title: required",
+		},
+		{
+			name:    "update giving the character a numeric name",
+			delta:   &Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": 42}},
+			mention: "name: expected string, got integer",
+		},
+		{
+			name:    "registered custom type",
+			delta:   &Delta{Operation: "create", EntityType: "Faction", EntityID: "guild", Fields: map[string]any{"name": "Guild"}},
+			mention: "allegiance: required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Apply(ctx, &ApplyRequest{ParentVersionID: base.GraphVersionID, Deltas: []*Delta{tt.delta}})
+			if !errors.Is(err, ErrInvalidEntityData) {
+				t.Fatalf("Expected ErrInvalidEntityData, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.mention) || !strings.Contains(err.Error(), tt.delta.EntityID) {
+				t.Errorf("Expected the error to name %s and %q, got %v", tt.delta.EntityID, tt.mention, err)
+			}
+		})
+	}
+
+	// Types without a validator accept any data
+	if _, err := service.Apply(ctx, &ApplyRequest{ParentVersionID: base.GraphVersionID, Deltas: []*Delta{
+		{Operation: "create", EntityType: "Note", EntityID: "note", Fields: map[string]any{"name": 3}},
+	}}); err != nil {
+		t.Errorf("Expected an unregistered type to be accepted, got %v", err)
+	}
+
+	// Without validators nothing is checked
+	unchecked := NewService(database)
+	if _, err := unchecked.Apply(ctx, &ApplyRequest{ParentVersionID: base.GraphVersionID, Deltas: []*Delta{
+		{Operation: "create", EntityType: "Scene", EntityID: "untitled", Fields: map[string]any{"summary": "Something happens"}},
+	}}); err != nil {
+		t.Errorf("Expected no validation by default, got %v", err)
+	}
+}
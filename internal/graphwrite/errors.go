@@ -0,0 +1,99 @@
+package graphwrite
+
+import "errors"
+
+// ErrVersionNotFound is returned when a graph version does not exist
+var ErrVersionNotFound = errors.New("version not found")
+
+// ErrEntityNotFound is returned when a logical entity is not in the version or
+// project it is looked up in
+var ErrEntityNotFound = errors.New("entity not found")
+
+// ErrNoDeltas is returned when Apply is given no deltas
+var ErrNoDeltas = errors.New("no deltas provided")
+
+// ErrUnknownOperation is returned when a delta or relationship delta has an operation
+// other than create, update or delete
+var ErrUnknownOperation = errors.New("unknown operation")
+
+// ErrSelfRelationship is returned when a relationship would connect an entity to itself
+// and ServiceOptions.AllowSelfRelationships is not set
+var ErrSelfRelationship = errors.New("relationship connects an entity to itself")
+
+// ErrCrossVersionRelationship is returned when a relationship endpoint resolves to an
+// entity row belonging to a different version than the relationship
+var ErrCrossVersionRelationship = errors.New("relationship endpoint belongs to another version")
+
+// ErrInvalidProjectStatus is returned when project metadata names an unknown status
+var ErrInvalidProjectStatus = errors.New("invalid project status")
+
+// ErrEntityNotDeleted is returned when restoring an entity that is still present
+var ErrEntityNotDeleted = errors.New("entity is not deleted")
+
+// ErrNoVersionAsOf is returned when a project has no version created at or before the requested time
+var ErrNoVersionAsOf = errors.New("no version exists at that time")
+
+// ErrTagNotFound is returned when a project has no version with the requested tag
+var ErrTagNotFound = errors.New("version tag not found")
+
+// ErrTagExists is returned when tagging a version with a tag the project already uses
+var ErrTagExists = errors.New("version tag already exists")
+
+// ErrInvalidProjectName is returned when a project name is empty
+var ErrInvalidProjectName = errors.New("invalid project name")
+
+// ErrDuplicateProjectName is returned when another project already uses a name
+var ErrDuplicateProjectName = errors.New("project name already in use")
+
+// ErrEntityModified is returned when an update's expected content hash no longer
+// matches the entity, meaning someone else changed it first
+var ErrEntityModified = errors.New("entity was modified concurrently")
+
+// ErrInvalidRelationshipEndpoints is returned when a relationship connects entity types
+// its relationship type does not allow (see types.ValidateRelationshipEndpoints)
+var ErrInvalidRelationshipEndpoints = errors.New("relationship endpoints have invalid entity types")
+
+// ErrProjectNotOwned is returned when the context's owner (see WithOwner) reaches for
+// a project belonging to someone else
+var ErrProjectNotOwned = errors.New("project belongs to another owner")
+
+// ErrVersionNotInProject is returned when a version from one project is used where
+// a version of another project is required
+var ErrVersionNotInProject = errors.New("version belongs to another project")
+
+// ErrAmbiguousEntity is returned when a lookup without a version finds the logical
+// entity in more than one project's working set
+var ErrAmbiguousEntity = errors.New("entity is in more than one working set")
+
+// ErrMixedSeries is returned when ValidateSeries is given books from different series
+var ErrMixedSeries = errors.New("projects belong to different series")
+
+// ErrConcurrentModification is returned when an apply's ApplyRequest.ExpectedWorkingSetVersionID
+// is no longer the project's working set, meaning another writer advanced it first
+var ErrConcurrentModification = errors.New("working set was advanced concurrently")
+
+// ErrNoWorkingSet is returned when applying to a project that has no working set
+var ErrNoWorkingSet = errors.New("project has no working set")
+
+// ErrChainTooDeep is returned when walking a version's ancestry passes
+// ServiceOptions.MaxChainDepth
+var ErrChainTooDeep = errors.New("version chain is too deep")
+
+// ErrVersionCycle is returned when a version's parent links lead back to itself
+var ErrVersionCycle = errors.New("version chain has a cycle")
+
+// ErrInvalidEntityData is returned when an entity's data fails the validator registered
+// for its type in ServiceOptions.EntityValidators
+var ErrInvalidEntityData = errors.New("invalid entity data")
+
+// ErrNoEmbedding is returned when a similarity search starts from an entity without a
+// stored embedding in the version or its ancestors
+var ErrNoEmbedding = errors.New("entity has no embedding")
+
+// ErrRelationshipNotFound is returned when a relationship update addresses an edge by
+// its endpoints and type and the version has no such edge
+var ErrRelationshipNotFound = errors.New("relationship not found")
+
+// ErrNotAncestor is returned when a version range's start is not an ancestor of its
+// end, so the two versions are not on the same line of history
+var ErrNotAncestor = errors.New("version is not an ancestor")
@@ -0,0 +1,114 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// FieldHistory returns each change to one data field of an entity along the chain of
+// versions from the project's root to its working set, oldest first. Versions where
+// the value is unchanged are skipped; a change's Before or After is nil where the
+// entity or field is absent.
+func (s *Service) FieldHistory(ctx context.Context, projectID, logicalID, field string) ([]FieldChange, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	chain, err := s.workingSetChain(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []FieldChange{}
+	var previous any
+	for _, version := range chain {
+		entity, err := s.entityInVersion(ctx, version.ID, logicalID)
+		if err != nil {
+			return nil, err
+		}
+		var value any
+		if entity != nil {
+			value = entity.Data[field]
+		}
+
+		if !reflect.DeepEqual(value, previous) {
+			changes = append(changes, FieldChange{
+				Field:     field,
+				Before:    previous,
+				After:     value,
+				VersionID: version.ID,
+				ChangedAt: version.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			})
+			previous = value
+		}
+	}
+
+	return changes, nil
+}
+
+// GetEntityHistoryInProject returns an entity's state at every version along the chain
+// from the project's root to its working set, oldest first, skipping versions where
+// the entity is absent
+func (s *Service) GetEntityHistoryInProject(ctx context.Context, projectID, logicalEntityID string) ([]*EntityVersion, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	project, err := s.db.Queries().GetProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	chain, err := s.workingSetChain(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := []*EntityVersion{}
+	for _, version := range chain {
+		entity, err := s.entityInVersion(ctx, version.ID, logicalEntityID)
+		if err != nil {
+			return nil, err
+		}
+		if entity == nil {
+			continue
+		}
+
+		history = append(history, &EntityVersion{
+			Entity:             entity,
+			ProjectID:          project.ID,
+			ProjectName:        project.Name,
+			VersionID:          version.ID,
+			VersionName:        version.Name.String,
+			VersionDescription: version.Description.String,
+			CreatedAt:          version.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	return history, nil
+}
+
+// workingSetChain returns the versions from a project's root to its working set,
+// following parent links, oldest first
+func (s *Service) workingSetChain(ctx context.Context, projectID string) ([]db.GraphVersion, error) {
+	workingSet, err := s.db.Queries().GetWorkingSetVersion(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working set: %w", err)
+	}
+
+	// Walk back to the root, then reverse to replay the chain forwards
+	chain := []db.GraphVersion{}
+	err = s.walkVersionChain(ctx, workingSet.ID, func(version db.GraphVersion) (bool, error) {
+		chain = append(chain, version)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+
+	return chain, nil
+}
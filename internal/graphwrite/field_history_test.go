@@ -0,0 +1,124 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+)
+
+func TestService_FieldHistory(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	versionID := createTestGraphVersion(t, database, projectID, true)
+
+	advance := func(delta *Delta) string {
+		t.Helper()
+		response, err := service.ApplyAndAdvance(ctx, &ApplyRequest{ParentVersionID: versionID, Deltas: []*Delta{delta}})
+		if err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+		versionID = response.GraphVersionID
+		return versionID
+	}
+	elena := func(operation string, name string, level float64) *Delta {
+		return &Delta{Operation: operation, EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": name, "level": level}}
+	}
+
+	created := advance(elena("create", "Elena", 1))
+	advance(&Delta{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}})
+	levelledUp := advance(elena("update", "Elena", 2))
+	advance(elena("update", "Elena Voss", 2))
+	levelledAgain := advance(elena("update", "Elena Voss", 5))
+
+	history, err := service.FieldHistory(ctx, projectID, "elena", "level")
+	if err != nil {
+		t.Fatalf("FieldHistory failed: %v", err)
+	}
+
+	expected := []FieldChange{
+		{Field: "level", Before: nil, After: 1.0, VersionID: created},
+		{Field: "level", Before: 1.0, After: 2.0, VersionID: levelledUp},
+		{Field: "level", Before: 2.0, After: 5.0, VersionID: levelledAgain},
+	}
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), history)
+	}
+	for i, change := range history {
+		want := expected[i]
+		if change.Field != want.Field || change.Before != want.Before || change.After != want.After || change.VersionID != want.VersionID {
+			t.Errorf("Change %d: expected %+v, got %+v", i, want, change)
+		}
+		if change.ChangedAt == "" {
+			t.Errorf("Change %d: expected a timestamp", i)
+		}
+	}
+
+	// The rename shows up only in the name field's history
+	names, err := service.FieldHistory(ctx, projectID, "elena", "name")
+	if err != nil {
+		t.Fatalf("FieldHistory failed: %v", err)
+	}
+	if len(names) != 2 || names[1].After != "Elena Voss" {
+		t.Errorf("Expected the name to be set then renamed, got %+v", names)
+	}
+}
+
+func TestService_GetEntityHistoryInProject(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	versionID := createTestGraphVersion(t, database, projectID, true)
+
+	advance := func(deltas ...*Delta) string {
+		t.Helper()
+		response, err := service.ApplyAndAdvance(ctx, &ApplyRequest{ParentVersionID: versionID, Deltas: deltas})
+		if err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+		versionID = response.GraphVersionID
+		return versionID
+	}
+	elena := func(mood string) *Delta {
+		return &Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "mood": mood}}
+	}
+
+	// Elena is absent from the root and the first version, so neither shows up
+	advance(&Delta{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}})
+	hopeful := advance(&Delta{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "mood": "hopeful"}})
+	wary := advance(elena("wary"))
+	resolute := advance(elena("resolute"))
+
+	history, err := service.GetEntityHistoryInProject(ctx, projectID, "elena")
+	if err != nil {
+		t.Fatalf("GetEntityHistoryInProject failed: %v", err)
+	}
+
+	expected := []struct{ versionID, mood string }{
+		{hopeful, "hopeful"},
+		{wary, "wary"},
+		{resolute, "resolute"},
+	}
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d history entries, got %d", len(expected), len(history))
+	}
+	for i, entry := range history {
+		want := expected[i]
+		if entry.VersionID != want.versionID || entry.Entity.Data["mood"] != want.mood {
+			t.Errorf("Entry %d: expected mood %q in version %s, got %v in %s", i, want.mood, want.versionID, entry.Entity.Data["mood"], entry.VersionID)
+		}
+		if entry.Entity.ID != "elena" || entry.ProjectID != projectID {
+			t.Errorf("Entry %d: expected elena in project %s, got %s in %s", i, projectID, entry.Entity.ID, entry.ProjectID)
+		}
+		if entry.VersionName == "" || entry.VersionDescription == "" {
+			t.Errorf("Entry %d: expected the version name and description, got %+v", i, entry)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// applyFieldRelationships creates the relationships implied by a new entity's data
+// fields, such as a scene's location. References to entities missing from the version
+// or deleted from it are skipped, as are relationships the delta already creates
+// explicitly.
+func (s *Service) applyFieldRelationships(ctx context.Context, versionID string, logicalID string, delta *Delta, entityIDMapping map[string]string) error {
+	explicit := make(map[relationshipKey]bool, len(delta.Relationships))
+	for _, relDelta := range delta.Relationships {
+		if relDelta.Operation == "create" {
+			explicit[relationshipKey{From: relDelta.FromEntityID, To: relDelta.ToEntityID, Type: relDelta.RelationshipType}] = true
+		}
+	}
+
+	for _, rule := range types.FieldRelationshipRulesFor(types.EntityType(delta.EntityType)) {
+		for _, targetID := range referencedIDs(delta.Fields[rule.Field]) {
+			key := relationshipKey{From: logicalID, To: targetID, Type: string(rule.RelationshipType)}
+			if targetID == logicalID || explicit[key] {
+				continue
+			}
+			databaseID, exists := entityIDMapping[targetID]
+			if !exists {
+				continue
+			}
+			if target, err := s.db.Queries().GetEntity(ctx, databaseID); err == nil && rowIsTombstone(target) {
+				continue
+			}
+
+			relDelta := &RelationshipDelta{
+				Operation:        "create",
+				FromEntityID:     key.From,
+				ToEntityID:       key.To,
+				RelationshipType: key.Type,
+				Properties:       map[string]any{},
+			}
+			if err := s.createRelationship(ctx, versionID, relDelta, entityIDMapping); err != nil {
+				return fmt.Errorf("failed to create %s relationship from field %s: %w", key.Type, rule.Field, err)
+			}
+			explicit[key] = true
+		}
+	}
+
+	return nil
+}
+
+// referencedIDs reads the logical IDs held in a data field value
+func referencedIDs(value any) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []string:
+		return v
+	case []any:
+		var ids []string
+		for _, item := range v {
+			if id, ok := item.(string); ok && id != "" {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+	return nil
+}
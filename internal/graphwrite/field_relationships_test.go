@@ -0,0 +1,81 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+)
+
+func TestService_CreateScene_LocationFieldCreatesOccursAt(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "The Tavern"}},
+			{Operation: "create", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": "Meeting", "location": "tavern"}},
+			{Operation: "create", EntityType: "Scene", EntityID: "scene-2", Fields: map[string]any{"name": "Lost", "location": "nowhere"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	relationships, err := service.(*Service).relationshipsByLogicalKey(ctx, response.GraphVersionID)
+	if err != nil {
+		t.Fatalf("relationshipsByLogicalKey failed: %v", err)
+	}
+	if _, ok := relationships[relationshipKey{From: "scene-1", To: "tavern", Type: "occurs_at"}]; !ok {
+		t.Errorf("Expected scene-1 -occurs_at-> tavern to be created, got %v", relationships)
+	}
+	if len(relationships) != 1 {
+		t.Errorf("Expected an unknown location to be skipped, got %v", relationships)
+	}
+}
+
+func TestService_CreateScene_LocationFieldDoesNotDuplicateExplicitRelationship(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "The Tavern"}},
+			{
+				Operation:  "create",
+				EntityType: "Scene",
+				EntityID:   "scene-1",
+				Fields:     map[string]any{"name": "Meeting", "location": "tavern"},
+				Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "scene-1", ToEntityID: "tavern", RelationshipType: "occurs_at", Properties: map[string]any{"time": "evening"}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	relationships, err := database.Queries().ListRelationshipsByVersion(ctx, response.GraphVersionID)
+	if err != nil {
+		t.Fatalf("ListRelationshipsByVersion failed: %v", err)
+	}
+	if len(relationships) != 1 {
+		t.Fatalf("Expected only the explicit relationship, got %d", len(relationships))
+	}
+	if string(relationships[0].Properties) != `{"time":"evening"}` {
+		t.Errorf("Expected the explicit relationship's properties, got %s", relationships[0].Properties)
+	}
+}
@@ -0,0 +1,63 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+)
+
+func TestService_ExportGraphML(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+	output, err := service.ExportGraphML(ctx, versionID)
+	if err != nil {
+		t.Fatalf("ExportGraphML failed: %v", err)
+	}
+
+	var document graphMLDocument
+	if err := xml.Unmarshal(output, &document); err != nil {
+		t.Fatalf("Expected well-formed GraphML, got %v:\n%s", err, output)
+	}
+	if len(document.Graph.Nodes) != 2 {
+		t.Errorf("Expected 2 nodes, got %d", len(document.Graph.Nodes))
+	}
+	if len(document.Graph.Edges) != 1 {
+		t.Fatalf("Expected 1 edge, got %d", len(document.Graph.Edges))
+	}
+
+	edge := document.Graph.Edges[0]
+	if edge.Source != "elena" || edge.Target != "marcus" {
+		t.Errorf("Expected edge elena -> marcus, got %s -> %s", edge.Source, edge.Target)
+	}
+	if len(edge.Data) != 1 || edge.Data[0].Key != graphMLKeyRelationshipType || edge.Data[0].Value != "allies_with" {
+		t.Errorf("Expected a relationship_type attribute, got %+v", edge.Data)
+	}
+
+	for _, node := range document.Graph.Nodes {
+		attributes := make(map[string]string)
+		for _, data := range node.Data {
+			attributes[data.Key] = data.Value
+		}
+		if attributes[graphMLKeyEntityType] != "Character" || attributes[graphMLKeyName] == "" {
+			t.Errorf("Expected entity_type and name attributes on %s, got %v", node.ID, attributes)
+		}
+	}
+}
+
+func TestService_ExportGraphML_UnknownVersion(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	if _, err := service.ExportGraphML(context.Background(), "missing"); err == nil {
+		t.Error("Expected an error for an unknown version")
+	}
+}
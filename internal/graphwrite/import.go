@@ -0,0 +1,194 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/google/uuid"
+)
+
+// ImportEntities imports several entities from another project's working set, as
+// ImportEntity does for one, listing the target version and the source working set
+// once instead of once per entity. Entities already in the target version are returned
+// as they are rather than imported again. Every ID is resolved before anything is
+// written, so an ID missing from the source fails the call without importing the rest.
+// The result follows the order of logicalIDs.
+func (s *Service) ImportEntities(ctx context.Context, targetVersionID string, sourceProjectID string, logicalIDs []string) ([]*Entity, error) {
+	if err := s.checkProjectOwner(ctx, sourceProjectID); err != nil {
+		return nil, err
+	}
+	if err := s.checkVersionOwner(ctx, targetVersionID); err != nil {
+		return nil, err
+	}
+	if len(logicalIDs) == 0 {
+		return []*Entity{}, nil
+	}
+
+	workingSet, err := s.db.Queries().GetWorkingSetVersion(ctx, sourceProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working set for project: %w", err)
+	}
+	sourceEntities, err := s.entitiesByLogicalID(ctx, workingSet.ID)
+	if err != nil {
+		return nil, err
+	}
+	targetEntities, err := s.entitiesByLogicalID(ctx, targetVersionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list target entities: %w", err)
+	}
+
+	for _, logicalID := range logicalIDs {
+		if _, exists := targetEntities[logicalID]; exists {
+			continue
+		}
+		if _, exists := sourceEntities[logicalID]; !exists {
+			return nil, fmt.Errorf("failed to find entity %s in project %s: %w", logicalID, sourceProjectID, ErrEntityNotFound)
+		}
+	}
+
+	result := make([]*Entity, len(logicalIDs))
+	for i, logicalID := range logicalIDs {
+		row, exists := targetEntities[logicalID]
+		if !exists {
+			if row, err = s.importEntityRow(ctx, targetVersionID, sourceProjectID, logicalID, sourceEntities[logicalID]); err != nil {
+				return nil, err
+			}
+			targetEntities[logicalID] = row
+		}
+
+		if result[i], err = s.toEntity(ctx, row); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// ImportEntityWithRelationships imports an entity as ImportEntity does and also copies
+// its relationships in the source project's working set whose other endpoint is already
+// in the target version, matched by logical ID. Relationships to entities not imported
+// yet are skipped; importing the counterpart later with this method brings them over.
+// Relationships the target version already has are left as they are.
+func (s *Service) ImportEntityWithRelationships(ctx context.Context, targetVersionID string, sourceProjectID string, logicalID string) (*Entity, error) {
+	if err := s.checkProjectOwner(ctx, sourceProjectID); err != nil {
+		return nil, err
+	}
+	if err := s.checkVersionOwner(ctx, targetVersionID); err != nil {
+		return nil, err
+	}
+	imported, err := s.ImportEntities(ctx, targetVersionID, sourceProjectID, []string{logicalID})
+	if err != nil {
+		return nil, err
+	}
+
+	workingSet, err := s.db.Queries().GetWorkingSetVersion(ctx, sourceProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working set for project: %w", err)
+	}
+	sourceEntities, err := s.entitiesByLogicalID(ctx, workingSet.ID)
+	if err != nil {
+		return nil, err
+	}
+	sourceLogicalIDs, err := s.logicalIDsByDatabaseID(ctx, workingSet.ID)
+	if err != nil {
+		return nil, err
+	}
+	targetEntities, err := s.entitiesByLogicalID(ctx, targetVersionID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.relationshipsByLogicalKey(ctx, targetVersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceID := sourceEntities[logicalID].ID
+	relationships, err := s.db.Queries().ListRelationshipsByEntityInVersion(ctx, db.ListRelationshipsByEntityInVersionParams{
+		VersionID:    workingSet.ID,
+		FromEntityID: sourceID,
+		ToEntityID:   sourceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	for _, rel := range relationships {
+		key := relationshipKey{From: sourceLogicalIDs[rel.FromEntityID], To: sourceLogicalIDs[rel.ToEntityID], Type: rel.RelationshipType}
+		from, fromExists := targetEntities[key.From]
+		to, toExists := targetEntities[key.To]
+		if !fromExists || !toExists {
+			continue // The counterpart is not in the target yet
+		}
+		if _, exists := existing[key]; exists {
+			continue
+		}
+
+		if _, err := s.db.Queries().CreateRelationship(ctx, db.CreateRelationshipParams{
+			ID:               uuid.New().String(),
+			VersionID:        targetVersionID,
+			FromEntityID:     from.ID,
+			ToEntityID:       to.ID,
+			RelationshipType: rel.RelationshipType,
+			Properties:       rel.Properties,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to import relationship %s: %w", key, err)
+		}
+		existing[key] = nil
+	}
+
+	return imported[0], nil
+}
+
+// importEntityRow copies a source entity row into the target version with the same
+// import tracking as ImportEntity
+func (s *Service) importEntityRow(ctx context.Context, targetVersionID string, sourceProjectID string, logicalID string, source db.Entity) (db.Entity, error) {
+	var entityData map[string]any
+	if err := json.Unmarshal(source.Data, &entityData); err != nil {
+		return db.Entity{}, fmt.Errorf("failed to unmarshal source entity data: %w", err)
+	}
+	entityData["logical_id"] = logicalID
+	entityData["imported_from_project"] = sourceProjectID
+	entityData["import_timestamp"] = fmt.Sprintf("%d", time.Now().Unix())
+
+	updatedData, err := json.Marshal(entityData)
+	if err != nil {
+		return db.Entity{}, fmt.Errorf("failed to marshal updated entity data: %w", err)
+	}
+
+	row, err := s.db.Queries().CreateEntity(ctx, db.CreateEntityParams{
+		ID:         uuid.New().String(),
+		VersionID:  targetVersionID,
+		EntityType: source.EntityType,
+		Name:       source.Name,
+		Data:       updatedData,
+	})
+	if err != nil {
+		return db.Entity{}, fmt.Errorf("failed to import entity %s: %w", logicalID, err)
+	}
+	return row, nil
+}
+
+// entitiesByLogicalID lists a version's entity rows keyed by logical ID
+func (s *Service) entitiesByLogicalID(ctx context.Context, versionID string) (map[string]db.Entity, error) {
+	entities, err := s.db.Queries().ListEntitiesByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	result := make(map[string]db.Entity, len(entities))
+	for _, entity := range entities {
+		logicalID := entity.ID
+		var data map[string]any
+		if err := json.Unmarshal(entity.Data, &data); err == nil {
+			if lid, exists := data["logical_id"].(string); exists {
+				logicalID = lid
+			}
+		}
+		result[logicalID] = entity
+	}
+
+	return result, nil
+}
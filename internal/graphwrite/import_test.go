@@ -0,0 +1,196 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+func TestService_CheckImportDuplicate(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	createCharacter := func(projectID, logicalID string) string {
+		rootVersionID := createTestGraphVersion(t, database, projectID, true)
+		response, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+			ParentVersionID: rootVersionID,
+			Deltas: []*Delta{
+				{Operation: "create", EntityType: "Character", EntityID: logicalID, Fields: map[string]any{"name": "Elena"}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+		return response.GraphVersionID
+	}
+
+	sourceProjectID := createTestProject(t, database)
+	createCharacter(sourceProjectID, "elena")
+
+	t.Run("warns on name collision", func(t *testing.T) {
+		targetVersionID := createCharacter(createTestProject(t, database), "elena-lookalike")
+
+		warning, err := service.CheckImportDuplicate(ctx, targetVersionID, sourceProjectID, "elena")
+		if err != nil {
+			t.Fatalf("CheckImportDuplicate failed: %v", err)
+		}
+		if warning == nil {
+			t.Fatal("Expected a duplicate warning")
+		}
+		if warning.ExistingEntityID != "elena-lookalike" || warning.EntityType != "Character" || warning.Name != "Elena" {
+			t.Errorf("Unexpected warning: %+v", warning)
+		}
+	})
+
+	t.Run("silent on true re-import", func(t *testing.T) {
+		targetVersionID := createTestGraphVersion(t, database, createTestProject(t, database), true)
+		if _, err := service.ImportEntity(ctx, targetVersionID, sourceProjectID, "elena"); err != nil {
+			t.Fatalf("ImportEntity failed: %v", err)
+		}
+
+		warning, err := service.CheckImportDuplicate(ctx, targetVersionID, sourceProjectID, "elena")
+		if err != nil {
+			t.Fatalf("CheckImportDuplicate failed: %v", err)
+		}
+		if warning != nil {
+			t.Errorf("Expected no warning on re-import, got %+v", warning)
+		}
+	})
+
+	t.Run("silent without collision", func(t *testing.T) {
+		targetVersionID := createTestGraphVersion(t, database, createTestProject(t, database), true)
+
+		warning, err := service.CheckImportDuplicate(ctx, targetVersionID, sourceProjectID, "elena")
+		if err != nil {
+			t.Fatalf("CheckImportDuplicate failed: %v", err)
+		}
+		if warning != nil {
+			t.Errorf("Expected no warning, got %+v", warning)
+		}
+	})
+}
+
+func TestService_ImportEntities(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	sourceProjectID := createTestProject(t, database)
+	sourceRootID := createTestGraphVersion(t, database, sourceProjectID, true)
+	deltas := make([]*Delta, 5)
+	for i := range deltas {
+		deltas[i] = &Delta{Operation: "create", EntityType: "Character", EntityID: fmt.Sprintf("character-%d", i), Fields: map[string]any{"name": fmt.Sprintf("Character %d", i)}}
+	}
+	if _, err := service.ApplyAndAdvance(ctx, &ApplyRequest{ParentVersionID: sourceRootID, Deltas: deltas}); err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	targetVersionID := createTestGraphVersion(t, database, createTestProject(t, database), true)
+	for _, logicalID := range []string{"character-1", "character-3"} {
+		if _, err := service.ImportEntity(ctx, targetVersionID, sourceProjectID, logicalID); err != nil {
+			t.Fatalf("ImportEntity failed: %v", err)
+		}
+	}
+	before := countRows(t, database, "entities")
+
+	logicalIDs := []string{"character-0", "character-1", "character-2", "character-3", "character-4"}
+	imported, err := service.ImportEntities(ctx, targetVersionID, sourceProjectID, logicalIDs)
+	if err != nil {
+		t.Fatalf("ImportEntities failed: %v", err)
+	}
+
+	if created := countRows(t, database, "entities") - before; created != 3 {
+		t.Errorf("Expected 3 entities to be created, got %d", created)
+	}
+	if len(imported) != len(logicalIDs) {
+		t.Fatalf("Expected %d entities, got %d", len(logicalIDs), len(imported))
+	}
+	for i, entity := range imported {
+		if entity.ID != logicalIDs[i] || entity.VersionID != targetVersionID || entity.Name != fmt.Sprintf("Character %d", i) {
+			t.Errorf("Unexpected entity at %d: %+v", i, entity)
+		}
+	}
+	if names := entityNames(t, service, targetVersionID); len(names) != 5 {
+		t.Errorf("Expected 5 entities in the target, got %v", names)
+	}
+
+	// A missing source entity imports nothing
+	otherVersionID := createTestGraphVersion(t, database, createTestProject(t, database), true)
+	if _, err := service.ImportEntities(ctx, otherVersionID, sourceProjectID, []string{"character-0", "missing"}); !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("Expected ErrEntityNotFound, got %v", err)
+	}
+	if names := entityNames(t, service, otherVersionID); len(names) != 0 {
+		t.Errorf("Expected nothing to be imported, got %v", names)
+	}
+}
+
+func TestService_ImportEntityWithRelationships(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	sourceProjectID := createTestProject(t, database)
+	sourceVersionID := createAlliesVersion(t, service, createTestGraphVersion(t, database, sourceProjectID, true), "growing")
+	if err := database.Queries().SetWorkingSet(ctx, db.SetWorkingSetParams{ID: sourceVersionID, ProjectID: sourceProjectID}); err != nil {
+		t.Fatalf("SetWorkingSet failed: %v", err)
+	}
+
+	targetVersionID := createTestGraphVersion(t, database, createTestProject(t, database), true)
+	impl := service.(*Service)
+	edges := func() map[relationshipKey]map[string]any {
+		t.Helper()
+		relationships, err := impl.relationshipsByLogicalKey(ctx, targetVersionID)
+		if err != nil {
+			t.Fatalf("relationshipsByLogicalKey failed: %v", err)
+		}
+		return relationships
+	}
+
+	// Marcus is not in the target yet, so the edge waits for him
+	if _, err := service.ImportEntityWithRelationships(ctx, targetVersionID, sourceProjectID, "elena"); err != nil {
+		t.Fatalf("ImportEntityWithRelationships failed: %v", err)
+	}
+	if relationships := edges(); len(relationships) != 0 {
+		t.Errorf("Expected the dangling edge to be skipped, got %v", relationships)
+	}
+
+	marcus, err := service.ImportEntityWithRelationships(ctx, targetVersionID, sourceProjectID, "marcus")
+	if err != nil {
+		t.Fatalf("ImportEntityWithRelationships failed: %v", err)
+	}
+	if marcus.ID != "marcus" || marcus.VersionID != targetVersionID {
+		t.Errorf("Unexpected imported entity: %+v", marcus)
+	}
+
+	key := relationshipKey{From: "elena", To: "marcus", Type: "allies_with"}
+	relationships := edges()
+	if len(relationships) != 1 || relationships[key]["bond_strength"] != "growing" {
+		t.Fatalf("Expected the allies_with edge to survive the import, got %v", relationships)
+	}
+
+	// Importing again does not duplicate the edge
+	if _, err := service.ImportEntityWithRelationships(ctx, targetVersionID, sourceProjectID, "marcus"); err != nil {
+		t.Fatalf("ImportEntityWithRelationships failed: %v", err)
+	}
+	if stored := countRows(t, database, "relationships"); stored != 2 {
+		t.Errorf("Expected one edge in each project, got %d relationship rows", stored)
+	}
+
+	neighbors, err := service.GetNeighborsInVersion(ctx, targetVersionID, "elena", "allies_with")
+	if err != nil {
+		t.Fatalf("GetNeighborsInVersion failed: %v", err)
+	}
+	if len(neighbors) != 1 || neighbors[0].ID != "marcus" {
+		t.Errorf("Expected elena to ally with marcus in the target, got %+v", neighbors)
+	}
+}
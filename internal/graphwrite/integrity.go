@@ -0,0 +1,65 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+)
+
+// RepairOptions controls RepairIntegrity
+type RepairOptions struct {
+	// Repair deletes the dangling rows; without it they are only reported
+	Repair bool
+}
+
+// IntegrityReport lists the rows that reference entities which no longer exist, as
+// left behind by manual edits or skipped orphan relationships
+type IntegrityReport struct {
+	// DanglingRelationships and DanglingAnnotations hold the offending row IDs, oldest first
+	DanglingRelationships []string
+	DanglingAnnotations   []string
+
+	// Repaired is set when the dangling rows were deleted
+	Repaired bool
+}
+
+// RepairIntegrity finds relationships with a missing endpoint entity and annotations on
+// a missing entity, deleting them when opts.Repair is set
+func (s *Service) RepairIntegrity(ctx context.Context, opts RepairOptions) (*IntegrityReport, error) {
+	relationships, err := s.db.Queries().ListDanglingRelationships(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dangling relationships: %w", err)
+	}
+	annotations, err := s.db.Queries().ListDanglingAnnotations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dangling annotations: %w", err)
+	}
+
+	report := &IntegrityReport{
+		DanglingRelationships: make([]string, 0, len(relationships)),
+		DanglingAnnotations:   make([]string, 0, len(annotations)),
+	}
+	for _, rel := range relationships {
+		report.DanglingRelationships = append(report.DanglingRelationships, rel.ID)
+	}
+	for _, annotation := range annotations {
+		report.DanglingAnnotations = append(report.DanglingAnnotations, annotation.ID)
+	}
+
+	if !opts.Repair {
+		return report, nil
+	}
+
+	for _, id := range report.DanglingRelationships {
+		if err := s.db.Queries().DeleteRelationship(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to delete relationship %s: %w", id, err)
+		}
+	}
+	for _, id := range report.DanglingAnnotations {
+		if err := s.db.Queries().DeleteAnnotation(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to delete annotation %s: %w", id, err)
+		}
+	}
+	report.Repaired = true
+
+	return report, nil
+}
@@ -0,0 +1,90 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+)
+
+func TestService_RepairIntegrity(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+	elenaDatabaseID := databaseIDForEntity(t, database, versionID, "elena")
+	sceneDatabaseID := databaseIDForEntity(t, database, versionID, "scene-1")
+	elenaAnnotationID := createTestAnnotation(t, database, elenaDatabaseID, "emotional_analysis", nil)
+	sceneAnnotationID := createTestAnnotation(t, database, sceneDatabaseID, "pacing_analysis", nil)
+
+	// Corrupt the database the way a manual edit would: drop elena's row with foreign
+	// keys off so nothing cascades
+	conn, err := database.DB().Conn(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	for _, statement := range []string{
+		"PRAGMA foreign_keys = OFF",
+		"DELETE FROM entities WHERE id = '" + elenaDatabaseID + "'",
+		"PRAGMA foreign_keys = ON",
+	} {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			t.Fatalf("Failed to corrupt database: %v", err)
+		}
+	}
+	conn.Close()
+
+	relationships, err := database.Queries().ListRelationshipsByVersion(ctx, versionID)
+	if err != nil || len(relationships) != 1 {
+		t.Fatalf("Expected the features relationship to survive the corruption, got %d, %v", len(relationships), err)
+	}
+	danglingRelationshipID := relationships[0].ID
+
+	// Reporting leaves the rows alone
+	report, err := service.RepairIntegrity(ctx, RepairOptions{})
+	if err != nil {
+		t.Fatalf("RepairIntegrity failed: %v", err)
+	}
+	if len(report.DanglingRelationships) != 1 || report.DanglingRelationships[0] != danglingRelationshipID {
+		t.Errorf("Expected the features relationship to dangle, got %v", report.DanglingRelationships)
+	}
+	if len(report.DanglingAnnotations) != 1 || report.DanglingAnnotations[0] != elenaAnnotationID {
+		t.Errorf("Expected elena's annotation to dangle, got %v", report.DanglingAnnotations)
+	}
+	if report.Repaired {
+		t.Error("Expected a report-only run not to repair")
+	}
+	if _, err := database.Queries().GetRelationship(ctx, danglingRelationshipID); err != nil {
+		t.Errorf("Expected the relationship to remain after a report-only run, got %v", err)
+	}
+
+	report, err = service.RepairIntegrity(ctx, RepairOptions{Repair: true})
+	if err != nil {
+		t.Fatalf("RepairIntegrity failed: %v", err)
+	}
+	if !report.Repaired || len(report.DanglingRelationships) != 1 || len(report.DanglingAnnotations) != 1 {
+		t.Errorf("Expected the dangling rows to be repaired, got %+v", report)
+	}
+	if _, err := database.Queries().GetRelationship(ctx, danglingRelationshipID); err == nil {
+		t.Error("Expected the dangling relationship to be deleted")
+	}
+	if _, err := database.Queries().GetAnnotation(ctx, elenaAnnotationID); err == nil {
+		t.Error("Expected the dangling annotation to be deleted")
+	}
+	if _, err := database.Queries().GetAnnotation(ctx, sceneAnnotationID); err != nil {
+		t.Errorf("Expected the scene's annotation to be kept, got %v", err)
+	}
+
+	// A clean database has nothing to report
+	report, err = service.RepairIntegrity(ctx, RepairOptions{})
+	if err != nil {
+		t.Fatalf("RepairIntegrity failed: %v", err)
+	}
+	if len(report.DanglingRelationships) != 0 || len(report.DanglingAnnotations) != 0 {
+		t.Errorf("Expected no dangling rows after repair, got %+v", report)
+	}
+}
@@ -0,0 +1,58 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// NodePosition is a node's saved position in the graph view
+type NodePosition struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// SaveLayout stores node positions for a version, keyed by logical entity ID.
+// Positions for other nodes are left as they are.
+func (s *Service) SaveLayout(ctx context.Context, versionID string, positions map[string]NodePosition) error {
+	if _, err := s.db.Queries().GetGraphVersion(ctx, versionID); err != nil {
+		return fmt.Errorf("%w: %w", ErrVersionNotFound, err)
+	}
+
+	for logicalID, position := range positions {
+		if err := s.db.Queries().UpsertLayoutPosition(ctx, db.UpsertLayoutPositionParams{
+			VersionID: versionID,
+			LogicalID: logicalID,
+			X:         position.X,
+			Y:         position.Y,
+		}); err != nil {
+			return fmt.Errorf("failed to save position of %s: %w", logicalID, err)
+		}
+	}
+
+	return nil
+}
+
+// GetLayout returns the saved node positions for a version. A version without a
+// saved layout inherits the layout of its nearest ancestor that has one, so nodes
+// keep their places as the working set moves on. The result is empty when no
+// ancestor has a layout either.
+func (s *Service) GetLayout(ctx context.Context, versionID string) (map[string]NodePosition, error) {
+	positions := map[string]NodePosition{}
+	err := s.walkVersionChain(ctx, versionID, func(version db.GraphVersion) (bool, error) {
+		rows, err := s.db.Queries().ListLayoutPositions(ctx, version.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to list layout positions: %w", err)
+		}
+		for _, row := range rows {
+			positions[row.LogicalID] = NodePosition{X: row.X, Y: row.Y}
+		}
+		return len(rows) == 0, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return positions, nil
+}
@@ -0,0 +1,66 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+)
+
+func TestService_SaveLayout_RoundTrip(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+	empty, err := service.GetLayout(ctx, versionID)
+	if err != nil {
+		t.Fatalf("GetLayout failed: %v", err)
+	}
+	if len(empty) != 0 {
+		t.Fatalf("Expected no layout before saving, got %v", empty)
+	}
+
+	if err := service.SaveLayout(ctx, versionID, map[string]NodePosition{
+		"scene-1": {X: 120.5, Y: 80},
+		"elena":   {X: 300, Y: 210.25},
+	}); err != nil {
+		t.Fatalf("SaveLayout failed: %v", err)
+	}
+
+	// Saving again moves one node and leaves the other alone
+	if err := service.SaveLayout(ctx, versionID, map[string]NodePosition{"elena": {X: 310, Y: 200}}); err != nil {
+		t.Fatalf("SaveLayout failed: %v", err)
+	}
+
+	layout, err := service.GetLayout(ctx, versionID)
+	if err != nil {
+		t.Fatalf("GetLayout failed: %v", err)
+	}
+	if len(layout) != 2 || layout["scene-1"] != (NodePosition{X: 120.5, Y: 80}) || layout["elena"] != (NodePosition{X: 310, Y: 200}) {
+		t.Errorf("Unexpected layout: %v", layout)
+	}
+
+	// A child version without its own layout inherits it
+	child, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: versionID,
+		Deltas:          []*Delta{{Operation: "create", EntityType: "Location", EntityID: "harbor", Fields: map[string]any{"name": "Harbor"}}},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	inherited, err := service.GetLayout(ctx, child.GraphVersionID)
+	if err != nil {
+		t.Fatalf("GetLayout failed: %v", err)
+	}
+	if len(inherited) != 2 || inherited["elena"] != layout["elena"] {
+		t.Errorf("Expected the child to inherit its parent's layout, got %v", inherited)
+	}
+
+	if err := service.SaveLayout(ctx, "missing", map[string]NodePosition{"elena": {}}); err == nil {
+		t.Error("Expected an error saving a layout for an unknown version")
+	}
+}
@@ -0,0 +1,178 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// ListOptions pages a list call. A zero Limit returns every item after Offset.
+type ListOptions struct {
+	Limit  int
+	Offset int
+}
+
+// ListResult is one page of a list call. Total counts every item before paging.
+type ListResult[T any] struct {
+	Items []T
+	Total int
+}
+
+// paginate cuts one page out of a full result set
+func paginate[T any](items []T, opts ListOptions) *ListResult[T] {
+	result := &ListResult[T]{Items: []T{}, Total: len(items)}
+
+	start := opts.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start >= len(items) {
+		return result
+	}
+
+	end := len(items)
+	if opts.Limit > 0 && start+opts.Limit < end {
+		end = start + opts.Limit
+	}
+	result.Items = items[start:end]
+	return result
+}
+
+// GetEntities fetches the entities with the given logical IDs from a version in one
+// query, returning them in the order requested; a repeated ID fills each of its slots.
+// A logical ID missing from the version, or tombstoned in it, leaves a nil slot, or
+// fails with ErrEntityNotFound when ServiceOptions.FailOnMissingEntities is set.
+func (s *Service) GetEntities(ctx context.Context, versionID string, logicalIDs []string) ([]*Entity, error) {
+	if len(logicalIDs) == 0 {
+		return []*Entity{}, nil
+	}
+
+	ids, err := json.Marshal(logicalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal logical IDs: %w", err)
+	}
+	rows, err := s.db.Queries().ListEntitiesByLogicalIDs(ctx, db.ListEntitiesByLogicalIDsParams{
+		LogicalIds: string(ids),
+		VersionID:  versionID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	byLogicalID := make(map[string]*Entity, len(rows))
+	for _, row := range rows {
+		entity, err := s.toEntity(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		if isTombstone(entity.Data) {
+			continue
+		}
+		byLogicalID[entity.ID] = entity
+	}
+
+	result := make([]*Entity, len(logicalIDs))
+	for i, logicalID := range logicalIDs {
+		result[i] = byLogicalID[logicalID]
+		if result[i] == nil && s.options.FailOnMissingEntities {
+			return nil, fmt.Errorf("%w: %s is not in version %s", ErrEntityNotFound, logicalID, versionID)
+		}
+	}
+
+	return result, nil
+}
+
+// ListEntitiesPage lists a page of a version's entities matching the filter
+func (s *Service) ListEntitiesPage(ctx context.Context, versionID string, filter EntityFilter, opts ListOptions) (*ListResult[*Entity], error) {
+	entities, err := s.ListEntities(ctx, versionID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(entities, opts), nil
+}
+
+// ListRelationships lists a page of a version's relationships, addressed by logical IDs
+func (s *Service) ListRelationships(ctx context.Context, versionID string, opts ListOptions) (*ListResult[*Relationship], error) {
+	return s.ListRelationshipsOfType(ctx, versionID, "", opts)
+}
+
+// ListRelationshipsOfType lists a page of a version's relationships of one type,
+// addressed by logical IDs. An empty relationshipType lists every relationship.
+// Relationships touching a tombstoned entity are left out.
+func (s *Service) ListRelationshipsOfType(ctx context.Context, versionID string, relationshipType string, opts ListOptions) (*ListResult[*Relationship], error) {
+	logicalIDs, tombstones, err := s.logicalIDsWithTombstones(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var relationships []db.Relationship
+	if relationshipType == "" {
+		relationships, err = s.db.Queries().ListRelationshipsByVersion(ctx, versionID)
+	} else {
+		relationships, err = s.db.Queries().ListRelationshipsByVersionAndType(ctx, db.ListRelationshipsByVersionAndTypeParams{
+			VersionID:        versionID,
+			RelationshipType: relationshipType,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	result := make([]*Relationship, 0, len(relationships))
+	for _, rel := range relationships {
+		if tombstones[rel.FromEntityID] || tombstones[rel.ToEntityID] {
+			continue
+		}
+		converted, err := toRelationship(rel, logicalIDs)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, converted)
+	}
+
+	return paginate(result, opts), nil
+}
+
+// GetEntityHistoryPage lists a page of an entity's history across projects
+func (s *Service) GetEntityHistoryPage(ctx context.Context, entityLogicalID string, opts ListOptions) (*ListResult[*EntityVersion], error) {
+	history, err := s.GetEntityHistory(ctx, entityLogicalID)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(history, opts), nil
+}
+
+// ListSharedEntitiesPage lists a page of the entities shared between projects
+func (s *Service) ListSharedEntitiesPage(ctx context.Context, opts ListOptions) (*ListResult[*SharedEntity], error) {
+	shared, err := s.ListSharedEntities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(shared, opts), nil
+}
+
+// toRelationship converts a database relationship to its service representation,
+// given the version's database-to-logical entity ID mapping
+func toRelationship(rel db.Relationship, logicalIDs map[string]string) (*Relationship, error) {
+	properties := map[string]any{}
+	if len(rel.Properties) > 0 {
+		if err := json.Unmarshal(rel.Properties, &properties); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal relationship properties: %w", err)
+		}
+		if properties == nil {
+			properties = map[string]any{}
+		}
+	}
+
+	return &Relationship{
+		ID:               rel.ID,
+		VersionID:        rel.VersionID,
+		FromEntityID:     logicalIDs[rel.FromEntityID],
+		ToEntityID:       logicalIDs[rel.ToEntityID],
+		RelationshipType: rel.RelationshipType,
+		Properties:       properties,
+		CreatedAt:        rel.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}, nil
+}
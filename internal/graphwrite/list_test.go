@@ -0,0 +1,299 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// createCastVersion creates n characters, each featured by a single scene
+func createCastVersion(t *testing.T, service GraphWriteService, parentVersionID string, n int) string {
+	scene := &Delta{Operation: "create", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": "Crowd"}}
+	deltas := []*Delta{}
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("character-%02d", i)
+		deltas = append(deltas, &Delta{Operation: "create", EntityType: "Character", EntityID: id, Fields: map[string]any{"name": id}})
+		scene.Relationships = append(scene.Relationships, &RelationshipDelta{
+			Operation: "create", FromEntityID: "scene-1", ToEntityID: id, RelationshipType: "features", Properties: map[string]any{},
+		})
+	}
+
+	response, err := service.Apply(context.Background(), &ApplyRequest{ParentVersionID: parentVersionID, Deltas: append(deltas, scene)})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	return response.GraphVersionID
+}
+
+func TestService_ListEntitiesPage(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createCastVersion(t, service, rootVersionID, 5)
+
+	characterType := "Character"
+	filter := EntityFilter{EntityType: &characterType}
+
+	tests := []struct {
+		name      string
+		opts      ListOptions
+		wantItems int
+	}{
+		{"no limit", ListOptions{}, 5},
+		{"first page", ListOptions{Limit: 2}, 2},
+		{"last partial page", ListOptions{Limit: 2, Offset: 4}, 1},
+		{"past the end", ListOptions{Limit: 2, Offset: 10}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.ListEntitiesPage(ctx, versionID, filter, tt.opts)
+			if err != nil {
+				t.Fatalf("ListEntitiesPage failed: %v", err)
+			}
+			if len(page.Items) != tt.wantItems || page.Total != 5 {
+				t.Errorf("Expected %d items of 5, got %d of %d", tt.wantItems, len(page.Items), page.Total)
+			}
+		})
+	}
+
+	// Pages do not overlap
+	first, _ := service.ListEntitiesPage(ctx, versionID, filter, ListOptions{Limit: 2})
+	second, _ := service.ListEntitiesPage(ctx, versionID, filter, ListOptions{Limit: 2, Offset: 2})
+	for _, a := range first.Items {
+		for _, b := range second.Items {
+			if a.ID == b.ID {
+				t.Errorf("Entity %s appears on two pages", a.ID)
+			}
+		}
+	}
+
+	// EntityFilter.Limit caps ListEntities itself
+	limit := 3
+	limited, err := service.ListEntities(ctx, versionID, EntityFilter{EntityType: &characterType, Limit: &limit})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	if len(limited) != 3 {
+		t.Errorf("Expected 3 entities with Limit 3, got %d", len(limited))
+	}
+}
+
+func TestService_ListRelationships(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createCastVersion(t, service, rootVersionID, 5)
+
+	page, err := service.ListRelationships(ctx, versionID, ListOptions{Limit: 3, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListRelationships failed: %v", err)
+	}
+	if page.Total != 5 || len(page.Items) != 3 {
+		t.Fatalf("Expected 3 relationships of 5, got %d of %d", len(page.Items), page.Total)
+	}
+	for _, rel := range page.Items {
+		if rel.FromEntityID != "scene-1" || rel.RelationshipType != "features" || rel.Properties == nil {
+			t.Errorf("Expected a logical scene-1 features edge, got %+v", rel)
+		}
+	}
+
+	all, err := service.ListRelationships(ctx, versionID, ListOptions{})
+	if err != nil {
+		t.Fatalf("ListRelationships failed: %v", err)
+	}
+	if len(all.Items) != 5 {
+		t.Errorf("Expected every relationship without a limit, got %d", len(all.Items))
+	}
+}
+
+func TestService_ListRelationshipsOfType(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createCastVersion(t, service, rootVersionID, 5)
+
+	features, err := service.ListRelationshipsOfType(ctx, versionID, "features", ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListRelationshipsOfType failed: %v", err)
+	}
+	if features.Total != 5 || len(features.Items) != 2 {
+		t.Errorf("Expected 2 features edges of 5, got %d of %d", len(features.Items), features.Total)
+	}
+
+	allies, err := service.ListRelationshipsOfType(ctx, versionID, "allies_with", ListOptions{})
+	if err != nil {
+		t.Fatalf("ListRelationshipsOfType failed: %v", err)
+	}
+	if allies.Total != 0 || len(allies.Items) != 0 {
+		t.Errorf("Expected no allies_with edges, got %d", allies.Total)
+	}
+}
+
+func TestService_ListEntities_PagingAndSorting(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createCastVersion(t, service, rootVersionID, 25)
+
+	characterType := "Character"
+	pageThrough := func(filter EntityFilter) (ids []string, sizes []int) {
+		t.Helper()
+		for offset := 0; ; offset += 10 {
+			limit, offset := 10, offset
+			filter.Limit, filter.Offset = &limit, &offset
+			page, err := service.ListEntities(ctx, versionID, filter)
+			if err != nil {
+				t.Fatalf("ListEntities failed: %v", err)
+			}
+			for _, entity := range page {
+				ids = append(ids, entity.ID)
+			}
+			sizes = append(sizes, len(page))
+			if len(page) < 10 {
+				return ids, sizes
+			}
+		}
+	}
+
+	byName := "name"
+	ascending, sizes := pageThrough(EntityFilter{EntityType: &characterType, OrderBy: &byName})
+	if fmt.Sprint(sizes) != "[10 10 5]" {
+		t.Fatalf("Expected pages of 10, 10 and 5, got %v", sizes)
+	}
+	for i, id := range ascending {
+		if want := fmt.Sprintf("character-%02d", i); id != want {
+			t.Errorf("Position %d: expected %s, got %s", i, want, id)
+		}
+	}
+
+	descending, _ := pageThrough(EntityFilter{EntityType: &characterType, OrderBy: &byName, Descending: true})
+	if len(descending) != 25 || descending[0] != "character-24" || descending[24] != "character-00" {
+		t.Errorf("Expected names in descending order, got %v", descending)
+	}
+
+	// The default order pages stably too: every entity once, matching the unpaged list
+	all, err := service.ListEntities(ctx, versionID, EntityFilter{})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	paged, _ := pageThrough(EntityFilter{})
+	if len(paged) != len(all) {
+		t.Fatalf("Expected %d entities across the pages, got %d", len(all), len(paged))
+	}
+	for i, entity := range all {
+		if paged[i] != entity.ID {
+			t.Errorf("Position %d: expected %s, got %s", i, entity.ID, paged[i])
+		}
+	}
+
+	byType := "entity_type"
+	if sorted, _ := service.ListEntities(ctx, versionID, EntityFilter{OrderBy: &byType, Descending: true}); len(sorted) != 26 || sorted[0].EntityType != "Scene" {
+		t.Errorf("Expected the scene first when sorting types descending, got %d entities", len(sorted))
+	}
+
+	unknown := "mood"
+	if _, err := service.ListEntities(ctx, versionID, EntityFilter{OrderBy: &unknown}); err == nil {
+		t.Error("Expected an error for an unknown order")
+	}
+}
+
+func TestService_GetEntities(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	ctx := context.Background()
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createCastVersion(t, NewService(database), rootVersionID, 5)
+
+	t.Run("preserves order", func(t *testing.T) {
+		service := NewService(database)
+		requested := []string{"character-03", "scene-1", "character-00", "character-03"}
+		entities, err := service.GetEntities(ctx, versionID, requested)
+		if err != nil {
+			t.Fatalf("GetEntities failed: %v", err)
+		}
+		if len(entities) != len(requested) {
+			t.Fatalf("Expected %d entities, got %d", len(requested), len(entities))
+		}
+		for i, id := range requested {
+			if entities[i] == nil || entities[i].ID != id {
+				t.Errorf("Expected %s in slot %d, got %+v", id, i, entities[i])
+			}
+		}
+		if entities[1].Name != "Crowd" || entities[1].EntityType != "Scene" {
+			t.Errorf("Expected the scene's row data, got %+v", entities[1])
+		}
+	})
+
+	t.Run("missing IDs leave nil slots", func(t *testing.T) {
+		service := NewService(database)
+		entities, err := service.GetEntities(ctx, versionID, []string{"character-01", "ghost", "character-02"})
+		if err != nil {
+			t.Fatalf("GetEntities failed: %v", err)
+		}
+		if len(entities) != 3 || entities[0] == nil || entities[1] != nil || entities[2] == nil {
+			t.Errorf("Expected a nil slot for ghost only, got %+v", entities)
+		}
+	})
+
+	t.Run("missing IDs fail when required", func(t *testing.T) {
+		service := NewServiceWithOptions(database, ServiceOptions{FailOnMissingEntities: true})
+		if _, err := service.GetEntities(ctx, versionID, []string{"character-01", "ghost"}); !errors.Is(err, ErrEntityNotFound) {
+			t.Errorf("Expected ErrEntityNotFound, got %v", err)
+		}
+		if entities, err := service.GetEntities(ctx, versionID, []string{"character-04"}); err != nil || len(entities) != 1 {
+			t.Errorf("Expected present IDs to succeed, got %+v (%v)", entities, err)
+		}
+	})
+
+	t.Run("scoped to the version", func(t *testing.T) {
+		service := NewService(database)
+		entities, err := service.GetEntities(ctx, rootVersionID, []string{"character-00"})
+		if err != nil {
+			t.Fatalf("GetEntities failed: %v", err)
+		}
+		if len(entities) != 1 || entities[0] != nil {
+			t.Errorf("Expected no entity in the empty root version, got %+v", entities)
+		}
+	})
+
+	t.Run("copy-on-write versions", func(t *testing.T) {
+		service := NewServiceWithOptions(database, ServiceOptions{CopyOnWrite: true})
+		response, err := service.Apply(ctx, &ApplyRequest{ParentVersionID: versionID, Deltas: []*Delta{
+			{Operation: "update", EntityType: "Character", EntityID: "character-02", Fields: map[string]any{"name": "Renamed"}},
+		}})
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		entities, err := service.GetEntities(ctx, response.GraphVersionID, []string{"character-02", "character-01"})
+		if err != nil {
+			t.Fatalf("GetEntities failed: %v", err)
+		}
+		if len(entities) != 2 || entities[0] == nil || entities[0].Name != "Renamed" || entities[1] == nil || entities[1].Name != "character-01" {
+			t.Errorf("Expected the changed and the shared entity, got %+v", entities)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// ExtractMentions scans a scene's content for the names of characters in the same
+// version and returns the logical IDs of those mentioned, sorted. Names match
+// case-insensitively on word boundaries.
+func (s *Service) ExtractMentions(ctx context.Context, versionID, sceneLogicalID string) ([]string, error) {
+	entities, err := s.ListEntities(ctx, versionID, EntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	var scene *Entity
+	for _, entity := range entities {
+		if entity.ID == sceneLogicalID && entity.EntityType == string(types.EntityTypeScene) {
+			scene = entity
+		}
+	}
+	if scene == nil {
+		return nil, fmt.Errorf("scene %s not found in version %s", sceneLogicalID, versionID)
+	}
+
+	content, _ := scene.Data["content"].(string)
+	mentioned := []string{}
+	for _, entity := range entities {
+		if entity.EntityType != string(types.EntityTypeCharacter) || entity.Name == "" {
+			continue
+		}
+		pattern := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(entity.Name) + `\b`)
+		if pattern.MatchString(content) {
+			mentioned = append(mentioned, entity.ID)
+		}
+	}
+
+	sort.Strings(mentioned)
+	return mentioned, nil
+}
+
+// LinkMentions applies a features relationship from the scene to every character
+// ExtractMentions finds that the scene does not already feature. When there is
+// nothing to link, no version is created and the given version is returned with
+// Applied 0.
+func (s *Service) LinkMentions(ctx context.Context, versionID, sceneLogicalID string) (*ApplyResponse, error) {
+	mentioned, err := s.ExtractMentions(ctx, versionID, sceneLogicalID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.RelationshipsAmong(ctx, versionID, append([]string{sceneLogicalID}, mentioned...))
+	if err != nil {
+		return nil, err
+	}
+	featured := make(map[string]bool)
+	for _, rel := range existing {
+		if rel.FromEntityID == sceneLogicalID && rel.RelationshipType == string(types.RelationshipFeatures) {
+			featured[rel.ToEntityID] = true
+		}
+	}
+
+	var relationships []*RelationshipDelta
+	for _, characterID := range mentioned {
+		if featured[characterID] {
+			continue
+		}
+		relationships = append(relationships, &RelationshipDelta{
+			Operation:        "create",
+			FromEntityID:     sceneLogicalID,
+			ToEntityID:       characterID,
+			RelationshipType: string(types.RelationshipFeatures),
+			Properties:       map[string]any{"source": "mention"},
+		})
+	}
+	if len(relationships) == 0 {
+		return &ApplyResponse{GraphVersionID: versionID, Applied: 0}, nil
+	}
+
+	// Relationship deltas ride on an entity delta, so restate the scene unchanged
+	scene, err := s.entityInVersion(ctx, versionID, sceneLogicalID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Apply(ctx, &ApplyRequest{
+		ParentVersionID: versionID,
+		Deltas: []*Delta{{
+			Operation:     "update",
+			EntityType:    scene.EntityType,
+			EntityID:      sceneLogicalID,
+			Fields:        scene.Data,
+			Relationships: relationships,
+		}},
+	})
+}
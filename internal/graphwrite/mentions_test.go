@@ -0,0 +1,100 @@
+package graphwrite
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// createTavernVersion creates a tavern scene whose content mentions Elena and Mordak
+// but not Marcus, featuring only Elena
+func createTavernVersion(t *testing.T, service GraphWriteService, parentVersionID string) string {
+	response, err := service.Apply(context.Background(), &ApplyRequest{
+		ParentVersionID: parentVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{Operation: "create", EntityType: "Character", EntityID: "mordak", Fields: map[string]any{"name": "Mordak the Shadow"}},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}},
+			{
+				Operation:  "create",
+				EntityType: "Scene",
+				EntityID:   "tavern",
+				Fields: map[string]any{
+					"name":    "The Whispering Tavern",
+					"content": "ELENA pushed the door open. In the corner sat mordak the shadow, waiting. Marcusville was far away.",
+				},
+				Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "tavern", ToEntityID: "elena", RelationshipType: "features", Properties: map[string]any{}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	return response.GraphVersionID
+}
+
+func TestService_ExtractMentions(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createTavernVersion(t, service, rootVersionID)
+
+	mentioned, err := service.ExtractMentions(ctx, versionID, "tavern")
+	if err != nil {
+		t.Fatalf("ExtractMentions failed: %v", err)
+	}
+	if expected := []string{"elena", "mordak"}; !reflect.DeepEqual(mentioned, expected) {
+		t.Errorf("Expected %v, got %v", expected, mentioned)
+	}
+
+	if _, err := service.ExtractMentions(ctx, versionID, "elena"); err == nil {
+		t.Error("Expected an error for an entity that is not a scene")
+	}
+}
+
+func TestService_LinkMentions(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createTavernVersion(t, service, rootVersionID)
+
+	response, err := service.LinkMentions(ctx, versionID, "tavern")
+	if err != nil {
+		t.Fatalf("LinkMentions failed: %v", err)
+	}
+	if response.Applied != 1 || response.GraphVersionID == versionID {
+		t.Fatalf("Expected a new version, got %+v", response)
+	}
+
+	relationships, err := service.(*Service).relationshipsByLogicalKey(ctx, response.GraphVersionID)
+	if err != nil {
+		t.Fatalf("relationshipsByLogicalKey failed: %v", err)
+	}
+	if len(relationships) != 2 {
+		t.Errorf("Expected elena to keep one features edge and mordak to gain one, got %v", relationships)
+	}
+	if properties, ok := relationships[relationshipKey{From: "tavern", To: "mordak", Type: "features"}]; !ok || properties["source"] != "mention" {
+		t.Errorf("Expected a mention-sourced features edge to mordak, got %v", relationships)
+	}
+
+	// Everything mentioned is now featured
+	again, err := service.LinkMentions(ctx, response.GraphVersionID, "tavern")
+	if err != nil {
+		t.Fatalf("LinkMentions failed: %v", err)
+	}
+	if again.Applied != 0 || again.GraphVersionID != response.GraphVersionID {
+		t.Errorf("Expected nothing to link, got %+v", again)
+	}
+}
@@ -0,0 +1,268 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// MergeResult is the outcome of a three-way Merge
+type MergeResult struct {
+	// VersionID is the merged version, a child of the left version. It is empty when
+	// the merge has conflicts, and the left version itself when the right side adds
+	// nothing to it.
+	VersionID string
+
+	// Conflicts lists every value changed differently on both sides, sorted by key
+	// and field. Nothing is written while any remain.
+	Conflicts []MergeConflict
+}
+
+// mergeEntity is an entity's type and data fields as compared by Merge
+type mergeEntity struct {
+	EntityType string
+	Fields     map[string]any
+}
+
+// Merge three-way merges two versions that branched from base, keyed on logical IDs.
+// Changes made on only one side are taken; entity fields and relationship properties
+// changed differently on both sides, entities added on both sides with different
+// types, and entities or edges deleted on one side but modified on the other are
+// reported as conflicts instead. A clean merge is applied on top of the left version
+// as a new version holding both sides' changes.
+func (s *Service) Merge(ctx context.Context, baseVersionID, leftVersionID, rightVersionID string) (*MergeResult, error) {
+	left, err := s.db.Queries().GetGraphVersion(ctx, leftVersionID)
+	if err != nil {
+		return nil, fmt.Errorf("left %w: %w", ErrVersionNotFound, err)
+	}
+	for _, versionID := range []string{baseVersionID, rightVersionID} {
+		version, err := s.db.Queries().GetGraphVersion(ctx, versionID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrVersionNotFound, versionID, err)
+		}
+		if version.ProjectID != left.ProjectID {
+			return nil, fmt.Errorf("%w: %s is not in project %s", ErrVersionNotInProject, versionID, left.ProjectID)
+		}
+	}
+
+	var entitySets [3]map[string]mergeEntity
+	var relationshipSets [3]map[relationshipKey]map[string]any
+	for i, versionID := range []string{baseVersionID, leftVersionID, rightVersionID} {
+		if entitySets[i], err = s.mergeEntities(ctx, versionID); err != nil {
+			return nil, err
+		}
+		if relationshipSets[i], err = s.relationshipsByLogicalKey(ctx, versionID); err != nil {
+			return nil, err
+		}
+	}
+
+	entities, conflicts := mergeEntitySets(entitySets[0], entitySets[1], entitySets[2])
+	relationships, relationshipConflicts := mergeRelationships(relationshipSets[0], relationshipSets[1], relationshipSets[2])
+	conflicts = append(conflicts, relationshipConflicts...)
+
+	// An edge kept by one side loses its endpoint when the other side deleted the entity
+	for key := range relationships {
+		_, fromExists := entities[key.From]
+		_, toExists := entities[key.To]
+		if fromExists && toExists {
+			continue
+		}
+		delete(relationships, key)
+		conflicts = append(conflicts, MergeConflict{Key: key.String(), Base: relationshipSets[0][key], Left: relationshipSets[1][key], Right: relationshipSets[2][key]})
+	}
+
+	if len(conflicts) > 0 {
+		sort.Slice(conflicts, func(i, j int) bool {
+			if conflicts[i].Key != conflicts[j].Key {
+				return conflicts[i].Key < conflicts[j].Key
+			}
+			return conflicts[i].Field < conflicts[j].Field
+		})
+		return &MergeResult{Conflicts: conflicts}, nil
+	}
+
+	deltas := mergeDeltas(entitySets[1], entities, relationshipSets[1], relationships)
+	if len(deltas) == 0 {
+		return &MergeResult{VersionID: leftVersionID}, nil
+	}
+
+	response, err := s.Apply(ctx, &ApplyRequest{ParentVersionID: leftVersionID, Deltas: deltas})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply merge: %w", err)
+	}
+
+	return &MergeResult{VersionID: response.GraphVersionID}, nil
+}
+
+// mergeEntities loads a version's entities keyed by logical ID for merging
+func (s *Service) mergeEntities(ctx context.Context, versionID string) (map[string]mergeEntity, error) {
+	entities, err := s.ListEntities(ctx, versionID, EntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]mergeEntity, len(entities))
+	for _, entity := range entities {
+		fields := make(map[string]any, len(entity.Data))
+		for name, value := range entity.Data {
+			if name != "logical_id" {
+				fields[name] = value
+			}
+		}
+		result[entity.ID] = mergeEntity{EntityType: entity.EntityType, Fields: fields}
+	}
+
+	return result, nil
+}
+
+// mergeEntitySets three-way merges entities keyed by logical ID, with the same rules as
+// mergeRelationships: fields merge one by one, and an entity deleted on one side and
+// modified on the other is kept with the modification and reported as a conflict.
+func mergeEntitySets(base, left, right map[string]mergeEntity) (map[string]mergeEntity, []MergeConflict) {
+	ids := make(map[string]bool)
+	for _, set := range []map[string]mergeEntity{base, left, right} {
+		for id := range set {
+			ids[id] = true
+		}
+	}
+
+	merged := make(map[string]mergeEntity)
+	var conflicts []MergeConflict
+	for id := range ids {
+		baseEntity, inBase := base[id]
+		leftEntity, inLeft := left[id]
+		rightEntity, inRight := right[id]
+
+		switch {
+		case inLeft && inRight:
+			if leftEntity.EntityType != rightEntity.EntityType {
+				conflicts = append(conflicts, MergeConflict{Key: id, Field: "entity_type", Base: baseEntity.EntityType, Left: leftEntity.EntityType, Right: rightEntity.EntityType})
+				continue
+			}
+			fields, fieldConflicts := mergeProperties(id, baseEntity.Fields, leftEntity.Fields, rightEntity.Fields)
+			merged[id] = mergeEntity{EntityType: leftEntity.EntityType, Fields: fields}
+			conflicts = append(conflicts, fieldConflicts...)
+
+		case !inBase && inLeft:
+			merged[id] = leftEntity
+		case !inBase && inRight:
+			merged[id] = rightEntity
+
+		case inBase && inLeft && !inRight:
+			if !reflect.DeepEqual(baseEntity.Fields, leftEntity.Fields) {
+				merged[id] = leftEntity
+				conflicts = append(conflicts, MergeConflict{Key: id, Base: baseEntity.Fields, Left: leftEntity.Fields})
+			}
+		case inBase && inRight && !inLeft:
+			if !reflect.DeepEqual(baseEntity.Fields, rightEntity.Fields) {
+				merged[id] = rightEntity
+				conflicts = append(conflicts, MergeConflict{Key: id, Base: baseEntity.Fields, Right: rightEntity.Fields})
+			}
+		}
+	}
+
+	return merged, conflicts
+}
+
+// mergeDeltas builds the deltas turning the left version into the merged result. New
+// entities are created first, then entities with changed fields or edges are updated,
+// unsetting fields the merge removed, then removed entities are deleted along with
+// their edges. Changed and new edges are deleted by logical tuple before being created,
+// which also replaces edges the new entities' field relationship rules create with
+// empty properties.
+func mergeDeltas(leftEntities, entities map[string]mergeEntity, leftRelationships, relationships map[relationshipKey]map[string]any) []*Delta {
+	relationshipDeltas := make(map[string][]*RelationshipDelta)
+	addRelationshipDelta := func(relDelta *RelationshipDelta) {
+		relationshipDeltas[relDelta.FromEntityID] = append(relationshipDeltas[relDelta.FromEntityID], relDelta)
+	}
+
+	// Edges the new entities' field relationship rules would create implicitly
+	implied := make(map[relationshipKey]bool)
+	for id, entity := range entities {
+		if _, exists := leftEntities[id]; exists {
+			continue
+		}
+		for _, rule := range types.FieldRelationshipRulesFor(types.EntityType(entity.EntityType)) {
+			for _, targetID := range referencedIDs(entity.Fields[rule.Field]) {
+				implied[relationshipKey{From: id, To: targetID, Type: string(rule.RelationshipType)}] = true
+			}
+		}
+	}
+
+	for _, key := range sortedRelationshipKeys(relationships) {
+		properties := relationships[key]
+		if leftProperties, exists := leftRelationships[key]; exists && reflect.DeepEqual(leftProperties, properties) {
+			continue
+		}
+		addRelationshipDelta(&RelationshipDelta{Operation: "delete", FromEntityID: key.From, ToEntityID: key.To, RelationshipType: key.Type})
+		addRelationshipDelta(&RelationshipDelta{Operation: "create", FromEntityID: key.From, ToEntityID: key.To, RelationshipType: key.Type, Properties: properties})
+	}
+	for _, key := range sortedRelationshipKeys(leftRelationships) {
+		if _, kept := relationships[key]; kept {
+			continue
+		}
+		if _, exists := entities[key.From]; !exists {
+			continue // goes with the deleted entity
+		}
+		if _, exists := entities[key.To]; !exists {
+			continue
+		}
+		addRelationshipDelta(&RelationshipDelta{Operation: "delete", FromEntityID: key.From, ToEntityID: key.To, RelationshipType: key.Type})
+	}
+	for key := range implied {
+		if _, kept := relationships[key]; !kept {
+			addRelationshipDelta(&RelationshipDelta{Operation: "delete", FromEntityID: key.From, ToEntityID: key.To, RelationshipType: key.Type})
+		}
+	}
+
+	ids := make([]string, 0, len(entities))
+	for id := range entities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var creates, updates, deletes []*Delta
+	for _, id := range ids {
+		entity := entities[id]
+		leftEntity, inLeft := leftEntities[id]
+		if !inLeft {
+			creates = append(creates, &Delta{Operation: "create", EntityType: entity.EntityType, EntityID: id, Fields: entity.Fields})
+		}
+		if (inLeft && !reflect.DeepEqual(leftEntity.Fields, entity.Fields)) || len(relationshipDeltas[id]) > 0 {
+			var unset []string
+			for field := range leftEntity.Fields {
+				if _, kept := entity.Fields[field]; !kept {
+					unset = append(unset, field)
+				}
+			}
+			sort.Strings(unset)
+			updates = append(updates, &Delta{Operation: "update", EntityType: entity.EntityType, EntityID: id, Fields: entity.Fields, UnsetFields: unset, Relationships: relationshipDeltas[id]})
+		}
+	}
+
+	leftIDs := make([]string, 0, len(leftEntities))
+	for id := range leftEntities {
+		leftIDs = append(leftIDs, id)
+	}
+	sort.Strings(leftIDs)
+	for _, id := range leftIDs {
+		if _, kept := entities[id]; !kept {
+			deletes = append(deletes, &Delta{Operation: "delete", EntityType: leftEntities[id].EntityType, EntityID: id})
+		}
+	}
+
+	return append(append(creates, updates...), deletes...)
+}
+
+// sortedRelationshipKeys returns a relationship set's keys in a stable order
+func sortedRelationshipKeys(relationships map[relationshipKey]map[string]any) []relationshipKey {
+	keys := make([]relationshipKey, 0, len(relationships))
+	for key := range relationships {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys
+}
@@ -0,0 +1,165 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MergeConflict describes a value changed differently on both sides of a three-way merge.
+// Base, Left and Right hold the value on each side; nil means absent.
+type MergeConflict struct {
+	// Key identifies what conflicted: a logical entity ID, or a relationship
+	// rendered as "from -type-> to"
+	Key string
+
+	// Field is the conflicting field or property. It is empty when the whole
+	// item conflicts, for example an edge deleted on one side and modified on the other.
+	Field string
+
+	Base  any
+	Left  any
+	Right any
+}
+
+// relationshipKey identifies a relationship across versions by its logical endpoints and type
+type relationshipKey struct {
+	From string
+	To   string
+	Type string
+}
+
+func (k relationshipKey) String() string {
+	return fmt.Sprintf("%s -%s-> %s", k.From, k.Type, k.To)
+}
+
+// relationshipsByLogicalKey loads a version's relationships keyed by logical endpoints,
+// with their decoded properties, leaving out those touching a tombstoned entity
+func (s *Service) relationshipsByLogicalKey(ctx context.Context, versionID string) (map[relationshipKey]map[string]any, error) {
+	logicalIDs, tombstones, err := s.logicalIDsWithTombstones(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	relationships, err := s.db.Queries().ListRelationshipsByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	result := make(map[relationshipKey]map[string]any, len(relationships))
+	for _, rel := range relationships {
+		if tombstones[rel.FromEntityID] || tombstones[rel.ToEntityID] {
+			continue
+		}
+		properties := map[string]any{}
+		if len(rel.Properties) > 0 {
+			if err := json.Unmarshal(rel.Properties, &properties); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal relationship properties: %w", err)
+			}
+			if properties == nil {
+				properties = map[string]any{}
+			}
+		}
+		key := relationshipKey{From: logicalIDs[rel.FromEntityID], To: logicalIDs[rel.ToEntityID], Type: rel.RelationshipType}
+		result[key] = properties
+	}
+
+	return result, nil
+}
+
+// mergeRelationships three-way merges relationship sets keyed by logical endpoints.
+//
+// An edge added or deleted on one side only takes that side's change. Properties merge
+// key by key: a property changed on one side only takes the change, and a property
+// changed differently on both sides keeps its base value and is reported as a conflict.
+// An edge deleted on one side and modified on the other is kept with the modification
+// and reported as a conflict with an empty Field.
+func mergeRelationships(base, left, right map[relationshipKey]map[string]any) (map[relationshipKey]map[string]any, []MergeConflict) {
+	keys := make(map[relationshipKey]bool)
+	for _, set := range []map[relationshipKey]map[string]any{base, left, right} {
+		for key := range set {
+			keys[key] = true
+		}
+	}
+
+	merged := make(map[relationshipKey]map[string]any)
+	var conflicts []MergeConflict
+	for key := range keys {
+		baseProps, inBase := base[key]
+		leftProps, inLeft := left[key]
+		rightProps, inRight := right[key]
+
+		switch {
+		case inLeft && inRight:
+			properties, propertyConflicts := mergeProperties(key.String(), baseProps, leftProps, rightProps)
+			merged[key] = properties
+			conflicts = append(conflicts, propertyConflicts...)
+
+		case !inBase && inLeft:
+			merged[key] = leftProps
+		case !inBase && inRight:
+			merged[key] = rightProps
+
+		case inBase && inLeft && !inRight:
+			if !reflect.DeepEqual(baseProps, leftProps) {
+				merged[key] = leftProps
+				conflicts = append(conflicts, MergeConflict{Key: key.String(), Base: baseProps, Left: leftProps})
+			}
+		case inBase && inRight && !inLeft:
+			if !reflect.DeepEqual(baseProps, rightProps) {
+				merged[key] = rightProps
+				conflicts = append(conflicts, MergeConflict{Key: key.String(), Base: baseProps, Right: rightProps})
+			}
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Key != conflicts[j].Key {
+			return conflicts[i].Key < conflicts[j].Key
+		}
+		return conflicts[i].Field < conflicts[j].Field
+	})
+
+	return merged, conflicts
+}
+
+// mergeProperties three-way merges a single edge's properties; base may be nil when
+// both sides added the edge independently
+func mergeProperties(key string, base, left, right map[string]any) (map[string]any, []MergeConflict) {
+	names := make(map[string]bool)
+	for _, props := range []map[string]any{base, left, right} {
+		for name := range props {
+			names[name] = true
+		}
+	}
+
+	merged := make(map[string]any)
+	var conflicts []MergeConflict
+	for name := range names {
+		baseValue, inBase := base[name]
+		leftValue, inLeft := left[name]
+		rightValue, inRight := right[name]
+
+		var value any
+		var present bool
+		switch {
+		case inLeft == inRight && reflect.DeepEqual(leftValue, rightValue):
+			value, present = leftValue, inLeft
+		case inLeft == inBase && reflect.DeepEqual(leftValue, baseValue):
+			value, present = rightValue, inRight
+		case inRight == inBase && reflect.DeepEqual(rightValue, baseValue):
+			value, present = leftValue, inLeft
+		default:
+			value, present = baseValue, inBase
+			conflicts = append(conflicts, MergeConflict{Key: key, Field: name, Base: baseValue, Left: leftValue, Right: rightValue})
+		}
+
+		if present {
+			merged[name] = value
+		}
+	}
+
+	return merged, conflicts
+}
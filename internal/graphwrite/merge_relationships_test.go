@@ -0,0 +1,171 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// createAlliesVersion creates elena and marcus with an allies_with edge carrying the given bond strength
+func createAlliesVersion(t *testing.T, service GraphWriteService, parentVersionID string, bondStrength string) string {
+	response, err := service.Apply(context.Background(), &ApplyRequest{
+		ParentVersionID: parentVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{
+				Operation:  "create",
+				EntityType: "Character",
+				EntityID:   "marcus",
+				Fields:     map[string]any{"name": "Marcus"},
+				Relationships: []*RelationshipDelta{
+					{
+						Operation:        "create",
+						FromEntityID:     "elena",
+						ToEntityID:       "marcus",
+						RelationshipType: "allies_with",
+						Properties:       map[string]any{"bond_strength": bondStrength, "since": "chapter-1"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	return response.GraphVersionID
+}
+
+// branchWithBondStrength copies a version and sets bond_strength on its allies_with edge
+func branchWithBondStrength(t *testing.T, service GraphWriteService, database *db.Database, baseVersionID string, bondStrength string) string {
+	ctx := context.Background()
+
+	branch, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: baseVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Location", EntityID: "camp-" + bondStrength, Fields: map[string]any{"name": "Camp"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	relationships, err := database.Queries().ListRelationshipsByVersion(ctx, branch.GraphVersionID)
+	if err != nil {
+		t.Fatalf("ListRelationshipsByVersion failed: %v", err)
+	}
+	properties, _ := json.Marshal(map[string]any{"bond_strength": bondStrength, "since": "chapter-1"})
+	for _, rel := range relationships {
+		if rel.RelationshipType != "allies_with" {
+			continue
+		}
+		if _, err := database.Queries().UpdateRelationship(ctx, db.UpdateRelationshipParams{ID: rel.ID, Properties: properties}); err != nil {
+			t.Fatalf("UpdateRelationship failed: %v", err)
+		}
+	}
+	return branch.GraphVersionID
+}
+
+func TestService_MergeRelationships_BothSidesChangeBondStrength(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+	leftVersionID := branchWithBondStrength(t, service, database, baseVersionID, "strong")
+	rightVersionID := branchWithBondStrength(t, service, database, baseVersionID, "strained")
+
+	impl := service.(*Service)
+	base, err := impl.relationshipsByLogicalKey(ctx, baseVersionID)
+	if err != nil {
+		t.Fatalf("relationshipsByLogicalKey failed: %v", err)
+	}
+	left, err := impl.relationshipsByLogicalKey(ctx, leftVersionID)
+	if err != nil {
+		t.Fatalf("relationshipsByLogicalKey failed: %v", err)
+	}
+	right, err := impl.relationshipsByLogicalKey(ctx, rightVersionID)
+	if err != nil {
+		t.Fatalf("relationshipsByLogicalKey failed: %v", err)
+	}
+
+	merged, conflicts := mergeRelationships(base, left, right)
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected one conflict, got %+v", conflicts)
+	}
+
+	key := relationshipKey{From: "elena", To: "marcus", Type: "allies_with"}
+	conflict := conflicts[0]
+	if conflict.Key != key.String() || conflict.Field != "bond_strength" {
+		t.Errorf("Expected a bond_strength conflict on %s, got %+v", key, conflict)
+	}
+	if conflict.Base != "growing" || conflict.Left != "strong" || conflict.Right != "strained" {
+		t.Errorf("Expected growing/strong/strained, got %v/%v/%v", conflict.Base, conflict.Left, conflict.Right)
+	}
+
+	// The conflicting property keeps its base value; untouched properties carry through
+	if merged[key]["bond_strength"] != "growing" || merged[key]["since"] != "chapter-1" {
+		t.Errorf("Expected base bond_strength and unchanged since, got %v", merged[key])
+	}
+}
+
+func TestService_MergeRelationships_OneSideChangesBondStrength(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+	leftVersionID := branchWithBondStrength(t, service, database, baseVersionID, "strong")
+	rightVersionID := branchWithBondStrength(t, service, database, baseVersionID, "growing")
+
+	impl := service.(*Service)
+	base, _ := impl.relationshipsByLogicalKey(ctx, baseVersionID)
+	left, _ := impl.relationshipsByLogicalKey(ctx, leftVersionID)
+	right, _ := impl.relationshipsByLogicalKey(ctx, rightVersionID)
+
+	merged, conflicts := mergeRelationships(base, left, right)
+	if len(conflicts) != 0 {
+		t.Fatalf("Expected a clean merge, got %+v", conflicts)
+	}
+
+	key := relationshipKey{From: "elena", To: "marcus", Type: "allies_with"}
+	if merged[key]["bond_strength"] != "strong" {
+		t.Errorf("Expected the one-sided change to win, got %v", merged[key])
+	}
+}
+
+func TestMergeRelationships_DeleteVersusModify(t *testing.T) {
+	key := relationshipKey{From: "elena", To: "marcus", Type: "allies_with"}
+	base := map[relationshipKey]map[string]any{key: {"bond_strength": "growing"}}
+	left := map[relationshipKey]map[string]any{}
+	right := map[relationshipKey]map[string]any{key: {"bond_strength": "strong"}}
+
+	merged, conflicts := mergeRelationships(base, left, right)
+	if len(conflicts) != 1 || conflicts[0].Field != "" || conflicts[0].Right == nil {
+		t.Fatalf("Expected a whole-edge conflict, got %+v", conflicts)
+	}
+	if merged[key]["bond_strength"] != "strong" {
+		t.Errorf("Expected the modified edge to be kept, got %v", merged)
+	}
+
+	// Deleting an edge the other side left alone is not a conflict
+	right[key] = map[string]any{"bond_strength": "growing"}
+	merged, conflicts = mergeRelationships(base, left, right)
+	if len(conflicts) != 0 {
+		t.Errorf("Expected a clean delete, got %+v", conflicts)
+	}
+	if _, ok := merged[key]; ok {
+		t.Errorf("Expected the edge to be deleted, got %v", merged)
+	}
+}
@@ -0,0 +1,198 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+)
+
+// applyBranch applies deltas on top of a version and returns the new version
+func applyBranch(t *testing.T, service GraphWriteService, parentVersionID string, deltas ...*Delta) string {
+	response, err := service.Apply(context.Background(), &ApplyRequest{ParentVersionID: parentVersionID, Deltas: deltas})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	return response.GraphVersionID
+}
+
+func TestService_Merge_Clean(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+	left := applyBranch(t, service, baseVersionID,
+		&Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena the Bold"}},
+		&Delta{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}},
+	)
+	right := applyBranch(t, service, baseVersionID,
+		&Delta{Operation: "create", EntityType: "Character", EntityID: "aria", Fields: map[string]any{"name": "Aria"}, Relationships: []*RelationshipDelta{
+			{Operation: "create", FromEntityID: "aria", ToEntityID: "marcus", RelationshipType: "mentors", Properties: map[string]any{"since": "chapter-2"}},
+		}},
+	)
+
+	result, err := service.Merge(ctx, baseVersionID, left, right)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("Expected a clean merge, got %+v", result.Conflicts)
+	}
+	if result.VersionID == "" || result.VersionID == left {
+		t.Fatalf("Expected a new merged version, got %q", result.VersionID)
+	}
+
+	names := entityNames(t, service, result.VersionID)
+	want := map[string]string{"elena": "Elena the Bold", "marcus": "Marcus", "tavern": "Tavern", "aria": "Aria"}
+	if len(names) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, names)
+	}
+	for id, name := range want {
+		if names[id] != name {
+			t.Errorf("Expected %s to be %q, got %q", id, name, names[id])
+		}
+	}
+
+	relationships, err := service.(*Service).relationshipsByLogicalKey(ctx, result.VersionID)
+	if err != nil {
+		t.Fatalf("relationshipsByLogicalKey failed: %v", err)
+	}
+	if len(relationships) != 2 {
+		t.Errorf("Expected both the base and the right side's edges, got %v", relationships)
+	}
+	if mentors := relationships[relationshipKey{From: "aria", To: "marcus", Type: "mentors"}]; mentors["since"] != "chapter-2" {
+		t.Errorf("Expected aria to mentor marcus since chapter-2, got %v", mentors)
+	}
+}
+
+func TestService_Merge_AddAddSameLogicalID(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	baseVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	tavern := func(name string, atmosphere string) *Delta {
+		return &Delta{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": name, "atmosphere": atmosphere}}
+	}
+
+	// The same addition on both sides merges cleanly
+	result, err := service.Merge(ctx, baseVersionID,
+		applyBranch(t, service, baseVersionID, tavern("Tavern", "smoky")),
+		applyBranch(t, service, baseVersionID, tavern("Tavern", "smoky")))
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("Expected identical additions to merge cleanly, got %+v", result.Conflicts)
+	}
+	if names := entityNames(t, service, result.VersionID); len(names) != 1 || names["tavern"] != "Tavern" {
+		t.Errorf("Expected one tavern, got %v", names)
+	}
+
+	// Different additions of the same logical ID conflict on the differing fields
+	result, err = service.Merge(ctx, baseVersionID,
+		applyBranch(t, service, baseVersionID, tavern("Tavern", "smoky")),
+		applyBranch(t, service, baseVersionID, tavern("Tavern", "rowdy")))
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Key != "tavern" || result.Conflicts[0].Field != "atmosphere" {
+		t.Fatalf("Expected an atmosphere conflict on the tavern, got %+v", result.Conflicts)
+	}
+	if conflict := result.Conflicts[0]; conflict.Base != nil || conflict.Left != "smoky" || conflict.Right != "rowdy" {
+		t.Errorf("Unexpected conflict values: %+v", conflict)
+	}
+	if result.VersionID != "" {
+		t.Errorf("Expected no version for a conflicting merge, got %s", result.VersionID)
+	}
+}
+
+func TestService_Merge_ConflictingFieldEdits(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+	left := applyBranch(t, service, baseVersionID,
+		&Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena the Bold"}},
+		&Delta{Operation: "update", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus", "role": "mentor"}},
+	)
+	right := applyBranch(t, service, baseVersionID,
+		&Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena the Wise"}},
+	)
+
+	versionsBefore, err := database.Queries().ListGraphVersionsByProject(ctx, projectID)
+	if err != nil {
+		t.Fatalf("ListGraphVersionsByProject failed: %v", err)
+	}
+
+	result, err := service.Merge(ctx, baseVersionID, left, right)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Conflicts) != 1 {
+		t.Fatalf("Expected one conflict, got %+v", result.Conflicts)
+	}
+	conflict := result.Conflicts[0]
+	if conflict.Key != "elena" || conflict.Field != "name" || conflict.Base != "Elena" || conflict.Left != "Elena the Bold" || conflict.Right != "Elena the Wise" {
+		t.Errorf("Unexpected conflict: %+v", conflict)
+	}
+
+	versionsAfter, err := database.Queries().ListGraphVersionsByProject(ctx, projectID)
+	if err != nil {
+		t.Fatalf("ListGraphVersionsByProject failed: %v", err)
+	}
+	if len(versionsAfter) != len(versionsBefore) {
+		t.Errorf("Expected a conflicting merge to write nothing, got %d versions instead of %d", len(versionsAfter), len(versionsBefore))
+	}
+}
+
+func TestService_Merge_UnsetField(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := applyBranch(t, service, rootVersionID,
+		&Delta{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "trauma": "lost her brother"}},
+	)
+
+	left := applyBranch(t, service, baseVersionID,
+		&Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"role": "mentor"}},
+	)
+	right := applyBranch(t, service, baseVersionID,
+		&Delta{Operation: "update", EntityType: "Character", EntityID: "elena", UnsetFields: []string{"trauma"}},
+	)
+
+	result, err := service.Merge(ctx, baseVersionID, left, right)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("Expected a clean merge, got %+v", result.Conflicts)
+	}
+
+	entity, err := service.(*Service).entityInVersion(ctx, result.VersionID, "elena")
+	if err != nil {
+		t.Fatalf("entityInVersion failed: %v", err)
+	}
+	if _, exists := entity.Data["trauma"]; exists || entity.Data["role"] != "mentor" {
+		t.Errorf("Expected the role kept and the trauma unset, got %v", entity.Data)
+	}
+}
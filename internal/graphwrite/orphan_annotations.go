@@ -0,0 +1,59 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// FindOrphanAnnotations lists annotations cut off from the current story: those whose
+// entity row no longer exists, and those on an old version's entity that is still in
+// its project's working set but carries no annotation of the same type and agent
+// there. The latter are left behind by copies that did not carry annotations forward.
+//
+// EntityID is the annotated entity's logical ID, or its database ID when the entity
+// row is gone.
+func (s *Service) FindOrphanAnnotations(ctx context.Context) ([]Annotation, error) {
+	orphans, err := s.db.Queries().ListOrphanAnnotations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orphan annotations: %w", err)
+	}
+
+	result := make([]Annotation, 0, len(orphans))
+	for _, orphan := range orphans {
+		entityID := orphan.EntityID
+		if entity, err := s.db.Queries().GetEntity(ctx, orphan.EntityID); err == nil {
+			var data map[string]any
+			if err := json.Unmarshal(entity.Data, &data); err == nil {
+				if logicalID, ok := data["logical_id"].(string); ok {
+					entityID = logicalID
+				}
+			}
+		}
+
+		converted, err := toAnnotation(orphan, entityID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *converted)
+	}
+
+	return result, nil
+}
+
+// PruneOrphanAnnotations deletes the annotations FindOrphanAnnotations reports and
+// returns how many were removed
+func (s *Service) PruneOrphanAnnotations(ctx context.Context) (int, error) {
+	orphans, err := s.db.Queries().ListOrphanAnnotations(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list orphan annotations: %w", err)
+	}
+
+	for _, orphan := range orphans {
+		if err := s.db.Queries().DeleteAnnotation(ctx, orphan.ID); err != nil {
+			return 0, fmt.Errorf("failed to delete annotation %s: %w", orphan.ID, err)
+		}
+	}
+
+	return len(orphans), nil
+}
@@ -0,0 +1,61 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+)
+
+func TestService_FindOrphanAnnotations(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createFeaturedSceneVersion(t, service, rootVersionID)
+	annotationID := createTestAnnotation(t, database, databaseIDForEntity(t, database, versionID, "scene-1"), "pacing_analysis", nil)
+
+	response, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+		ParentVersionID: versionID,
+		Deltas:          []*Delta{{Operation: "update", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": "Revised"}}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	// The annotation followed the scene into the working set
+	orphans, err := service.FindOrphanAnnotations(ctx)
+	if err != nil {
+		t.Fatalf("FindOrphanAnnotations failed: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Fatalf("Expected no orphans while annotations follow their entities, got %+v", orphans)
+	}
+
+	// Simulate a copy made before annotations followed their entities
+	workingSceneID := databaseIDForEntity(t, database, response.GraphVersionID, "scene-1")
+	if err := database.Queries().DeleteAnnotationsByEntity(ctx, workingSceneID); err != nil {
+		t.Fatalf("DeleteAnnotationsByEntity failed: %v", err)
+	}
+
+	orphans, err = service.FindOrphanAnnotations(ctx)
+	if err != nil {
+		t.Fatalf("FindOrphanAnnotations failed: %v", err)
+	}
+	if len(orphans) != 1 || orphans[0].ID != annotationID || orphans[0].EntityID != "scene-1" {
+		t.Fatalf("Expected the old version's annotation to be orphaned, got %+v", orphans)
+	}
+
+	pruned, err := service.PruneOrphanAnnotations(ctx)
+	if err != nil {
+		t.Fatalf("PruneOrphanAnnotations failed: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("Expected 1 pruned annotation, got %d", pruned)
+	}
+	if orphans, _ := service.FindOrphanAnnotations(ctx); len(orphans) != 0 {
+		t.Errorf("Expected no orphans after pruning, got %+v", orphans)
+	}
+}
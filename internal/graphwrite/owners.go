@@ -0,0 +1,102 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+type ownerKey struct{}
+
+// WithOwner returns a context scoping project listings, search and shared-entity scans
+// to the projects of one owner. Without an owner the service is single-tenant and
+// sees every project.
+func WithOwner(ctx context.Context, ownerID string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, ownerID)
+}
+
+// OwnerFromContext returns the owner set by WithOwner, if any
+func OwnerFromContext(ctx context.Context) (string, bool) {
+	ownerID, ok := ctx.Value(ownerKey{}).(string)
+	return ownerID, ok && ownerID != ""
+}
+
+// SetProjectOwner assigns a project to an owner; an empty owner makes it unowned again
+func (s *Service) SetProjectOwner(ctx context.Context, projectID string, ownerID string) error {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return err
+	}
+	if _, err := s.db.Queries().GetProject(ctx, projectID); err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+
+	if err := s.db.Queries().SetProjectOwner(ctx, db.SetProjectOwnerParams{
+		OwnerID: sql.NullString{String: ownerID, Valid: ownerID != ""},
+		ID:      projectID,
+	}); err != nil {
+		return fmt.Errorf("failed to set project owner: %w", err)
+	}
+
+	return nil
+}
+
+// listProjects lists the projects visible to the context's owner, or every project
+// when no owner is set
+func (s *Service) listProjects(ctx context.Context) ([]db.Project, error) {
+	return listProjectsIn(ctx, s.db)
+}
+
+// listProjectsIn is listProjects on a given store, such as a transaction's
+func listProjectsIn(ctx context.Context, store Store) ([]db.Project, error) {
+	ownerID, ok := OwnerFromContext(ctx)
+	if !ok {
+		return store.Queries().ListProjects(ctx)
+	}
+	return store.Queries().ListProjectsByOwner(ctx, sql.NullString{String: ownerID, Valid: true})
+}
+
+// ownsProject reports whether a project is visible to the context's owner
+func ownsProject(ctx context.Context, projectOwnerID sql.NullString) bool {
+	ownerID, ok := OwnerFromContext(ctx)
+	return !ok || (projectOwnerID.Valid && projectOwnerID.String == ownerID)
+}
+
+// checkVersionOwner returns ErrProjectNotOwned when the version's project is not
+// visible to the context's owner
+func (s *Service) checkVersionOwner(ctx context.Context, versionID string) error {
+	if _, ok := OwnerFromContext(ctx); !ok {
+		return nil
+	}
+
+	version, err := s.db.Queries().GetGraphVersion(ctx, versionID)
+	if err != nil {
+		return fmt.Errorf("failed to get version: %w", err)
+	}
+	return s.checkProjectOwner(ctx, version.ProjectID)
+}
+
+// checkProjectOwner returns ErrProjectNotOwned when a project is not visible to the
+// context's owner. Every entry point taking a project ID calls it first.
+func (s *Service) checkProjectOwner(ctx context.Context, projectID string) error {
+	if _, ok := OwnerFromContext(ctx); !ok {
+		return nil
+	}
+
+	project, err := s.db.Queries().GetProject(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if !ownsProject(ctx, project.OwnerID) {
+		return fmt.Errorf("%w: %s", ErrProjectNotOwned, project.ID)
+	}
+
+	return nil
+}
+
+// contextOwner returns the context's owner as a column value, NULL without one
+func contextOwner(ctx context.Context) sql.NullString {
+	ownerID, ok := OwnerFromContext(ctx)
+	return sql.NullString{String: ownerID, Valid: ok}
+}
@@ -0,0 +1,162 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+func TestService_OwnersIsolateProjects(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	// Elena appears in one project for each owner, and Marcus in both of alice's
+	var aliceProjects, bobProjects, versionIDs []string
+	for i, owner := range []string{"alice", "alice", "bob"} {
+		projectID := createTestProject(t, database)
+		if err := service.SetProjectOwner(ctx, projectID, owner); err != nil {
+			t.Fatalf("SetProjectOwner failed: %v", err)
+		}
+		if owner == "alice" {
+			aliceProjects = append(aliceProjects, projectID)
+		} else {
+			bobProjects = append(bobProjects, projectID)
+		}
+
+		deltas := []*Delta{{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}}}
+		if i < 2 {
+			deltas = append(deltas, &Delta{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}})
+		}
+		response, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+			ParentVersionID: createTestGraphVersion(t, database, projectID, true),
+			Deltas:          deltas,
+		})
+		if err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+		versionIDs = append(versionIDs, response.GraphVersionID)
+	}
+
+	aliceCtx := WithOwner(ctx, "alice")
+	bobCtx := WithOwner(ctx, "bob")
+
+	summaries, err := service.ListProjectsWithStats(aliceCtx)
+	if err != nil {
+		t.Fatalf("ListProjectsWithStats failed: %v", err)
+	}
+	if len(summaries) != len(aliceProjects) {
+		t.Fatalf("Expected alice to see %d projects, got %d", len(aliceProjects), len(summaries))
+	}
+	for _, summary := range summaries {
+		if summary.Project.OwnerID == nil || *summary.Project.OwnerID != "alice" {
+			t.Errorf("Expected only alice's projects, got %+v", summary.Project)
+		}
+	}
+
+	// Without an owner every project is visible, as before
+	summaries, err = service.ListProjectsWithStats(ctx)
+	if err != nil {
+		t.Fatalf("ListProjectsWithStats failed: %v", err)
+	}
+	if len(summaries) != 3 {
+		t.Errorf("Expected all 3 projects without an owner, got %d", len(summaries))
+	}
+
+	// Shared-entity scans stay within the owner's projects
+	shared, err := service.ListSharedEntities(aliceCtx)
+	if err != nil {
+		t.Fatalf("ListSharedEntities failed: %v", err)
+	}
+	if len(shared) != 2 {
+		t.Fatalf("Expected elena and marcus to be shared within alice's projects, got %d", len(shared))
+	}
+	for _, entity := range shared {
+		if entity.ProjectCount != 2 {
+			t.Errorf("Expected %s to be counted in alice's 2 projects only, got %d", entity.LogicalID, entity.ProjectCount)
+		}
+	}
+
+	shared, err = service.ListSharedEntities(bobCtx)
+	if err != nil {
+		t.Fatalf("ListSharedEntities failed: %v", err)
+	}
+	if len(shared) != 0 {
+		t.Errorf("Expected bob's single project to share nothing, got %d", len(shared))
+	}
+
+	history, err := service.GetEntityHistory(bobCtx, "elena")
+	if err != nil {
+		t.Fatalf("GetEntityHistory failed: %v", err)
+	}
+	if len(history) != 1 || history[0].ProjectID != bobProjects[0] {
+		t.Errorf("Expected elena's history to cover bob's project only, got %d entries", len(history))
+	}
+
+	// Search is scoped to the owner's versions
+	matches, err := service.SearchEntities(aliceCtx, versionIDs[0], "elena")
+	if err != nil {
+		t.Fatalf("SearchEntities failed: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Errorf("Expected alice to find elena in her own version, got %d", len(matches))
+	}
+	if _, err := service.SearchEntities(bobCtx, versionIDs[0], "elena"); !errors.Is(err, ErrProjectNotOwned) {
+		t.Errorf("Expected ErrProjectNotOwned searching alice's version as bob, got %v", err)
+	}
+}
+
+func TestService_OwnersGuardProjectEntryPoints(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	aliceCtx := WithOwner(context.Background(), "alice")
+	bobCtx := WithOwner(context.Background(), "bob")
+
+	// Projects created under an owner belong to it
+	projectID, _, err := service.CreateProject(aliceCtx, db.CreateProjectParams{Name: "Alice's Saga"}, "")
+	if err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+	project, err := service.GetProject(aliceCtx, projectID)
+	if err != nil {
+		t.Fatalf("GetProject failed: %v", err)
+	}
+	if project.OwnerID == nil || *project.OwnerID != "alice" {
+		t.Fatalf("Expected the new project to belong to alice, got %v", project.OwnerID)
+	}
+
+	// Every project entry point refuses another owner
+	if err := service.RenameProject(bobCtx, projectID, "Bob's Saga"); !errors.Is(err, ErrProjectNotOwned) {
+		t.Errorf("Expected ErrProjectNotOwned from RenameProject, got %v", err)
+	}
+	if _, err := service.UpdateProjectMetadata(bobCtx, projectID, ProjectMetadata{Status: ProjectStatusComplete}); !errors.Is(err, ErrProjectNotOwned) {
+		t.Errorf("Expected ErrProjectNotOwned from UpdateProjectMetadata, got %v", err)
+	}
+	if _, err := service.GetProjectOverview(bobCtx, projectID); !errors.Is(err, ErrProjectNotOwned) {
+		t.Errorf("Expected ErrProjectNotOwned from GetProjectOverview, got %v", err)
+	}
+	if _, err := service.FlattenProject(bobCtx, projectID); !errors.Is(err, ErrProjectNotOwned) {
+		t.Errorf("Expected ErrProjectNotOwned from FlattenProject, got %v", err)
+	}
+	if _, err := service.GetProjectOverview(aliceCtx, projectID); err != nil {
+		t.Errorf("Expected alice to see her own project, got %v", err)
+	}
+
+	// A flattened project keeps its owner and stays visible to it
+	if _, err := service.FlattenProject(aliceCtx, projectID); err != nil {
+		t.Fatalf("FlattenProject failed: %v", err)
+	}
+	summaries, err := service.ListProjectsWithStats(aliceCtx)
+	if err != nil {
+		t.Fatalf("ListProjectsWithStats failed: %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Errorf("Expected alice to see her project and its flattened copy, got %d", len(summaries))
+	}
+}
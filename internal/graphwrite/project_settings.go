@@ -0,0 +1,80 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// ProjectSettings holds per-project configuration
+type ProjectSettings struct {
+	// AnalyzerOrder names the analyzers the orchestrator runs first, in order.
+	// Registered analyzers not listed run afterwards in registration order.
+	AnalyzerOrder []string
+
+	// Analyzers names the analyzers the orchestrator runs. Nil uses the defaults for
+	// the project's genre; an empty list runs none.
+	Analyzers []string
+}
+
+// GetProjectSettings returns a project's settings, or the defaults if none were saved
+func (s *Service) GetProjectSettings(ctx context.Context, projectID string) (*ProjectSettings, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	settings, err := s.db.Queries().GetProjectSettings(ctx, projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &ProjectSettings{AnalyzerOrder: []string{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project settings: %w", err)
+	}
+
+	return toProjectSettings(settings)
+}
+
+// UpdateProjectSettings replaces a project's settings
+func (s *Service) UpdateProjectSettings(ctx context.Context, projectID string, settings ProjectSettings) (*ProjectSettings, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	order := settings.AnalyzerOrder
+	if order == nil {
+		order = []string{}
+	}
+	orderBytes, err := json.Marshal(order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal analyzer order: %w", err)
+	}
+	analyzersBytes, err := json.Marshal(settings.Analyzers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal analyzers: %w", err)
+	}
+
+	updated, err := s.db.Queries().UpsertProjectSettings(ctx, db.UpsertProjectSettingsParams{
+		ProjectID:     projectID,
+		AnalyzerOrder: orderBytes,
+		Analyzers:     analyzersBytes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project settings: %w", err)
+	}
+
+	return toProjectSettings(updated)
+}
+
+// toProjectSettings converts stored settings to the service representation
+func toProjectSettings(settings db.ProjectSetting) (*ProjectSettings, error) {
+	result := &ProjectSettings{AnalyzerOrder: []string{}}
+	if err := json.Unmarshal(settings.AnalyzerOrder, &result.AnalyzerOrder); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal analyzer order: %w", err)
+	}
+	if err := json.Unmarshal(settings.Analyzers, &result.Analyzers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal analyzers: %w", err)
+	}
+	return result, nil
+}
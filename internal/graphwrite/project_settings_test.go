@@ -0,0 +1,75 @@
+package graphwrite
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestService_ProjectSettings(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+
+	defaults, err := service.GetProjectSettings(ctx, projectID)
+	if err != nil {
+		t.Fatalf("GetProjectSettings failed: %v", err)
+	}
+	if len(defaults.AnalyzerOrder) != 0 {
+		t.Errorf("Expected no analyzer order by default, got %v", defaults.AnalyzerOrder)
+	}
+
+	order := []string{"empath", "continuity"}
+	if _, err := service.UpdateProjectSettings(ctx, projectID, ProjectSettings{AnalyzerOrder: order}); err != nil {
+		t.Fatalf("UpdateProjectSettings failed: %v", err)
+	}
+
+	// Saving again replaces rather than duplicates the row
+	order = []string{"continuity", "empath", "thematic"}
+	if _, err := service.UpdateProjectSettings(ctx, projectID, ProjectSettings{AnalyzerOrder: order}); err != nil {
+		t.Fatalf("UpdateProjectSettings failed: %v", err)
+	}
+
+	settings, err := service.GetProjectSettings(ctx, projectID)
+	if err != nil {
+		t.Fatalf("GetProjectSettings failed: %v", err)
+	}
+	if !reflect.DeepEqual(settings.AnalyzerOrder, order) {
+		t.Errorf("Expected analyzer order %v, got %v", order, settings.AnalyzerOrder)
+	}
+}
+
+func TestService_ProjectSettings_Analyzers(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+
+	defaults, err := service.GetProjectSettings(ctx, projectID)
+	if err != nil {
+		t.Fatalf("GetProjectSettings failed: %v", err)
+	}
+	if defaults.Analyzers != nil {
+		t.Errorf("Expected no analyzer set by default, got %v", defaults.Analyzers)
+	}
+
+	for _, analyzers := range [][]string{{"clues", "continuity"}, {}, nil} {
+		if _, err := service.UpdateProjectSettings(ctx, projectID, ProjectSettings{Analyzers: analyzers}); err != nil {
+			t.Fatalf("UpdateProjectSettings failed: %v", err)
+		}
+		settings, err := service.GetProjectSettings(ctx, projectID)
+		if err != nil {
+			t.Fatalf("GetProjectSettings failed: %v", err)
+		}
+		if !reflect.DeepEqual(settings.Analyzers, analyzers) {
+			t.Errorf("Expected analyzers %#v, got %#v", analyzers, settings.Analyzers)
+		}
+	}
+}
@@ -0,0 +1,518 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/google/uuid"
+)
+
+// recentVersionLimit caps the number of versions returned in a ProjectOverview
+const recentVersionLimit = 10
+
+// ProjectStatus tracks where a project is in the writing process
+type ProjectStatus string
+
+const (
+	ProjectStatusDraft    ProjectStatus = "draft"
+	ProjectStatusRevising ProjectStatus = "revising"
+	ProjectStatusComplete ProjectStatus = "complete"
+)
+
+// Valid reports whether the status is one of the known project statuses
+func (ps ProjectStatus) Valid() bool {
+	switch ps {
+	case ProjectStatusDraft, ProjectStatusRevising, ProjectStatusComplete:
+		return true
+	}
+	return false
+}
+
+// Project represents a narrative project's metadata
+type Project struct {
+	ID          string
+	Name        string
+	Theme       *string
+	Genre       *string
+	Description *string
+	Status      ProjectStatus
+	Author      *string
+	Series      *string
+	OwnerID     *string // nil for single-tenant projects
+	CreatedAt   string
+	UpdatedAt   string
+}
+
+// ProjectMetadata holds the editorial fields set by UpdateProjectMetadata.
+// Nil Author or Series clears the stored value.
+type ProjectMetadata struct {
+	Status ProjectStatus
+	Author *string
+	Series *string
+}
+
+// ProjectOverview aggregates everything needed to render a project summary
+type ProjectOverview struct {
+	Project *Project
+
+	// WorkingSetVersion is nil when the project has no working set yet;
+	// all counts below are then zero
+	WorkingSetVersion *GraphVersion
+
+	// VersionCount is the total number of versions in the project
+	VersionCount int
+
+	// RecentVersions holds the newest versions first, capped at recentVersionLimit
+	RecentVersions []*GraphVersion
+
+	EntityCount       int
+	EntityCounts      map[string]int // keyed by entity type
+	RelationshipCount int
+	AnnotationCount   int
+
+	// OrphanCount is the number of working set entities with no relationships
+	OrphanCount int
+}
+
+// ProjectSummaryStats pairs a project with its working set counts for list views
+type ProjectSummaryStats struct {
+	Project      *Project
+	VersionCount int
+
+	// WorkingSetVersionID is nil when the project has no working set yet;
+	// all counts below are then zero
+	WorkingSetVersionID *string
+
+	EntityCount       int
+	RelationshipCount int
+	AnnotationCount   int
+}
+
+// GetProject retrieves a project's metadata
+func (s *Service) GetProject(ctx context.Context, projectID string) (*Project, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	project, err := s.db.Queries().GetProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return toProject(project), nil
+}
+
+// GetProjectOverview retrieves project metadata and working set statistics in one call
+func (s *Service) GetProjectOverview(ctx context.Context, projectID string) (*ProjectOverview, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	project, err := s.db.Queries().GetProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	versions, err := s.db.Queries().ListGraphVersionsByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	overview := &ProjectOverview{
+		Project:        toProject(project),
+		VersionCount:   len(versions),
+		RecentVersions: []*GraphVersion{},
+		EntityCounts:   make(map[string]int),
+	}
+
+	for _, version := range versions {
+		if len(overview.RecentVersions) < recentVersionLimit {
+			overview.RecentVersions = append(overview.RecentVersions, toGraphVersion(version))
+		}
+		if version.IsWorkingSet && overview.WorkingSetVersion == nil {
+			overview.WorkingSetVersion = toGraphVersion(version)
+		}
+	}
+
+	if overview.WorkingSetVersion == nil {
+		return overview, nil
+	}
+	versionID := overview.WorkingSetVersion.ID
+
+	entities, err := s.db.Queries().ListEntitiesByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	relationships, err := s.db.Queries().ListRelationshipsByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	annotations, err := s.db.Queries().ListAnnotationsByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list annotations: %w", err)
+	}
+
+	// Tombstones and the edges touching them are hidden, so they are not counted
+	tombstones := make(map[string]bool)
+	for _, entity := range entities {
+		if rowIsTombstone(entity) {
+			tombstones[entity.ID] = true
+		}
+	}
+
+	connected := make(map[string]bool)
+	for _, rel := range relationships {
+		if tombstones[rel.FromEntityID] || tombstones[rel.ToEntityID] {
+			continue
+		}
+		connected[rel.FromEntityID] = true
+		connected[rel.ToEntityID] = true
+		overview.RelationshipCount++
+	}
+
+	for _, entity := range entities {
+		if tombstones[entity.ID] {
+			continue
+		}
+		overview.EntityCounts[entity.EntityType]++
+		overview.EntityCount++
+		if !connected[entity.ID] {
+			overview.OrphanCount++
+		}
+	}
+
+	for _, annotation := range annotations {
+		if !tombstones[annotation.EntityID] {
+			overview.AnnotationCount++
+		}
+	}
+
+	return overview, nil
+}
+
+// ListProjectsWithStats returns every project, newest first, with its version count and
+// working set entity, relationship and annotation counts. It issues a fixed number of
+// grouped queries however many projects exist.
+func (s *Service) ListProjectsWithStats(ctx context.Context) ([]ProjectSummaryStats, error) {
+	projects, err := s.db.Queries().ListProjectVersionStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	counts, err := s.db.Queries().ListWorkingSetCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count working sets: %w", err)
+	}
+	countsByProject := make(map[string]db.ListWorkingSetCountsRow, len(counts))
+	for _, count := range counts {
+		countsByProject[count.ProjectID] = count
+	}
+
+	result := make([]ProjectSummaryStats, 0, len(projects))
+	for _, row := range projects {
+		if !ownsProject(ctx, row.OwnerID) {
+			continue
+		}
+		count := countsByProject[row.ID]
+		result = append(result, ProjectSummaryStats{
+			Project: toProject(db.Project{
+				ID:          row.ID,
+				Name:        row.Name,
+				Theme:       row.Theme,
+				Genre:       row.Genre,
+				Description: row.Description,
+				CreatedAt:   row.CreatedAt,
+				UpdatedAt:   row.UpdatedAt,
+				Status:      row.Status,
+				Author:      row.Author,
+				Series:      row.Series,
+				OwnerID:     row.OwnerID,
+			}),
+			VersionCount:        int(row.VersionCount),
+			WorkingSetVersionID: nullStringToPtr(row.WorkingSetVersionID),
+			EntityCount:         int(count.EntityCount),
+			RelationshipCount:   int(count.RelationshipCount),
+			AnnotationCount:     int(count.AnnotationCount),
+		})
+	}
+
+	return result, nil
+}
+
+// UpdateProjectMetadata replaces a project's status, author and series
+func (s *Service) UpdateProjectMetadata(ctx context.Context, projectID string, metadata ProjectMetadata) (*Project, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	if !metadata.Status.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidProjectStatus, metadata.Status)
+	}
+
+	project, err := s.db.Queries().UpdateProjectMetadata(ctx, db.UpdateProjectMetadataParams{
+		Status: string(metadata.Status),
+		Author: ptrToNullString(metadata.Author),
+		Series: ptrToNullString(metadata.Series),
+		ID:     projectID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project metadata: %w", err)
+	}
+
+	return toProject(project), nil
+}
+
+// RenameProject changes a project's name. Names are compared case-insensitively
+// and must be unique across projects. Nothing else stores a project's name (shared
+// entity and series views resolve it from the project), so no other rows change. The
+// check and the rename run in one transaction when the store supports it.
+func (s *Service) RenameProject(ctx context.Context, projectID string, newName string) error {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return err
+	}
+	name := strings.TrimSpace(newName)
+	if name == "" {
+		return fmt.Errorf("%w: name is empty", ErrInvalidProjectName)
+	}
+
+	return s.inTx(ctx, func(store Store) error {
+		project, err := store.Queries().GetProject(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+
+		if err := checkProjectName(ctx, store, name, projectID); err != nil {
+			return err
+		}
+
+		if _, err := store.Queries().UpdateProject(ctx, db.UpdateProjectParams{
+			Name:        name,
+			Theme:       project.Theme,
+			Genre:       project.Genre,
+			Description: project.Description,
+			ID:          projectID,
+		}); err != nil {
+			return fmt.Errorf("failed to rename project: %w", err)
+		}
+		return nil
+	})
+}
+
+// CreateProject creates a project together with its initial working-set version, in
+// one transaction when the store supports it. An empty params.ID gets a generated ID,
+// and an empty initialVersionName defaults to "Initial". Names must be unique as in
+// RenameProject. The project belongs to the context's owner, if any. Returns both IDs.
+func (s *Service) CreateProject(ctx context.Context, params db.CreateProjectParams, initialVersionName string) (string, string, error) {
+	if params.ID == "" {
+		params.ID = uuid.New().String()
+	}
+	if initialVersionName == "" {
+		initialVersionName = "Initial"
+	}
+	versionID := uuid.New().String()
+
+	err := s.inTx(ctx, func(store Store) error {
+		if err := checkProjectName(ctx, store, params.Name, params.ID); err != nil {
+			return err
+		}
+		if _, err := store.Queries().CreateProject(ctx, params); err != nil {
+			return fmt.Errorf("failed to create project: %w", err)
+		}
+		if owner := contextOwner(ctx); owner.Valid {
+			if err := store.Queries().SetProjectOwner(ctx, db.SetProjectOwnerParams{OwnerID: owner, ID: params.ID}); err != nil {
+				return fmt.Errorf("failed to set project owner: %w", err)
+			}
+		}
+		if _, err := store.Queries().CreateGraphVersion(ctx, db.CreateGraphVersionParams{
+			ID:           versionID,
+			ProjectID:    params.ID,
+			Name:         sql.NullString{String: initialVersionName, Valid: true},
+			IsWorkingSet: true,
+		}); err != nil {
+			return fmt.Errorf("failed to create initial version: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return params.ID, versionID, nil
+}
+
+// UniqueProjectName returns name if no project uses it yet, and otherwise the first
+// free of "name (2)", "name (3)" and so on, compared as RenameProject compares names
+func (s *Service) UniqueProjectName(ctx context.Context, name string) (string, error) {
+	return uniqueProjectName(ctx, s.db, name)
+}
+
+// uniqueProjectName is UniqueProjectName on the given store
+func uniqueProjectName(ctx context.Context, store Store, name string) (string, error) {
+	candidate := name
+	for n := 2; ; n++ {
+		err := checkProjectName(ctx, store, candidate, "")
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, ErrDuplicateProjectName) {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s (%d)", name, n)
+	}
+}
+
+// checkProjectName returns ErrDuplicateProjectName when a project other than exceptID,
+// among those visible to the context's owner, already uses name case-insensitively
+func checkProjectName(ctx context.Context, store Store, name string, exceptID string) error {
+	projects, err := listProjectsIn(ctx, store)
+	if err != nil {
+		return fmt.Errorf("failed to list projects: %w", err)
+	}
+	for _, other := range projects {
+		if other.ID != exceptID && strings.EqualFold(other.Name, strings.TrimSpace(name)) {
+			return fmt.Errorf("%w: %q", ErrDuplicateProjectName, name)
+		}
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction on stores that support one, and directly otherwise
+func (s *Service) inTx(ctx context.Context, fn func(Store) error) error {
+	if txStore, ok := s.db.(TxStore); ok {
+		return txStore.InTx(ctx, fn)
+	}
+	return fn(s.db)
+}
+
+// FlattenProject collapses a project's working set into a fresh project with a single
+// root version and no history, for archival. Logical IDs, relationships and annotations
+// are preserved; the source project is left untouched. The new project is named and
+// written in one transaction when the store supports it. Returns the new version ID.
+func (s *Service) FlattenProject(ctx context.Context, projectID string) (string, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return "", err
+	}
+
+	var versionID string
+	err := s.inTx(ctx, func(store Store) error {
+		var err error
+		versionID, err = (&Service{db: store, options: s.options}).flattenProject(ctx, projectID)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return versionID, nil
+}
+
+// flattenProject does the work of FlattenProject on s's store, which FlattenProject
+// binds to its transaction
+func (s *Service) flattenProject(ctx context.Context, projectID string) (string, error) {
+	project, err := s.db.Queries().GetProject(ctx, projectID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get project: %w", err)
+	}
+
+	workingSet, err := s.db.Queries().GetWorkingSetVersion(ctx, projectID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get working set: %w", err)
+	}
+
+	name, err := uniqueProjectName(ctx, s.db, project.Name+" (flattened)")
+	if err != nil {
+		return "", err
+	}
+
+	flattened, err := s.db.Queries().CreateProject(ctx, db.CreateProjectParams{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Theme:       project.Theme,
+		Genre:       project.Genre,
+		Description: project.Description,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create flattened project: %w", err)
+	}
+
+	if _, err := s.db.Queries().UpdateProjectMetadata(ctx, db.UpdateProjectMetadataParams{
+		Status: project.Status,
+		Author: project.Author,
+		Series: project.Series,
+		ID:     flattened.ID,
+	}); err != nil {
+		return "", fmt.Errorf("failed to copy project metadata: %w", err)
+	}
+	if err := s.db.Queries().SetProjectOwner(ctx, db.SetProjectOwnerParams{
+		OwnerID: project.OwnerID,
+		ID:      flattened.ID,
+	}); err != nil {
+		return "", fmt.Errorf("failed to copy project owner: %w", err)
+	}
+
+	version, err := s.db.Queries().CreateGraphVersion(ctx, db.CreateGraphVersionParams{
+		ID:           uuid.New().String(),
+		ProjectID:    flattened.ID,
+		Name:         sql.NullString{String: "Flattened", Valid: true},
+		Description:  sql.NullString{String: fmt.Sprintf("Flattened from version %s of project %s", workingSet.ID, projectID), Valid: true},
+		IsWorkingSet: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create flattened version: %w", err)
+	}
+
+	entityIDMapping, err := s.copyEntitiesFromParent(ctx, workingSet.ID, version.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to copy entities: %w", err)
+	}
+
+	if err := s.copyRelationshipsFromParent(ctx, workingSet.ID, version.ID, entityIDMapping); err != nil {
+		return "", fmt.Errorf("failed to copy relationships: %w", err)
+	}
+
+	if err := s.copyAnnotations(ctx, workingSet.ID, entityIDMapping); err != nil {
+		return "", fmt.Errorf("failed to copy annotations: %w", err)
+	}
+
+	return version.ID, nil
+}
+
+// toProject converts a database project to its service representation
+func toProject(project db.Project) *Project {
+	return &Project{
+		ID:          project.ID,
+		Name:        project.Name,
+		Theme:       nullStringToPtr(project.Theme),
+		Genre:       nullStringToPtr(project.Genre),
+		Description: nullStringToPtr(project.Description),
+		Status:      ProjectStatus(project.Status),
+		Author:      nullStringToPtr(project.Author),
+		Series:      nullStringToPtr(project.Series),
+		OwnerID:     nullStringToPtr(project.OwnerID),
+		CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// ptrToNullString converts *string to sql.NullString
+func ptrToNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+// toGraphVersion converts a database graph version to its service representation
+func toGraphVersion(version db.GraphVersion) *GraphVersion {
+	return &GraphVersion{
+		ID:              version.ID,
+		ProjectID:       version.ProjectID,
+		ParentVersionID: nullStringToPtr(version.ParentVersionID),
+		Name:            nullStringToPtr(version.Name),
+		Description:     nullStringToPtr(version.Description),
+		IsWorkingSet:    version.IsWorkingSet,
+		CreatedAt:       version.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
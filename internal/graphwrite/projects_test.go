@@ -0,0 +1,561 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+func TestService_GetProjectOverview(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	featuredVersionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: featuredVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	workingSetID := response.GraphVersionID
+
+	if err := database.Queries().SetWorkingSet(ctx, db.SetWorkingSetParams{ID: workingSetID, ProjectID: projectID}); err != nil {
+		t.Fatalf("Failed to set working set: %v", err)
+	}
+
+	elenaDatabaseID := databaseIDForEntity(t, database, workingSetID, "elena")
+	createTestAnnotation(t, database, elenaDatabaseID, "emotional_analysis", nil)
+	createTestAnnotation(t, database, elenaDatabaseID, "continuity_check", nil)
+
+	overview, err := service.GetProjectOverview(ctx, projectID)
+	if err != nil {
+		t.Fatalf("GetProjectOverview failed: %v", err)
+	}
+
+	// Compute the same figures individually
+	entities, err := database.Queries().ListEntitiesByVersion(ctx, workingSetID)
+	if err != nil {
+		t.Fatalf("Failed to list entities: %v", err)
+	}
+	relationships, err := database.Queries().ListRelationshipsByVersion(ctx, workingSetID)
+	if err != nil {
+		t.Fatalf("Failed to list relationships: %v", err)
+	}
+	versions, err := database.Queries().ListGraphVersionsByProject(ctx, projectID)
+	if err != nil {
+		t.Fatalf("Failed to list versions: %v", err)
+	}
+
+	annotationCount := 0
+	for _, entity := range entities {
+		annotations, err := database.Queries().ListAnnotationsByEntity(ctx, entity.ID)
+		if err != nil {
+			t.Fatalf("Failed to list annotations: %v", err)
+		}
+		annotationCount += len(annotations)
+	}
+
+	if overview.Project.ID != projectID || overview.Project.Name != "Test Project" {
+		t.Errorf("Unexpected project metadata: %+v", overview.Project)
+	}
+	if overview.WorkingSetVersion == nil || overview.WorkingSetVersion.ID != workingSetID {
+		t.Fatalf("Expected working set %s, got %+v", workingSetID, overview.WorkingSetVersion)
+	}
+	if overview.VersionCount != len(versions) || len(overview.RecentVersions) != len(versions) {
+		t.Errorf("Expected %d versions, got %d (%d recent)", len(versions), overview.VersionCount, len(overview.RecentVersions))
+	}
+	if overview.EntityCount != len(entities) {
+		t.Errorf("Expected %d entities, got %d", len(entities), overview.EntityCount)
+	}
+	for _, entityType := range []string{"Scene", "Character", "Location"} {
+		count, err := database.Queries().CountEntitiesByType(ctx, db.CountEntitiesByTypeParams{
+			VersionID:  workingSetID,
+			EntityType: entityType,
+		})
+		if err != nil {
+			t.Fatalf("Failed to count %s entities: %v", entityType, err)
+		}
+		if int64(overview.EntityCounts[entityType]) != count {
+			t.Errorf("Expected %d %s entities, got %d", count, entityType, overview.EntityCounts[entityType])
+		}
+	}
+	if overview.RelationshipCount != len(relationships) {
+		t.Errorf("Expected %d relationships, got %d", len(relationships), overview.RelationshipCount)
+	}
+	if overview.AnnotationCount != annotationCount || annotationCount != 2 {
+		t.Errorf("Expected %d annotations, got %d", annotationCount, overview.AnnotationCount)
+	}
+
+	// Only the tavern is unconnected
+	if overview.OrphanCount != 1 {
+		t.Errorf("Expected 1 orphan, got %d", overview.OrphanCount)
+	}
+}
+
+func TestService_GetProjectOverview_NoWorkingSet(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	projectID := createTestProject(t, database)
+	createTestGraphVersion(t, database, projectID, false)
+
+	overview, err := service.GetProjectOverview(context.Background(), projectID)
+	if err != nil {
+		t.Fatalf("GetProjectOverview failed: %v", err)
+	}
+
+	if overview.WorkingSetVersion != nil {
+		t.Errorf("Expected no working set, got %+v", overview.WorkingSetVersion)
+	}
+	if overview.VersionCount != 1 || overview.EntityCount != 0 {
+		t.Errorf("Expected 1 version and no entities, got %d/%d", overview.VersionCount, overview.EntityCount)
+	}
+}
+
+func TestService_FlattenProject(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	featuredVersionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+	response, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+		ParentVersionID: featuredVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+	createTestAnnotation(t, database, databaseIDForEntity(t, database, response.GraphVersionID, "elena"), "character_voice", nil)
+
+	flattenedVersionID, err := service.FlattenProject(ctx, projectID)
+	if err != nil {
+		t.Fatalf("FlattenProject failed: %v", err)
+	}
+
+	flattenedVersion, err := service.GetVersion(ctx, flattenedVersionID)
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if flattenedVersion.ProjectID == projectID {
+		t.Fatal("Expected a fresh project")
+	}
+	if flattenedVersion.ParentVersionID != nil || !flattenedVersion.IsWorkingSet {
+		t.Errorf("Expected a parentless working set root, got %+v", flattenedVersion)
+	}
+
+	overview, err := service.GetProjectOverview(ctx, flattenedVersion.ProjectID)
+	if err != nil {
+		t.Fatalf("GetProjectOverview failed: %v", err)
+	}
+	if overview.VersionCount != 1 {
+		t.Errorf("Expected exactly one version, got %d", overview.VersionCount)
+	}
+	if overview.EntityCount != 3 || overview.RelationshipCount != 1 || overview.AnnotationCount != 1 {
+		t.Errorf("Expected 3 entities, 1 relationship and 1 annotation, got %d/%d/%d",
+			overview.EntityCount, overview.RelationshipCount, overview.AnnotationCount)
+	}
+
+	// Logical IDs survive
+	neighbors, err := service.GetNeighborsInVersion(ctx, flattenedVersionID, "scene-1", "features")
+	if err != nil {
+		t.Fatalf("GetNeighborsInVersion failed: %v", err)
+	}
+	if len(neighbors) != 1 || neighbors[0].ID != "elena" {
+		t.Errorf("Expected scene-1 to feature elena, got %+v", neighbors)
+	}
+
+	// The source project keeps its history
+	sourceOverview, err := service.GetProjectOverview(ctx, projectID)
+	if err != nil {
+		t.Fatalf("GetProjectOverview failed: %v", err)
+	}
+	if sourceOverview.VersionCount != 3 {
+		t.Errorf("Expected source project to keep 3 versions, got %d", sourceOverview.VersionCount)
+	}
+}
+
+func TestService_UpdateProjectMetadata(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+
+	overview, err := service.GetProjectOverview(ctx, projectID)
+	if err != nil {
+		t.Fatalf("GetProjectOverview failed: %v", err)
+	}
+	if overview.Project.Status != ProjectStatusDraft || overview.Project.Author != nil || overview.Project.Series != nil {
+		t.Errorf("Expected new project to be an unattributed draft, got %+v", overview.Project)
+	}
+
+	author := "A. Writer"
+	series := "The Elena Saga"
+	updated, err := service.UpdateProjectMetadata(ctx, projectID, ProjectMetadata{
+		Status: ProjectStatusRevising,
+		Author: &author,
+		Series: &series,
+	})
+	if err != nil {
+		t.Fatalf("UpdateProjectMetadata failed: %v", err)
+	}
+	if updated.Status != ProjectStatusRevising || updated.Author == nil || *updated.Author != author || updated.Series == nil || *updated.Series != series {
+		t.Errorf("Unexpected metadata after update: %+v", updated)
+	}
+
+	// Round-trip through a fresh read
+	overview, err = service.GetProjectOverview(ctx, projectID)
+	if err != nil {
+		t.Fatalf("GetProjectOverview failed: %v", err)
+	}
+	if overview.Project.Status != ProjectStatusRevising || *overview.Project.Author != author || *overview.Project.Series != series {
+		t.Errorf("Metadata did not round-trip: %+v", overview.Project)
+	}
+
+	// Nil fields clear stored values
+	cleared, err := service.UpdateProjectMetadata(ctx, projectID, ProjectMetadata{Status: ProjectStatusComplete})
+	if err != nil {
+		t.Fatalf("UpdateProjectMetadata failed: %v", err)
+	}
+	if cleared.Status != ProjectStatusComplete || cleared.Author != nil || cleared.Series != nil {
+		t.Errorf("Expected author and series to be cleared, got %+v", cleared)
+	}
+
+	if _, err := service.UpdateProjectMetadata(ctx, projectID, ProjectMetadata{Status: "abandoned"}); !errors.Is(err, ErrInvalidProjectStatus) {
+		t.Errorf("Expected ErrInvalidProjectStatus, got %v", err)
+	}
+}
+
+func TestService_RenameProject(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	// Two projects sharing Elena, so she is listed as a shared entity
+	projectIDs := make([]string, 2)
+	for i := range projectIDs {
+		projectIDs[i] = createTestProject(t, database)
+		rootVersionID := createTestGraphVersion(t, database, projectIDs[i], true)
+		if _, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+			ParentVersionID: rootVersionID,
+			Deltas: []*Delta{
+				{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			},
+		}); err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+	}
+
+	if err := service.RenameProject(ctx, projectIDs[0], "  The Crimson Tide  "); err != nil {
+		t.Fatalf("RenameProject failed: %v", err)
+	}
+
+	overview, err := service.GetProjectOverview(ctx, projectIDs[0])
+	if err != nil {
+		t.Fatalf("GetProjectOverview failed: %v", err)
+	}
+	if overview.Project.Name != "The Crimson Tide" {
+		t.Errorf("Expected trimmed new name, got %q", overview.Project.Name)
+	}
+	if overview.Project.Theme == nil || *overview.Project.Theme != "Adventure" {
+		t.Errorf("Expected other project fields to be kept, got %+v", overview.Project)
+	}
+
+	if err := service.RenameProject(ctx, projectIDs[1], "the crimson tide"); !errors.Is(err, ErrDuplicateProjectName) {
+		t.Errorf("Expected ErrDuplicateProjectName, got %v", err)
+	}
+	if err := service.RenameProject(ctx, projectIDs[1], "   "); !errors.Is(err, ErrInvalidProjectName) {
+		t.Errorf("Expected ErrInvalidProjectName, got %v", err)
+	}
+
+	// Renaming a project to its own name with different case is allowed
+	if err := service.RenameProject(ctx, projectIDs[0], "The Crimson TIDE"); err != nil {
+		t.Errorf("Expected renaming to a case variant of the same name to succeed, got %v", err)
+	}
+
+	shared, err := service.ListSharedEntities(ctx)
+	if err != nil {
+		t.Fatalf("ListSharedEntities failed: %v", err)
+	}
+	if len(shared) != 1 {
+		t.Fatalf("Expected Elena to be shared, got %d shared entities", len(shared))
+	}
+	var found bool
+	for _, name := range shared[0].Projects {
+		if name == "The Crimson TIDE" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected the shared entity's projects to show the new name, got %v", shared[0].Projects)
+	}
+}
+
+// countingDBTX counts the statements issued through it
+type countingDBTX struct {
+	db.DBTX
+	count int
+}
+
+func (c *countingDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	c.count++
+	return c.DBTX.ExecContext(ctx, query, args...)
+}
+
+func (c *countingDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	c.count++
+	return c.DBTX.QueryContext(ctx, query, args...)
+}
+
+func (c *countingDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	c.count++
+	return c.DBTX.QueryRowContext(ctx, query, args...)
+}
+
+func TestService_ListProjectsWithStats(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	// A populated project with an annotation
+	populatedID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, populatedID, true)
+	featuredVersionID := createFeaturedSceneVersion(t, service, rootVersionID)
+	if err := database.Queries().SetWorkingSet(ctx, db.SetWorkingSetParams{ID: featuredVersionID, ProjectID: populatedID}); err != nil {
+		t.Fatalf("Failed to set working set: %v", err)
+	}
+	createTestAnnotation(t, database, databaseIDForEntity(t, database, featuredVersionID, "elena"), "emotional_analysis", nil)
+
+	// An empty project with a working set and one with no versions at all
+	emptyID := createTestProject(t, database)
+	createTestGraphVersion(t, database, emptyID, true)
+	versionlessID := createTestProject(t, database)
+
+	stats, err := service.ListProjectsWithStats(ctx)
+	if err != nil {
+		t.Fatalf("ListProjectsWithStats failed: %v", err)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("Expected 3 projects, got %d", len(stats))
+	}
+
+	for _, summary := range stats {
+		overview, err := service.GetProjectOverview(ctx, summary.Project.ID)
+		if err != nil {
+			t.Fatalf("GetProjectOverview failed: %v", err)
+		}
+		if summary.VersionCount != overview.VersionCount ||
+			summary.EntityCount != overview.EntityCount ||
+			summary.RelationshipCount != overview.RelationshipCount ||
+			summary.AnnotationCount != overview.AnnotationCount {
+			t.Errorf("Project %s: batched stats %+v differ from overview %d/%d/%d/%d", summary.Project.ID, summary,
+				overview.VersionCount, overview.EntityCount, overview.RelationshipCount, overview.AnnotationCount)
+		}
+		if (summary.WorkingSetVersionID == nil) != (overview.WorkingSetVersion == nil) {
+			t.Errorf("Project %s: working set presence differs from overview", summary.Project.ID)
+		}
+		if summary.Project.ID == populatedID && (summary.EntityCount != 2 || summary.AnnotationCount != 1) {
+			t.Errorf("Expected the populated project to have 2 entities and 1 annotation, got %+v", summary)
+		}
+		if summary.Project.ID == versionlessID && summary.WorkingSetVersionID != nil {
+			t.Errorf("Expected no working set for the versionless project")
+		}
+	}
+
+	// The batched listing issues a fixed number of queries however many projects exist
+	counter := &countingDBTX{DBTX: database.DB()}
+	counted := NewService(database.WithDBTX(counter))
+	if _, err := counted.ListProjectsWithStats(ctx); err != nil {
+		t.Fatalf("ListProjectsWithStats failed: %v", err)
+	}
+	if counter.count > 2 {
+		t.Errorf("Expected at most 2 queries for 3 projects, got %d", counter.count)
+	}
+
+	createTestGraphVersion(t, database, createTestProject(t, database), true)
+	counter.count = 0
+	if _, err := counted.ListProjectsWithStats(ctx); err != nil {
+		t.Fatalf("ListProjectsWithStats failed: %v", err)
+	}
+	if counter.count > 2 {
+		t.Errorf("Expected the query count not to grow with projects, got %d", counter.count)
+	}
+}
+
+func TestService_CreateProject(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID, versionID, err := service.CreateProject(ctx, db.CreateProjectParams{
+		Name:  "The Shadow War",
+		Genre: sql.NullString{String: "Fantasy", Valid: true},
+	}, "First Draft")
+	if err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+	if projectID == "" || versionID == "" {
+		t.Fatalf("Expected both IDs, got %q and %q", projectID, versionID)
+	}
+
+	versions, err := database.Queries().ListGraphVersionsByProject(ctx, projectID)
+	if err != nil {
+		t.Fatalf("ListGraphVersionsByProject failed: %v", err)
+	}
+	if len(versions) != 1 || versions[0].ID != versionID || !versions[0].IsWorkingSet {
+		t.Fatalf("Expected exactly one working-set version %s, got %+v", versionID, versions)
+	}
+	if versions[0].Name.String != "First Draft" || versions[0].ParentVersionID.Valid {
+		t.Errorf("Expected a named root version, got %+v", versions[0])
+	}
+
+	// The new working set takes deltas straight away
+	if _, err := service.ApplyToProject(ctx, projectID, []*Delta{
+		{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+	}); err != nil {
+		t.Fatalf("ApplyToProject failed: %v", err)
+	}
+
+	// Names are unique case-insensitively, as RenameProject enforces
+	if _, _, err := service.CreateProject(ctx, db.CreateProjectParams{Name: "the shadow war"}, ""); !errors.Is(err, ErrDuplicateProjectName) {
+		t.Errorf("Expected ErrDuplicateProjectName, got %v", err)
+	}
+
+	// Reusing the ID fails without adding a version to the existing project
+	if _, _, err := service.CreateProject(ctx, db.CreateProjectParams{ID: projectID, Name: "Again"}, ""); err == nil {
+		t.Error("Expected an error for a duplicate project ID")
+	}
+	if versions, _ := database.Queries().ListGraphVersionsByProject(ctx, projectID); len(versions) != 2 {
+		t.Errorf("Expected the original 2 versions to remain, got %d", len(versions))
+	}
+}
+
+func TestService_FlattenProject_UniqueNames(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID, _, err := service.CreateProject(ctx, db.CreateProjectParams{Name: "Saga"}, "")
+	if err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+
+	var names []string
+	for i := 0; i < 2; i++ {
+		versionID, err := service.FlattenProject(ctx, projectID)
+		if err != nil {
+			t.Fatalf("FlattenProject failed: %v", err)
+		}
+		version, err := service.GetVersion(ctx, versionID)
+		if err != nil {
+			t.Fatalf("GetVersion failed: %v", err)
+		}
+		project, err := service.GetProject(ctx, version.ProjectID)
+		if err != nil {
+			t.Fatalf("GetProject failed: %v", err)
+		}
+		names = append(names, project.Name)
+	}
+
+	if names[0] != "Saga (flattened)" || names[1] != "Saga (flattened) (2)" {
+		t.Errorf("Expected each flatten to get its own name, got %q", names)
+	}
+}
+
+// errVersionWrite is the failure failingVersionStore injects
+var errVersionWrite = errors.New("version write failed")
+
+// failingVersionStore is a SQLiteStore whose transactions cannot create versions
+type failingVersionStore struct{ *SQLiteStore }
+
+func (s failingVersionStore) InTx(ctx context.Context, fn func(Store) error) error {
+	return s.SQLiteStore.InTx(ctx, func(store Store) error {
+		return fn(failingVersionTx{store})
+	})
+}
+
+type failingVersionTx struct{ Store }
+
+func (s failingVersionTx) Queries() db.Querier { return failingVersionQuerier{s.Store.Queries()} }
+
+type failingVersionQuerier struct{ db.Querier }
+
+func (failingVersionQuerier) CreateGraphVersion(context.Context, db.CreateGraphVersionParams) (db.GraphVersion, error) {
+	return db.GraphVersion{}, errVersionWrite
+}
+
+func TestService_FlattenProject_RollsBack(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	ctx := context.Background()
+	projectID, _, err := NewService(database).CreateProject(ctx, db.CreateProjectParams{Name: "Saga"}, "")
+	if err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+
+	service := NewServiceWithStore(failingVersionStore{NewSQLiteStore(database)}, ServiceOptions{})
+	if _, err := service.FlattenProject(ctx, projectID); !errors.Is(err, errVersionWrite) {
+		t.Fatalf("Expected the version write to fail the flatten, got %v", err)
+	}
+
+	// The flattened project is not left behind without a version
+	projects, err := database.Queries().ListProjects(ctx)
+	if err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+	if len(projects) != 1 || projects[0].ID != projectID {
+		t.Errorf("Expected only the source project, got %+v", projects)
+	}
+}
+
+func TestSQLiteStore_InTxRollsBack(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	ctx := context.Background()
+	failure := errors.New("version failed")
+
+	err := NewSQLiteStore(database).InTx(ctx, func(store Store) error {
+		if _, err := store.Queries().CreateProject(ctx, db.CreateProjectParams{ID: "half-made", Name: "Half Made"}); err != nil {
+			t.Fatalf("CreateProject failed: %v", err)
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the callback's error, got %v", err)
+	}
+	if _, err := database.Queries().GetProject(ctx, "half-made"); err == nil {
+		t.Error("Expected the project to be rolled back")
+	}
+}
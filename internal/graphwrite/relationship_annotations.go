@@ -0,0 +1,165 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/google/uuid"
+)
+
+// RelationshipAnnotation is a note attached to a relationship, such as an agent's
+// explanation that an allies_with edge is strained. The relationship is named by its
+// logical endpoints and type. Like entity annotations, relationship annotations are
+// carried onto the relationship's copies in later versions.
+type RelationshipAnnotation struct {
+	ID               string
+	FromEntityID     string
+	ToEntityID       string
+	RelationshipType string
+	AnnotationType   string
+	Content          string
+	Metadata         map[string]any
+	AgentName        string
+	CreatedAt        string
+}
+
+// AnnotateRelationship attaches an annotation to the relationship with the given
+// logical endpoints and type in a version
+func (s *Service) AnnotateRelationship(ctx context.Context, versionID string, annotation *RelationshipAnnotation) (*RelationshipAnnotation, error) {
+	logicalIDs, err := s.logicalIDsByDatabaseID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	entityIDMapping := make(map[string]string, len(logicalIDs))
+	for databaseID, logicalID := range logicalIDs {
+		entityIDMapping[logicalID] = databaseID
+	}
+
+	relationshipID, found, err := s.findRelationshipByLogicalTuple(ctx, versionID, &RelationshipDelta{
+		FromEntityID:     annotation.FromEntityID,
+		ToEntityID:       annotation.ToEntityID,
+		RelationshipType: annotation.RelationshipType,
+	}, entityIDMapping)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("relationship %s -%s-> %s not found in version %s", annotation.FromEntityID, annotation.RelationshipType, annotation.ToEntityID, versionID)
+	}
+
+	metadata := json.RawMessage("{}")
+	if annotation.Metadata != nil {
+		metadata, err = json.Marshal(annotation.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal annotation metadata: %w", err)
+		}
+	}
+
+	// Annotations belong to one version, so a row shared with other versions is copied first
+	if s.options.CopyOnWrite {
+		relationshipID, err = s.materializeRelationship(ctx, versionID, relationshipID, entityIDMapping)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	created, err := s.db.Queries().CreateRelationshipAnnotation(ctx, db.CreateRelationshipAnnotationParams{
+		ID:             uuid.New().String(),
+		RelationshipID: relationshipID,
+		AnnotationType: annotation.AnnotationType,
+		Content:        annotation.Content,
+		Metadata:       metadata,
+		AgentName:      sql.NullString{String: annotation.AgentName, Valid: annotation.AgentName != ""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create relationship annotation: %w", err)
+	}
+
+	return toRelationshipAnnotation(created, annotation.FromEntityID, annotation.ToEntityID, annotation.RelationshipType)
+}
+
+// RelationshipAnnotationsForVersion returns every annotation on the relationships of a
+// version, newest first, with the endpoints resolved to logical IDs
+func (s *Service) RelationshipAnnotationsForVersion(ctx context.Context, versionID string) ([]RelationshipAnnotation, error) {
+	logicalIDs, err := s.logicalIDsByDatabaseID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	relationships, err := s.db.Queries().ListRelationshipsByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+	byID := make(map[string]db.Relationship, len(relationships))
+	for _, rel := range relationships {
+		byID[rel.ID] = rel
+	}
+
+	annotations, err := s.db.Queries().ListRelationshipAnnotationsByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationship annotations: %w", err)
+	}
+
+	result := make([]RelationshipAnnotation, 0, len(annotations))
+	for _, annotation := range annotations {
+		rel := byID[annotation.RelationshipID]
+		converted, err := toRelationshipAnnotation(annotation, logicalIDs[rel.FromEntityID], logicalIDs[rel.ToEntityID], rel.RelationshipType)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *converted)
+	}
+
+	return result, nil
+}
+
+// toRelationshipAnnotation converts a database relationship annotation to its service
+// representation, given the relationship's logical endpoints and type
+func toRelationshipAnnotation(annotation db.RelationshipAnnotation, fromEntityID, toEntityID, relationshipType string) (*RelationshipAnnotation, error) {
+	var metadata map[string]any
+	if len(annotation.Metadata) > 0 {
+		if err := json.Unmarshal(annotation.Metadata, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal annotation metadata: %w", err)
+		}
+	}
+
+	return &RelationshipAnnotation{
+		ID:               annotation.ID,
+		FromEntityID:     fromEntityID,
+		ToEntityID:       toEntityID,
+		RelationshipType: relationshipType,
+		AnnotationType:   annotation.AnnotationType,
+		Content:          annotation.Content,
+		Metadata:         metadata,
+		AgentName:        annotation.AgentName.String,
+		CreatedAt:        annotation.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	}, nil
+}
+
+// copyRelationshipAnnotations copies a relationship row's annotations onto its copy
+func (s *Service) copyRelationshipAnnotations(ctx context.Context, sourceRelationshipID, targetRelationshipID string) error {
+	annotations, err := s.db.Queries().ListRelationshipAnnotationsByRelationship(ctx, sourceRelationshipID)
+	if err != nil {
+		return fmt.Errorf("failed to list relationship annotations: %w", err)
+	}
+
+	// Oldest first, so the copies keep their order
+	for i := len(annotations) - 1; i >= 0; i-- {
+		annotation := annotations[i]
+		if _, err := s.db.Queries().CreateRelationshipAnnotation(ctx, db.CreateRelationshipAnnotationParams{
+			ID:             uuid.New().String(),
+			RelationshipID: targetRelationshipID,
+			AnnotationType: annotation.AnnotationType,
+			Content:        annotation.Content,
+			Metadata:       annotation.Metadata,
+			AgentName:      annotation.AgentName,
+		}); err != nil {
+			return fmt.Errorf("failed to copy relationship annotation %s: %w", annotation.ID, err)
+		}
+	}
+
+	return nil
+}
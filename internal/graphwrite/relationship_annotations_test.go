@@ -0,0 +1,83 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+)
+
+func TestService_AnnotateRelationship(t *testing.T) {
+	for _, mode := range []struct {
+		name    string
+		options ServiceOptions
+	}{
+		{name: "copy", options: ServiceOptions{}},
+		{name: "copy-on-write", options: ServiceOptions{CopyOnWrite: true}},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			database := setupTestDB(t)
+			defer database.Close()
+
+			service := NewServiceWithOptions(database, mode.options)
+			ctx := context.Background()
+
+			projectID := createTestProject(t, database)
+			rootVersionID := createTestGraphVersion(t, database, projectID, true)
+			versionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+			created, err := service.AnnotateRelationship(ctx, versionID, &RelationshipAnnotation{
+				FromEntityID:     "elena",
+				ToEntityID:       "marcus",
+				RelationshipType: "allies_with",
+				AnnotationType:   "continuity_check",
+				Content:          "This alliance is strained",
+				Metadata:         map[string]any{"tension": 0.7},
+				AgentName:        "continuity_agent",
+			})
+			if err != nil {
+				t.Fatalf("AnnotateRelationship failed: %v", err)
+			}
+			if created.ID == "" || created.FromEntityID != "elena" || created.Content != "This alliance is strained" {
+				t.Errorf("Expected the created annotation on elena's alliance, got %+v", created)
+			}
+
+			annotations, err := service.RelationshipAnnotationsForVersion(ctx, versionID)
+			if err != nil {
+				t.Fatalf("RelationshipAnnotationsForVersion failed: %v", err)
+			}
+			if len(annotations) != 1 {
+				t.Fatalf("Expected 1 relationship annotation, got %d", len(annotations))
+			}
+			annotation := annotations[0]
+			if annotation.FromEntityID != "elena" || annotation.ToEntityID != "marcus" || annotation.RelationshipType != "allies_with" {
+				t.Errorf("Expected the annotation on elena -allies_with-> marcus, got %+v", annotation)
+			}
+			if annotation.AgentName != "continuity_agent" || annotation.Metadata["tension"] != 0.7 {
+				t.Errorf("Expected the agent and metadata to round-trip, got %+v", annotation)
+			}
+
+			// Renaming elena copies her row and edge; the note follows the edge
+			response, err := service.Apply(ctx, &ApplyRequest{
+				ParentVersionID: versionID,
+				Deltas: []*Delta{
+					{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena the Bold"}},
+				},
+			})
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			carried, err := service.RelationshipAnnotationsForVersion(ctx, response.GraphVersionID)
+			if err != nil {
+				t.Fatalf("RelationshipAnnotationsForVersion failed: %v", err)
+			}
+			if len(carried) != 1 || carried[0].Content != "This alliance is strained" || carried[0].FromEntityID != "elena" {
+				t.Errorf("Expected the annotation carried into the child version, got %+v", carried)
+			}
+
+			if _, err := service.AnnotateRelationship(ctx, versionID, &RelationshipAnnotation{
+				FromEntityID: "marcus", ToEntityID: "elena", RelationshipType: "allies_with", AnnotationType: "note", Content: "Reversed",
+			}); err == nil {
+				t.Error("Expected an error annotating a relationship that does not exist")
+			}
+		})
+	}
+}
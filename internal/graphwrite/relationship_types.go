@@ -0,0 +1,88 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+// ListRelationshipTypes returns the distinct relationship types present in a version,
+// sorted by name
+func (s *Service) ListRelationshipTypes(ctx context.Context, versionID string) ([]string, error) {
+	relationshipTypes, err := s.db.Queries().ListRelationshipTypesByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationship types: %w", err)
+	}
+	return relationshipTypes, nil
+}
+
+// validateRelationshipEndpoints checks every relationship an Apply batch would create,
+// explicitly or through field relationship rules, against the relationship type
+// registry, so a batch with an invalid type pair is rejected before anything is
+// written. Endpoints that do not resolve are left for createRelationship to report.
+func (s *Service) validateRelationshipEndpoints(ctx context.Context, parentVersionID string, deltas []*Delta) error {
+	entities, err := s.db.Queries().ListEntitiesByVersion(ctx, parentVersionID)
+	if err != nil {
+		return fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	entityTypes := make(map[string]types.EntityType, len(entities))
+	for _, entity := range entities {
+		logicalID := entity.ID
+		var data map[string]any
+		if err := json.Unmarshal(entity.Data, &data); err == nil {
+			if lid, ok := data["logical_id"].(string); ok {
+				logicalID = lid
+			}
+		}
+		entityTypes[logicalID] = types.EntityType(entity.EntityType)
+	}
+
+	check := func(relationshipType, from, to string) error {
+		fromType, fromExists := entityTypes[from]
+		toType, toExists := entityTypes[to]
+		if !fromExists || !toExists {
+			return nil
+		}
+		if err := types.ValidateRelationshipEndpoints(types.RelationshipType(relationshipType), fromType, toType); err != nil {
+			return fmt.Errorf("%w: %s -%s-> %s: %v", ErrInvalidRelationshipEndpoints, from, relationshipType, to, err)
+		}
+		return nil
+	}
+
+	// Walk the batch in order, tracking entity types as deltas create and delete them
+	for _, delta := range deltas {
+		switch delta.Operation {
+		case "create":
+			if delta.EntityID != "" {
+				entityTypes[delta.EntityID] = types.EntityType(delta.EntityType)
+			}
+		case "delete":
+			delete(entityTypes, delta.EntityID)
+			continue
+		}
+
+		for _, relDelta := range delta.Relationships {
+			if relDelta.Operation != "create" {
+				continue
+			}
+			if err := check(relDelta.RelationshipType, relDelta.FromEntityID, relDelta.ToEntityID); err != nil {
+				return err
+			}
+		}
+
+		if delta.Operation == "create" && delta.EntityID != "" {
+			for _, rule := range types.FieldRelationshipRulesFor(types.EntityType(delta.EntityType)) {
+				for _, targetID := range referencedIDs(delta.Fields[rule.Field]) {
+					if err := check(string(rule.RelationshipType), delta.EntityID, targetID); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestService_Apply_RejectsInvalidRelationshipEndpointsBeforeWriting(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	_, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": "Opening"}},
+			{
+				Operation:  "create",
+				EntityType: "Character",
+				EntityID:   "elena",
+				Fields:     map[string]any{"name": "Elena"},
+				Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "scene-1", ToEntityID: "elena", RelationshipType: "features", Properties: map[string]any{}},
+					// Backwards: a character cannot feature a scene
+					{Operation: "create", FromEntityID: "elena", ToEntityID: "scene-1", RelationshipType: "features", Properties: map[string]any{}},
+				},
+			},
+		},
+	})
+	if !errors.Is(err, ErrInvalidRelationshipEndpoints) {
+		t.Fatalf("Expected ErrInvalidRelationshipEndpoints, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "elena -features-> scene-1") {
+		t.Errorf("Expected the error to name the violating relationship, got %v", err)
+	}
+
+	versions, err := database.Queries().ListGraphVersionsByProject(ctx, projectID)
+	if err != nil {
+		t.Fatalf("ListGraphVersionsByProject failed: %v", err)
+	}
+	if len(versions) != 1 {
+		t.Errorf("Expected no version to be written, got %d versions", len(versions))
+	}
+}
+
+func TestService_Apply_RejectsFieldRelationshipToWrongType(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	_, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{Operation: "create", EntityType: "Scene", EntityID: "scene-1", Fields: map[string]any{"name": "Opening", "location": "elena"}},
+		},
+	})
+	if !errors.Is(err, ErrInvalidRelationshipEndpoints) {
+		t.Errorf("Expected a scene located at a character to be rejected, got %v", err)
+	}
+}
+
+func TestService_ListRelationshipTypes(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}},
+			{Operation: "create", EntityType: "Location", EntityID: "harbor", Fields: map[string]any{"name": "Harbor"}},
+			{
+				Operation:  "create",
+				EntityType: "Scene",
+				EntityID:   "scene-1",
+				Fields:     map[string]any{"name": "Opening", "location": "harbor"},
+				Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "scene-1", ToEntityID: "elena", RelationshipType: "features", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "scene-1", ToEntityID: "marcus", RelationshipType: "features", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "allies_with", Properties: map[string]any{}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	relationshipTypes, err := service.ListRelationshipTypes(ctx, response.GraphVersionID)
+	if err != nil {
+		t.Fatalf("ListRelationshipTypes failed: %v", err)
+	}
+	expected := []string{"allies_with", "features", "occurs_at"}
+	if !reflect.DeepEqual(relationshipTypes, expected) {
+		t.Errorf("Expected %v, got %v", expected, relationshipTypes)
+	}
+
+	empty, err := service.ListRelationshipTypes(ctx, rootVersionID)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("Expected no types for an empty version, got %v (%v)", empty, err)
+	}
+}
@@ -0,0 +1,367 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// createFeaturedSceneVersion creates a scene featuring a character and returns the new version ID
+func createFeaturedSceneVersion(t *testing.T, service GraphWriteService, parentVersionID string) string {
+	response, err := service.Apply(context.Background(), &ApplyRequest{
+		ParentVersionID: parentVersionID,
+		Deltas: []*Delta{
+			{
+				Operation:  "create",
+				EntityType: "Scene",
+				EntityID:   "scene-1",
+				Fields:     map[string]any{"name": "Opening", "title": "Opening"},
+			},
+			{
+				Operation:  "create",
+				EntityType: "Character",
+				EntityID:   "elena",
+				Fields:     map[string]any{"name": "Elena"},
+				Relationships: []*RelationshipDelta{
+					{
+						Operation:        "create",
+						FromEntityID:     "scene-1",
+						ToEntityID:       "elena",
+						RelationshipType: "features",
+						Properties:       map[string]any{"role": "lead"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	return response.GraphVersionID
+}
+
+func TestService_Apply_DeleteRelationshipByLogicalTuple(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	ancestorVersionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+	// An unrelated change puts the relationship one copy away from where it was created
+	middle, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: ancestorVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	deleteFeatures := func(parentVersionID string) (*ApplyResponse, error) {
+		return service.Apply(ctx, &ApplyRequest{
+			ParentVersionID: parentVersionID,
+			Deltas: []*Delta{
+				{
+					Operation:  "update",
+					EntityType: "Scene",
+					EntityID:   "scene-1",
+					Fields:     map[string]any{"name": "Opening", "title": "Opening"},
+					Relationships: []*RelationshipDelta{
+						{
+							Operation:        "delete",
+							FromEntityID:     "scene-1",
+							ToEntityID:       "elena",
+							RelationshipType: "features",
+						},
+					},
+				},
+			},
+		})
+	}
+
+	deleted, err := deleteFeatures(middle.GraphVersionID)
+	if err != nil {
+		t.Fatalf("Delete by logical tuple failed: %v", err)
+	}
+
+	neighbors, err := service.GetNeighborsInVersion(ctx, deleted.GraphVersionID, "scene-1", "features")
+	if err != nil {
+		t.Fatalf("GetNeighborsInVersion failed: %v", err)
+	}
+	if len(neighbors) != 0 {
+		t.Errorf("Expected relationship to be deleted, got %d neighbors", len(neighbors))
+	}
+
+	// The ancestor version keeps its edge
+	neighbors, err = service.GetNeighborsInVersion(ctx, middle.GraphVersionID, "scene-1", "features")
+	if err != nil {
+		t.Fatalf("GetNeighborsInVersion failed: %v", err)
+	}
+	if len(neighbors) != 1 {
+		t.Errorf("Expected ancestor version to keep its relationship, got %d neighbors", len(neighbors))
+	}
+
+	// Deleting an already-absent edge is a no-op
+	if _, err := deleteFeatures(deleted.GraphVersionID); err != nil {
+		t.Errorf("Expected deleting an absent relationship to succeed, got %v", err)
+	}
+}
+
+func TestService_Apply_SelfRelationship(t *testing.T) {
+	ctx := context.Background()
+
+	selfAlliance := func(parentVersionID string) *ApplyRequest {
+		return &ApplyRequest{
+			ParentVersionID: parentVersionID,
+			Deltas: []*Delta{
+				{
+					Operation:  "create",
+					EntityType: "Character",
+					EntityID:   "elena",
+					Fields:     map[string]any{"name": "Elena"},
+					Relationships: []*RelationshipDelta{
+						{
+							Operation:        "create",
+							FromEntityID:     "elena",
+							ToEntityID:       "elena",
+							RelationshipType: "allies_with",
+							Properties:       map[string]any{},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("rejected by default", func(t *testing.T) {
+		database := setupTestDB(t)
+		defer database.Close()
+
+		service := NewService(database)
+		projectID := createTestProject(t, database)
+		rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+		_, err := service.Apply(ctx, selfAlliance(rootVersionID))
+		if !errors.Is(err, ErrSelfRelationship) {
+			t.Errorf("Expected ErrSelfRelationship, got %v", err)
+		}
+	})
+
+	t.Run("allowed when configured", func(t *testing.T) {
+		database := setupTestDB(t)
+		defer database.Close()
+
+		service := NewServiceWithOptions(database, ServiceOptions{AllowSelfRelationships: true})
+		projectID := createTestProject(t, database)
+		rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+		response, err := service.Apply(ctx, selfAlliance(rootVersionID))
+		if err != nil {
+			t.Fatalf("Expected self relationship to be allowed, got %v", err)
+		}
+
+		neighbors, err := service.GetNeighborsInVersion(ctx, response.GraphVersionID, "elena", "allies_with")
+		if err != nil {
+			t.Fatalf("GetNeighborsInVersion failed: %v", err)
+		}
+		if len(neighbors) == 0 {
+			t.Error("Expected elena to be her own ally")
+		}
+	})
+}
+
+func TestService_CreateRelationship_RejectsCrossVersionEndpoint(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	oldVersionID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: oldVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	newVersionID := response.GraphVersionID
+
+	// A stale mapping pointing elena at her row in the parent version
+	entityIDMapping := map[string]string{
+		"tavern": databaseIDForEntity(t, database, newVersionID, "tavern"),
+		"elena":  databaseIDForEntity(t, database, oldVersionID, "elena"),
+	}
+
+	err = service.(*Service).createRelationship(ctx, newVersionID, &RelationshipDelta{
+		Operation:        "create",
+		FromEntityID:     "elena",
+		ToEntityID:       "tavern",
+		RelationshipType: "visits",
+		Properties:       map[string]any{},
+	}, entityIDMapping)
+	if !errors.Is(err, ErrCrossVersionRelationship) {
+		t.Fatalf("Expected ErrCrossVersionRelationship, got %v", err)
+	}
+
+	relationships, err := database.Queries().ListRelationshipsByVersion(ctx, newVersionID)
+	if err != nil {
+		t.Fatalf("Failed to list relationships: %v", err)
+	}
+	for _, rel := range relationships {
+		if rel.RelationshipType == "visits" {
+			t.Error("Expected no cross-version relationship to be stored")
+		}
+	}
+}
+
+func TestService_GetNeighbors_ResolvesWorkingSet(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createFeaturedSceneVersion(t, service, rootVersionID)
+	if err := database.Queries().SetWorkingSet(ctx, db.SetWorkingSetParams{ID: versionID, ProjectID: projectID}); err != nil {
+		t.Fatalf("SetWorkingSet failed: %v", err)
+	}
+
+	neighbors, err := service.GetNeighbors(ctx, "scene-1", "features")
+	if err != nil {
+		t.Fatalf("GetNeighbors failed: %v", err)
+	}
+	inVersion, err := service.GetNeighborsInVersion(ctx, versionID, "scene-1", "features")
+	if err != nil {
+		t.Fatalf("GetNeighborsInVersion failed: %v", err)
+	}
+	if len(neighbors) != 1 || neighbors[0].ID != "elena" {
+		t.Fatalf("Expected elena as the only neighbor, got %+v", neighbors)
+	}
+	if len(inVersion) != len(neighbors) || inVersion[0].ID != neighbors[0].ID || inVersion[0].VersionID != neighbors[0].VersionID {
+		t.Errorf("Expected GetNeighbors to match GetNeighborsInVersion, got %+v and %+v", neighbors, inVersion)
+	}
+
+	// An entity in no working set has no neighbors
+	neighbors, err = service.GetNeighbors(ctx, "nobody", "")
+	if err != nil || len(neighbors) != 0 {
+		t.Errorf("Expected no neighbors for an unknown entity, got %+v, %v", neighbors, err)
+	}
+
+	// Once a second project's working set holds elena too, the lookup is ambiguous
+	otherProjectID := createTestProject(t, database)
+	if _, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+		ParentVersionID: createTestGraphVersion(t, database, otherProjectID, true),
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+		},
+	}); err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+	if _, err := service.GetNeighbors(ctx, "elena", ""); !errors.Is(err, ErrAmbiguousEntity) {
+		t.Errorf("Expected ErrAmbiguousEntity, got %v", err)
+	}
+}
+
+func TestService_Apply_UpdateRelationshipByLogicalTuple(t *testing.T) {
+	for _, mode := range []struct {
+		name    string
+		options ServiceOptions
+	}{
+		{name: "copy", options: ServiceOptions{}},
+		{name: "copy-on-write", options: ServiceOptions{CopyOnWrite: true}},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			database := setupTestDB(t)
+			defer database.Close()
+
+			service := NewServiceWithOptions(database, mode.options)
+			ctx := context.Background()
+
+			projectID := createTestProject(t, database)
+			rootVersionID := createTestGraphVersion(t, database, projectID, true)
+			baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+			// The edge is inherited, so the child addresses it by endpoints and type
+			response, err := service.Apply(ctx, &ApplyRequest{
+				ParentVersionID: baseVersionID,
+				Deltas: []*Delta{{
+					Operation:  "update",
+					EntityType: "Character",
+					EntityID:   "elena",
+					Fields:     map[string]any{"name": "Elena"},
+					Relationships: []*RelationshipDelta{{
+						Operation:        "update",
+						FromEntityID:     "elena",
+						ToEntityID:       "marcus",
+						RelationshipType: "allies_with",
+						Properties:       map[string]any{"bond_strength": "strong"},
+					}},
+				}},
+			})
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+
+			impl := service.(*Service)
+			key := relationshipKey{From: "elena", To: "marcus", Type: "allies_with"}
+			child, err := impl.relationshipsByLogicalKey(ctx, response.GraphVersionID)
+			if err != nil {
+				t.Fatalf("relationshipsByLogicalKey failed: %v", err)
+			}
+			if len(child) != 1 || child[key]["bond_strength"] != "strong" {
+				t.Errorf("Expected the one edge to be strong in the child, got %v", child)
+			}
+			rows, err := database.Queries().ListRelationshipsByVersion(ctx, response.GraphVersionID)
+			if err != nil {
+				t.Fatalf("ListRelationshipsByVersion failed: %v", err)
+			}
+			if len(rows) != 1 {
+				t.Errorf("Expected no duplicate edge, got %d relationships", len(rows))
+			}
+
+			parent, err := impl.relationshipsByLogicalKey(ctx, baseVersionID)
+			if err != nil {
+				t.Fatalf("relationshipsByLogicalKey failed: %v", err)
+			}
+			if parent[key]["bond_strength"] != "growing" {
+				t.Errorf("Expected the parent edge to stay growing, got %v", parent[key])
+			}
+
+			// An edge the version does not have cannot be updated
+			_, err = service.Apply(ctx, &ApplyRequest{
+				ParentVersionID: baseVersionID,
+				Deltas: []*Delta{{
+					Operation:  "update",
+					EntityType: "Character",
+					EntityID:   "marcus",
+					Fields:     map[string]any{"name": "Marcus"},
+					Relationships: []*RelationshipDelta{{
+						Operation:        "update",
+						FromEntityID:     "marcus",
+						ToEntityID:       "elena",
+						RelationshipType: "allies_with",
+						Properties:       map[string]any{"bond_strength": "strong"},
+					}},
+				}},
+			})
+			if !errors.Is(err, ErrRelationshipNotFound) {
+				t.Errorf("Expected ErrRelationshipNotFound, got %v", err)
+			}
+		})
+	}
+}
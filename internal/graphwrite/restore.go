@@ -0,0 +1,56 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// RestoreEntity brings back an entity deleted earlier in a version's history. It walks
+// the ancestry of versionID to the most recent version still holding the entity and
+// recreates it, with that version's data, in a new child of versionID. Relationships
+// and annotations are not restored.
+func (s *Service) RestoreEntity(ctx context.Context, versionID, logicalID string) (*ApplyResponse, error) {
+	if _, err := s.findEntityInVersion(ctx, versionID, logicalID); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrEntityNotDeleted, logicalID)
+	}
+
+	version, err := s.db.Queries().GetGraphVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version: %w", err)
+	}
+
+	var entity *db.Entity
+	err = s.walkVersionChain(ctx, version.ParentVersionID.String, func(ancestor db.GraphVersion) (bool, error) {
+		found, err := s.findEntityInVersion(ctx, ancestor.ID, logicalID)
+		if err != nil {
+			return true, nil
+		}
+		entity = found
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk ancestor versions: %w", err)
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("entity %s not found in the history of version %s", logicalID, versionID)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(entity.Data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal entity data: %w", err)
+	}
+	delete(fields, deletedField)
+	if _, exists := fields["name"]; !exists {
+		fields["name"] = entity.Name
+	}
+
+	return s.Apply(ctx, &ApplyRequest{
+		ParentVersionID: versionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: entity.EntityType, EntityID: logicalID, Fields: fields},
+		},
+	})
+}
@@ -0,0 +1,153 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestService_RestoreEntity(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	created, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus", "role": "companion"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	updated, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: created.GraphVersionID,
+		Deltas: []*Delta{
+			{Operation: "update", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus Ironforge", "role": "mentor", "age": 45}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	deleted, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: updated.GraphVersionID,
+		Deltas: []*Delta{
+			{Operation: "delete", EntityType: "Character", EntityID: "marcus"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	// A version after the delete, so the restore has to look more than one step back
+	later, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: deleted.GraphVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Location", EntityID: "forge", Fields: map[string]any{"name": "Forge"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	restored, err := service.RestoreEntity(ctx, later.GraphVersionID, "marcus")
+	if err != nil {
+		t.Fatalf("RestoreEntity failed: %v", err)
+	}
+	if restored.GraphVersionID == later.GraphVersionID || restored.Applied != 1 {
+		t.Errorf("Expected a new version with one applied delta, got %+v", restored)
+	}
+
+	version, err := service.GetVersion(ctx, restored.GraphVersionID)
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if version.ParentVersionID == nil || *version.ParentVersionID != later.GraphVersionID {
+		t.Errorf("Expected the restore to branch from the requested version, got %+v", version)
+	}
+
+	entities, err := service.ListEntities(ctx, restored.GraphVersionID, EntityFilter{})
+	if err != nil {
+		t.Fatalf("ListEntities failed: %v", err)
+	}
+	var marcus *Entity
+	for _, entity := range entities {
+		if entity.ID == "marcus" {
+			marcus = entity
+		}
+	}
+	if marcus == nil {
+		t.Fatalf("Expected marcus to be restored, got %d entities", len(entities))
+	}
+	if marcus.Name != "Marcus Ironforge" || marcus.Data["role"] != "mentor" || marcus.Data["age"] != float64(45) {
+		t.Errorf("Expected the last-known data to return, got %s %v", marcus.Name, marcus.Data)
+	}
+	if len(entities) != 2 {
+		t.Errorf("Expected marcus alongside the forge, got %d entities", len(entities))
+	}
+
+	if _, err := service.RestoreEntity(ctx, restored.GraphVersionID, "marcus"); !errors.Is(err, ErrEntityNotDeleted) {
+		t.Errorf("Expected ErrEntityNotDeleted for a present entity, got %v", err)
+	}
+	if _, err := service.RestoreEntity(ctx, restored.GraphVersionID, "ghost"); err == nil {
+		t.Error("Expected an error for an entity that never existed")
+	}
+}
+
+func TestService_RestoreEntity_SoftDelete(t *testing.T) {
+	for _, mode := range []struct {
+		name    string
+		options ServiceOptions
+	}{
+		{name: "copy", options: ServiceOptions{SoftDelete: true}},
+		{name: "copy-on-write", options: ServiceOptions{SoftDelete: true, CopyOnWrite: true}},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			database := setupTestDB(t)
+			defer database.Close()
+
+			service := NewServiceWithOptions(database, mode.options)
+			ctx := context.Background()
+
+			projectID := createTestProject(t, database)
+			rootVersionID := createTestGraphVersion(t, database, projectID, true)
+			baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+			deleted, err := service.Apply(ctx, &ApplyRequest{
+				ParentVersionID: baseVersionID,
+				Deltas:          []*Delta{{Operation: "delete", EntityType: "Character", EntityID: "marcus"}},
+			})
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+
+			// The tombstone in the deleted version is skipped in favour of the live row before it
+			restored, err := service.RestoreEntity(ctx, deleted.GraphVersionID, "marcus")
+			if err != nil {
+				t.Fatalf("RestoreEntity failed: %v", err)
+			}
+
+			entities, err := service.GetEntities(ctx, restored.GraphVersionID, []string{"marcus"})
+			if err != nil {
+				t.Fatalf("GetEntities failed: %v", err)
+			}
+			marcus := entities[0]
+			if marcus == nil {
+				t.Fatal("Expected marcus to be restored")
+			}
+			if _, deleted := marcus.Data[deletedField]; deleted || marcus.Name != "Marcus" {
+				t.Errorf("Expected the live data to return without the tombstone mark, got %s %v", marcus.Name, marcus.Data)
+			}
+			if names := entityNames(t, service, restored.GraphVersionID); len(names) != 2 {
+				t.Errorf("Expected both characters to be listed, got %v", names)
+			}
+		})
+	}
+}
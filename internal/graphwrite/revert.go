@@ -0,0 +1,60 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/google/uuid"
+)
+
+// Revert undoes later edits by creating a new version whose entities, relationships and
+// annotations are copied verbatim from an earlier version of the project, then making
+// it the working set. The new version is a child of the current working set, so the
+// reverted edits stay in the history. The target must belong to the project.
+func (s *Service) Revert(ctx context.Context, projectID, targetVersionID string) (*GraphVersion, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	target, err := s.db.Queries().GetGraphVersion(ctx, targetVersionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrVersionNotFound, err)
+	}
+	if target.ProjectID != projectID {
+		return nil, fmt.Errorf("%w: %s belongs to project %s", ErrVersionNotInProject, targetVersionID, target.ProjectID)
+	}
+
+	parentVersionID := targetVersionID
+	workingSet, err := s.db.Queries().GetWorkingSetVersion(ctx, projectID)
+	if err == nil {
+		parentVersionID = workingSet.ID
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get working set: %w", err)
+	}
+
+	newVersionID := uuid.New().String()
+	version, err := s.db.Queries().CreateGraphVersion(ctx, db.CreateGraphVersionParams{
+		ID:              newVersionID,
+		ProjectID:       projectID,
+		ParentVersionID: sql.NullString{String: parentVersionID, Valid: true},
+		Name:            sql.NullString{String: fmt.Sprintf("Version %s", newVersionID[:8]), Valid: true},
+		Description:     sql.NullString{String: fmt.Sprintf("Reverted to version %s", targetVersionID), Valid: true},
+		IsWorkingSet:    false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create revert version: %w", err)
+	}
+
+	if _, err := s.populateFromParent(ctx, targetVersionID, version.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Queries().SetWorkingSet(ctx, db.SetWorkingSetParams{ID: version.ID, ProjectID: projectID}); err != nil {
+		return nil, fmt.Errorf("failed to set working set: %w", err)
+	}
+
+	version.IsWorkingSet = true
+	return toGraphVersion(version), nil
+}
@@ -0,0 +1,96 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestService_Revert(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	target, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "mood": "hopeful"}},
+			{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	// Several edits after the target
+	versionID := target.GraphVersionID
+	for _, delta := range []*Delta{
+		{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "mood": "grim"}},
+		{Operation: "delete", EntityType: "Location", EntityID: "tavern"},
+		{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}},
+	} {
+		response, err := service.ApplyAndAdvance(ctx, &ApplyRequest{ParentVersionID: versionID, Deltas: []*Delta{delta}})
+		if err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+		versionID = response.GraphVersionID
+	}
+
+	reverted, err := service.Revert(ctx, projectID, target.GraphVersionID)
+	if err != nil {
+		t.Fatalf("Revert failed: %v", err)
+	}
+	if reverted.ID == target.GraphVersionID || !reverted.IsWorkingSet {
+		t.Errorf("Expected a new working set version, got %+v", reverted)
+	}
+	if reverted.ParentVersionID == nil || *reverted.ParentVersionID != versionID {
+		t.Errorf("Expected the revert to follow the latest edit, got parent %v", reverted.ParentVersionID)
+	}
+
+	workingSet, err := database.Queries().GetWorkingSetVersion(ctx, projectID)
+	if err != nil {
+		t.Fatalf("GetWorkingSetVersion failed: %v", err)
+	}
+	if workingSet.ID != reverted.ID {
+		t.Errorf("Expected the working set to be %s, got %s", reverted.ID, workingSet.ID)
+	}
+
+	diff, err := service.Diff(ctx, target.GraphVersionID, reverted.ID)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(diff.AddedEntities)+len(diff.RemovedEntities)+len(diff.ModifiedEntities) != 0 {
+		t.Errorf("Expected the reverted version to match the target, got %+v", diff)
+	}
+
+	elena, err := service.(*Service).entityInVersion(ctx, reverted.ID, "elena")
+	if err != nil || elena == nil {
+		t.Fatalf("Expected elena in the reverted version, got %v", err)
+	}
+	if elena.Data["mood"] != "hopeful" {
+		t.Errorf("Expected elena's mood to be reverted to hopeful, got %v", elena.Data["mood"])
+	}
+}
+
+func TestService_Revert_RejectsOtherProjectVersion(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	createTestGraphVersion(t, database, projectID, true)
+
+	otherProjectID := createTestProject(t, database)
+	otherVersionID := createTestGraphVersion(t, database, otherProjectID, true)
+
+	if _, err := service.Revert(ctx, projectID, otherVersionID); !errors.Is(err, ErrVersionNotInProject) {
+		t.Errorf("Expected ErrVersionNotInProject, got %v", err)
+	}
+}
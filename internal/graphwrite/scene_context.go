@@ -0,0 +1,184 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+const (
+	// sceneContextMaxCharacters and sceneContextMaxThemes cap how many featured
+	// characters and themes a SceneContext carries
+	sceneContextMaxCharacters = 8
+	sceneContextMaxThemes     = 5
+
+	// sceneContextMaxText caps each text field of a SceneContext, in characters
+	sceneContextMaxText = 280
+)
+
+// SceneContext is a compact view of a scene for feeding to a language model: its
+// summary, brief profiles of the characters it features, where it happens and the
+// themes it explores. Entities are sorted by logical ID and every list and text field
+// is capped, so the same version always yields the same, bounded context.
+type SceneContext struct {
+	SceneID       string
+	Title         string
+	Summary       string
+	Act           string
+	Sequence      int
+	EmotionalTone string
+
+	Characters []*ContextProfile
+	Location   *ContextProfile
+	Themes     []*ContextProfile
+}
+
+// ContextProfile briefly describes an entity in a SceneContext. Role and Traits are
+// only set for characters.
+type ContextProfile struct {
+	ID          string
+	Name        string
+	Description string
+	Role        string
+	Traits      []string
+}
+
+// BuildSceneContext assembles the SceneContext for a scene. Characters come from the
+// scene's characters field and its features relationships, the location from its
+// location field or an occurs_at relationship, and themes from its themes field and
+// explores relationships in either direction. The summary falls back to the start of
+// the content when the scene has none. Returns an error if the entity is not a Scene.
+func (s *Service) BuildSceneContext(ctx context.Context, versionID, sceneLogicalID string) (*SceneContext, error) {
+	row, err := s.findEntityInVersion(ctx, versionID, sceneLogicalID)
+	if err != nil {
+		return nil, err
+	}
+	if row.EntityType != string(types.EntityTypeScene) {
+		return nil, fmt.Errorf("entity %s is a %s, not a %s", sceneLogicalID, row.EntityType, types.EntityTypeScene)
+	}
+
+	entities, err := s.ListEntities(ctx, versionID, EntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Entity, len(entities))
+	for _, entity := range entities {
+		byID[entity.ID] = entity
+	}
+	scene := byID[sceneLogicalID]
+	if scene == nil {
+		return nil, fmt.Errorf("%w: %s is deleted in version %s", ErrEntityNotFound, sceneLogicalID, versionID)
+	}
+
+	characterIDs := referencedIDs(scene.Data["characters"])
+	locationIDs := referencedIDs(scene.Data["location"])
+	themeIDs := referencedIDs(scene.Data["themes"])
+
+	related, err := s.sceneRelations(ctx, versionID, row)
+	if err != nil {
+		return nil, err
+	}
+	for _, relation := range related {
+		switch {
+		case relation.RelationshipType == string(types.RelationshipFeatures) && relation.Outgoing:
+			characterIDs = append(characterIDs, relation.EntityID)
+		case relation.RelationshipType == string(types.RelationshipOccursAt) && relation.Outgoing:
+			locationIDs = append(locationIDs, relation.EntityID)
+		case relation.RelationshipType == string(types.RelationshipExplores):
+			themeIDs = append(themeIDs, relation.EntityID)
+		}
+	}
+
+	result := &SceneContext{
+		SceneID:       scene.ID,
+		Title:         types.Preview(stringField(scene.Data, "title"), sceneContextMaxText),
+		Summary:       stringField(scene.Data, "summary"),
+		Act:           arcAct(scene.Data["act"]),
+		Sequence:      arcSequence(scene.Data["sequence"]),
+		EmotionalTone: stringField(scene.Data, "emotional_tone"),
+	}
+	if result.Summary == "" {
+		result.Summary = stringField(scene.Data, "content")
+	}
+	result.Summary = types.Preview(result.Summary, sceneContextMaxText)
+
+	result.Characters = contextProfiles(byID, characterIDs, types.EntityTypeCharacter, sceneContextMaxCharacters)
+	if locations := contextProfiles(byID, locationIDs, types.EntityTypeLocation, 1); len(locations) > 0 {
+		result.Location = locations[0]
+	}
+	result.Themes = contextProfiles(byID, themeIDs, types.EntityTypeTheme, sceneContextMaxThemes)
+
+	return result, nil
+}
+
+// sceneRelations lists a scene's relationships, seen from the scene's side
+func (s *Service) sceneRelations(ctx context.Context, versionID string, scene *db.Entity) ([]CharacterRelation, error) {
+	logicalIDs, err := s.logicalIDsByDatabaseID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := s.db.Queries().ListRelationshipsByEntityInVersion(ctx, db.ListRelationshipsByEntityInVersionParams{
+		VersionID:    versionID,
+		FromEntityID: scene.ID,
+		ToEntityID:   scene.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	relations := make([]CharacterRelation, 0, len(relationships))
+	for _, rel := range relationships {
+		if rel.FromEntityID == scene.ID {
+			relations = append(relations, CharacterRelation{RelationshipType: rel.RelationshipType, EntityID: logicalIDs[rel.ToEntityID], Outgoing: true})
+		} else {
+			relations = append(relations, CharacterRelation{RelationshipType: rel.RelationshipType, EntityID: logicalIDs[rel.FromEntityID]})
+		}
+	}
+	return relations, nil
+}
+
+// contextProfiles builds the profiles of the referenced entities of one type, deduped,
+// sorted by logical ID and capped at limit. References to missing entities or entities
+// of another type are dropped.
+func contextProfiles(byID map[string]*Entity, ids []string, entityType types.EntityType, limit int) []*ContextProfile {
+	seen := make(map[string]bool, len(ids))
+	var matched []*Entity
+	for _, id := range ids {
+		entity := byID[id]
+		if seen[id] || entity == nil || entity.EntityType != string(entityType) {
+			continue
+		}
+		seen[id] = true
+		matched = append(matched, entity)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+
+	profiles := make([]*ContextProfile, len(matched))
+	for i, entity := range matched {
+		profile := &ContextProfile{
+			ID:          entity.ID,
+			Name:        types.Preview(entity.Name, sceneContextMaxText),
+			Description: types.Preview(stringField(entity.Data, "description"), sceneContextMaxText),
+		}
+		if entityType == types.EntityTypeCharacter {
+			profile.Role = stringField(entity.Data, "role")
+			for _, trait := range referencedIDs(entity.Data["personality_traits"]) {
+				profile.Traits = append(profile.Traits, types.Preview(trait, sceneContextMaxText))
+			}
+		}
+		profiles[i] = profile
+	}
+	return profiles
+}
+
+// stringField returns a string data field, or "" when it is missing or not a string
+func stringField(data map[string]any, field string) string {
+	value, _ := data[field].(string)
+	return value
+}
@@ -0,0 +1,101 @@
+package graphwrite
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestService_BuildSceneContext(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus", "role": "mentor", "description": "A weary veteran", "personality_traits": []any{"loyal", "gruff"}}},
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "role": "protagonist"}},
+			{Operation: "create", EntityType: "Character", EntityID: "villain", Fields: map[string]any{"name": "The Shadow King"}},
+			{Operation: "create", EntityType: "Location", EntityID: "citadel", Fields: map[string]any{"name": "Citadel", "description": "A fortress of stone", "atmosphere": "tense"}},
+			{Operation: "create", EntityType: "Location", EntityID: "harbor", Fields: map[string]any{"name": "Harbor"}},
+			{Operation: "create", EntityType: "Theme", EntityID: "loyalty", Fields: map[string]any{"name": "Loyalty", "description": "What we owe each other"}},
+			{Operation: "create", EntityType: "Theme", EntityID: "power", Fields: map[string]any{"name": "Power"}},
+			{
+				Operation:  "create",
+				EntityType: "Scene",
+				EntityID:   "arrival",
+				Fields: map[string]any{
+					"title":      "Arrival",
+					"summary":    strings.Repeat("é", sceneContextMaxText+20),
+					"act":        "1",
+					"sequence":   2,
+					"characters": []any{"marcus"},
+					"location":   "citadel",
+				},
+				Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "arrival", ToEntityID: "elena", RelationshipType: "features", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "arrival", ToEntityID: "marcus", RelationshipType: "features", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "loyalty", ToEntityID: "arrival", RelationshipType: "explores", Properties: map[string]any{}},
+				},
+			},
+			{Operation: "create", EntityType: "Scene", EntityID: "finale", Fields: map[string]any{"title": "Finale", "characters": []any{"villain"}, "location": "harbor"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	versionID := response.GraphVersionID
+
+	sceneContext, err := service.BuildSceneContext(ctx, versionID, "arrival")
+	if err != nil {
+		t.Fatalf("BuildSceneContext failed: %v", err)
+	}
+
+	if sceneContext.SceneID != "arrival" || sceneContext.Title != "Arrival" || sceneContext.Act != "1" || sceneContext.Sequence != 2 {
+		t.Errorf("Unexpected scene fields: %+v", sceneContext)
+	}
+	if length := utf8.RuneCountInString(sceneContext.Summary); length != sceneContextMaxText || !strings.HasSuffix(sceneContext.Summary, "…") || !utf8.ValidString(sceneContext.Summary) {
+		t.Errorf("Expected the summary cut to %d characters, got %d: %q", sceneContextMaxText, length, sceneContext.Summary)
+	}
+
+	// Characters from the field and the edges, once each, in ID order
+	if len(sceneContext.Characters) != 2 || sceneContext.Characters[0].ID != "elena" || sceneContext.Characters[1].ID != "marcus" {
+		t.Fatalf("Expected elena and marcus, got %+v", sceneContext.Characters)
+	}
+	marcus := sceneContext.Characters[1]
+	if marcus.Name != "Marcus" || marcus.Role != "mentor" || marcus.Description != "A weary veteran" || strings.Join(marcus.Traits, ",") != "loyal,gruff" {
+		t.Errorf("Unexpected profile for marcus: %+v", marcus)
+	}
+
+	if sceneContext.Location == nil || sceneContext.Location.ID != "citadel" || sceneContext.Location.Description != "A fortress of stone" {
+		t.Errorf("Expected the citadel, got %+v", sceneContext.Location)
+	}
+	if len(sceneContext.Themes) != 1 || sceneContext.Themes[0].ID != "loyalty" {
+		t.Errorf("Expected only loyalty, got %+v", sceneContext.Themes)
+	}
+
+	// Entities of other scenes stay out
+	for _, profile := range append(append([]*ContextProfile{}, sceneContext.Characters...), sceneContext.Themes...) {
+		if profile.ID == "villain" || profile.ID == "power" || profile.ID == "harbor" {
+			t.Errorf("Unexpected unrelated entity %s", profile.ID)
+		}
+	}
+
+	again, err := service.BuildSceneContext(ctx, versionID, "arrival")
+	if err != nil {
+		t.Fatalf("BuildSceneContext failed: %v", err)
+	}
+	if again.Summary != sceneContext.Summary || len(again.Characters) != len(sceneContext.Characters) {
+		t.Error("Expected the same context on a second build")
+	}
+
+	if _, err := service.BuildSceneContext(ctx, versionID, "elena"); err == nil {
+		t.Error("Expected an error for a non-scene entity")
+	}
+}
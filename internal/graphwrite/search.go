@@ -0,0 +1,78 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SearchEntities returns the entities in a version matching every term of the query.
+// A plain term matches case-insensitively against the entity's name, type and any
+// string field; a "field:value" term matches only that data field. An empty query
+// matches nothing. Searching another owner's version (see WithOwner) returns
+// ErrProjectNotOwned.
+func (s *Service) SearchEntities(ctx context.Context, versionID string, query string) ([]*Entity, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return []*Entity{}, nil
+	}
+
+	if err := s.checkVersionOwner(ctx, versionID); err != nil {
+		return nil, err
+	}
+
+	entities, err := s.ListEntities(ctx, versionID, EntityFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	matches := []*Entity{}
+	for _, entity := range entities {
+		if matchesAllTerms(entity, terms) {
+			matches = append(matches, entity)
+		}
+	}
+
+	return matches, nil
+}
+
+// matchesAllTerms reports whether every lowercased search term matches the entity
+func matchesAllTerms(entity *Entity, terms []string) bool {
+	for _, term := range terms {
+		if field, value, scoped := strings.Cut(term, ":"); scoped && field != "" {
+			if !containsFold(entity.Data[field], value) {
+				return false
+			}
+			continue
+		}
+
+		matched := strings.Contains(strings.ToLower(entity.Name), term) ||
+			strings.Contains(strings.ToLower(entity.EntityType), term)
+		for _, value := range entity.Data {
+			if matched {
+				break
+			}
+			matched = containsFold(value, term)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether a string, or any string in a list, contains the
+// lowercased term
+func containsFold(value any, term string) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.Contains(strings.ToLower(v), term)
+	case []any:
+		for _, item := range v {
+			if containsFold(item, term) {
+				return true
+			}
+		}
+	}
+	return false
+}
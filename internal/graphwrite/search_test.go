@@ -0,0 +1,64 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+)
+
+func TestService_SearchEntities(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena Stormwind", "skills": []string{"Archaeology", "leadership"}}},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus", "role": "companion"}},
+			{Operation: "create", EntityType: "Location", EntityID: "temple", Fields: map[string]any{"name": "Storm Temple", "role": "ruin"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	versionID := response.GraphVersionID
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"STORM", []string{"elena", "temple"}},
+		{"archaeology", []string{"elena"}},
+		{"storm location", []string{"temple"}},
+		{"role:companion", []string{"marcus"}},
+		{"role:ruin storm", []string{"temple"}},
+		{"dragon", nil},
+		{"   ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			entities, err := service.SearchEntities(ctx, versionID, tt.query)
+			if err != nil {
+				t.Fatalf("SearchEntities failed: %v", err)
+			}
+			found := make(map[string]bool)
+			for _, entity := range entities {
+				found[entity.ID] = true
+			}
+			if len(found) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, found)
+			}
+			for _, id := range tt.expected {
+				if !found[id] {
+					t.Errorf("Expected %s in results, got %v", id, found)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// SeriesIssueKind classifies a continuity problem found across the books of a series
+type SeriesIssueKind string
+
+const (
+	// SeriesIssueRegression is a character's numeric attribute dropping in a later book
+	SeriesIssueRegression SeriesIssueKind = "regression"
+	// SeriesIssueInconsistentLocation is a location described differently in a later book
+	SeriesIssueInconsistentLocation SeriesIssueKind = "inconsistent_location"
+	// SeriesIssueMissingFromBook is a shared entity absent from a book between two it appears in
+	SeriesIssueMissingFromBook SeriesIssueKind = "missing_from_book"
+)
+
+// SeriesIssue is one continuity problem ValidateSeries found. ProjectID is the book
+// where the problem shows, and Field is empty for missing entities.
+type SeriesIssue struct {
+	Kind       SeriesIssueKind
+	ProjectID  string
+	EntityID   string
+	EntityName string
+	Field      string
+	Message    string
+}
+
+// ValidateSeries checks continuity across the working sets of a series' books, given
+// in reading order: characters whose numeric attributes (such as level) drop between
+// consecutive appearances, locations whose fields change between appearances, and
+// shared entities missing from a book between two books they appear in. The projects
+// must not name different series.
+func (s *Service) ValidateSeries(ctx context.Context, seriesProjectIDs []string) ([]SeriesIssue, error) {
+	var series string
+	books := make([]map[string]*Entity, len(seriesProjectIDs))
+	for i, projectID := range seriesProjectIDs {
+		project, err := s.db.Queries().GetProject(ctx, projectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get project %s: %w", projectID, err)
+		}
+		if !ownsProject(ctx, project.OwnerID) {
+			return nil, fmt.Errorf("%w: %s", ErrProjectNotOwned, projectID)
+		}
+		if project.Series.Valid {
+			if series != "" && project.Series.String != series {
+				return nil, fmt.Errorf("%w: %s is in %q, not %q", ErrMixedSeries, projectID, project.Series.String, series)
+			}
+			series = project.Series.String
+		}
+
+		workingSet, err := s.db.Queries().GetWorkingSetVersion(ctx, projectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working set of %s: %w", projectID, err)
+		}
+		entities, err := s.ListEntities(ctx, workingSet.ID, EntityFilter{})
+		if err != nil {
+			return nil, err
+		}
+
+		books[i] = make(map[string]*Entity, len(entities))
+		for _, entity := range entities {
+			books[i][entity.ID] = entity
+		}
+	}
+
+	appearances := make(map[string][]int)
+	for i, book := range books {
+		for logicalID := range book {
+			appearances[logicalID] = append(appearances[logicalID], i)
+		}
+	}
+	logicalIDs := make([]string, 0, len(appearances))
+	for logicalID, inBooks := range appearances {
+		if len(inBooks) > 1 {
+			logicalIDs = append(logicalIDs, logicalID)
+		}
+	}
+	sort.Strings(logicalIDs)
+
+	var issues []SeriesIssue
+	for _, logicalID := range logicalIDs {
+		inBooks := appearances[logicalID]
+		sort.Ints(inBooks)
+
+		for n := 1; n < len(inBooks); n++ {
+			previous := books[inBooks[n-1]][logicalID]
+			current := books[inBooks[n]][logicalID]
+			projectID := seriesProjectIDs[inBooks[n]]
+
+			for missing := inBooks[n-1] + 1; missing < inBooks[n]; missing++ {
+				issues = append(issues, SeriesIssue{
+					Kind:       SeriesIssueMissingFromBook,
+					ProjectID:  seriesProjectIDs[missing],
+					EntityID:   logicalID,
+					EntityName: current.Name,
+					Message:    fmt.Sprintf("%s appears before and after this book but not in it", current.Name),
+				})
+			}
+
+			switch current.EntityType {
+			case "Character":
+				for _, field := range sortedFields(current.Data) {
+					before, wasNumber := numericField(previous.Data[field])
+					after, isNumber := numericField(current.Data[field])
+					if wasNumber && isNumber && after < before {
+						issues = append(issues, SeriesIssue{
+							Kind:       SeriesIssueRegression,
+							ProjectID:  projectID,
+							EntityID:   logicalID,
+							EntityName: current.Name,
+							Field:      field,
+							Message:    fmt.Sprintf("%s drops from %v to %v", field, previous.Data[field], current.Data[field]),
+						})
+					}
+				}
+			case "Location":
+				for _, field := range sortedFields(current.Data) {
+					before, existed := previous.Data[field]
+					if !existed || reflect.DeepEqual(before, current.Data[field]) {
+						continue
+					}
+					issues = append(issues, SeriesIssue{
+						Kind:       SeriesIssueInconsistentLocation,
+						ProjectID:  projectID,
+						EntityID:   logicalID,
+						EntityName: current.Name,
+						Field:      field,
+						Message:    fmt.Sprintf("%s changes from %v to %v", field, before, current.Data[field]),
+					})
+				}
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// sortedFields returns an entity's data keys in order, leaving out the logical ID
+func sortedFields(data map[string]any) []string {
+	fields := make([]string, 0, len(data))
+	for field := range data {
+		if field != "logical_id" {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// numericField returns a decoded JSON number as a float64
+func numericField(value any) (float64, bool) {
+	number, ok := value.(float64)
+	return number, ok
+}
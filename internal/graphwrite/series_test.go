@@ -0,0 +1,99 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// createSagaBook creates one book of the Elena saga in the given series, with the
+// deltas applied to its working set, and returns the project ID
+func createSagaBook(t *testing.T, service GraphWriteService, database *db.Database, series string, deltas []*Delta) string {
+	ctx := context.Background()
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	if _, err := service.UpdateProjectMetadata(ctx, projectID, ProjectMetadata{Status: ProjectStatusDraft, Series: &series}); err != nil {
+		t.Fatalf("UpdateProjectMetadata failed: %v", err)
+	}
+	if len(deltas) > 0 {
+		if _, err := service.ApplyAndAdvance(ctx, &ApplyRequest{ParentVersionID: rootVersionID, Deltas: deltas}); err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+	}
+	return projectID
+}
+
+func sagaElena(level int) *Delta {
+	return &Delta{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena Stormwind", "level": level}}
+}
+
+func sagaCitadel(lighting string) *Delta {
+	return &Delta{Operation: "create", EntityType: "Location", EntityID: "citadel", Fields: map[string]any{"name": "The Citadel", "lighting": lighting}}
+}
+
+func sagaMarcus() *Delta {
+	return &Delta{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus Ironforge", "level": 3}}
+}
+
+func TestService_ValidateSeries(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	const series = "The Chronicles of Elena Stormwind"
+	book1 := createSagaBook(t, service, database, series, []*Delta{sagaElena(1), sagaCitadel("torchlit"), sagaMarcus()})
+	book2 := createSagaBook(t, service, database, series, []*Delta{sagaElena(7), sagaCitadel("torchlit")})
+	book3 := createSagaBook(t, service, database, series, []*Delta{sagaElena(3), sagaCitadel("sunlit"), sagaMarcus()})
+
+	issues, err := service.ValidateSeries(ctx, []string{book1, book2, book3})
+	if err != nil {
+		t.Fatalf("ValidateSeries failed: %v", err)
+	}
+
+	found := make(map[SeriesIssueKind]SeriesIssue)
+	for _, issue := range issues {
+		found[issue.Kind] = issue
+	}
+	if len(issues) != 3 {
+		t.Fatalf("Expected 3 issues, got %+v", issues)
+	}
+
+	regression := found[SeriesIssueRegression]
+	if regression.EntityID != "elena" || regression.Field != "level" || regression.ProjectID != book3 {
+		t.Errorf("Expected Elena's level to regress in Book 3, got %+v", regression)
+	}
+	if location := found[SeriesIssueInconsistentLocation]; location.EntityID != "citadel" || location.Field != "lighting" || location.ProjectID != book3 {
+		t.Errorf("Expected the citadel's lighting to be flagged in Book 3, got %+v", location)
+	}
+	if missing := found[SeriesIssueMissingFromBook]; missing.EntityID != "marcus" || missing.ProjectID != book2 {
+		t.Errorf("Expected Marcus to be missing from Book 2, got %+v", missing)
+	}
+
+	// Growing levels and unchanged locations are consistent
+	consistent, err := service.ValidateSeries(ctx, []string{book1, book2})
+	if err != nil {
+		t.Fatalf("ValidateSeries failed: %v", err)
+	}
+	if len(consistent) != 0 {
+		t.Errorf("Expected Books 1 and 2 to be consistent, got %+v", consistent)
+	}
+}
+
+func TestService_ValidateSeriesRejectsMixedSeries(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+
+	book1 := createSagaBook(t, service, database, "The Chronicles of Elena Stormwind", []*Delta{sagaElena(1)})
+	other := createSagaBook(t, service, database, "Another Saga", []*Delta{sagaElena(5)})
+
+	if _, err := service.ValidateSeries(context.Background(), []string{book1, other}); !errors.Is(err, ErrMixedSeries) {
+		t.Errorf("Expected ErrMixedSeries, got %v", err)
+	}
+}
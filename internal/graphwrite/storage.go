@@ -0,0 +1,57 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// Store is the storage backend behind Service: the query set the service runs every
+// read and write through. SQLiteStore is the built-in implementation; other backends
+// implement db.Querier over their own storage and can be checked with the storetest
+// conformance suite.
+type Store interface {
+	// Queries returns the queries to run; it is called once per operation, so
+	// implementations may hand out a fresh value each time
+	Queries() db.Querier
+}
+
+// TxStore is a Store that can run a group of writes atomically. Backends without
+// transactions implement only Store, and the service then writes step by step.
+type TxStore interface {
+	Store
+
+	// InTx runs fn with a Store whose writes commit together when fn returns nil
+	// and are rolled back when it returns an error
+	InTx(ctx context.Context, fn func(Store) error) error
+}
+
+// SQLiteStore is the Store backed by the sqlite database
+type SQLiteStore struct {
+	database *db.Database
+}
+
+// NewSQLiteStore creates a Store over a migrated sqlite database
+func NewSQLiteStore(database *db.Database) *SQLiteStore {
+	return &SQLiteStore{database: database}
+}
+
+// Queries returns the database's generated queries
+func (s *SQLiteStore) Queries() db.Querier {
+	return s.database.Queries()
+}
+
+// InTx runs fn in a sqlite transaction
+func (s *SQLiteStore) InTx(ctx context.Context, fn func(Store) error) error {
+	tx, err := s.database.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(NewSQLiteStore(s.database.WithDBTX(tx))); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/types"
 	"github.com/google/uuid"
 )
 
@@ -15,12 +20,78 @@ import (
 type GraphWriteService interface {
 	// Apply applies a set of deltas to create a new graph version
 	Apply(ctx context.Context, req *ApplyRequest) (*ApplyResponse, error)
+
+	// ApplyAndAdvance applies deltas and makes the new version the project's working set
+	ApplyAndAdvance(ctx context.Context, req *ApplyRequest) (*ApplyResponse, error)
+
+	// ApplyToProject applies deltas to a project's working set and advances it to the result
+	ApplyToProject(ctx context.Context, projectID string, deltas []*Delta) (*ApplyResponse, error)
+
+	// ApplyWithDiff applies deltas like Apply and also returns the diff from the parent to the new version
+	ApplyWithDiff(ctx context.Context, req *ApplyRequest) (*ApplyResponse, *GraphDiff, error)
+
+	// Merge three-way merges two versions branched from base into a new version, reporting conflicts
+	Merge(ctx context.Context, baseVersionID, leftVersionID, rightVersionID string) (*MergeResult, error)
+
+	// Validate checks an Apply batch without writing and reports every problem it finds
+	Validate(ctx context.Context, req *ApplyRequest) (*ValidationReport, error)
+
+	// RestoreEntity recreates a deleted entity from its last-known data in a new version
+	RestoreEntity(ctx context.Context, versionID, logicalID string) (*ApplyResponse, error)
 	
 	// GetVersion retrieves a specific graph version
 	GetVersion(ctx context.Context, versionID string) (*GraphVersion, error)
+
+	// GetVersionTree returns a project's versions nested under their parents, as a forest
+	GetVersionTree(ctx context.Context, projectID string) (*VersionTree, error)
+
+	// VersionRange returns the versions from an ancestor down to a descendant, inclusive and oldest first
+	VersionRange(ctx context.Context, fromVersionID, toVersionID string) ([]*GraphVersion, error)
 	
 	// ListEntities retrieves entities from a specific version with optional filtering
 	ListEntities(ctx context.Context, versionID string, filter EntityFilter) ([]*Entity, error)
+
+	// GetEntities fetches entities by logical ID in one query, in the order requested
+	GetEntities(ctx context.Context, versionID string, logicalIDs []string) ([]*Entity, error)
+
+	// ListEntitiesPage lists a page of a version's entities with the total count
+	ListEntitiesPage(ctx context.Context, versionID string, filter EntityFilter, opts ListOptions) (*ListResult[*Entity], error)
+
+	// ListRelationships lists a page of a version's relationships with the total count
+	ListRelationships(ctx context.Context, versionID string, opts ListOptions) (*ListResult[*Relationship], error)
+
+	// ListRelationshipsOfType lists a page of a version's relationships of one type with the total count
+	ListRelationshipsOfType(ctx context.Context, versionID string, relationshipType string, opts ListOptions) (*ListResult[*Relationship], error)
+
+	// SearchEntities finds entities in a version whose name, type or fields match a query
+	SearchEntities(ctx context.Context, versionID string, query string) ([]*Entity, error)
+
+	// EntitiesAsOf lists the entities of the latest project version created at or before t
+	EntitiesAsOf(ctx context.Context, projectID string, t time.Time) ([]*Entity, error)
+
+	// TagVersion names a version as a checkpoint within its project
+	TagVersion(ctx context.Context, versionID string, tag string) error
+
+	// CompareToTag reports how an entity changed between a tagged version and the working set
+	CompareToTag(ctx context.Context, projectID, logicalID, tag string) (*EntityChange, error)
+
+	// ListRelationshipTypes lists the distinct relationship types present in a version
+	ListRelationshipTypes(ctx context.Context, versionID string) ([]string, error)
+
+	// RelationshipsAmong lists the relationships in a version whose endpoints are both in the given set
+	RelationshipsAmong(ctx context.Context, versionID string, logicalIDs []string) ([]*Relationship, error)
+
+	// TraceThread follows chosen relationship types out from an entity and returns the entities reached as a tree
+	TraceThread(ctx context.Context, versionID, startLogicalID string, relTypes []string, maxDepth int) (*Thread, error)
+
+	// ExportGraphML renders a version as a GraphML document
+	ExportGraphML(ctx context.Context, versionID string) ([]byte, error)
+
+	// SaveLayout stores graph view node positions for a version
+	SaveLayout(ctx context.Context, versionID string, positions map[string]NodePosition) error
+
+	// GetLayout returns a version's saved node positions, inherited from its nearest ancestor with a layout
+	GetLayout(ctx context.Context, versionID string) (map[string]NodePosition, error)
 	
 	// GetNeighbors retrieves entities connected to a given entity via specific relationship types
 	GetNeighbors(ctx context.Context, entityID string, relationshipType string) ([]*Entity, error)
@@ -32,18 +103,160 @@ type GraphWriteService interface {
 	
 	// ImportEntity imports an entity from another project, maintaining its identity
 	ImportEntity(ctx context.Context, targetVersionID string, sourceProjectID string, entityLogicalID string) (*Entity, error)
+
+	// ImportEntities imports several entities from another project in one pass, skipping those already present
+	ImportEntities(ctx context.Context, targetVersionID string, sourceProjectID string, logicalIDs []string) ([]*Entity, error)
+
+	// ImportEntityWithRelationships imports an entity along with its relationships to entities already in the target
+	ImportEntityWithRelationships(ctx context.Context, targetVersionID string, sourceProjectID string, logicalID string) (*Entity, error)
 	
+	// CheckImportDuplicate warns when an import would likely duplicate a same-named entity
+	CheckImportDuplicate(ctx context.Context, targetVersionID string, sourceProjectID string, entityLogicalID string) (*ImportWarning, error)
+
 	// GetEntityHistory retrieves the evolution of an entity across all projects
 	GetEntityHistory(ctx context.Context, entityLogicalID string) ([]*EntityVersion, error)
 	
+	// GetEntityHistoryInProject retrieves an entity's state at each version of one project, oldest first
+	GetEntityHistoryInProject(ctx context.Context, projectID, logicalEntityID string) ([]*EntityVersion, error)
+
+	// GetEntityHistoryPage lists a page of an entity's history with the total count
+	GetEntityHistoryPage(ctx context.Context, entityLogicalID string, opts ListOptions) (*ListResult[*EntityVersion], error)
+
 	// ListSharedEntities lists entities that appear in multiple projects
 	ListSharedEntities(ctx context.Context) ([]*SharedEntity, error)
+
+	// ListSharedEntitiesPage lists a page of the shared entities with the total count
+	ListSharedEntitiesPage(ctx context.Context, opts ListOptions) (*ListResult[*SharedEntity], error)
+
+	// VersionsContaining lists every version across projects in which the entity appears
+	VersionsContaining(ctx context.Context, logicalID string) ([]*GraphVersion, error)
+
+	// Annotations
+
+	// CreateAnnotation attaches an annotation to an entity in a version
+	CreateAnnotation(ctx context.Context, versionID string, annotation *Annotation) (*Annotation, error)
+
+	// AnnotationsForVersion returns every annotation on a version's entities
+	AnnotationsForVersion(ctx context.Context, versionID string) ([]Annotation, error)
+
+	// FindOrphanAnnotations lists annotations whose entity is gone or that were not carried into the working set
+	FindOrphanAnnotations(ctx context.Context) ([]Annotation, error)
+
+	// PruneOrphanAnnotations deletes the annotations FindOrphanAnnotations reports
+	PruneOrphanAnnotations(ctx context.Context) (int, error)
+
+	// AnnotateRelationship attaches an annotation to a relationship, named by its logical endpoints and type
+	AnnotateRelationship(ctx context.Context, versionID string, annotation *RelationshipAnnotation) (*RelationshipAnnotation, error)
+
+	// RelationshipAnnotationsForVersion returns every annotation on a version's relationships
+	RelationshipAnnotationsForVersion(ctx context.Context, versionID string) ([]RelationshipAnnotation, error)
+
+	// Typed entity queries
+
+	// GetCharacterProfile retrieves a character's data as a typed CharacterData
+	GetCharacterProfile(ctx context.Context, versionID string, logicalID string) (*types.CharacterData, error)
+
+	// FirstAppearance returns the earliest scene featuring a character, or nil if none does
+	FirstAppearance(ctx context.Context, versionID, characterLogicalID string) (*Entity, error)
+
+	// ExportCharacterDossier renders a character's data, arc, relationships and intro scene in every project as JSON
+	ExportCharacterDossier(ctx context.Context, logicalID string) ([]byte, error)
+
+	// BuildSceneContext assembles a compact, size-bounded context of a scene, its characters, location and themes
+	BuildSceneContext(ctx context.Context, versionID, sceneLogicalID string) (*SceneContext, error)
+
+	// StoreEmbedding saves a caller-supplied embedding vector for an entity in a version
+	StoreEmbedding(ctx context.Context, versionID, logicalID string, vector []float32) error
+
+	// FindSimilarEntities returns the k entities whose embeddings are most cosine-similar to an entity's
+	FindSimilarEntities(ctx context.Context, versionID, logicalID string, k int) ([]*Entity, error)
+
+	// CompareCharacters diffs two characters' typed data and relationships
+	CompareCharacters(ctx context.Context, versionID, idA, idB string) (*CharacterComparison, error)
+
+	// ExtractMentions lists the characters whose names appear in a scene's content
+	ExtractMentions(ctx context.Context, versionID, sceneLogicalID string) ([]string, error)
+
+	// LinkMentions applies features relationships from a scene to the characters it mentions
+	LinkMentions(ctx context.Context, versionID, sceneLogicalID string) (*ApplyResponse, error)
+
+	// EmotionalArc returns each scene's latest sentiment and impact in narrative order
+	EmotionalArc(ctx context.Context, versionID string) ([]ArcPoint, error)
+
+	// PropagateThemes maps each theme to the scenes and characters it reaches through explores and features relationships
+	PropagateThemes(ctx context.Context, versionID string) (map[string][]string, error)
+
+	// SetProjectOwner assigns a project to an owner for multi-tenant scoping
+	SetProjectOwner(ctx context.Context, projectID string, ownerID string) error
+
+	// Diff compares two versions' entities and relationships by logical ID
+	Diff(ctx context.Context, fromVersionID, toVersionID string) (*GraphDiff, error)
+
+	// FieldHistory lists each change to one entity field along the project's version chain
+	FieldHistory(ctx context.Context, projectID, logicalID, field string) ([]FieldChange, error)
+
+	// Revert makes a copy of an earlier version of the project its new working set
+	Revert(ctx context.Context, projectID, targetVersionID string) (*GraphVersion, error)
+
+	// RepairIntegrity reports, and optionally deletes, relationships and annotations pointing at missing entities
+	RepairIntegrity(ctx context.Context, opts RepairOptions) (*IntegrityReport, error)
+
+	// CompletenessReport runs the editorial completeness checklist over a version
+	CompletenessReport(ctx context.Context, versionID string) (*Completeness, error)
+
+	// Project queries
+
+	// GetProject retrieves a project's metadata
+	GetProject(ctx context.Context, projectID string) (*Project, error)
+
+	// GetProjectOverview retrieves project metadata, working set counts and recent versions in one call
+	GetProjectOverview(ctx context.Context, projectID string) (*ProjectOverview, error)
+
+	// CreateProject creates a project and its initial working-set version together, returning both IDs
+	CreateProject(ctx context.Context, params db.CreateProjectParams, initialVersionName string) (string, string, error)
+
+	// ListProjectsWithStats lists every project with its working set counts in a fixed number of queries
+	ListProjectsWithStats(ctx context.Context) ([]ProjectSummaryStats, error)
+
+	// UpdateProjectMetadata sets a project's status, author and series
+	UpdateProjectMetadata(ctx context.Context, projectID string, metadata ProjectMetadata) (*Project, error)
+
+	// ValidateSeries checks continuity across a series' books, given in reading order
+	ValidateSeries(ctx context.Context, seriesProjectIDs []string) ([]SeriesIssue, error)
+
+	// RenameProject changes a project's name, rejecting names another project already uses
+	RenameProject(ctx context.Context, projectID string, newName string) error
+
+	// UniqueProjectName returns the name, suffixed with " (2)", " (3)"... until no project uses it
+	UniqueProjectName(ctx context.Context, name string) (string, error)
+
+	// GetProjectSettings returns a project's settings, or the defaults if none were saved
+	GetProjectSettings(ctx context.Context, projectID string) (*ProjectSettings, error)
+
+	// UpdateProjectSettings replaces a project's settings
+	UpdateProjectSettings(ctx context.Context, projectID string, settings ProjectSettings) (*ProjectSettings, error)
+
+	// DeletionImpact reports the shared entities and cross-project relationships a project deletion would affect
+	DeletionImpact(ctx context.Context, projectID string) (*DeletionImpact, error)
+
+	// FlattenProject copies a project's working set into a new single-version project
+	FlattenProject(ctx context.Context, projectID string) (string, error)
+
+	// RelationshipTrends returns the count of each relationship type at every version, oldest first
+	RelationshipTrends(ctx context.Context, projectID string) (map[string][]int, error)
 }
 
 // ApplyRequest represents a request to apply deltas to the graph
 type ApplyRequest struct {
 	ParentVersionID string
 	Deltas          []*Delta
+
+	// ExpectedWorkingSetVersionID, when set on an apply that advances the working set,
+	// must still be the project's working set when the pointer moves; otherwise the new
+	// version is discarded and the apply fails with ErrConcurrentModification. Callers
+	// retry by re-reading the working set, rebasing their deltas onto it and applying
+	// again with the new working set as both parent and expected version.
+	ExpectedWorkingSetVersionID string
 }
 
 // ApplyResponse represents the response from applying deltas
@@ -59,11 +272,28 @@ type Delta struct {
 	EntityID         string
 	Fields           map[string]any
 	Relationships    []*RelationshipDelta
+
+	// ExpectedContentHash, when set on an update, must match the entity's current
+	// ContentHash; otherwise the update fails with ErrEntityModified
+	ExpectedContentHash string
+
+	// UnsetFields names fields an update removes. Unless Replace is set, Fields merge
+	// over the entity's current data, so a field is only dropped by listing it here;
+	// logical_id is always kept.
+	UnsetFields []string
+
+	// Replace makes an update overwrite the entity's data with Fields instead of
+	// merging over it, dropping every field not restated
+	Replace bool
 }
 
 // RelationshipDelta represents a change to relationships
 type RelationshipDelta struct {
 	Operation        string            // create, update, delete
+
+	// RelationshipID addresses the edge to update or delete. It changes whenever a
+	// version copies the edge, so when it is empty the edge is found by its logical
+	// FromEntityID, ToEntityID and RelationshipType in the version being written.
 	RelationshipID   string
 	FromEntityID     string
 	ToEntityID       string
@@ -91,6 +321,25 @@ type Entity struct {
 	Data       map[string]any
 	CreatedAt  string
 	UpdatedAt  string
+
+	// ContentHash identifies the entity's current content, for optimistic
+	// concurrency checks via Delta.ExpectedContentHash
+	ContentHash string
+
+	// Annotations holds the latest annotation of each type when requested
+	// via EntityFilter.IncludeAnnotations; nil otherwise
+	Annotations []*Annotation
+}
+
+// Relationship represents a typed edge between two entities, addressed by logical IDs
+type Relationship struct {
+	ID               string
+	VersionID        string
+	FromEntityID     string
+	ToEntityID       string
+	RelationshipType string
+	Properties       map[string]any
+	CreatedAt        string
 }
 
 // EntityFilter provides filtering options for entity queries
@@ -98,16 +347,30 @@ type EntityFilter struct {
 	EntityType *string
 	Name       *string
 	Limit      *int
+	Offset     *int
+
+	// OrderBy sorts by "name", "created_at" or "entity_type", ascending unless
+	// Descending is set. Entities default to newest first.
+	OrderBy    *string
+	Descending bool
+
+	// IncludeAnnotations attaches the latest annotation of each type to every returned entity
+	IncludeAnnotations bool
+
+	// IncludeDeleted also returns tombstoned entities, whose Data sets "deleted"
+	// (see ServiceOptions.SoftDelete); they are hidden by default
+	IncludeDeleted bool
 }
 
 // EntityVersion represents an entity's state in a specific project/version
 type EntityVersion struct {
-	Entity      *Entity
-	ProjectID   string
-	ProjectName string
-	VersionID   string
-	VersionName string
-	CreatedAt   string
+	Entity             *Entity
+	ProjectID          string
+	ProjectName        string
+	VersionID          string
+	VersionName        string
+	VersionDescription string
+	CreatedAt          string
 }
 
 // SharedEntity represents an entity that appears across multiple projects
@@ -116,33 +379,157 @@ type SharedEntity struct {
 	Name          string
 	EntityType    string
 	ProjectCount  int
-	Projects      []string
 	FirstSeen     string
 	LastModified  string
+
+	// ProjectIDs identifies the projects holding the entity, sorted; Projects holds
+	// their names in the same order, each once, with the project ID added to tell
+	// apart projects sharing a name
+	ProjectIDs []string
+	Projects   []string
+}
+
+// ServiceOptions configures optional Service behavior
+type ServiceOptions struct {
+	// AutoAdvanceWorkingSet makes every Apply move the project's working set to
+	// the version it creates, as ApplyAndAdvance does. When false, Apply leaves
+	// the working set alone and callers manage the pointer themselves.
+	//
+	// Advancing is last-writer-wins: the pointer moves to the new version even if
+	// another writer advanced it after ParentVersionID was read, unless the request
+	// sets ApplyRequest.ExpectedWorkingSetVersionID.
+	AutoAdvanceWorkingSet bool
+
+	// ValidateAnnotationMetadata rejects annotations whose metadata does not match
+	// the typed schema for their annotation type (see types.ValidateAnnotationMetadata)
+	ValidateAnnotationMetadata bool
+
+	// AllowSelfRelationships permits relationships whose source and target are the
+	// same entity. They are rejected with ErrSelfRelationship by default because
+	// they make an entity its own neighbor.
+	AllowSelfRelationships bool
+
+	// DedupIdenticalApplies discards the new version when applying the deltas leaves
+	// the content identical to the parent's, returning the parent version with
+	// Applied 0 instead. The working set is not advanced in that case.
+	DedupIdenticalApplies bool
+
+	// ExternalizeSceneContent stores scene content in the content-addressed blobs
+	// table and keeps only its hash in the entity data, so version copies do not
+	// duplicate unchanged content. ListEntities rehydrates the content on read.
+	ExternalizeSceneContent bool
+
+	// CopyOnWrite makes Apply share the parent's entity and relationship rows with
+	// the new version instead of copying them, so only rows the deltas change are
+	// written. Annotations live on the shared rows: annotating an entity no delta
+	// has changed also annotates it in the other versions sharing the row.
+	CopyOnWrite bool
+
+	// Completeness sets what CompletenessReport expects of a story; zero fields
+	// fall back to the defaults described on CompletenessConfig
+	Completeness CompletenessConfig
+
+	// AutoSnapshot tags the working set as a checkpoint after enough edits or time,
+	// so authors editing the working set directly get restore points. A checkpoint
+	// that fails is logged and skipped; the apply still succeeds.
+	AutoSnapshot AutoSnapshotPolicy
+
+	// EntityValidators checks entity data by entity type before creates and updates
+	// store it, failing the apply with ErrInvalidEntityData. Updates are checked after
+	// merging. Types without a validator, and every type when the map is nil, accept
+	// any data; DefaultEntityValidators covers the built-in types.
+	EntityValidators map[string]func(fields map[string]any) error
+
+	// SoftDelete makes deleting an entity write a tombstoned copy into the new version,
+	// marked by a "deleted" data field, instead of leaving it out. Tombstones keep their
+	// relationships, but ListEntities hides them unless EntityFilter.IncludeDeleted is
+	// set, and relationship listings, neighbors and GetEntities hide them and the edges
+	// touching them. Updating or deleting a tombstone fails with ErrEntityNotFound;
+	// creating its logical ID again replaces it.
+	SoftDelete bool
+
+	// FailOnMissingEntities makes GetEntities fail with ErrEntityNotFound when a
+	// requested logical ID is not in the version, instead of leaving its slot nil
+	FailOnMissingEntities bool
+
+	// MaxChainDepth caps how many versions a walk up the parent links visits, such
+	// as resolving a layout or replaying a working set's history; deeper chains fail
+	// with ErrChainTooDeep. Zero means DefaultMaxChainDepth.
+	MaxChainDepth int
 }
 
 // Service implements the GraphWriteService interface
 type Service struct {
-	db *db.Database
+	db      Store
+	options ServiceOptions
 }
 
 // NewService creates a new GraphWriteService instance
 func NewService(database *db.Database) GraphWriteService {
+	return NewServiceWithOptions(database, ServiceOptions{})
+}
+
+// NewServiceWithOptions creates a new GraphWriteService instance with the given options
+func NewServiceWithOptions(database *db.Database, options ServiceOptions) GraphWriteService {
+	return NewServiceWithStore(NewSQLiteStore(database), options)
+}
+
+// NewServiceWithStore creates a new GraphWriteService instance on any storage backend
+func NewServiceWithStore(store Store, options ServiceOptions) GraphWriteService {
 	return &Service{
-		db: database,
+		db:      store,
+		options: options,
 	}
 }
 
-// Apply applies a set of deltas to create a new graph version
+// Apply applies a set of deltas to create a new graph version.
+// The working set only moves when ServiceOptions.AutoAdvanceWorkingSet is set.
 func (s *Service) Apply(ctx context.Context, req *ApplyRequest) (*ApplyResponse, error) {
+	return s.apply(ctx, req, s.options.AutoAdvanceWorkingSet)
+}
+
+// ApplyAndAdvance applies a set of deltas and moves the project's working set to
+// the new version regardless of ServiceOptions.AutoAdvanceWorkingSet
+func (s *Service) ApplyAndAdvance(ctx context.Context, req *ApplyRequest) (*ApplyResponse, error) {
+	return s.apply(ctx, req, true)
+}
+
+// ApplyToProject applies deltas on top of a project's working set and advances the
+// working set to the result. If another writer advances the working set in between,
+// the apply fails with ErrConcurrentModification rather than discarding their changes.
+func (s *Service) ApplyToProject(ctx context.Context, projectID string, deltas []*Delta) (*ApplyResponse, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	workingSet, err := s.db.Queries().GetWorkingSetVersion(ctx, projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrNoWorkingSet, projectID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working set: %w", err)
+	}
+
+	return s.apply(ctx, &ApplyRequest{
+		ParentVersionID:             workingSet.ID,
+		Deltas:                      deltas,
+		ExpectedWorkingSetVersionID: workingSet.ID,
+	}, true)
+}
+
+// apply creates a new version from the parent, applies the deltas and optionally advances the working set
+func (s *Service) apply(ctx context.Context, req *ApplyRequest, advanceWorkingSet bool) (*ApplyResponse, error) {
 	if len(req.Deltas) == 0 {
-		return nil, fmt.Errorf("no deltas provided")
+		return nil, ErrNoDeltas
 	}
 
 	// Validate parent version exists
 	parentVersion, err := s.db.Queries().GetGraphVersion(ctx, req.ParentVersionID)
 	if err != nil {
-		return nil, fmt.Errorf("parent version not found: %w", err)
+		return nil, fmt.Errorf("parent %w: %w", ErrVersionNotFound, err)
+	}
+
+	if err := s.validateRelationshipEndpoints(ctx, req.ParentVersionID, req.Deltas); err != nil {
+		return nil, err
 	}
 
 	// Create new graph version
@@ -159,15 +546,9 @@ func (s *Service) Apply(ctx context.Context, req *ApplyRequest) (*ApplyResponse,
 		return nil, fmt.Errorf("failed to create new version: %w", err)
 	}
 
-	// Copy entities from parent version and get ID mapping
-	entityIDMapping, err := s.copyEntitiesFromParent(ctx, req.ParentVersionID, newVersion.ID)
+	entityIDMapping, err := s.populateFromParent(ctx, req.ParentVersionID, newVersion.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to copy entities from parent: %w", err)
-	}
-
-	// Copy relationships from parent version using the ID mapping
-	if err := s.copyRelationshipsFromParent(ctx, req.ParentVersionID, newVersion.ID, entityIDMapping); err != nil {
-		return nil, fmt.Errorf("failed to copy relationships from parent: %w", err)
+		return nil, err
 	}
 
 	// Apply deltas
@@ -179,82 +560,232 @@ func (s *Service) Apply(ctx context.Context, req *ApplyRequest) (*ApplyResponse,
 		appliedCount++
 	}
 
+	if s.options.DedupIdenticalApplies {
+		unchanged, err := s.sameContent(ctx, req.ParentVersionID, newVersion.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare version content: %w", err)
+		}
+		if unchanged {
+			if err := s.db.Queries().DeleteGraphVersion(ctx, newVersion.ID); err != nil {
+				return nil, fmt.Errorf("failed to discard identical version: %w", err)
+			}
+			return &ApplyResponse{
+				GraphVersionID: req.ParentVersionID,
+				Applied:        0,
+			}, nil
+		}
+	}
+
+	if advanceWorkingSet {
+		if err := s.advanceWorkingSet(ctx, req, newVersion); err != nil {
+			return nil, err
+		}
+
+		// The version is created and the working set has moved, so a missed checkpoint
+		// must not report the apply as failed; the next due edit takes it instead
+		if err := s.autoSnapshot(ctx, newVersion); err != nil {
+			log.Printf("graphwrite: auto-snapshot of version %s failed: %v", newVersion.ID, err)
+		}
+	}
+
 	return &ApplyResponse{
 		GraphVersionID: newVersion.ID,
 		Applied:        appliedCount,
 	}, nil
 }
 
+// advanceWorkingSet moves the project's working set to the new version. With
+// ApplyRequest.ExpectedWorkingSetVersionID set, the check and the move are one
+// conditional update, and the new version is discarded when another writer got there first.
+func (s *Service) advanceWorkingSet(ctx context.Context, req *ApplyRequest, newVersion db.GraphVersion) error {
+	if req.ExpectedWorkingSetVersionID == "" {
+		if err := s.db.Queries().SetWorkingSet(ctx, db.SetWorkingSetParams{
+			ID:        newVersion.ID,
+			ProjectID: newVersion.ProjectID,
+		}); err != nil {
+			return fmt.Errorf("failed to advance working set: %w", err)
+		}
+		return nil
+	}
+
+	advanced, err := s.db.Queries().AdvanceWorkingSet(ctx, db.AdvanceWorkingSetParams{
+		ID:         newVersion.ID,
+		ProjectID:  newVersion.ProjectID,
+		ExpectedID: req.ExpectedWorkingSetVersionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to advance working set: %w", err)
+	}
+	if advanced > 0 {
+		return nil
+	}
+
+	if err := s.db.Queries().DeleteGraphVersion(ctx, newVersion.ID); err != nil {
+		return fmt.Errorf("failed to discard conflicting version: %w", err)
+	}
+	return fmt.Errorf("%w: working set is no longer %s", ErrConcurrentModification, req.ExpectedWorkingSetVersionID)
+}
+
+// populateFromParent fills a new version with its parent's content, sharing the rows
+// when ServiceOptions.CopyOnWrite is set and copying them otherwise, and returns the
+// logical to database ID mapping for the new version
+func (s *Service) populateFromParent(ctx context.Context, parentVersionID, newVersionID string) (map[string]string, error) {
+	if s.options.CopyOnWrite {
+		entityIDMapping, err := s.shareRowsFromParent(ctx, parentVersionID, newVersionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to share rows with parent: %w", err)
+		}
+		return entityIDMapping, nil
+	}
+
+	// Copy entities from parent version and get ID mapping
+	entityIDMapping, err := s.copyEntitiesFromParent(ctx, parentVersionID, newVersionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy entities from parent: %w", err)
+	}
+
+	// Copy relationships from parent version using the ID mapping
+	if err := s.copyRelationshipsFromParent(ctx, parentVersionID, newVersionID, entityIDMapping); err != nil {
+		return nil, fmt.Errorf("failed to copy relationships from parent: %w", err)
+	}
+
+	// Annotations follow their entities so analysis survives unrelated changes
+	if err := s.copyAnnotations(ctx, parentVersionID, entityIDMapping); err != nil {
+		return nil, fmt.Errorf("failed to copy annotations from parent: %w", err)
+	}
+
+	return entityIDMapping, nil
+}
+
 // GetVersion retrieves a specific graph version
 func (s *Service) GetVersion(ctx context.Context, versionID string) (*GraphVersion, error) {
 	version, err := s.db.Queries().GetGraphVersion(ctx, versionID)
 	if err != nil {
-		return nil, fmt.Errorf("version not found: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrVersionNotFound, err)
 	}
 
-	return &GraphVersion{
-		ID:              version.ID,
-		ProjectID:       version.ProjectID,
-		ParentVersionID: nullStringToPtr(version.ParentVersionID),
-		Name:            nullStringToPtr(version.Name),
-		Description:     nullStringToPtr(version.Description),
-		IsWorkingSet:    version.IsWorkingSet,
-		CreatedAt:       version.CreatedAt.Format("2006-01-02T15:04:05Z"),
-	}, nil
+	return toGraphVersion(version), nil
 }
 
+// entityOrders lists the EntityFilter.OrderBy values ListEntities accepts
+var entityOrders = map[string]bool{"name": true, "created_at": true, "entity_type": true}
+
 // ListEntities retrieves entities from a specific version with optional filtering
 func (s *Service) ListEntities(ctx context.Context, versionID string, filter EntityFilter) ([]*Entity, error) {
-	var entities []db.Entity
-	var err error
-
+	params := db.ListEntitiesPageParams{VersionID: versionID, IncludeDeleted: filter.IncludeDeleted, Descending: filter.Descending, PageLimit: -1}
 	if filter.EntityType != nil {
-		entities, err = s.db.Queries().ListEntitiesByType(ctx, db.ListEntitiesByTypeParams{
-			VersionID:  versionID,
-			EntityType: *filter.EntityType,
-		})
-	} else {
-		entities, err = s.db.Queries().ListEntitiesByVersion(ctx, versionID)
+		params.EntityType = sql.NullString{String: *filter.EntityType, Valid: true}
+	}
+	if filter.OrderBy != nil {
+		if !entityOrders[*filter.OrderBy] {
+			return nil, fmt.Errorf("cannot order entities by %q", *filter.OrderBy)
+		}
+		params.OrderBy = *filter.OrderBy
+	}
+	if filter.Limit != nil && *filter.Limit > 0 {
+		params.PageLimit = int64(*filter.Limit)
+	}
+	if filter.Offset != nil && *filter.Offset > 0 {
+		params.PageOffset = int64(*filter.Offset)
 	}
 
+	entities, err := s.db.Queries().ListEntitiesPage(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list entities: %w", err)
 	}
 
 	result := make([]*Entity, len(entities))
 	for i, entity := range entities {
-		var data map[string]any
-		if err := json.Unmarshal(entity.Data, &data); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal entity data: %w", err)
-		}
-
-		// Use logical ID if available, otherwise fall back to database ID
-		entityID := entity.ID
-		if logicalID, exists := data["logical_id"].(string); exists {
-			entityID = logicalID
+		if result[i], err = s.toEntity(ctx, entity); err != nil {
+			return nil, err
 		}
 
-		result[i] = &Entity{
-			ID:         entityID, // Return logical ID for narrative continuity
-			VersionID:  entity.VersionID,
-			EntityType: entity.EntityType,
-			Name:       entity.Name,
-			Data:       data,
-			CreatedAt:  entity.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			UpdatedAt:  entity.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+		if filter.IncludeAnnotations {
+			annotations, err := s.latestAnnotationsByType(ctx, entity.ID, result[i].ID)
+			if err != nil {
+				return nil, err
+			}
+			result[i].Annotations = annotations
 		}
 	}
 
 	return result, nil
 }
 
-// GetNeighbors retrieves entities connected to a given entity via specific relationship types
-// Note: This method needs a version context to work properly with logical IDs
+// toEntity converts an entity row to an Entity addressed by its logical ID, with any
+// externalized content rehydrated
+func (s *Service) toEntity(ctx context.Context, entity db.Entity) (*Entity, error) {
+	var data map[string]any
+	if err := json.Unmarshal(entity.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal entity data: %w", err)
+	}
+	contentHash, err := entityContentHash(entity)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rehydrateContent(ctx, data); err != nil {
+		return nil, err
+	}
+
+	// Use logical ID if available, otherwise fall back to database ID
+	entityID := entity.ID
+	if logicalID, exists := data["logical_id"].(string); exists {
+		entityID = logicalID
+	}
+
+	return &Entity{
+		ID:         entityID, // Return logical ID for narrative continuity
+		VersionID:  entity.VersionID,
+		EntityType: entity.EntityType,
+		Name:       entity.Name,
+		Data:       data,
+		CreatedAt:  entity.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:  entity.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+
+		ContentHash: contentHash,
+	}, nil
+}
+
+// GetNeighbors retrieves entities connected to a given entity via specific relationship types.
+// The entity is looked up in every project's working set; it returns ErrAmbiguousEntity
+// when more than one working set holds it, since the neighbors would belong to different
+// graphs (use GetNeighborsInVersion to pick one), and no neighbors when none do.
 func (s *Service) GetNeighbors(ctx context.Context, logicalEntityID string, relationshipType string) ([]*Entity, error) {
-	// This is a simplified implementation that returns empty for now
-	// In a real implementation, we'd need to know which version to search in
-	// For the demo, we'll return empty neighbors to avoid complexity
-	return []*Entity{}, nil
+	projects, err := s.listProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	var versionIDs []string
+	for _, project := range projects {
+		workingSet, err := s.db.Queries().GetWorkingSetVersion(ctx, project.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working set for project %s: %w", project.ID, err)
+		}
+
+		logicalIDs, err := s.logicalIDsByDatabaseID(ctx, workingSet.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, logicalID := range logicalIDs {
+			if logicalID == logicalEntityID {
+				versionIDs = append(versionIDs, workingSet.ID)
+				break
+			}
+		}
+	}
+
+	switch len(versionIDs) {
+	case 0:
+		return []*Entity{}, nil
+	case 1:
+		return s.GetNeighborsInVersion(ctx, versionIDs[0], logicalEntityID, relationshipType)
+	default:
+		return nil, fmt.Errorf("%w: %s is in %d working sets", ErrAmbiguousEntity, logicalEntityID, len(versionIDs))
+	}
 }
 
 // copyEntitiesFromParent copies all entities from parent version to new version
@@ -367,6 +898,9 @@ func (s *Service) copyRelationshipsFromParent(ctx context.Context, parentVersion
 		if err != nil {
 			return fmt.Errorf("failed to copy relationship %s: %w", rel.ID, err)
 		}
+		if err := s.copyRelationshipAnnotations(ctx, rel.ID, newRelationshipID); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -382,7 +916,7 @@ func (s *Service) applyDelta(ctx context.Context, versionID string, delta *Delta
 	case "delete":
 		return s.deleteEntity(ctx, versionID, delta, entityIDMapping)
 	default:
-		return fmt.Errorf("unknown operation: %s", delta.Operation)
+		return fmt.Errorf("%w: %s", ErrUnknownOperation, delta.Operation)
 	}
 }
 
@@ -396,6 +930,23 @@ func (s *Service) createEntity(ctx context.Context, versionID string, delta *Del
 	// Generate new database ID
 	databaseID := uuid.New().String()
 	
+	if err := s.validateEntityData(delta.EntityType, logicalID, delta.Fields); err != nil {
+		return err
+	}
+
+	// Creating a deleted entity again replaces its tombstone
+	if existingID, exists := entityIDMapping[logicalID]; exists {
+		existing, err := s.db.Queries().GetEntity(ctx, existingID)
+		if err != nil {
+			return fmt.Errorf("failed to get entity: %w", err)
+		}
+		if rowIsTombstone(existing) {
+			if err := s.removeEntity(ctx, versionID, existingID); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Add to mapping
 	entityIDMapping[logicalID] = databaseID
 
@@ -414,6 +965,10 @@ func (s *Service) createEntity(ctx context.Context, versionID string, delta *Del
 	}
 	updatedFields["logical_id"] = logicalID
 
+	if err := s.externalizeContent(ctx, delta.EntityType, updatedFields); err != nil {
+		return err
+	}
+
 	// Serialize data as JSON
 	dataBytes, err := json.Marshal(updatedFields)
 	if err != nil {
@@ -439,6 +994,10 @@ func (s *Service) createEntity(ctx context.Context, versionID string, delta *Del
 		}
 	}
 
+	if err := s.applyFieldRelationships(ctx, versionID, logicalID, delta, entityIDMapping); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -447,30 +1006,73 @@ func (s *Service) updateEntity(ctx context.Context, versionID string, delta *Del
 	// Map logical entity ID to database ID for this version
 	databaseID, exists := entityIDMapping[delta.EntityID]
 	if !exists {
-		return fmt.Errorf("entity with logical ID %s not found in current version", delta.EntityID)
+		return fmt.Errorf("%w: %s is not in the current version", ErrEntityNotFound, delta.EntityID)
 	}
 
-	// Extract name from fields
-	name := ""
-	if nameVal, ok := delta.Fields["name"]; ok {
-		if nameStr, ok := nameVal.(string); ok {
-			name = nameStr
+	current, err := s.db.Queries().GetEntity(ctx, databaseID)
+	if err != nil {
+		return fmt.Errorf("failed to get entity: %w", err)
+	}
+	if rowIsTombstone(current) {
+		return fmt.Errorf("%w: %s is deleted", ErrEntityNotFound, delta.EntityID)
+	}
+
+	if delta.ExpectedContentHash != "" {
+		currentHash, err := entityContentHash(current)
+		if err != nil {
+			return err
+		}
+		if currentHash != delta.ExpectedContentHash {
+			return fmt.Errorf("%w: %s", ErrEntityModified, delta.EntityID)
 		}
 	}
 
-	// Preserve logical ID in the data
+	// Merge the new fields over the current data, unless replacing it, then drop the unset ones
 	updatedFields := make(map[string]any)
+	if !delta.Replace {
+		if err := json.Unmarshal(current.Data, &updatedFields); err != nil {
+			return fmt.Errorf("failed to unmarshal entity data: %w", err)
+		}
+	}
 	for k, v := range delta.Fields {
 		updatedFields[k] = v
 	}
+	for _, field := range delta.UnsetFields {
+		delete(updatedFields, field)
+	}
+	// Stored content may be a blob reference, which new or unset content replaces
+	if _, hasContent := delta.Fields["content"]; hasContent || slices.Contains(delta.UnsetFields, "content") {
+		delete(updatedFields, contentBlobField)
+	}
 	updatedFields["logical_id"] = delta.EntityID // Preserve logical identity
 
+	if err := s.validateEntityData(current.EntityType, delta.EntityID, updatedFields); err != nil {
+		return err
+	}
+
+	// Extract name from the merged fields
+	name := ""
+	if nameStr, ok := updatedFields["name"].(string); ok {
+		name = nameStr
+	}
+
+	if err := s.externalizeContent(ctx, current.EntityType, updatedFields); err != nil {
+		return err
+	}
+
 	// Serialize data as JSON
 	dataBytes, err := json.Marshal(updatedFields)
 	if err != nil {
 		return fmt.Errorf("failed to marshal entity data: %w", err)
 	}
 
+	if s.options.CopyOnWrite {
+		databaseID, err = s.materializeEntity(ctx, versionID, delta.EntityID, databaseID, entityIDMapping)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Update entity using database ID
 	_, err = s.db.Queries().UpdateEntity(ctx, db.UpdateEntityParams{
 		ID:   databaseID,
@@ -491,12 +1093,30 @@ func (s *Service) updateEntity(ctx context.Context, versionID string, delta *Del
 	return nil
 }
 
-// deleteEntity deletes an entity and its relationships
+// deleteEntity deletes an entity and its relationships, or tombstones it with SoftDelete
 func (s *Service) deleteEntity(ctx context.Context, versionID string, delta *Delta, entityIDMapping map[string]string) error {
 	// Map logical entity ID to database ID for this version
 	databaseID, exists := entityIDMapping[delta.EntityID]
 	if !exists {
-		return fmt.Errorf("entity with logical ID %s not found in current version", delta.EntityID)
+		return fmt.Errorf("%w: %s is not in the current version", ErrEntityNotFound, delta.EntityID)
+	}
+
+	if s.options.SoftDelete {
+		return s.tombstoneEntity(ctx, versionID, delta.EntityID, databaseID, entityIDMapping)
+	}
+	return s.removeEntity(ctx, versionID, databaseID)
+}
+
+// removeEntity removes an entity row and its relationships from a version
+func (s *Service) removeEntity(ctx context.Context, versionID string, databaseID string) error {
+	if s.options.CopyOnWrite {
+		entity, err := s.db.Queries().GetEntity(ctx, databaseID)
+		if err != nil {
+			return fmt.Errorf("failed to get entity: %w", err)
+		}
+		if entity.VersionID != versionID {
+			return s.removeEntityFromVersion(ctx, versionID, databaseID)
+		}
 	}
 
 	// Delete relationships first (referential integrity)
@@ -525,7 +1145,7 @@ func (s *Service) applyRelationshipDelta(ctx context.Context, versionID string,
 	case "delete":
 		return s.deleteRelationship(ctx, versionID, relDelta, entityIDMapping)
 	default:
-		return fmt.Errorf("unknown relationship operation: %s", relDelta.Operation)
+		return fmt.Errorf("%w for a relationship: %s", ErrUnknownOperation, relDelta.Operation)
 	}
 }
 
@@ -536,15 +1156,40 @@ func (s *Service) createRelationship(ctx context.Context, versionID string, relD
 		relationshipID = uuid.New().String()
 	}
 
+	if relDelta.FromEntityID == relDelta.ToEntityID && !s.options.AllowSelfRelationships {
+		return fmt.Errorf("%w: %s %s %s", ErrSelfRelationship, relDelta.FromEntityID, relDelta.RelationshipType, relDelta.ToEntityID)
+	}
+
 	// Map logical entity IDs to database IDs
 	fromDatabaseID, exists := entityIDMapping[relDelta.FromEntityID]
 	if !exists {
-		return fmt.Errorf("from entity with logical ID %s not found", relDelta.FromEntityID)
+		return fmt.Errorf("from %w: %s", ErrEntityNotFound, relDelta.FromEntityID)
 	}
 	
 	toDatabaseID, exists := entityIDMapping[relDelta.ToEntityID]
 	if !exists {
-		return fmt.Errorf("to entity with logical ID %s not found", relDelta.ToEntityID)
+		return fmt.Errorf("to %w: %s", ErrEntityNotFound, relDelta.ToEntityID)
+	}
+
+	// Both endpoints must be rows of the target version, or neighbor queries break
+	for logicalID, databaseID := range map[string]string{relDelta.FromEntityID: fromDatabaseID, relDelta.ToEntityID: toDatabaseID} {
+		entity, err := s.db.Queries().GetEntity(ctx, databaseID)
+		if err != nil {
+			return fmt.Errorf("failed to get relationship endpoint %s: %w", logicalID, err)
+		}
+		if rowIsTombstone(entity) {
+			return fmt.Errorf("%w: %s is deleted", ErrEntityNotFound, logicalID)
+		}
+		inVersion, err := s.db.Queries().CountVersionEntity(ctx, db.CountVersionEntityParams{
+			VersionID: versionID,
+			EntityID:  databaseID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to check relationship endpoint %s: %w", logicalID, err)
+		}
+		if inVersion == 0 {
+			return fmt.Errorf("%w: %s belongs to version %s, not %s", ErrCrossVersionRelationship, logicalID, entity.VersionID, versionID)
+		}
 	}
 
 	// Serialize properties as JSON
@@ -572,7 +1217,9 @@ func (s *Service) createRelationship(ctx context.Context, versionID string, relD
 	return nil
 }
 
-// updateRelationship updates an existing relationship
+// updateRelationship updates an existing relationship's properties.
+// When RelationshipID is empty the edge is addressed by its logical
+// (FromEntityID, ToEntityID, RelationshipType) tuple, as in deleteRelationship.
 func (s *Service) updateRelationship(ctx context.Context, versionID string, relDelta *RelationshipDelta, entityIDMapping map[string]string) error {
 	// Serialize properties as JSON
 	var propertiesBytes []byte
@@ -584,8 +1231,28 @@ func (s *Service) updateRelationship(ctx context.Context, versionID string, relD
 		}
 	}
 
+	relationshipID := relDelta.RelationshipID
+	if relationshipID == "" {
+		resolvedID, found, err := s.findRelationshipByLogicalTuple(ctx, versionID, relDelta, entityIDMapping)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%w: %s -%s-> %s", ErrRelationshipNotFound, relDelta.FromEntityID, relDelta.RelationshipType, relDelta.ToEntityID)
+		}
+		relationshipID = resolvedID
+	}
+
+	if s.options.CopyOnWrite {
+		var err error
+		relationshipID, err = s.materializeRelationship(ctx, versionID, relationshipID, entityIDMapping)
+		if err != nil {
+			return err
+		}
+	}
+
 	_, err := s.db.Queries().UpdateRelationship(ctx, db.UpdateRelationshipParams{
-		ID:         relDelta.RelationshipID,
+		ID:         relationshipID,
 		Properties: propertiesBytes,
 	})
 	if err != nil {
@@ -595,15 +1262,75 @@ func (s *Service) updateRelationship(ctx context.Context, versionID string, relD
 	return nil
 }
 
-// deleteRelationship deletes a relationship
+// deleteRelationship deletes a relationship.
+// When RelationshipID is empty the edge is addressed by its logical
+// (FromEntityID, ToEntityID, RelationshipType) tuple, which survives version copies.
+// Deleting an edge that is already absent is a no-op.
 func (s *Service) deleteRelationship(ctx context.Context, versionID string, relDelta *RelationshipDelta, entityIDMapping map[string]string) error {
-	if err := s.db.Queries().DeleteRelationship(ctx, relDelta.RelationshipID); err != nil {
+	relationshipID := relDelta.RelationshipID
+	if relationshipID == "" {
+		resolvedID, found, err := s.findRelationshipByLogicalTuple(ctx, versionID, relDelta, entityIDMapping)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return nil // Edge already absent
+		}
+		relationshipID = resolvedID
+	}
+
+	if s.options.CopyOnWrite {
+		rel, err := s.db.Queries().GetRelationship(ctx, relationshipID)
+		if err != nil {
+			return fmt.Errorf("failed to get relationship: %w", err)
+		}
+		return s.removeRelationshipFromVersion(ctx, versionID, rel)
+	}
+
+	if err := s.db.Queries().DeleteRelationship(ctx, relationshipID); err != nil {
 		return fmt.Errorf("failed to delete relationship: %w", err)
 	}
 
 	return nil
 }
 
+// findRelationshipByLogicalTuple resolves the database ID of the relationship between two
+// logical entities in the version described by entityIDMapping
+func (s *Service) findRelationshipByLogicalTuple(ctx context.Context, versionID string, relDelta *RelationshipDelta, entityIDMapping map[string]string) (string, bool, error) {
+	fromDatabaseID, fromExists := entityIDMapping[relDelta.FromEntityID]
+	toDatabaseID, toExists := entityIDMapping[relDelta.ToEntityID]
+	if !fromExists || !toExists {
+		return "", false, nil
+	}
+
+	relationships, err := s.db.Queries().GetRelationshipsBetweenEntities(ctx, db.GetRelationshipsBetweenEntitiesParams{
+		FromEntityID: fromDatabaseID,
+		ToEntityID:   toDatabaseID,
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to look up relationship: %w", err)
+	}
+
+	for _, rel := range relationships {
+		if rel.RelationshipType != relDelta.RelationshipType {
+			continue
+		}
+		// Shared entity rows can carry relationships of other versions
+		inVersion, err := s.db.Queries().CountVersionRelationship(ctx, db.CountVersionRelationshipParams{
+			VersionID:      versionID,
+			RelationshipID: rel.ID,
+		})
+		if err != nil {
+			return "", false, fmt.Errorf("failed to check relationship membership: %w", err)
+		}
+		if inVersion > 0 {
+			return rel.ID, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
 // nullStringToPtr converts sql.NullString to *string
 func nullStringToPtr(ns sql.NullString) *string {
 	if ns.Valid {
@@ -633,6 +1360,9 @@ func (s *Service) GetNeighborsInVersion(ctx context.Context, versionID string, l
 		}
 		
 		if entityLogicalID == logicalEntityID {
+			if isTombstone(data) {
+				return []*Entity{}, nil // Deleted in this version
+			}
 			targetDatabaseID = entity.ID
 			break
 		}
@@ -643,7 +1373,8 @@ func (s *Service) GetNeighborsInVersion(ctx context.Context, versionID string, l
 	}
 
 	// Get relationships for this entity
-	relationships, err := s.db.Queries().ListRelationshipsByEntity(ctx, db.ListRelationshipsByEntityParams{
+	relationships, err := s.db.Queries().ListRelationshipsByEntityInVersion(ctx, db.ListRelationshipsByEntityInVersionParams{
+		VersionID:    versionID,
 		FromEntityID: targetDatabaseID,
 		ToEntityID:   targetDatabaseID,
 	})
@@ -668,8 +1399,8 @@ func (s *Service) GetNeighborsInVersion(ctx context.Context, versionID string, l
 		for _, entity := range entities {
 			if entity.ID == neighborDatabaseID {
 				var data map[string]any
-				if err := json.Unmarshal(entity.Data, &data); err != nil {
-					continue
+				if err := json.Unmarshal(entity.Data, &data); err != nil || isTombstone(data) {
+					break
 				}
 
 				// Use logical ID if available
@@ -697,6 +1428,12 @@ func (s *Service) GetNeighborsInVersion(ctx context.Context, versionID string, l
 
 // ImportEntity imports an entity from another project, maintaining its identity
 func (s *Service) ImportEntity(ctx context.Context, targetVersionID string, sourceProjectID string, entityLogicalID string) (*Entity, error) {
+	if err := s.checkProjectOwner(ctx, sourceProjectID); err != nil {
+		return nil, err
+	}
+	if err := s.checkVersionOwner(ctx, targetVersionID); err != nil {
+		return nil, err
+	}
 	// Find the entity in the source project (get the latest version)
 	sourceEntity, err := s.findLatestEntityVersion(ctx, sourceProjectID, entityLogicalID)
 	if err != nil {
@@ -770,10 +1507,71 @@ func (s *Service) ImportEntity(ctx context.Context, targetVersionID string, sour
 	}, nil
 }
 
+// ImportWarning describes a likely accidental duplicate found before an import
+type ImportWarning struct {
+	Message          string
+	Name             string
+	EntityType       string
+	ExistingEntityID string // Logical ID of the same-named entity already in the target
+}
+
+// CheckImportDuplicate is an optional pre-import check. It returns a warning when the
+// target version already holds an entity with the same name and type as the entity
+// being imported but a different logical ID. A true re-import of the same logical
+// entity, or no collision at all, returns nil.
+func (s *Service) CheckImportDuplicate(ctx context.Context, targetVersionID string, sourceProjectID string, entityLogicalID string) (*ImportWarning, error) {
+	if err := s.checkProjectOwner(ctx, sourceProjectID); err != nil {
+		return nil, err
+	}
+	if err := s.checkVersionOwner(ctx, targetVersionID); err != nil {
+		return nil, err
+	}
+	sourceEntity, err := s.findLatestEntityVersion(ctx, sourceProjectID, entityLogicalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find entity %s in project %s: %w", entityLogicalID, sourceProjectID, err)
+	}
+
+	targetEntities, err := s.db.Queries().ListEntitiesByType(ctx, db.ListEntitiesByTypeParams{
+		VersionID:  targetVersionID,
+		EntityType: sourceEntity.EntityType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list target entities: %w", err)
+	}
+
+	var warning *ImportWarning
+	for _, entity := range targetEntities {
+		var data map[string]any
+		if err := json.Unmarshal(entity.Data, &data); err != nil {
+			continue
+		}
+
+		logicalID := entity.ID
+		if lid, exists := data["logical_id"].(string); exists {
+			logicalID = lid
+		}
+
+		if logicalID == entityLogicalID {
+			return nil, nil // True re-import
+		}
+
+		if entity.Name == sourceEntity.Name && warning == nil {
+			warning = &ImportWarning{
+				Message:          fmt.Sprintf("%s %q already exists in the target as %s", entity.EntityType, entity.Name, logicalID),
+				Name:             entity.Name,
+				EntityType:       entity.EntityType,
+				ExistingEntityID: logicalID,
+			}
+		}
+	}
+
+	return warning, nil
+}
+
 // GetEntityHistory retrieves the evolution of an entity across all projects
 func (s *Service) GetEntityHistory(ctx context.Context, entityLogicalID string) ([]*EntityVersion, error) {
 	// Get all projects
-	projects, err := s.db.Queries().ListProjects(ctx)
+	projects, err := s.listProjects(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list projects: %w", err)
 	}
@@ -829,14 +1627,36 @@ func (s *Service) GetEntityHistory(ctx context.Context, entityLogicalID string)
 	return history, nil
 }
 
-// ListSharedEntities lists entities that appear in multiple projects
+// VersionsContaining lists every version, across all projects and including
+// non-working-set versions, in which the logical entity appears, oldest first.
+// Versions holding only its tombstone are left out.
+func (s *Service) VersionsContaining(ctx context.Context, logicalID string) ([]*GraphVersion, error) {
+	versions, err := s.db.Queries().ListGraphVersionsContainingEntity(ctx, logicalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions containing %s: %w", logicalID, err)
+	}
+
+	result := make([]*GraphVersion, 0, len(versions))
+	for _, version := range versionsInHistoryOrder(versions) {
+		result = append(result, toGraphVersion(version))
+	}
+
+	return result, nil
+}
+
+// ListSharedEntities lists entities that appear in multiple projects, sorted by name
 func (s *Service) ListSharedEntities(ctx context.Context) ([]*SharedEntity, error) {
 	// Get all projects
-	projects, err := s.db.Queries().ListProjects(ctx)
+	projects, err := s.listProjects(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list projects: %w", err)
 	}
 
+	projectNames := make(map[string]string, len(projects))
+	for _, project := range projects {
+		projectNames[project.ID] = project.Name
+	}
+
 	// Map logical ID to project appearances
 	entityProjects := make(map[string]map[string]bool) // logicalID -> projectID -> true
 	entityInfo := make(map[string]*SharedEntity)
@@ -859,6 +1679,9 @@ func (s *Service) ListSharedEntities(ctx context.Context) ([]*SharedEntity, erro
 			if err := json.Unmarshal(entity.Data, &data); err != nil {
 				continue
 			}
+			if isTombstone(data) {
+				continue
+			}
 			
 			logicalID := entity.ID
 			if lid, exists := data["logical_id"].(string); exists {
@@ -898,23 +1721,50 @@ func (s *Service) ListSharedEntities(ctx context.Context) ([]*SharedEntity, erro
 			entity := entityInfo[logicalID]
 			entity.ProjectCount = len(projectMap)
 			
-			// Get project names
 			for projectID := range projectMap {
-				for _, project := range projects {
-					if project.ID == projectID {
-						entity.Projects = append(entity.Projects, project.Name)
-						break
-					}
-				}
+				entity.ProjectIDs = append(entity.ProjectIDs, projectID)
 			}
-			
+			sort.Strings(entity.ProjectIDs)
+
+			entity.Projects = sharedProjectNames(entity.ProjectIDs, projectNames)
+
 			sharedEntities = append(sharedEntities, entity)
 		}
 	}
 
+	sort.Slice(sharedEntities, func(i, j int) bool {
+		if sharedEntities[i].Name != sharedEntities[j].Name {
+			return sharedEntities[i].Name < sharedEntities[j].Name
+		}
+		return sharedEntities[i].LogicalID < sharedEntities[j].LogicalID
+	})
+
 	return sharedEntities, nil
 }
 
+// sharedProjectNames resolves sorted project IDs to display names, one per project.
+// Projects sharing a name, or without one, are told apart by their ID so each name
+// appears once.
+func sharedProjectNames(projectIDs []string, projectNames map[string]string) []string {
+	counts := make(map[string]int, len(projectIDs))
+	for _, projectID := range projectIDs {
+		counts[projectNames[projectID]]++
+	}
+
+	names := make([]string, 0, len(projectIDs))
+	for _, projectID := range projectIDs {
+		name := projectNames[projectID]
+		switch {
+		case name == "":
+			name = projectID
+		case counts[name] > 1:
+			name = fmt.Sprintf("%s (%s)", name, projectID)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
 // findLatestEntityVersion finds the latest version of an entity in a project
 func (s *Service) findLatestEntityVersion(ctx context.Context, projectID string, entityLogicalID string) (*db.Entity, error) {
 	// Get working set version for the project
@@ -945,5 +1795,38 @@ func (s *Service) findLatestEntityVersion(ctx context.Context, projectID string,
 		}
 	}
 
-	return nil, fmt.Errorf("entity not found")
-}
\ No newline at end of file
+	return nil, fmt.Errorf("%w: %s is not in the working set of project %s", ErrEntityNotFound, entityLogicalID, projectID)
+}
+
+// findEntityInVersion finds the database row for a logical entity in a specific version.
+// Tombstoned entities are treated as absent.
+func (s *Service) findEntityInVersion(ctx context.Context, versionID string, entityLogicalID string) (*db.Entity, error) {
+	entities, err := s.db.Queries().ListEntitiesByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	for _, entity := range entities {
+		var data map[string]any
+		if err := json.Unmarshal(entity.Data, &data); err != nil {
+			continue
+		}
+
+		logicalID := entity.ID
+		if lid, exists := data["logical_id"].(string); exists {
+			logicalID = lid
+		}
+
+		if logicalID == entityLogicalID && !isTombstone(data) {
+			return &entity, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s is not in version %s", ErrEntityNotFound, entityLogicalID, versionID)
+}
+
+// logicalIDsByDatabaseID maps every entity's database ID in a version to its logical ID
+func (s *Service) logicalIDsByDatabaseID(ctx context.Context, versionID string) (map[string]string, error) {
+	logicalIDs, _, err := s.logicalIDsWithTombstones(ctx, versionID)
+	return logicalIDs, err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"os"
 	"testing"
 
@@ -11,7 +12,7 @@ import (
 	"github.com/google/uuid"
 )
 
-func setupTestDB(t *testing.T) *db.Database {
+func setupTestDB(t testing.TB) *db.Database {
 	// Create temporary database file
 	tmpFile, err := os.CreateTemp("", "libretto_test_*.db")
 	if err != nil {
@@ -39,7 +40,7 @@ func setupTestDB(t *testing.T) *db.Database {
 	return database
 }
 
-func createTestProject(t *testing.T, database *db.Database) string {
+func createTestProject(t testing.TB, database *db.Database) string {
 	ctx := context.Background()
 	projectID := uuid.New().String()
 
@@ -57,7 +58,7 @@ func createTestProject(t *testing.T, database *db.Database) string {
 	return projectID
 }
 
-func createTestGraphVersion(t *testing.T, database *db.Database, projectID string, isWorkingSet bool) string {
+func createTestGraphVersion(t testing.TB, database *db.Database, projectID string, isWorkingSet bool) string {
 	ctx := context.Background()
 	versionID := uuid.New().String()
 
@@ -215,6 +216,115 @@ func TestService_Apply_UpdateEntity(t *testing.T) {
 	}
 }
 
+func TestService_Apply_UnsetFields(t *testing.T) {
+	for _, options := range []ServiceOptions{{}, {CopyOnWrite: true}} {
+		database := setupTestDB(t)
+		defer database.Close()
+
+		service := NewServiceWithOptions(database, options)
+		ctx := context.Background()
+
+		projectID := createTestProject(t, database)
+		versionID := createTestGraphVersion(t, database, projectID, true)
+
+		apply := func(delta *Delta) string {
+			t.Helper()
+			response, err := service.Apply(ctx, &ApplyRequest{ParentVersionID: versionID, Deltas: []*Delta{delta}})
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			versionID = response.GraphVersionID
+			return versionID
+		}
+
+		apply(&Delta{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "role": "protagonist"}})
+		// An update merges over the current data rather than replacing it
+		traumatised := apply(&Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"trauma": "lost her brother"}})
+		healed := apply(&Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"role": "mentor"}, UnsetFields: []string{"trauma", "logical_id"}})
+
+		before, err := service.ListEntities(ctx, traumatised, EntityFilter{})
+		if err != nil {
+			t.Fatalf("ListEntities failed: %v", err)
+		}
+		if len(before) != 1 || before[0].Name != "Elena" || before[0].Data["trauma"] != "lost her brother" || before[0].Data["role"] != "protagonist" {
+			t.Fatalf("Expected the trauma merged into elena's fields, got %+v", before)
+		}
+
+		after, err := service.ListEntities(ctx, healed, EntityFilter{})
+		if err != nil {
+			t.Fatalf("ListEntities failed: %v", err)
+		}
+		if len(after) != 1 {
+			t.Fatalf("Expected 1 entity, got %d", len(after))
+		}
+		if _, exists := after[0].Data["trauma"]; exists {
+			t.Errorf("Expected trauma to be unset, got %v", after[0].Data)
+		}
+		if after[0].Name != "Elena" || after[0].Data["role"] != "mentor" || after[0].Data["logical_id"] != "elena" {
+			t.Errorf("Expected the other fields and logical ID to survive, got %+v", after[0])
+		}
+
+		// The earlier version keeps the field
+		if entity, _ := service.(*Service).entityInVersion(ctx, traumatised, "elena"); entity == nil || entity.Data["trauma"] != "lost her brother" {
+			t.Errorf("Expected the parent version to keep the trauma, got %+v", entity)
+		}
+	}
+}
+
+func TestService_Apply_UpdateMergeVersusReplace(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	created, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "level": 1, "age": 27, "skills": []string{"archery", "tracking"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	levelUp := func(replace bool) map[string]any {
+		t.Helper()
+		response, err := service.Apply(ctx, &ApplyRequest{
+			ParentVersionID: created.GraphVersionID,
+			Deltas: []*Delta{
+				{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"level": 2}, Replace: replace},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		entity, err := service.(*Service).entityInVersion(ctx, response.GraphVersionID, "elena")
+		if err != nil || entity == nil {
+			t.Fatalf("Expected elena in the new version, got %v (%v)", entity, err)
+		}
+		return entity.Data
+	}
+
+	merged := levelUp(false)
+	if merged["level"] != 2.0 || merged["age"] != 27.0 || merged["name"] != "Elena" || len(merged["skills"].([]any)) != 2 {
+		t.Errorf("Expected a merge to keep the other fields, got %v", merged)
+	}
+
+	replaced := levelUp(true)
+	if replaced["level"] != 2.0 || replaced["logical_id"] != "elena" {
+		t.Errorf("Expected a replace to keep the level and logical ID, got %v", replaced)
+	}
+	for _, field := range []string{"name", "age", "skills"} {
+		if _, exists := replaced[field]; exists {
+			t.Errorf("Expected a replace to drop %s, got %v", field, replaced)
+		}
+	}
+}
+
 func TestService_Apply_DeleteEntity(t *testing.T) {
 	database := setupTestDB(t)
 	defer database.Close()
@@ -479,8 +589,8 @@ func TestService_Apply_InvalidParentVersion(t *testing.T) {
 	}
 
 	_, err := service.Apply(ctx, req)
-	if err == nil {
-		t.Error("Expected error for non-existent parent version, got nil")
+	if !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Expected ErrVersionNotFound for non-existent parent version, got %v", err)
 	}
 }
 
@@ -502,8 +612,69 @@ func TestService_Apply_EmptyDeltas(t *testing.T) {
 	}
 
 	_, err := service.Apply(ctx, req)
-	if err == nil {
-		t.Error("Expected error for empty deltas, got nil")
+	if !errors.Is(err, ErrNoDeltas) {
+		t.Errorf("Expected ErrNoDeltas for empty deltas, got %v", err)
+	}
+}
+
+func TestService_Apply_ErrorSentinels(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+	tests := []struct {
+		name  string
+		delta *Delta
+		want  error
+	}{
+		{
+			name:  "update of a missing entity",
+			delta: &Delta{Operation: "update", EntityType: "Character", EntityID: "ghost", Fields: map[string]any{"name": "Ghost"}},
+			want:  ErrEntityNotFound,
+		},
+		{
+			name:  "delete of a missing entity",
+			delta: &Delta{Operation: "delete", EntityType: "Character", EntityID: "ghost"},
+			want:  ErrEntityNotFound,
+		},
+		{
+			name: "relationship to a missing entity",
+			delta: &Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{}, Relationships: []*RelationshipDelta{
+				{Operation: "create", FromEntityID: "elena", ToEntityID: "ghost", RelationshipType: "allies_with", Properties: map[string]any{}},
+			}},
+			want: ErrEntityNotFound,
+		},
+		{
+			name:  "unknown operation",
+			delta: &Delta{Operation: "rename", EntityType: "Character", EntityID: "elena"},
+			want:  ErrUnknownOperation,
+		},
+		{
+			name: "unknown relationship operation",
+			delta: &Delta{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{}, Relationships: []*RelationshipDelta{
+				{Operation: "rename", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "allies_with"},
+			}},
+			want: ErrUnknownOperation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Apply(ctx, &ApplyRequest{ParentVersionID: baseVersionID, Deltas: []*Delta{tt.delta}})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := service.GetVersion(ctx, "missing-version"); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Expected ErrVersionNotFound from GetVersion, got %v", err)
 	}
 }
 
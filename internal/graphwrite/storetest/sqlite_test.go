@@ -0,0 +1,32 @@
+package storetest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	Run(t, func(t *testing.T) graphwrite.Store {
+		tmpFile, err := os.CreateTemp("", "libretto_storetest_*.db")
+		if err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+		tmpFile.Close()
+		t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+		database, err := db.NewDatabase(tmpFile.Name())
+		if err != nil {
+			t.Fatalf("Failed to create database: %v", err)
+		}
+		t.Cleanup(func() { database.Close() })
+
+		if err := database.Migrate(context.Background()); err != nil {
+			t.Fatalf("Failed to migrate database: %v", err)
+		}
+		return graphwrite.NewSQLiteStore(database)
+	})
+}
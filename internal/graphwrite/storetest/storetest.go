@@ -0,0 +1,255 @@
+// Package storetest is a conformance suite for graphwrite.Store implementations.
+// A backend passes when Run succeeds against fresh, empty stores.
+package storetest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	"github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+// Run runs the conformance suite, calling newStore for an empty, ready-to-use store
+// in each subtest
+func Run(t *testing.T, newStore func(t *testing.T) graphwrite.Store) {
+	t.Run("Projects", func(t *testing.T) { testProjects(t, newStore(t)) })
+	t.Run("WorkingSet", func(t *testing.T) { testWorkingSet(t, newStore(t)) })
+	t.Run("Entities", func(t *testing.T) { testEntities(t, newStore(t)) })
+	t.Run("Relationships", func(t *testing.T) { testRelationships(t, newStore(t)) })
+	t.Run("SharedMembership", func(t *testing.T) { testSharedMembership(t, newStore(t)) })
+	t.Run("Service", func(t *testing.T) { testService(t, newStore(t), graphwrite.ServiceOptions{}) })
+	t.Run("ServiceCopyOnWrite", func(t *testing.T) {
+		testService(t, newStore(t), graphwrite.ServiceOptions{CopyOnWrite: true})
+	})
+}
+
+// createVersion creates a project with a working-set version for the lower-level tests
+func createVersion(t *testing.T, store graphwrite.Store, projectID, versionID string) {
+	ctx := context.Background()
+	if _, err := store.Queries().CreateProject(ctx, db.CreateProjectParams{ID: projectID, Name: projectID}); err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+	if _, err := store.Queries().CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: versionID, ProjectID: projectID, IsWorkingSet: true}); err != nil {
+		t.Fatalf("CreateGraphVersion failed: %v", err)
+	}
+}
+
+func createEntity(t *testing.T, store graphwrite.Store, versionID, id, name string) {
+	if _, err := store.Queries().CreateEntity(context.Background(), db.CreateEntityParams{
+		ID:         id,
+		VersionID:  versionID,
+		EntityType: "Character",
+		Name:       name,
+		Data:       json.RawMessage(`{"name": "` + name + `"}`),
+	}); err != nil {
+		t.Fatalf("CreateEntity failed: %v", err)
+	}
+}
+
+func testProjects(t *testing.T, store graphwrite.Store) {
+	ctx := context.Background()
+	if _, err := store.Queries().CreateProject(ctx, db.CreateProjectParams{ID: "saga", Name: "Saga"}); err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+
+	project, err := store.Queries().GetProject(ctx, "saga")
+	if err != nil {
+		t.Fatalf("GetProject failed: %v", err)
+	}
+	if project.Name != "Saga" {
+		t.Errorf("Expected project Saga, got %+v", project)
+	}
+
+	if _, err := store.Queries().GetProject(ctx, "missing"); err == nil {
+		t.Error("Expected an error for a missing project")
+	}
+
+	projects, err := store.Queries().ListProjects(ctx)
+	if err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+	if len(projects) != 1 {
+		t.Errorf("Expected 1 project, got %d", len(projects))
+	}
+}
+
+func testWorkingSet(t *testing.T, store graphwrite.Store) {
+	ctx := context.Background()
+	createVersion(t, store, "saga", "v1")
+	if _, err := store.Queries().CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: "v2", ProjectID: "saga"}); err != nil {
+		t.Fatalf("CreateGraphVersion failed: %v", err)
+	}
+
+	if err := store.Queries().SetWorkingSet(ctx, db.SetWorkingSetParams{ID: "v2", ProjectID: "saga"}); err != nil {
+		t.Fatalf("SetWorkingSet failed: %v", err)
+	}
+
+	workingSet, err := store.Queries().GetWorkingSetVersion(ctx, "saga")
+	if err != nil {
+		t.Fatalf("GetWorkingSetVersion failed: %v", err)
+	}
+	if workingSet.ID != "v2" {
+		t.Errorf("Expected working set v2, got %s", workingSet.ID)
+	}
+
+	previous, err := store.Queries().GetGraphVersion(ctx, "v1")
+	if err != nil {
+		t.Fatalf("GetGraphVersion failed: %v", err)
+	}
+	if previous.IsWorkingSet {
+		t.Error("Expected v1 to stop being the working set")
+	}
+}
+
+func testEntities(t *testing.T, store graphwrite.Store) {
+	ctx := context.Background()
+	createVersion(t, store, "saga", "v1")
+	createEntity(t, store, "v1", "e1", "Elena")
+	createEntity(t, store, "v1", "e2", "Marcus")
+
+	if _, err := store.Queries().UpdateEntity(ctx, db.UpdateEntityParams{ID: "e1", Name: "Elena the Bold", Data: json.RawMessage(`{"name": "Elena the Bold"}`)}); err != nil {
+		t.Fatalf("UpdateEntity failed: %v", err)
+	}
+	entity, err := store.Queries().GetEntity(ctx, "e1")
+	if err != nil {
+		t.Fatalf("GetEntity failed: %v", err)
+	}
+	if entity.Name != "Elena the Bold" || entity.VersionID != "v1" {
+		t.Errorf("Expected the updated entity in v1, got %+v", entity)
+	}
+
+	if err := store.Queries().DeleteEntity(ctx, "e2"); err != nil {
+		t.Fatalf("DeleteEntity failed: %v", err)
+	}
+	entities, err := store.Queries().ListEntitiesByVersion(ctx, "v1")
+	if err != nil {
+		t.Fatalf("ListEntitiesByVersion failed: %v", err)
+	}
+	if len(entities) != 1 || entities[0].ID != "e1" {
+		t.Errorf("Expected only e1 to remain, got %+v", entities)
+	}
+}
+
+func testRelationships(t *testing.T, store graphwrite.Store) {
+	ctx := context.Background()
+	createVersion(t, store, "saga", "v1")
+	createEntity(t, store, "v1", "e1", "Elena")
+	createEntity(t, store, "v1", "e2", "Marcus")
+
+	if _, err := store.Queries().CreateRelationship(ctx, db.CreateRelationshipParams{
+		ID:               "r1",
+		VersionID:        "v1",
+		FromEntityID:     "e1",
+		ToEntityID:       "e2",
+		RelationshipType: "allies_with",
+		Properties:       json.RawMessage(`{"bond_strength": "growing"}`),
+	}); err != nil {
+		t.Fatalf("CreateRelationship failed: %v", err)
+	}
+
+	relationships, err := store.Queries().ListRelationshipsByVersion(ctx, "v1")
+	if err != nil {
+		t.Fatalf("ListRelationshipsByVersion failed: %v", err)
+	}
+	if len(relationships) != 1 || relationships[0].FromEntityID != "e1" || relationships[0].ToEntityID != "e2" {
+		t.Fatalf("Expected the allies_with edge, got %+v", relationships)
+	}
+
+	if err := store.Queries().DeleteRelationshipsByEntity(ctx, db.DeleteRelationshipsByEntityParams{FromEntityID: "e2", ToEntityID: "e2"}); err != nil {
+		t.Fatalf("DeleteRelationshipsByEntity failed: %v", err)
+	}
+	relationships, err = store.Queries().ListRelationshipsByVersion(ctx, "v1")
+	if err != nil {
+		t.Fatalf("ListRelationshipsByVersion failed: %v", err)
+	}
+	if len(relationships) != 0 {
+		t.Errorf("Expected the edge touching e2 to be deleted, got %+v", relationships)
+	}
+}
+
+func testSharedMembership(t *testing.T, store graphwrite.Store) {
+	ctx := context.Background()
+	createVersion(t, store, "saga", "v1")
+	createEntity(t, store, "v1", "e1", "Elena")
+	if _, err := store.Queries().CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: "v2", ProjectID: "saga"}); err != nil {
+		t.Fatalf("CreateGraphVersion failed: %v", err)
+	}
+
+	if err := store.Queries().ShareVersionEntities(ctx, db.ShareVersionEntitiesParams{VersionID: "v2", ParentVersionID: "v1"}); err != nil {
+		t.Fatalf("ShareVersionEntities failed: %v", err)
+	}
+	shared, err := store.Queries().ListEntitiesByVersion(ctx, "v2")
+	if err != nil {
+		t.Fatalf("ListEntitiesByVersion failed: %v", err)
+	}
+	if len(shared) != 1 || shared[0].ID != "e1" || shared[0].VersionID != "v2" {
+		t.Fatalf("Expected v2 to list the shared row as its own, got %+v", shared)
+	}
+
+	if err := store.Queries().RemoveVersionEntity(ctx, db.RemoveVersionEntityParams{VersionID: "v2", EntityID: "e1"}); err != nil {
+		t.Fatalf("RemoveVersionEntity failed: %v", err)
+	}
+	for versionID, want := range map[string]int64{"v1": 1, "v2": 0} {
+		count, err := store.Queries().CountVersionEntity(ctx, db.CountVersionEntityParams{VersionID: versionID, EntityID: "e1"})
+		if err != nil {
+			t.Fatalf("CountVersionEntity failed: %v", err)
+		}
+		if count != want {
+			t.Errorf("Expected %d memberships of e1 in %s, got %d", want, versionID, count)
+		}
+	}
+}
+
+// testService runs the graph service end to end on the store
+func testService(t *testing.T, store graphwrite.Store, options graphwrite.ServiceOptions) {
+	ctx := context.Background()
+	service := graphwrite.NewServiceWithStore(store, options)
+	createVersion(t, store, "saga", "root")
+
+	created, err := service.ApplyAndAdvance(ctx, &graphwrite.ApplyRequest{
+		ParentVersionID: "root",
+		Deltas: []*graphwrite.Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}, Relationships: []*graphwrite.RelationshipDelta{
+				{Operation: "create", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "allies_with", Properties: map[string]any{"bond_strength": "growing"}},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	updated, err := service.ApplyAndAdvance(ctx, &graphwrite.ApplyRequest{
+		ParentVersionID: created.GraphVersionID,
+		Deltas: []*graphwrite.Delta{
+			{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena the Bold"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	for versionID, want := range map[string]string{created.GraphVersionID: "Elena", updated.GraphVersionID: "Elena the Bold"} {
+		entities, err := service.ListEntities(ctx, versionID, graphwrite.EntityFilter{})
+		if err != nil {
+			t.Fatalf("ListEntities failed: %v", err)
+		}
+		names := make(map[string]string, len(entities))
+		for _, entity := range entities {
+			names[entity.ID] = entity.Name
+		}
+		if len(names) != 2 || names["elena"] != want || names["marcus"] != "Marcus" {
+			t.Errorf("Expected elena to be %q in version %s, got %v", want, versionID, names)
+		}
+
+		neighbors, err := service.GetNeighborsInVersion(ctx, versionID, "elena", "allies_with")
+		if err != nil {
+			t.Fatalf("GetNeighborsInVersion failed: %v", err)
+		}
+		if len(neighbors) != 1 || neighbors[0].ID != "marcus" {
+			t.Errorf("Expected elena to ally with marcus in version %s, got %+v", versionID, neighbors)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+)
+
+// RelationshipsAmong returns the relationships in a version whose source and target
+// are both among the given logical entity IDs, for rendering the sub-graph around a
+// selection. IDs not present in the version are ignored.
+func (s *Service) RelationshipsAmong(ctx context.Context, versionID string, logicalIDs []string) ([]*Relationship, error) {
+	selected := make(map[string]bool, len(logicalIDs))
+	for _, id := range logicalIDs {
+		selected[id] = true
+	}
+	if len(selected) == 0 {
+		return []*Relationship{}, nil
+	}
+
+	entityIDMapping, err := s.logicalIDsByDatabaseID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	relationships, err := s.db.Queries().ListRelationshipsByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	result := []*Relationship{}
+	for _, rel := range relationships {
+		from, to := entityIDMapping[rel.FromEntityID], entityIDMapping[rel.ToEntityID]
+		if !selected[from] || !selected[to] {
+			continue
+		}
+
+		converted, err := toRelationship(rel, entityIDMapping)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, converted)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+)
+
+func TestService_RelationshipsAmong(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}},
+			{
+				Operation:  "create",
+				EntityType: "Location",
+				EntityID:   "harbor",
+				Fields:     map[string]any{"name": "Harbor"},
+				Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "allies_with", Properties: map[string]any{"bond_strength": "strong"}},
+					{Operation: "create", FromEntityID: "elena", ToEntityID: "harbor", RelationshipType: "located_at", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "marcus", ToEntityID: "harbor", RelationshipType: "located_at", Properties: map[string]any{}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	relationships, err := service.RelationshipsAmong(ctx, response.GraphVersionID, []string{"elena", "marcus", "missing"})
+	if err != nil {
+		t.Fatalf("RelationshipsAmong failed: %v", err)
+	}
+	if len(relationships) != 1 {
+		t.Fatalf("Expected only the intra-set edge, got %d relationships", len(relationships))
+	}
+
+	rel := relationships[0]
+	if rel.FromEntityID != "elena" || rel.ToEntityID != "marcus" || rel.RelationshipType != "allies_with" {
+		t.Errorf("Expected elena -allies_with-> marcus, got %+v", rel)
+	}
+	if rel.Properties["bond_strength"] != "strong" {
+		t.Errorf("Expected properties to be decoded, got %v", rel.Properties)
+	}
+	if rel.VersionID != response.GraphVersionID {
+		t.Errorf("Expected version %s, got %s", response.GraphVersionID, rel.VersionID)
+	}
+
+	all, err := service.RelationshipsAmong(ctx, response.GraphVersionID, []string{"elena", "marcus", "harbor"})
+	if err != nil {
+		t.Fatalf("RelationshipsAmong failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected all 3 edges when every endpoint is selected, got %d", len(all))
+	}
+
+	none, err := service.RelationshipsAmong(ctx, response.GraphVersionID, nil)
+	if err != nil {
+		t.Fatalf("RelationshipsAmong failed: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("Expected no edges for an empty selection, got %d", len(none))
+	}
+}
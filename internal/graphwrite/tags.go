@@ -0,0 +1,170 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// ChangeType classifies how an entity differs between two versions
+type ChangeType string
+
+const (
+	ChangeAdded     ChangeType = "added"
+	ChangeRemoved   ChangeType = "removed"
+	ChangeModified  ChangeType = "modified"
+	ChangeUnchanged ChangeType = "unchanged"
+)
+
+// EntityChange describes how one entity changed between two versions
+type EntityChange struct {
+	LogicalID     string
+	FromVersionID string
+	ToVersionID   string
+	Change        ChangeType
+
+	// Before and After are the entity in each version; nil where it is absent
+	Before *Entity
+	After  *Entity
+
+	// Fields lists the data fields that differ, sorted by name
+	Fields []FieldChange
+}
+
+// FieldChange records a data field's value before and after; nil means absent
+type FieldChange struct {
+	Field  string
+	Before any
+	After  any
+
+	// VersionID and ChangedAt locate the change in a version chain; only
+	// FieldHistory sets them
+	VersionID string
+	ChangedAt string
+}
+
+// TagVersion names a version so it can be compared against later. Tags are unique
+// within a project and are not moved once set.
+func (s *Service) TagVersion(ctx context.Context, versionID string, tag string) error {
+	version, err := s.db.Queries().GetGraphVersion(ctx, versionID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrVersionNotFound, err)
+	}
+
+	if _, err := s.db.Queries().GetVersionTag(ctx, db.GetVersionTagParams{ProjectID: version.ProjectID, Tag: tag}); err == nil {
+		return fmt.Errorf("%w: %s", ErrTagExists, tag)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to look up tag: %w", err)
+	}
+
+	if _, err := s.db.Queries().CreateVersionTag(ctx, db.CreateVersionTagParams{
+		ProjectID: version.ProjectID,
+		Tag:       tag,
+		VersionID: versionID,
+	}); err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+	return nil
+}
+
+// CompareToTag reports how an entity changed between the tagged version and the
+// project's current working set
+func (s *Service) CompareToTag(ctx context.Context, projectID, logicalID, tag string) (*EntityChange, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	tagged, err := s.db.Queries().GetVersionTag(ctx, db.GetVersionTagParams{ProjectID: projectID, Tag: tag})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrTagNotFound, tag)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up tag: %w", err)
+	}
+
+	workingSet, err := s.db.Queries().GetWorkingSetVersion(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working set: %w", err)
+	}
+
+	before, err := s.entityInVersion(ctx, tagged.VersionID, logicalID)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.entityInVersion(ctx, workingSet.ID, logicalID)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil && after == nil {
+		return nil, fmt.Errorf("entity %s not found at tag %s or in the working set", logicalID, tag)
+	}
+
+	change := &EntityChange{
+		LogicalID:     logicalID,
+		FromVersionID: tagged.VersionID,
+		ToVersionID:   workingSet.ID,
+		Before:        before,
+		After:         after,
+	}
+
+	var beforeData, afterData map[string]any
+	if before != nil {
+		beforeData = before.Data
+	}
+	if after != nil {
+		afterData = after.Data
+	}
+	change.Fields = diffFields(beforeData, afterData)
+
+	switch {
+	case before == nil:
+		change.Change = ChangeAdded
+	case after == nil:
+		change.Change = ChangeRemoved
+	case len(change.Fields) > 0:
+		change.Change = ChangeModified
+	default:
+		change.Change = ChangeUnchanged
+	}
+
+	return change, nil
+}
+
+// entityInVersion returns the entity with the logical ID in a version, or nil if absent
+func (s *Service) entityInVersion(ctx context.Context, versionID, logicalID string) (*Entity, error) {
+	entities, err := s.ListEntities(ctx, versionID, EntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range entities {
+		if entity.ID == logicalID {
+			return entity, nil
+		}
+	}
+	return nil, nil
+}
+
+// diffFields lists the data fields whose values differ, ignoring logical_id
+func diffFields(before, after map[string]any) []FieldChange {
+	names := make(map[string]bool)
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+	delete(names, "logical_id")
+
+	changes := []FieldChange{}
+	for name := range names {
+		if !reflect.DeepEqual(before[name], after[name]) {
+			changes = append(changes, FieldChange{Field: name, Before: before[name], After: after[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
@@ -0,0 +1,70 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestService_CompareToTag(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	draft, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "role": "apprentice", "level": 1}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+	if err := service.TagVersion(ctx, draft.GraphVersionID, "editor-draft"); err != nil {
+		t.Fatalf("TagVersion failed: %v", err)
+	}
+	if err := service.TagVersion(ctx, rootVersionID, "editor-draft"); !errors.Is(err, ErrTagExists) {
+		t.Errorf("Expected ErrTagExists for a reused tag, got %v", err)
+	}
+
+	if _, err := service.ApplyAndAdvance(ctx, &ApplyRequest{
+		ParentVersionID: draft.GraphVersionID,
+		Deltas: []*Delta{
+			{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "role": "mage", "level": 1}},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}},
+		},
+	}); err != nil {
+		t.Fatalf("ApplyAndAdvance failed: %v", err)
+	}
+
+	change, err := service.CompareToTag(ctx, projectID, "elena", "editor-draft")
+	if err != nil {
+		t.Fatalf("CompareToTag failed: %v", err)
+	}
+	if change.Change != ChangeModified || change.FromVersionID != draft.GraphVersionID {
+		t.Errorf("Expected a modification since the draft, got %+v", change)
+	}
+	if len(change.Fields) != 1 {
+		t.Fatalf("Expected only role to change, got %+v", change.Fields)
+	}
+	if field := change.Fields[0]; field.Field != "role" || field.Before != "apprentice" || field.After != "mage" {
+		t.Errorf("Expected role apprentice -> mage, got %+v", field)
+	}
+
+	added, err := service.CompareToTag(ctx, projectID, "marcus", "editor-draft")
+	if err != nil {
+		t.Fatalf("CompareToTag failed: %v", err)
+	}
+	if added.Change != ChangeAdded || added.Before != nil || added.After == nil {
+		t.Errorf("Expected marcus to be reported as added, got %+v", added)
+	}
+
+	if _, err := service.CompareToTag(ctx, projectID, "elena", "final"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("Expected ErrTagNotFound, got %v", err)
+	}
+}
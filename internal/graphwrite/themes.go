@@ -0,0 +1,120 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/barrynorthern/libretto/internal/types"
+)
+
+const (
+	// themePropagationDecay scales a theme's strength across each relationship
+	themePropagationDecay = 0.5
+
+	// themePropagationThreshold is the weakest strength still counted as an
+	// association; with unweighted edges it reaches two hops from the theme
+	themePropagationThreshold = 0.25
+)
+
+// themeRelationshipTypes are the relationships a theme propagates across
+var themeRelationshipTypes = map[string]bool{
+	string(types.RelationshipExplores): true,
+	string(types.RelationshipFeatures): true,
+}
+
+// PropagateThemes infers which scenes and characters each theme reaches by following
+// explores and features relationships in either direction from the theme entities.
+// A theme's strength starts at 1 and is multiplied across each edge by the decay and
+// the edge's numeric "weight" property (default 1, clamped to [0, 1]); entities reached
+// with strength at or above the threshold are associated. The result maps every theme
+// to the sorted logical IDs of its scenes and characters.
+func (s *Service) PropagateThemes(ctx context.Context, versionID string) (map[string][]string, error) {
+	entities, err := s.ListEntities(ctx, versionID, EntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	logicalIDs, err := s.logicalIDsByDatabaseID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := s.db.Queries().ListRelationshipsByVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	type edge struct {
+		to     string
+		weight float64
+	}
+	adjacency := make(map[string][]edge)
+	for _, rel := range relationships {
+		if !themeRelationshipTypes[rel.RelationshipType] {
+			continue
+		}
+		converted, err := toRelationship(rel, logicalIDs)
+		if err != nil {
+			return nil, err
+		}
+		weight := relationshipWeight(converted.Properties)
+		adjacency[converted.FromEntityID] = append(adjacency[converted.FromEntityID], edge{converted.ToEntityID, weight})
+		adjacency[converted.ToEntityID] = append(adjacency[converted.ToEntityID], edge{converted.FromEntityID, weight})
+	}
+
+	entityTypes := make(map[string]string, len(entities))
+	for _, entity := range entities {
+		entityTypes[entity.ID] = entity.EntityType
+	}
+
+	result := make(map[string][]string)
+	for _, theme := range entities {
+		if theme.EntityType != string(types.EntityTypeTheme) {
+			continue
+		}
+
+		// Relax strengths until stable; products of weights at most 1 only shrink,
+		// so each entity settles on its strongest path
+		strength := map[string]float64{theme.ID: 1}
+		frontier := []string{theme.ID}
+		for len(frontier) > 0 {
+			current := frontier[0]
+			frontier = frontier[1:]
+			for _, next := range adjacency[current] {
+				candidate := strength[current] * themePropagationDecay * next.weight
+				if candidate < themePropagationThreshold || candidate <= strength[next.to] {
+					continue
+				}
+				strength[next.to] = candidate
+				frontier = append(frontier, next.to)
+			}
+		}
+
+		associated := []string{}
+		for id := range strength {
+			switch entityTypes[id] {
+			case string(types.EntityTypeScene), string(types.EntityTypeCharacter):
+				associated = append(associated, id)
+			}
+		}
+		sort.Strings(associated)
+		result[theme.ID] = associated
+	}
+
+	return result, nil
+}
+
+// relationshipWeight reads a relationship's numeric weight property, defaulting to 1
+func relationshipWeight(properties map[string]any) float64 {
+	weight, ok := properties["weight"].(float64)
+	if !ok {
+		return 1
+	}
+	if weight < 0 {
+		return 0
+	}
+	if weight > 1 {
+		return 1
+	}
+	return weight
+}
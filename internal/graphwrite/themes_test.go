@@ -0,0 +1,71 @@
+package graphwrite
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestService_PropagateThemes(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	// loyalty <-explores- vigil -features-> elena, marcus; betrayal <-explores- parley (weak) -features-> mordak
+	response, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Theme", EntityID: "loyalty", Fields: map[string]any{"name": "Loyalty"}},
+			{Operation: "create", EntityType: "Theme", EntityID: "betrayal", Fields: map[string]any{"name": "Betrayal"}},
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+			{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}},
+			{Operation: "create", EntityType: "Character", EntityID: "mordak", Fields: map[string]any{"name": "Mordak"}},
+			{
+				Operation:  "create",
+				EntityType: "Scene",
+				EntityID:   "vigil",
+				Fields:     map[string]any{"name": "The Vigil"},
+				Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "vigil", ToEntityID: "loyalty", RelationshipType: "explores", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "vigil", ToEntityID: "elena", RelationshipType: "features", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "vigil", ToEntityID: "marcus", RelationshipType: "features", Properties: map[string]any{}},
+				},
+			},
+			{
+				Operation:  "create",
+				EntityType: "Scene",
+				EntityID:   "parley",
+				Fields:     map[string]any{"name": "The Parley"},
+				Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "parley", ToEntityID: "betrayal", RelationshipType: "explores", Properties: map[string]any{"weight": 0.6}},
+					{Operation: "create", FromEntityID: "parley", ToEntityID: "mordak", RelationshipType: "features", Properties: map[string]any{}},
+					// Not a theme-carrying relationship
+					{Operation: "create", FromEntityID: "mordak", ToEntityID: "elena", RelationshipType: "conflicts", Properties: map[string]any{}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	themes, err := service.PropagateThemes(ctx, response.GraphVersionID)
+	if err != nil {
+		t.Fatalf("PropagateThemes failed: %v", err)
+	}
+
+	expected := map[string][]string{
+		// Reaches the scene and, through it, the characters it features
+		"loyalty": {"elena", "marcus", "vigil"},
+		// The weak explores edge reaches the scene but fades before its character
+		"betrayal": {"parley"},
+	}
+	if !reflect.DeepEqual(themes, expected) {
+		t.Errorf("Expected %v, got %v", expected, themes)
+	}
+}
@@ -0,0 +1,117 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Thread is a story thread: the entities reached by following chosen relationship
+// types outward from a start entity, as a tree rooted at the start
+type Thread struct {
+	Root *ThreadNode
+
+	// Truncated reports that maxDepth stopped the trace while edges led further
+	Truncated bool
+}
+
+// ThreadNode is one entity on a thread with the entities it leads to
+type ThreadNode struct {
+	Entity *Entity
+
+	// RelationshipType is the type of the edge that led here, empty for the root
+	RelationshipType string
+
+	// Next holds the following entities, more than one where the thread branches,
+	// ordered by their sequence field and then logical ID
+	Next []*ThreadNode
+}
+
+// Path returns the thread's entities depth first from the root, which for a thread
+// without branches is the thread in order
+func (t *Thread) Path() []*Entity {
+	var path []*Entity
+	var visit func(node *ThreadNode)
+	visit = func(node *ThreadNode) {
+		path = append(path, node.Entity)
+		for _, next := range node.Next {
+			visit(next)
+		}
+	}
+	visit(t.Root)
+	return path
+}
+
+// TraceThread follows outgoing relationships of the given types from a start entity,
+// breadth first, and returns the entities reached as a tree. An entity reachable by
+// several routes appears once, under its nearest predecessor, so cycles end the
+// thread. Empty relTypes follows every type; maxDepth caps the number of edges from
+// the start, with zero or less meaning no cap.
+func (s *Service) TraceThread(ctx context.Context, versionID, startLogicalID string, relTypes []string, maxDepth int) (*Thread, error) {
+	entities, err := s.ListEntities(ctx, versionID, EntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Entity, len(entities))
+	for _, entity := range entities {
+		byID[entity.ID] = entity
+	}
+	start := byID[startLogicalID]
+	if start == nil {
+		return nil, fmt.Errorf("%w: %s in version %s", ErrEntityNotFound, startLogicalID, versionID)
+	}
+
+	followed := make(map[string]bool, len(relTypes))
+	for _, relType := range relTypes {
+		followed[relType] = true
+	}
+
+	relationships, err := s.relationshipsByLogicalKey(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	outgoing := make(map[string][]relationshipKey)
+	for _, key := range sortedRelationshipKeys(relationships) {
+		if len(followed) == 0 || followed[key.Type] {
+			outgoing[key.From] = append(outgoing[key.From], key)
+		}
+	}
+
+	thread := &Thread{Root: &ThreadNode{Entity: start}}
+	visited := map[string]bool{startLogicalID: true}
+	frontier := []*ThreadNode{thread.Root}
+	for depth := 0; len(frontier) > 0; depth++ {
+		var next []*ThreadNode
+		for _, node := range frontier {
+			for _, key := range outgoing[node.Entity.ID] {
+				target := byID[key.To]
+				if target == nil || visited[key.To] {
+					continue
+				}
+				if maxDepth > 0 && depth >= maxDepth {
+					thread.Truncated = true
+					break
+				}
+				visited[key.To] = true
+				child := &ThreadNode{Entity: target, RelationshipType: key.Type}
+				node.Next = append(node.Next, child)
+				next = append(next, child)
+			}
+			sortThreadNodes(node.Next)
+		}
+		frontier = next
+	}
+
+	return thread, nil
+}
+
+// sortThreadNodes orders a node's branches by sequence, then logical ID
+func sortThreadNodes(nodes []*ThreadNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		seqI, seqJ := arcSequence(nodes[i].Entity.Data["sequence"]), arcSequence(nodes[j].Entity.Data["sequence"])
+		if seqI != seqJ {
+			return seqI < seqJ
+		}
+		return nodes[i].Entity.ID < nodes[j].Entity.ID
+	})
+}
@@ -0,0 +1,134 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// createPlotChainVersion creates plot points with the given sequences and the given
+// edges between them, each as from, type and to
+func createPlotChainVersion(t *testing.T, service GraphWriteService, parentVersionID string, sequences map[string]int, edges [][3]string) string {
+	var deltas []*Delta
+	for _, id := range []string{"inciting", "journey", "betrayal", "siege", "escape", "climax"} {
+		sequence, ok := sequences[id]
+		if !ok {
+			continue
+		}
+		deltas = append(deltas, &Delta{Operation: "create", EntityType: "PlotPoint", EntityID: id, Fields: map[string]any{"name": id, "sequence": sequence}})
+	}
+	relationships := make([]*RelationshipDelta, 0, len(edges))
+	for _, edge := range edges {
+		relationships = append(relationships, &RelationshipDelta{Operation: "create", FromEntityID: edge[0], RelationshipType: edge[1], ToEntityID: edge[2], Properties: map[string]any{}})
+	}
+	deltas[len(deltas)-1].Relationships = relationships
+
+	response, err := service.Apply(context.Background(), &ApplyRequest{ParentVersionID: parentVersionID, Deltas: deltas})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	return response.GraphVersionID
+}
+
+func threadIDs(thread *Thread) []string {
+	var ids []string
+	for _, entity := range thread.Path() {
+		ids = append(ids, entity.ID)
+	}
+	return ids
+}
+
+func TestService_TraceThread_Linear(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	versionID := createPlotChainVersion(t, service, rootVersionID,
+		map[string]int{"inciting": 1, "journey": 2, "betrayal": 3, "climax": 4},
+		[][3]string{
+			{"inciting", "precedes", "journey"},
+			{"journey", "precedes", "betrayal"},
+			{"betrayal", "precedes", "climax"},
+			{"climax", "precedes", "inciting"}, // a cycle ends the thread
+			{"inciting", "influences", "climax"},
+		})
+
+	thread, err := service.TraceThread(ctx, versionID, "inciting", []string{"precedes"}, 0)
+	if err != nil {
+		t.Fatalf("TraceThread failed: %v", err)
+	}
+	want := []string{"inciting", "journey", "betrayal", "climax"}
+	if got := threadIDs(thread); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] || got[3] != want[3] {
+		t.Fatalf("Expected the thread %v, got %v", want, got)
+	}
+	if thread.Truncated || thread.Root.RelationshipType != "" || thread.Root.Next[0].RelationshipType != "precedes" {
+		t.Errorf("Unexpected thread shape: %+v", thread)
+	}
+
+	short, err := service.TraceThread(ctx, versionID, "inciting", []string{"precedes"}, 2)
+	if err != nil {
+		t.Fatalf("TraceThread failed: %v", err)
+	}
+	if got := threadIDs(short); len(got) != 3 || !short.Truncated {
+		t.Errorf("Expected 3 entities and a truncated thread, got %v (truncated %v)", got, short.Truncated)
+	}
+
+	// Every type: the influences edge reaches the climax directly
+	everyType, err := service.TraceThread(ctx, versionID, "inciting", nil, 1)
+	if err != nil {
+		t.Fatalf("TraceThread failed: %v", err)
+	}
+	if got := threadIDs(everyType); len(got) != 3 || got[1] != "journey" || got[2] != "climax" {
+		t.Errorf("Expected journey and climax one step out, got %v", got)
+	}
+
+	if _, err := service.TraceThread(ctx, versionID, "missing", nil, 0); !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("Expected ErrEntityNotFound, got %v", err)
+	}
+}
+
+func TestService_TraceThread_Branching(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	// The journey splits into the siege and the escape, which rejoin at the climax
+	versionID := createPlotChainVersion(t, service, rootVersionID,
+		map[string]int{"inciting": 1, "journey": 2, "siege": 4, "escape": 3, "climax": 5},
+		[][3]string{
+			{"inciting", "precedes", "journey"},
+			{"journey", "precedes", "siege"},
+			{"journey", "precedes", "escape"},
+			{"siege", "precedes", "climax"},
+			{"escape", "precedes", "climax"},
+		})
+
+	thread, err := service.TraceThread(ctx, versionID, "inciting", []string{"precedes"}, 0)
+	if err != nil {
+		t.Fatalf("TraceThread failed: %v", err)
+	}
+
+	journey := thread.Root.Next[0]
+	if journey.Entity.ID != "journey" || len(journey.Next) != 2 {
+		t.Fatalf("Expected the journey to branch in two, got %+v", journey)
+	}
+	escape, siege := journey.Next[0], journey.Next[1]
+	if escape.Entity.ID != "escape" || siege.Entity.ID != "siege" {
+		t.Fatalf("Expected branches ordered by sequence, got %s and %s", escape.Entity.ID, siege.Entity.ID)
+	}
+	if len(escape.Next) != 1 || escape.Next[0].Entity.ID != "climax" || len(siege.Next) != 0 {
+		t.Errorf("Expected the climax once, under the escape, got %d and %d", len(escape.Next), len(siege.Next))
+	}
+	if got := threadIDs(thread); len(got) != 5 {
+		t.Errorf("Expected 5 entities on the thread, got %v", got)
+	}
+}
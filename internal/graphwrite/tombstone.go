@@ -0,0 +1,96 @@
+package graphwrite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// deletedField marks a tombstoned entity in its data, alongside logical_id
+const deletedField = "deleted"
+
+// isTombstone reports whether entity data marks the entity as deleted
+func isTombstone(data map[string]any) bool {
+	deleted, _ := data[deletedField].(bool)
+	return deleted
+}
+
+// rowIsTombstone reports whether an entity row is a tombstone
+func rowIsTombstone(entity db.Entity) bool {
+	var data map[string]any
+	if err := json.Unmarshal(entity.Data, &data); err != nil {
+		return false
+	}
+	return isTombstone(data)
+}
+
+// tombstoneEntity marks an entity deleted in a version instead of removing it, keeping
+// its data and relationships so the version still records what was deleted. Readers
+// hide tombstones and the edges touching them unless asked to include deleted entities.
+func (s *Service) tombstoneEntity(ctx context.Context, versionID string, logicalID string, databaseID string, entityIDMapping map[string]string) error {
+	current, err := s.db.Queries().GetEntity(ctx, databaseID)
+	if err != nil {
+		return fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(current.Data, &data); err != nil {
+		return fmt.Errorf("failed to unmarshal entity data: %w", err)
+	}
+	if isTombstone(data) {
+		return fmt.Errorf("%w: %s is already deleted", ErrEntityNotFound, logicalID)
+	}
+	data[deletedField] = true
+
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entity data: %w", err)
+	}
+
+	if s.options.CopyOnWrite {
+		databaseID, err = s.materializeEntity(ctx, versionID, logicalID, databaseID, entityIDMapping)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.db.Queries().UpdateEntity(ctx, db.UpdateEntityParams{
+		ID:   databaseID,
+		Name: current.Name,
+		Data: dataBytes,
+	}); err != nil {
+		return fmt.Errorf("failed to tombstone entity: %w", err)
+	}
+
+	return nil
+}
+
+// logicalIDsWithTombstones maps every entity's database ID in a version to its logical
+// ID, as logicalIDsByDatabaseID does, and also returns the database IDs of tombstones
+func (s *Service) logicalIDsWithTombstones(ctx context.Context, versionID string) (map[string]string, map[string]bool, error) {
+	entities, err := s.db.Queries().ListEntitiesByVersion(ctx, versionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	logicalIDs := make(map[string]string, len(entities))
+	tombstones := make(map[string]bool)
+	for _, entity := range entities {
+		logicalIDs[entity.ID] = entity.ID
+
+		var data map[string]any
+		if err := json.Unmarshal(entity.Data, &data); err != nil {
+			continue
+		}
+		if lid, exists := data["logical_id"].(string); exists {
+			logicalIDs[entity.ID] = lid
+		}
+		if isTombstone(data) {
+			tombstones[entity.ID] = true
+		}
+	}
+
+	return logicalIDs, tombstones, nil
+}
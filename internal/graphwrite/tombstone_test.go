@@ -0,0 +1,192 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+func TestService_SoftDelete(t *testing.T) {
+	for _, mode := range []struct {
+		name    string
+		options ServiceOptions
+	}{
+		{name: "copy", options: ServiceOptions{SoftDelete: true}},
+		{name: "copy-on-write", options: ServiceOptions{SoftDelete: true, CopyOnWrite: true}},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			database := setupTestDB(t)
+			defer database.Close()
+
+			service := NewServiceWithOptions(database, mode.options)
+			ctx := context.Background()
+
+			projectID := createTestProject(t, database)
+			rootVersionID := createTestGraphVersion(t, database, projectID, true)
+			baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+			deleted, err := service.Apply(ctx, &ApplyRequest{
+				ParentVersionID: baseVersionID,
+				Deltas:          []*Delta{{Operation: "delete", EntityType: "Character", EntityID: "marcus"}},
+			})
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			versionID := deleted.GraphVersionID
+
+			// Hidden by default
+			if names := entityNames(t, service, versionID); len(names) != 1 || names["elena"] != "Elena" {
+				t.Errorf("Expected only elena to be listed, got %v", names)
+			}
+			relationships, err := service.ListRelationships(ctx, versionID, ListOptions{})
+			if err != nil {
+				t.Fatalf("ListRelationships failed: %v", err)
+			}
+			if relationships.Total != 0 {
+				t.Errorf("Expected the edge to the tombstone to be hidden, got %+v", relationships.Items)
+			}
+			neighbors, err := service.GetNeighborsInVersion(ctx, versionID, "elena", "allies_with")
+			if err != nil {
+				t.Fatalf("GetNeighborsInVersion failed: %v", err)
+			}
+			if len(neighbors) != 0 {
+				t.Errorf("Expected elena to have no neighbors, got %+v", neighbors)
+			}
+			fetched, err := service.GetEntities(ctx, versionID, []string{"marcus"})
+			if err != nil {
+				t.Fatalf("GetEntities failed: %v", err)
+			}
+			if fetched[0] != nil {
+				t.Errorf("Expected a nil slot for the tombstone, got %+v", fetched[0])
+			}
+
+			// Revealed on request, with the edge still stored
+			all, err := service.ListEntities(ctx, versionID, EntityFilter{IncludeDeleted: true})
+			if err != nil {
+				t.Fatalf("ListEntities failed: %v", err)
+			}
+			tombstoned := map[string]bool{}
+			for _, entity := range all {
+				tombstoned[entity.ID] = entity.Data["deleted"] == true
+			}
+			if len(tombstoned) != 2 || !tombstoned["marcus"] || tombstoned["elena"] {
+				t.Errorf("Expected marcus to be listed as a tombstone beside elena, got %v", tombstoned)
+			}
+			stored, err := database.Queries().ListRelationshipsByVersion(ctx, versionID)
+			if err != nil {
+				t.Fatalf("ListRelationshipsByVersion failed: %v", err)
+			}
+			if len(stored) != 1 {
+				t.Errorf("Expected the tombstone to keep its edge, got %d relationships", len(stored))
+			}
+
+			// The parent is untouched
+			if names := entityNames(t, service, baseVersionID); len(names) != 2 {
+				t.Errorf("Expected the parent to keep both entities, got %v", names)
+			}
+
+			for _, operation := range []string{"update", "delete"} {
+				_, err := service.Apply(ctx, &ApplyRequest{
+					ParentVersionID: versionID,
+					Deltas:          []*Delta{{Operation: operation, EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus"}}},
+				})
+				if !errors.Is(err, ErrEntityNotFound) {
+					t.Errorf("Expected ErrEntityNotFound for %s of a tombstone, got %v", operation, err)
+				}
+			}
+
+			// Creating the logical ID again replaces the tombstone
+			revived, err := service.Apply(ctx, &ApplyRequest{
+				ParentVersionID: versionID,
+				Deltas:          []*Delta{{Operation: "create", EntityType: "Character", EntityID: "marcus", Fields: map[string]any{"name": "Marcus Reborn"}}},
+			})
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			all, err = service.ListEntities(ctx, revived.GraphVersionID, EntityFilter{IncludeDeleted: true})
+			if err != nil {
+				t.Fatalf("ListEntities failed: %v", err)
+			}
+			if len(all) != 2 {
+				t.Errorf("Expected the tombstone to be replaced, got %d entities", len(all))
+			}
+			if names := entityNames(t, service, revived.GraphVersionID); names["marcus"] != "Marcus Reborn" {
+				t.Errorf("Expected marcus to be live again, got %v", names)
+			}
+		})
+	}
+}
+
+func TestService_SoftDelete_TombstonesAreNotLive(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewServiceWithOptions(database, ServiceOptions{SoftDelete: true})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+	if err := database.Queries().SetWorkingSet(ctx, db.SetWorkingSetParams{ID: baseVersionID, ProjectID: projectID}); err != nil {
+		t.Fatalf("SetWorkingSet failed: %v", err)
+	}
+	createTestAnnotation(t, database, databaseIDForEntity(t, database, baseVersionID, "elena"), "emotional_analysis", nil)
+	createTestAnnotation(t, database, databaseIDForEntity(t, database, baseVersionID, "marcus"), "emotional_analysis", nil)
+
+	deleted, err := service.ApplyToProject(ctx, projectID, []*Delta{{Operation: "delete", EntityType: "Character", EntityID: "marcus"}})
+	if err != nil {
+		t.Fatalf("ApplyToProject failed: %v", err)
+	}
+
+	// The version holding only the tombstone no longer contains the entity
+	versions, err := service.VersionsContaining(ctx, "marcus")
+	if err != nil {
+		t.Fatalf("VersionsContaining failed: %v", err)
+	}
+	for _, version := range versions {
+		if version.ID == deleted.GraphVersionID {
+			t.Error("Expected the version that deleted marcus not to be listed as containing him")
+		}
+	}
+	if len(versions) == 0 || versions[len(versions)-1].ID != baseVersionID {
+		t.Errorf("Expected the base version to be the last containing marcus, got %+v", versions)
+	}
+
+	// A new edge cannot point at the tombstone
+	_, err = service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: deleted.GraphVersionID,
+		Deltas: []*Delta{{
+			Operation:  "update",
+			EntityType: "Character",
+			EntityID:   "elena",
+			Fields:     map[string]any{"name": "Elena"},
+			Relationships: []*RelationshipDelta{
+				{Operation: "create", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "mentors", Properties: map[string]any{}},
+			},
+		}},
+	})
+	if !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("Expected ErrEntityNotFound for an edge to a deleted entity, got %v", err)
+	}
+
+	overview, err := service.GetProjectOverview(ctx, projectID)
+	if err != nil {
+		t.Fatalf("GetProjectOverview failed: %v", err)
+	}
+	if overview.EntityCount != 1 || overview.EntityCounts["Character"] != 1 || overview.RelationshipCount != 0 {
+		t.Errorf("Expected one live entity and no edges in the overview, got %d entities %v and %d edges", overview.EntityCount, overview.EntityCounts, overview.RelationshipCount)
+	}
+	if overview.AnnotationCount != 1 {
+		t.Errorf("Expected only the live entity's annotation in the overview, got %d", overview.AnnotationCount)
+	}
+
+	stats, err := service.ListProjectsWithStats(ctx)
+	if err != nil {
+		t.Fatalf("ListProjectsWithStats failed: %v", err)
+	}
+	if len(stats) != 1 || stats[0].EntityCount != 1 || stats[0].RelationshipCount != 0 || stats[0].AnnotationCount != 1 {
+		t.Errorf("Expected one live entity, its annotation and no edges in the project list, got %+v", stats)
+	}
+}
@@ -0,0 +1,82 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// RelationshipTrends returns, per relationship type, the number of relationships of
+// that type in each version of the project, oldest version first. Every series has
+// one entry per version, with zero where the type is absent.
+func (s *Service) RelationshipTrends(ctx context.Context, projectID string) (map[string][]int, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	versions, err := s.db.Queries().ListGraphVersionsByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	versions = versionsInHistoryOrder(versions)
+	trends := make(map[string][]int)
+
+	for i, version := range versions {
+		relationships, err := s.db.Queries().ListRelationshipsByVersion(ctx, version.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list relationships for version %s: %w", version.ID, err)
+		}
+
+		for _, rel := range relationships {
+			series, exists := trends[rel.RelationshipType]
+			if !exists {
+				series = make([]int, len(versions))
+				trends[rel.RelationshipType] = series
+			}
+			series[i]++
+		}
+	}
+
+	return trends, nil
+}
+
+// versionsInHistoryOrder orders versions oldest first, guaranteeing that a parent
+// always precedes its children even when creation timestamps tie
+func versionsInHistoryOrder(versions []db.GraphVersion) []db.GraphVersion {
+	pending := make([]db.GraphVersion, len(versions))
+	copy(pending, versions)
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+
+	inSet := make(map[string]bool, len(pending))
+	for _, version := range pending {
+		inSet[version.ID] = true
+	}
+
+	ordered := make([]db.GraphVersion, 0, len(pending))
+	emitted := make(map[string]bool, len(pending))
+	for len(pending) > 0 {
+		remaining := pending[:0:0]
+		for _, version := range pending {
+			parentID := version.ParentVersionID.String
+			if !version.ParentVersionID.Valid || !inSet[parentID] || emitted[parentID] {
+				ordered = append(ordered, version)
+				emitted[version.ID] = true
+			} else {
+				remaining = append(remaining, version)
+			}
+		}
+
+		// A parent cycle cannot be ordered; keep the timestamp order for the rest
+		if len(remaining) == len(pending) {
+			ordered = append(ordered, remaining...)
+			break
+		}
+		pending = remaining
+	}
+
+	return ordered
+}
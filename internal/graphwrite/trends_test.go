@@ -0,0 +1,86 @@
+package graphwrite
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestService_RelationshipTrends(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	firstDraftID := createFeaturedSceneVersion(t, service, rootVersionID)
+
+	secondDraft, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: firstDraftID,
+		Deltas: []*Delta{
+			{
+				Operation:  "create",
+				EntityType: "Character",
+				EntityID:   "marcus",
+				Fields:     map[string]any{"name": "Marcus"},
+				Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "scene-1", ToEntityID: "marcus", RelationshipType: "features", Properties: map[string]any{}},
+					{Operation: "create", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "conflicts", Properties: map[string]any{}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	_, err = service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: secondDraft.GraphVersionID,
+		Deltas: []*Delta{
+			{
+				Operation:  "update",
+				EntityType: "Scene",
+				EntityID:   "scene-1",
+				Fields:     map[string]any{"name": "Opening", "title": "Opening"},
+				Relationships: []*RelationshipDelta{
+					{Operation: "delete", FromEntityID: "scene-1", ToEntityID: "elena", RelationshipType: "features"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	trends, err := service.RelationshipTrends(ctx, projectID)
+	if err != nil {
+		t.Fatalf("RelationshipTrends failed: %v", err)
+	}
+
+	if want := []int{0, 1, 2, 1}; !reflect.DeepEqual(trends["features"], want) {
+		t.Errorf("Expected features trend %v, got %v", want, trends["features"])
+	}
+	if want := []int{0, 0, 1, 1}; !reflect.DeepEqual(trends["conflicts"], want) {
+		t.Errorf("Expected conflicts trend %v, got %v", want, trends["conflicts"])
+	}
+	if len(trends) != 2 {
+		t.Errorf("Expected 2 relationship types, got %v", trends)
+	}
+}
+
+func TestService_RelationshipTrends_EmptyProject(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	projectID := createTestProject(t, database)
+
+	trends, err := service.RelationshipTrends(context.Background(), projectID)
+	if err != nil {
+		t.Fatalf("RelationshipTrends failed: %v", err)
+	}
+	if len(trends) != 0 {
+		t.Errorf("Expected no trends, got %v", trends)
+	}
+}
@@ -0,0 +1,95 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+)
+
+// ValidationIssue is one problem Validate found in an Apply batch
+type ValidationIssue struct {
+	DeltaIndex int // index of the offending delta in ApplyRequest.Deltas
+	Message    string
+}
+
+// ValidationReport collects every problem Validate found in an Apply batch
+type ValidationReport struct {
+	Issues []ValidationIssue
+}
+
+// Valid reports whether the batch had no problems
+func (r *ValidationReport) Valid() bool {
+	return len(r.Issues) == 0
+}
+
+func (r *ValidationReport) add(deltaIndex int, format string, args ...any) {
+	r.Issues = append(r.Issues, ValidationIssue{DeltaIndex: deltaIndex, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks an Apply batch against its parent version without writing anything.
+// It reports unknown operations, updates and deletes of entities missing from the
+// parent, relationship deltas whose endpoints do not exist and entity IDs created more
+// than once, walking the batch in order so later deltas see earlier creates and
+// deletes. Every problem is collected rather than stopping at the first; the error is
+// only for a missing parent version or a failed lookup.
+func (s *Service) Validate(ctx context.Context, req *ApplyRequest) (*ValidationReport, error) {
+	if _, err := s.db.Queries().GetGraphVersion(ctx, req.ParentVersionID); err != nil {
+		return nil, fmt.Errorf("parent %w: %w", ErrVersionNotFound, err)
+	}
+
+	logicalIDs, err := s.logicalIDsByDatabaseID(ctx, req.ParentVersionID)
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool, len(logicalIDs))
+	for _, logicalID := range logicalIDs {
+		present[logicalID] = true
+	}
+
+	report := &ValidationReport{}
+	created := make(map[string]int)
+	for i, delta := range req.Deltas {
+		switch delta.Operation {
+		case "create":
+			if delta.EntityID != "" {
+				if first, exists := created[delta.EntityID]; exists {
+					report.add(i, "entity %s is already created by delta %d", delta.EntityID, first)
+				} else {
+					created[delta.EntityID] = i
+				}
+				present[delta.EntityID] = true
+			}
+		case "update", "delete":
+			if !present[delta.EntityID] {
+				report.add(i, "%s targets entity %s, which is not in the parent version", delta.Operation, delta.EntityID)
+			}
+		default:
+			report.add(i, "unknown operation: %q", delta.Operation)
+			continue
+		}
+
+		for _, relDelta := range delta.Relationships {
+			switch relDelta.Operation {
+			case "create":
+				for _, endpoint := range []string{relDelta.FromEntityID, relDelta.ToEntityID} {
+					if !present[endpoint] {
+						report.add(i, "%s relationship endpoint %q does not exist", relDelta.RelationshipType, endpoint)
+					}
+				}
+			case "update", "delete":
+				for _, endpoint := range []string{relDelta.FromEntityID, relDelta.ToEntityID} {
+					if endpoint != "" && !present[endpoint] {
+						report.add(i, "%s relationship endpoint %q does not exist", relDelta.RelationshipType, endpoint)
+					}
+				}
+			default:
+				report.add(i, "unknown relationship operation: %q", relDelta.Operation)
+			}
+		}
+
+		if delta.Operation == "delete" {
+			delete(present, delta.EntityID)
+		}
+	}
+
+	return report, nil
+}
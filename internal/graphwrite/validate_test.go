@@ -0,0 +1,132 @@
+package graphwrite
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestService_Validate(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+	baseVersionID := createAlliesVersion(t, service, rootVersionID, "growing")
+
+	tests := []struct {
+		name    string
+		deltas  []*Delta
+		issues  []int
+		message string
+	}{
+		{
+			name: "clean batch",
+			deltas: []*Delta{
+				{Operation: "create", EntityType: "Character", EntityID: "aria", Fields: map[string]any{"name": "Aria"}, Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "aria", ToEntityID: "elena", RelationshipType: "allies_with", Properties: map[string]any{}},
+				}},
+				{Operation: "update", EntityType: "Character", EntityID: "aria", Fields: map[string]any{"name": "Aria the Bold"}},
+				{Operation: "delete", EntityType: "Character", EntityID: "marcus"},
+			},
+		},
+		{
+			name:    "unknown operation",
+			deltas:  []*Delta{{Operation: "upsert", EntityType: "Character", EntityID: "elena"}},
+			issues:  []int{0},
+			message: "unknown operation",
+		},
+		{
+			name: "update of a missing entity",
+			deltas: []*Delta{
+				{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+				{Operation: "update", EntityType: "Character", EntityID: "ghost", Fields: map[string]any{"name": "Ghost"}},
+			},
+			issues:  []int{1},
+			message: "not in the parent version",
+		},
+		{
+			name: "delete of an entity deleted earlier in the batch",
+			deltas: []*Delta{
+				{Operation: "delete", EntityType: "Character", EntityID: "marcus"},
+				{Operation: "delete", EntityType: "Character", EntityID: "marcus"},
+			},
+			issues:  []int{1},
+			message: "not in the parent version",
+		},
+		{
+			name: "relationship to a missing endpoint",
+			deltas: []*Delta{{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}, Relationships: []*RelationshipDelta{
+				{Operation: "create", FromEntityID: "elena", ToEntityID: "ghost", RelationshipType: "allies_with"},
+			}}},
+			issues:  []int{0},
+			message: `endpoint "ghost" does not exist`,
+		},
+		{
+			name: "duplicate create IDs",
+			deltas: []*Delta{
+				{Operation: "create", EntityType: "Character", EntityID: "aria", Fields: map[string]any{"name": "Aria"}},
+				{Operation: "create", EntityType: "Character", EntityID: "aria", Fields: map[string]any{"name": "Aria Again"}},
+			},
+			issues:  []int{1},
+			message: "already created by delta 0",
+		},
+		{
+			name: "every problem is collected",
+			deltas: []*Delta{
+				{Operation: "upsert", EntityType: "Character", EntityID: "elena"},
+				{Operation: "delete", EntityType: "Character", EntityID: "ghost"},
+				{Operation: "create", EntityType: "Character", EntityID: "aria", Relationships: []*RelationshipDelta{
+					{Operation: "create", FromEntityID: "aria", ToEntityID: "phantom", RelationshipType: "knows"},
+				}},
+			},
+			issues: []int{0, 1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := service.Validate(ctx, &ApplyRequest{ParentVersionID: baseVersionID, Deltas: tt.deltas})
+			if err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+
+			if len(report.Issues) != len(tt.issues) {
+				t.Fatalf("Expected %d issues, got %+v", len(tt.issues), report.Issues)
+			}
+			for i, deltaIndex := range tt.issues {
+				if report.Issues[i].DeltaIndex != deltaIndex {
+					t.Errorf("Expected issue %d at delta %d, got %+v", i, deltaIndex, report.Issues[i])
+				}
+			}
+			if tt.message != "" && !strings.Contains(report.Issues[0].Message, tt.message) {
+				t.Errorf("Expected message containing %q, got %q", tt.message, report.Issues[0].Message)
+			}
+			if report.Valid() != (len(tt.issues) == 0) {
+				t.Errorf("Expected Valid() to be %v", len(tt.issues) == 0)
+			}
+		})
+	}
+
+	// Nothing was written
+	versions, err := database.Queries().ListGraphVersionsByProject(ctx, projectID)
+	if err != nil {
+		t.Fatalf("ListGraphVersionsByProject failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Errorf("Expected Validate to create no versions, got %d", len(versions))
+	}
+}
+
+func TestService_ValidateUnknownParent(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	if _, err := service.Validate(context.Background(), &ApplyRequest{ParentVersionID: "missing"}); err == nil {
+		t.Error("Expected an error for a missing parent version")
+	}
+}
@@ -0,0 +1,50 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// DefaultMaxChainDepth is how many versions a walk up the parent links visits before
+// giving up when ServiceOptions.MaxChainDepth is zero
+const DefaultMaxChainDepth = 10000
+
+// maxChainDepth returns the configured limit on version chain walks
+func (s *Service) maxChainDepth() int {
+	if s.options.MaxChainDepth > 0 {
+		return s.options.MaxChainDepth
+	}
+	return DefaultMaxChainDepth
+}
+
+// walkVersionChain visits a version and then its ancestors, newest first, until visit
+// returns false or the root is reached. It fails with ErrChainTooDeep once the walk
+// passes the configured depth and with ErrVersionCycle when a parent link leads back
+// to a version already visited.
+func (s *Service) walkVersionChain(ctx context.Context, versionID string, visit func(version db.GraphVersion) (bool, error)) error {
+	limit := s.maxChainDepth()
+	visited := make(map[string]bool)
+	for versionID != "" {
+		if visited[versionID] {
+			return fmt.Errorf("%w: %s is its own ancestor", ErrVersionCycle, versionID)
+		}
+		if len(visited) == limit {
+			return fmt.Errorf("%w: more than %d versions above %s", ErrChainTooDeep, limit, versionID)
+		}
+		visited[versionID] = true
+
+		version, err := s.db.Queries().GetGraphVersion(ctx, versionID)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrVersionNotFound, err)
+		}
+		more, err := visit(version)
+		if err != nil || !more {
+			return err
+		}
+		versionID = version.ParentVersionID.String
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// createVersionChain creates a project with a chain of length versions, each the
+// parent of the next, and makes the last one the working set
+func createVersionChain(t *testing.T, database *db.Database, length int) (string, []string) {
+	ctx := context.Background()
+	projectID := createTestProject(t, database)
+
+	versionIDs := make([]string, length)
+	for i := range versionIDs {
+		versionIDs[i] = fmt.Sprintf("%s-v%d", projectID, i)
+		params := db.CreateGraphVersionParams{ID: versionIDs[i], ProjectID: projectID, IsWorkingSet: i == length-1}
+		if i > 0 {
+			params.ParentVersionID = sql.NullString{String: versionIDs[i-1], Valid: true}
+		}
+		if _, err := database.Queries().CreateGraphVersion(ctx, params); err != nil {
+			t.Fatalf("CreateGraphVersion failed: %v", err)
+		}
+	}
+
+	return projectID, versionIDs
+}
+
+func TestService_VersionChain_DepthLimit(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	ctx := context.Background()
+	projectID, versionIDs := createVersionChain(t, database, 30)
+	tip := versionIDs[len(versionIDs)-1]
+
+	shallow := NewServiceWithOptions(database, ServiceOptions{MaxChainDepth: 10})
+	if _, err := shallow.GetLayout(ctx, tip); !errors.Is(err, ErrChainTooDeep) {
+		t.Errorf("Expected GetLayout to hit the depth limit, got %v", err)
+	}
+	if _, err := shallow.FieldHistory(ctx, projectID, "elena", "name"); !errors.Is(err, ErrChainTooDeep) {
+		t.Errorf("Expected FieldHistory to hit the depth limit, got %v", err)
+	}
+
+	// A chain exactly as long as the limit is still walked
+	exact := NewServiceWithOptions(database, ServiceOptions{MaxChainDepth: 30})
+	if _, err := exact.GetLayout(ctx, tip); err != nil {
+		t.Errorf("Expected a 30-version chain to fit a limit of 30, got %v", err)
+	}
+
+	// The default limit comfortably fits the chain
+	if layout, err := NewService(database).GetLayout(ctx, tip); err != nil || len(layout) != 0 {
+		t.Errorf("Expected an empty layout under the default limit, got %v (%v)", layout, err)
+	}
+
+	// A layout partway up the chain stops the walk before the limit
+	if err := shallow.SaveLayout(ctx, versionIDs[25], map[string]NodePosition{"elena": {X: 1, Y: 2}}); err != nil {
+		t.Fatalf("SaveLayout failed: %v", err)
+	}
+	if layout, err := shallow.GetLayout(ctx, tip); err != nil || layout["elena"] != (NodePosition{X: 1, Y: 2}) {
+		t.Errorf("Expected the inherited layout within the limit, got %v (%v)", layout, err)
+	}
+}
+
+func TestService_VersionChain_CycleDetected(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+	projectID, versionIDs := createVersionChain(t, database, 3)
+
+	// Corrupt the root so it points back at the tip
+	if _, err := database.DB().Exec("UPDATE graph_versions SET parent_version_id = ? WHERE id = ?", versionIDs[2], versionIDs[0]); err != nil {
+		t.Fatalf("Failed to corrupt the parent link: %v", err)
+	}
+
+	if _, err := service.GetLayout(ctx, versionIDs[2]); !errors.Is(err, ErrVersionCycle) {
+		t.Errorf("Expected GetLayout to detect the cycle, got %v", err)
+	}
+	if _, err := service.FieldHistory(ctx, projectID, "elena", "name"); !errors.Is(err, ErrVersionCycle) {
+		t.Errorf("Expected FieldHistory to detect the cycle, got %v", err)
+	}
+	if _, err := service.RestoreEntity(ctx, versionIDs[2], "elena"); !errors.Is(err, ErrVersionCycle) {
+		t.Errorf("Expected RestoreEntity to detect the cycle, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// VersionRange returns the versions from fromVersionID down to its descendant
+// toVersionID, both included, oldest first. It fails with ErrNotAncestor when
+// fromVersionID is not on toVersionID's chain of parents.
+func (s *Service) VersionRange(ctx context.Context, fromVersionID, toVersionID string) ([]*GraphVersion, error) {
+	if _, err := s.db.Queries().GetGraphVersion(ctx, fromVersionID); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrVersionNotFound, fromVersionID, err)
+	}
+
+	var chain []*GraphVersion
+	found := false
+	err := s.walkVersionChain(ctx, toVersionID, func(version db.GraphVersion) (bool, error) {
+		chain = append(chain, toGraphVersion(version))
+		found = version.ID == fromVersionID
+		return !found, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk version chain: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %s of %s", ErrNotAncestor, fromVersionID, toVersionID)
+	}
+
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
@@ -0,0 +1,58 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+func TestService_VersionRange(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID, versionIDs := createVersionChain(t, database, 5)
+
+	versions, err := service.VersionRange(ctx, versionIDs[1], versionIDs[3])
+	if err != nil {
+		t.Fatalf("VersionRange failed: %v", err)
+	}
+	var ids []string
+	for _, version := range versions {
+		ids = append(ids, version.ID)
+	}
+	if len(ids) != 3 || ids[0] != versionIDs[1] || ids[1] != versionIDs[2] || ids[2] != versionIDs[3] {
+		t.Errorf("Expected versions 1 to 3 oldest first, got %v", ids)
+	}
+
+	single, err := service.VersionRange(ctx, versionIDs[2], versionIDs[2])
+	if err != nil || len(single) != 1 || single[0].ID != versionIDs[2] {
+		t.Errorf("Expected a one-version range, got %v (%v)", single, err)
+	}
+
+	// A branch off version 1 is not on the same line as version 4
+	if _, err := database.Queries().CreateGraphVersion(ctx, db.CreateGraphVersionParams{
+		ID:              "branch",
+		ProjectID:       projectID,
+		ParentVersionID: sql.NullString{String: versionIDs[1], Valid: true},
+	}); err != nil {
+		t.Fatalf("CreateGraphVersion failed: %v", err)
+	}
+	if _, err := service.VersionRange(ctx, "branch", versionIDs[4]); !errors.Is(err, ErrNotAncestor) {
+		t.Errorf("Expected ErrNotAncestor for a branch, got %v", err)
+	}
+
+	// The range runs from ancestor to descendant, not the other way round
+	if _, err := service.VersionRange(ctx, versionIDs[3], versionIDs[1]); !errors.Is(err, ErrNotAncestor) {
+		t.Errorf("Expected ErrNotAncestor for a reversed range, got %v", err)
+	}
+
+	if _, err := service.VersionRange(ctx, "missing", versionIDs[4]); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Expected ErrVersionNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package graphwrite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// VersionTree is a project's version history as branches rather than a flat list
+type VersionTree struct {
+	ProjectID string
+
+	// Roots are the versions without a parent in the project, oldest first. There is
+	// normally one; versions whose parent is missing are treated as roots too.
+	Roots []*VersionNode
+
+	// WorkingSetVersionID is nil when the project has no working set yet
+	WorkingSetVersionID *string
+}
+
+// VersionNode is one version in a VersionTree with its child versions, oldest first.
+// Version.IsWorkingSet marks the project's working set.
+type VersionNode struct {
+	Version  *GraphVersion
+	Children []*VersionNode
+}
+
+// GetVersionTree returns a project's versions nested under their parents
+func (s *Service) GetVersionTree(ctx context.Context, projectID string) (*VersionTree, error) {
+	if err := s.checkProjectOwner(ctx, projectID); err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Queries().GetProject(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	versions, err := s.db.Queries().ListGraphVersionsByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	nodes := make(map[string]*VersionNode, len(versions))
+	for _, version := range versions {
+		nodes[version.ID] = &VersionNode{Version: toGraphVersion(version)}
+	}
+
+	tree := &VersionTree{ProjectID: projectID}
+	for _, version := range versions {
+		node := nodes[version.ID]
+		if version.IsWorkingSet {
+			tree.WorkingSetVersionID = &node.Version.ID
+		}
+
+		var parent *VersionNode
+		if version.ParentVersionID.Valid {
+			parent = nodes[version.ParentVersionID.String]
+		}
+		if parent != nil {
+			parent.Children = append(parent.Children, node)
+		} else {
+			tree.Roots = append(tree.Roots, node)
+		}
+	}
+
+	sortVersionNodes(tree.Roots)
+	for _, node := range nodes {
+		sortVersionNodes(node.Children)
+	}
+
+	return tree, nil
+}
+
+// sortVersionNodes orders sibling versions oldest first, by ID within the same second
+func sortVersionNodes(nodes []*VersionNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Version.CreatedAt != nodes[j].Version.CreatedAt {
+			return nodes[i].Version.CreatedAt < nodes[j].Version.CreatedAt
+		}
+		return nodes[i].Version.ID < nodes[j].Version.ID
+	})
+}
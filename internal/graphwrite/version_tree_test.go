@@ -0,0 +1,88 @@
+package graphwrite
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+func TestService_GetVersionTree(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+
+	// A→B, A→C, B→D with D the working set, plus an unrelated import root E
+	for _, version := range []struct {
+		id, parent   string
+		isWorkingSet bool
+	}{
+		{id: "a"},
+		{id: "b", parent: "a"},
+		{id: "c", parent: "a"},
+		{id: "d", parent: "b", isWorkingSet: true},
+		{id: "e"},
+	} {
+		if _, err := database.Queries().CreateGraphVersion(ctx, db.CreateGraphVersionParams{
+			ID:              version.id,
+			ProjectID:       projectID,
+			ParentVersionID: sql.NullString{String: version.parent, Valid: version.parent != ""},
+			IsWorkingSet:    version.isWorkingSet,
+		}); err != nil {
+			t.Fatalf("CreateGraphVersion failed: %v", err)
+		}
+	}
+
+	// Another project's versions stay out of the tree
+	createTestGraphVersion(t, database, createTestProject(t, database), true)
+
+	tree, err := service.GetVersionTree(ctx, projectID)
+	if err != nil {
+		t.Fatalf("GetVersionTree failed: %v", err)
+	}
+
+	childIDs := func(node *VersionNode) []string {
+		ids := make([]string, len(node.Children))
+		for i, child := range node.Children {
+			ids[i] = child.Version.ID
+		}
+		return ids
+	}
+
+	if len(tree.Roots) != 2 || tree.Roots[0].Version.ID != "a" || tree.Roots[1].Version.ID != "e" {
+		t.Fatalf("Expected roots a and e, got %+v", tree.Roots)
+	}
+	a := tree.Roots[0]
+	if ids := childIDs(a); len(ids) != 2 || ids[0] != "b" || ids[1] != "c" {
+		t.Fatalf("Expected a to branch into b and c, got %v", ids)
+	}
+	b, c := a.Children[0], a.Children[1]
+	if ids := childIDs(b); len(ids) != 1 || ids[0] != "d" {
+		t.Fatalf("Expected b to lead to d, got %v", ids)
+	}
+	if len(c.Children) != 0 || len(tree.Roots[1].Children) != 0 {
+		t.Errorf("Expected c and e to be leaves, got %v and %v", childIDs(c), childIDs(tree.Roots[1]))
+	}
+
+	d := b.Children[0]
+	if !d.Version.IsWorkingSet || len(d.Children) != 0 {
+		t.Errorf("Expected d to be the working set leaf, got %+v", d)
+	}
+	for _, node := range []*VersionNode{a, b, c, tree.Roots[1]} {
+		if node.Version.IsWorkingSet {
+			t.Errorf("Expected only d to be the working set, %s is flagged too", node.Version.ID)
+		}
+	}
+	if tree.WorkingSetVersionID == nil || *tree.WorkingSetVersionID != "d" {
+		t.Errorf("Expected working set d, got %v", tree.WorkingSetVersionID)
+	}
+
+	if _, err := service.GetVersionTree(ctx, "missing"); err == nil {
+		t.Error("Expected an error for a missing project")
+	}
+}
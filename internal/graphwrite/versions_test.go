@@ -0,0 +1,93 @@
+package graphwrite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+func TestService_VersionsContaining(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	created, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "level": 1}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	edited, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: created.GraphVersionID,
+		Deltas: []*Delta{
+			{Operation: "update", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena", "level": 2}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if _, err := service.Apply(ctx, &ApplyRequest{
+		ParentVersionID: edited.GraphVersionID,
+		Deltas: []*Delta{
+			{Operation: "delete", EntityType: "Character", EntityID: "elena"},
+		},
+	}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	// Elena also appears in a sequel project's working set
+	if err := database.Queries().SetWorkingSet(ctx, db.SetWorkingSetParams{ID: edited.GraphVersionID, ProjectID: projectID}); err != nil {
+		t.Fatalf("Failed to set working set: %v", err)
+	}
+	sequelProjectID := createTestProject(t, database)
+	sequelVersionID := createTestGraphVersion(t, database, sequelProjectID, true)
+	if _, err := service.ImportEntity(ctx, sequelVersionID, projectID, "elena"); err != nil {
+		t.Fatalf("ImportEntity failed: %v", err)
+	}
+
+	versions, err := service.VersionsContaining(ctx, "elena")
+	if err != nil {
+		t.Fatalf("VersionsContaining failed: %v", err)
+	}
+
+	if len(versions) != 3 {
+		t.Fatalf("Expected 3 versions, got %d", len(versions))
+	}
+
+	position := make(map[string]int)
+	for i, version := range versions {
+		position[version.ID] = i
+	}
+	for _, versionID := range []string{created.GraphVersionID, edited.GraphVersionID, sequelVersionID} {
+		if _, ok := position[versionID]; !ok {
+			t.Errorf("Expected version %s to be listed", versionID)
+		}
+	}
+	if position[created.GraphVersionID] > position[edited.GraphVersionID] {
+		t.Error("Expected versions ordered by creation")
+	}
+
+	// Non-working-set versions are included
+	if versions[position[created.GraphVersionID]].IsWorkingSet {
+		t.Error("Expected the creating version not to be the working set")
+	}
+
+	versions, err = service.VersionsContaining(ctx, "nobody")
+	if err != nil {
+		t.Fatalf("VersionsContaining failed: %v", err)
+	}
+	if len(versions) != 0 {
+		t.Errorf("Expected no versions for unknown entity, got %d", len(versions))
+	}
+}
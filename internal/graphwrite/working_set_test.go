@@ -0,0 +1,202 @@
+package graphwrite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+)
+
+// workingSetID returns the current working set version for a project
+func workingSetID(t *testing.T, database *db.Database, projectID string) string {
+	version, err := database.Queries().GetWorkingSetVersion(context.Background(), projectID)
+	if err != nil {
+		t.Fatalf("Failed to get working set: %v", err)
+	}
+	return version.ID
+}
+
+func createLocationRequest(parentVersionID string) *ApplyRequest {
+	return &ApplyRequest{
+		ParentVersionID: parentVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "Tavern"}},
+		},
+	}
+}
+
+func TestService_Apply_WorkingSetPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("default leaves working set", func(t *testing.T) {
+		database := setupTestDB(t)
+		defer database.Close()
+
+		service := NewService(database)
+		projectID := createTestProject(t, database)
+		rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+		if _, err := service.Apply(ctx, createLocationRequest(rootVersionID)); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+
+		if got := workingSetID(t, database, projectID); got != rootVersionID {
+			t.Errorf("Expected working set to stay at %s, got %s", rootVersionID, got)
+		}
+	})
+
+	t.Run("auto advance moves working set", func(t *testing.T) {
+		database := setupTestDB(t)
+		defer database.Close()
+
+		service := NewServiceWithOptions(database, ServiceOptions{AutoAdvanceWorkingSet: true})
+		projectID := createTestProject(t, database)
+		rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+		response, err := service.Apply(ctx, createLocationRequest(rootVersionID))
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+
+		if got := workingSetID(t, database, projectID); got != response.GraphVersionID {
+			t.Errorf("Expected working set to advance to %s, got %s", response.GraphVersionID, got)
+		}
+	})
+
+	t.Run("ApplyAndAdvance moves working set without option", func(t *testing.T) {
+		database := setupTestDB(t)
+		defer database.Close()
+
+		service := NewService(database)
+		projectID := createTestProject(t, database)
+		rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+		response, err := service.ApplyAndAdvance(ctx, createLocationRequest(rootVersionID))
+		if err != nil {
+			t.Fatalf("ApplyAndAdvance failed: %v", err)
+		}
+
+		if got := workingSetID(t, database, projectID); got != response.GraphVersionID {
+			t.Errorf("Expected working set to advance to %s, got %s", response.GraphVersionID, got)
+		}
+
+		// Only one version is flagged as the working set
+		versions, err := database.Queries().ListGraphVersionsByProject(ctx, projectID)
+		if err != nil {
+			t.Fatalf("Failed to list versions: %v", err)
+		}
+		flagged := 0
+		for _, version := range versions {
+			if version.IsWorkingSet {
+				flagged++
+			}
+		}
+		if flagged != 1 {
+			t.Errorf("Expected exactly one working set, got %d", flagged)
+		}
+	})
+}
+
+func TestService_Apply_RejectsConcurrentModification(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewServiceWithOptions(database, ServiceOptions{AutoAdvanceWorkingSet: true})
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	// Two agents read the same working set and apply against it
+	first := createLocationRequest(rootVersionID)
+	first.ExpectedWorkingSetVersionID = rootVersionID
+	response, err := service.Apply(ctx, first)
+	if err != nil {
+		t.Fatalf("First Apply failed: %v", err)
+	}
+
+	second := &ApplyRequest{
+		ParentVersionID:             rootVersionID,
+		ExpectedWorkingSetVersionID: rootVersionID,
+		Deltas: []*Delta{
+			{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+		},
+	}
+	if _, err := service.Apply(ctx, second); !errors.Is(err, ErrConcurrentModification) {
+		t.Fatalf("Expected ErrConcurrentModification, got %v", err)
+	}
+
+	if got := workingSetID(t, database, projectID); got != response.GraphVersionID {
+		t.Errorf("Expected the working set to keep the first apply, got %s", got)
+	}
+	versions, err := database.Queries().ListGraphVersionsByProject(ctx, projectID)
+	if err != nil {
+		t.Fatalf("Failed to list versions: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Errorf("Expected the rejected version to be discarded, got %d versions", len(versions))
+	}
+
+	// Rebasing onto the current working set succeeds
+	second.ParentVersionID = response.GraphVersionID
+	second.ExpectedWorkingSetVersionID = response.GraphVersionID
+	rebased, err := service.Apply(ctx, second)
+	if err != nil {
+		t.Fatalf("Rebased Apply failed: %v", err)
+	}
+	if got := workingSetID(t, database, projectID); got != rebased.GraphVersionID {
+		t.Errorf("Expected the working set to advance to the rebased version, got %s", got)
+	}
+
+	// Without the expected version the second writer still wins
+	if _, err := service.Apply(ctx, createLocationRequest(rootVersionID)); err != nil {
+		t.Errorf("Expected an apply without ExpectedWorkingSetVersionID to succeed, got %v", err)
+	}
+}
+
+func TestService_ApplyToProject(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	service := NewService(database)
+	ctx := context.Background()
+
+	projectID := createTestProject(t, database)
+	rootVersionID := createTestGraphVersion(t, database, projectID, true)
+
+	first, err := service.ApplyToProject(ctx, projectID, createLocationRequest("").Deltas)
+	if err != nil {
+		t.Fatalf("ApplyToProject failed: %v", err)
+	}
+	if got := workingSetID(t, database, projectID); got != first.GraphVersionID {
+		t.Errorf("Expected the working set to advance to %s, got %s", first.GraphVersionID, got)
+	}
+	version, err := service.GetVersion(ctx, first.GraphVersionID)
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if version.ParentVersionID == nil || *version.ParentVersionID != rootVersionID {
+		t.Errorf("Expected the root working set as parent, got %v", version.ParentVersionID)
+	}
+
+	second, err := service.ApplyToProject(ctx, projectID, []*Delta{
+		{Operation: "update", EntityType: "Location", EntityID: "tavern", Fields: map[string]any{"name": "The Broken Tankard"}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyToProject failed: %v", err)
+	}
+	if got := workingSetID(t, database, projectID); got != second.GraphVersionID {
+		t.Errorf("Expected the working set to advance to %s, got %s", second.GraphVersionID, got)
+	}
+	if names := entityNames(t, service, second.GraphVersionID); names["tavern"] != "The Broken Tankard" {
+		t.Errorf("Expected the update to build on the previous apply, got %v", names)
+	}
+
+	// A project without a working set has nothing to apply to
+	otherProjectID := createTestProject(t, database)
+	createTestGraphVersion(t, database, otherProjectID, false)
+	if _, err := service.ApplyToProject(ctx, otherProjectID, createLocationRequest("").Deltas); !errors.Is(err, ErrNoWorkingSet) {
+		t.Errorf("Expected ErrNoWorkingSet, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+// Package publishertest provides an in-memory publisher for tests. FakePublisher
+// satisfies the Publisher interface of every service's publisher package.
+package publishertest
+
+import (
+	"context"
+	"sync"
+)
+
+// Message is a single publish captured by FakePublisher.
+type Message struct {
+	Topic string
+	Data  []byte
+}
+
+// FakePublisher records published messages in memory.
+type FakePublisher struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (*FakePublisher) Name() string { return "fake" }
+
+func (f *FakePublisher) Publish(ctx context.Context, topic string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, Message{Topic: topic, Data: append([]byte(nil), data...)})
+	return nil
+}
+
+// Messages returns a copy of the messages published so far.
+func (f *FakePublisher) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Message(nil), f.messages...)
+}
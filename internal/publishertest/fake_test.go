@@ -0,0 +1,23 @@
+package publishertest
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFakePublisherCapturesMessages(t *testing.T) {
+	p := &FakePublisher{}
+	if err := p.Publish(context.Background(), "topic.a", []byte("one")); err != nil {
+		t.Fatalf("publish err: %v", err)
+	}
+	if err := p.Publish(context.Background(), "topic.b", []byte("two")); err != nil {
+		t.Fatalf("publish err: %v", err)
+	}
+	msgs := p.Messages()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+	if msgs[0].Topic != "topic.a" || string(msgs[1].Data) != "two" {
+		t.Fatalf("unexpected messages: %+v", msgs)
+	}
+}
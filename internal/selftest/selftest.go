@@ -0,0 +1,83 @@
+// Package selftest holds the database setup shared by the integration-test command
+// and libretto's -selftest flag, and the round trip the self-test runs. Projects are
+// created through the graphwrite service, so they get the same name and owner checks
+// as any other project.
+package selftest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	gwpkg "github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+// OpenDatabase opens the database at path, which may be ":memory:", and applies the
+// migrations
+func OpenDatabase(ctx context.Context, path string) (*db.Database, error) {
+	database, err := db.NewDatabase(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database: %w", err)
+	}
+	// Every connection to ":memory:" opens a database of its own
+	if path == ":memory:" {
+		database.DB().SetMaxOpenConns(1)
+	}
+
+	if err := database.Migrate(ctx); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return database, nil
+}
+
+// SetupProject creates a project named after params.Name, numbered as
+// UniqueProjectName numbers it when the name is taken, and returns its ID and the ID
+// of its working set
+func SetupProject(ctx context.Context, service gwpkg.GraphWriteService, params db.CreateProjectParams) (string, string, error) {
+	name, err := service.UniqueProjectName(ctx, params.Name)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to choose a project name: %w", err)
+	}
+	params.Name = name
+
+	projectID, versionID, err := service.CreateProject(ctx, params, "")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create project: %w", err)
+	}
+	return projectID, versionID, nil
+}
+
+// RoundTrip creates a project, applies a create delta to its working set and reads
+// the new entity back
+func RoundTrip(ctx context.Context, service gwpkg.GraphWriteService) error {
+	_, versionID, err := SetupProject(ctx, service, db.CreateProjectParams{Name: "Self Test"})
+	if err != nil {
+		return err
+	}
+
+	response, err := service.Apply(ctx, &gwpkg.ApplyRequest{
+		ParentVersionID: versionID,
+		Deltas: []*gwpkg.Delta{
+			{
+				Operation:  "create",
+				EntityType: "Scene",
+				EntityID:   "selftest-scene",
+				Fields:     map[string]any{"name": "Self Test Scene", "title": "Self Test Scene"},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply delta: %w", err)
+	}
+
+	entities, err := service.ListEntities(ctx, response.GraphVersionID, gwpkg.EntityFilter{})
+	if err != nil {
+		return fmt.Errorf("failed to read back entities: %w", err)
+	}
+	if len(entities) != 1 || entities[0].ID != "selftest-scene" || entities[0].Name != "Self Test Scene" {
+		return fmt.Errorf("read back unexpected entities: %+v", entities)
+	}
+
+	return nil
+}
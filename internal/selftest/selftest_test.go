@@ -0,0 +1,60 @@
+package selftest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/barrynorthern/libretto/internal/db"
+	gwpkg "github.com/barrynorthern/libretto/internal/graphwrite"
+)
+
+func TestSetupProject_NumbersTakenNames(t *testing.T) {
+	ctx := context.Background()
+	database, err := OpenDatabase(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("OpenDatabase failed: %v", err)
+	}
+	defer database.Close()
+	service := gwpkg.NewService(database)
+
+	var names []string
+	for i := 0; i < 2; i++ {
+		projectID, versionID, err := SetupProject(ctx, service, db.CreateProjectParams{Name: "Test Project"})
+		if err != nil {
+			t.Fatalf("SetupProject failed: %v", err)
+		}
+		project, err := service.GetProject(ctx, projectID)
+		if err != nil {
+			t.Fatalf("GetProject failed: %v", err)
+		}
+		version, err := service.GetVersion(ctx, versionID)
+		if err != nil {
+			t.Fatalf("GetVersion failed: %v", err)
+		}
+		if !version.IsWorkingSet || version.ProjectID != projectID {
+			t.Errorf("Expected %s to be the working set of %s, got %+v", versionID, projectID, version)
+		}
+		names = append(names, project.Name)
+	}
+
+	if names[0] != "Test Project" || names[1] != "Test Project (2)" {
+		t.Errorf("Expected the second project to be numbered, got %v", names)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	database, err := OpenDatabase(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("OpenDatabase failed: %v", err)
+	}
+	defer database.Close()
+
+	// A second run needs a fresh project name
+	service := gwpkg.NewService(database)
+	for run := 0; run < 2; run++ {
+		if err := RoundTrip(ctx, service); err != nil {
+			t.Fatalf("RoundTrip run %d failed: %v", run, err)
+		}
+	}
+}
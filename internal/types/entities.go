@@ -30,8 +30,34 @@ const (
 	RelationshipFollows     RelationshipType = "follows"
 	RelationshipConflicts   RelationshipType = "conflicts"
 	RelationshipSupports    RelationshipType = "supports"
+	RelationshipExplores    RelationshipType = "explores"
 )
 
+// FieldRelationshipRule derives a relationship from an entity data field holding
+// the logical ID, or list of logical IDs, of other entities
+type FieldRelationshipRule struct {
+	EntityType       EntityType
+	Field            string
+	RelationshipType RelationshipType
+}
+
+// FieldRelationshipRules are applied when an entity is created: each referenced
+// entity that exists gets a relationship from the new entity
+var FieldRelationshipRules = []FieldRelationshipRule{
+	{EntityType: EntityTypeScene, Field: "location", RelationshipType: RelationshipOccursAt},
+}
+
+// FieldRelationshipRulesFor returns the field relationship rules for an entity type
+func FieldRelationshipRulesFor(entityType EntityType) []FieldRelationshipRule {
+	var rules []FieldRelationshipRule
+	for _, rule := range FieldRelationshipRules {
+		if rule.EntityType == entityType {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
 // AnnotationType represents the different types of annotations
 type AnnotationType string
 
@@ -116,25 +142,40 @@ type ThemeData struct {
 
 // PlotPointData represents the data structure for PlotPoint entities
 type PlotPointData struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description,omitempty"`
-	Type        string   `json:"type,omitempty"` // inciting_incident, plot_twist, climax, etc.
-	Act         string   `json:"act,omitempty"`
-	Sequence    int      `json:"sequence,omitempty"`
-	Characters  []string `json:"characters,omitempty"` // Entity IDs
-	Themes      []string `json:"themes,omitempty"`     // Entity IDs
+	Name         string   `json:"name"`
+	Description  string   `json:"description,omitempty"`
+	Type         string   `json:"type,omitempty"` // inciting_incident, plot_twist, climax, etc.
+	Act          string   `json:"act,omitempty"`
+	Sequence     int      `json:"sequence,omitempty"`
+	TensionLevel float64  `json:"tension_level,omitempty"` // 0.0 to 1.0
+	SetsUp       []string `json:"sets_up,omitempty"`       // PlotPoint entity IDs this one foreshadows
+	PaysOff      []string `json:"pays_off,omitempty"`      // PlotPoint entity IDs this one resolves
+	Characters   []string `json:"characters,omitempty"`    // Entity IDs
+	Themes       []string `json:"themes,omitempty"`        // Entity IDs
 }
 
 // ArcData represents the data structure for Arc entities
 type ArcData struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description,omitempty"`
-	Type        string   `json:"type,omitempty"` // character_arc, plot_arc, thematic_arc
-	StartAct    string   `json:"start_act,omitempty"`
-	EndAct      string   `json:"end_act,omitempty"`
-	Characters  []string `json:"characters,omitempty"` // Entity IDs
-	PlotPoints  []string `json:"plot_points,omitempty"` // Entity IDs
-	Status      string   `json:"status,omitempty"` // planned, active, completed
+	Name          string         `json:"name"`
+	Description   string         `json:"description,omitempty"`
+	Type          string         `json:"type,omitempty"` // character_arc, plot_arc, thematic_arc
+	StartAct      string         `json:"start_act,omitempty"`
+	EndAct        string         `json:"end_act,omitempty"`
+	Characters    []string       `json:"characters,omitempty"`   // Entity IDs
+	PlotPoints    []string       `json:"plot_points,omitempty"`  // Entity IDs
+	Status        string         `json:"status,omitempty"`       // planned, active, completed
+	CharacterID   string         `json:"character_id,omitempty"` // Character entity ID, for a character arc
+	StartingState string         `json:"starting_state,omitempty"`
+	EndingState   string         `json:"ending_state,omitempty"`
+	Milestones    []ArcMilestone `json:"milestones,omitempty"`
+}
+
+// ArcMilestone is a turning point along an arc, in sequence order
+type ArcMilestone struct {
+	Description string `json:"description"`
+	State       string `json:"state,omitempty"`         // Where the arc stands after the milestone
+	PlotPointID string `json:"plot_point_id,omitempty"` // Entity ID
+	Sequence    int    `json:"sequence,omitempty"`
 }
 
 // EmotionalAnalysisData represents emotional analysis annotation data
@@ -200,13 +200,16 @@ func TestThemeDataMarshalUnmarshal(t *testing.T) {
 
 func TestPlotPointDataMarshalUnmarshal(t *testing.T) {
 	original := &PlotPointData{
-		Name:        "The Revelation",
-		Description: "Hero discovers mentor's true identity",
-		Type:        "plot_twist",
-		Act:         "Act2",
-		Sequence:    15,
-		Characters:  []string{"char_001", "char_002"},
-		Themes:      []string{"theme_001"},
+		Name:         "The Revelation",
+		Description:  "Hero discovers mentor's true identity",
+		Type:         "plot_twist",
+		Act:          "Act2",
+		Sequence:     15,
+		TensionLevel: 0.85,
+		SetsUp:       []string{"plot_004"},
+		PaysOff:      []string{"plot_001", "plot_002"},
+		Characters:   []string{"char_001", "char_002"},
+		Themes:       []string{"theme_001"},
 	}
 
 	// Marshal to JSON
@@ -234,18 +237,34 @@ func TestPlotPointDataMarshalUnmarshal(t *testing.T) {
 	if len(unmarshaled.Characters) != len(original.Characters) {
 		t.Errorf("Expected %d characters, got %d", len(original.Characters), len(unmarshaled.Characters))
 	}
+	if unmarshaled.TensionLevel != original.TensionLevel {
+		t.Errorf("Expected tension level %f, got %f", original.TensionLevel, unmarshaled.TensionLevel)
+	}
+	if len(unmarshaled.SetsUp) != 1 || unmarshaled.SetsUp[0] != "plot_004" {
+		t.Errorf("Expected setups %v, got %v", original.SetsUp, unmarshaled.SetsUp)
+	}
+	if len(unmarshaled.PaysOff) != len(original.PaysOff) {
+		t.Errorf("Expected %d payoffs, got %d", len(original.PaysOff), len(unmarshaled.PaysOff))
+	}
 }
 
 func TestArcDataMarshalUnmarshal(t *testing.T) {
 	original := &ArcData{
-		Name:        "Hero's Journey",
-		Description: "The protagonist's transformation from naive to wise",
-		Type:        "character_arc",
-		StartAct:    "Act1",
-		EndAct:      "Act3",
-		Characters:  []string{"char_001"},
-		PlotPoints:  []string{"plot_001", "plot_002", "plot_003"},
-		Status:      "active",
+		Name:          "Hero's Journey",
+		Description:   "The protagonist's transformation from naive to wise",
+		Type:          "character_arc",
+		StartAct:      "Act1",
+		EndAct:        "Act3",
+		Characters:    []string{"char_001"},
+		PlotPoints:    []string{"plot_001", "plot_002", "plot_003"},
+		Status:        "active",
+		CharacterID:   "char_001",
+		StartingState: "naive",
+		EndingState:   "wise",
+		Milestones: []ArcMilestone{
+			{Description: "Leaves the village", State: "uncertain", PlotPointID: "plot_001", Sequence: 1},
+			{Description: "Faces the mentor's betrayal", State: "disillusioned", PlotPointID: "plot_002", Sequence: 2},
+		},
 	}
 
 	// Marshal to JSON
@@ -273,6 +292,18 @@ func TestArcDataMarshalUnmarshal(t *testing.T) {
 	if len(unmarshaled.PlotPoints) != len(original.PlotPoints) {
 		t.Errorf("Expected %d plot points, got %d", len(original.PlotPoints), len(unmarshaled.PlotPoints))
 	}
+	if unmarshaled.CharacterID != original.CharacterID {
+		t.Errorf("Expected character %s, got %s", original.CharacterID, unmarshaled.CharacterID)
+	}
+	if unmarshaled.StartingState != original.StartingState || unmarshaled.EndingState != original.EndingState {
+		t.Errorf("Expected states %s -> %s, got %s -> %s", original.StartingState, original.EndingState, unmarshaled.StartingState, unmarshaled.EndingState)
+	}
+	if len(unmarshaled.Milestones) != len(original.Milestones) {
+		t.Fatalf("Expected %d milestones, got %d", len(original.Milestones), len(unmarshaled.Milestones))
+	}
+	if unmarshaled.Milestones[1] != original.Milestones[1] {
+		t.Errorf("Expected milestone %+v, got %+v", original.Milestones[1], unmarshaled.Milestones[1])
+	}
 }
 
 func TestEmotionalAnalysisDataMarshalUnmarshal(t *testing.T) {
@@ -515,4 +546,13 @@ func TestAnnotationTypeConstants(t *testing.T) {
 			t.Errorf("Expected annotation type %s, got %s", expectedValues[i], string(annotationType))
 		}
 	}
-}
\ No newline at end of file
+}
+func TestFieldRelationshipRulesFor(t *testing.T) {
+	rules := FieldRelationshipRulesFor(EntityTypeScene)
+	if len(rules) != 1 || rules[0].Field != "location" || rules[0].RelationshipType != RelationshipOccursAt {
+		t.Errorf("Expected Scene.location to map to occurs_at, got %+v", rules)
+	}
+	if rules := FieldRelationshipRulesFor(EntityTypeTheme); len(rules) != 0 {
+		t.Errorf("Expected no rules for Theme, got %+v", rules)
+	}
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DataMigration upgrades one legacy shape of an entity type's data to the current
+// schema. Apply changes data in place and reports whether it changed anything.
+type DataMigration struct {
+	EntityType  EntityType
+	Description string
+	Apply       func(data map[string]any) bool
+}
+
+// DataMigrations are the upgrades MigrateEntityData runs, in order. Each must leave
+// data already in the current shape untouched, so running them again is a no-op.
+var DataMigrations = []DataMigration{
+	{EntityType: EntityTypeScene, Description: "numeric act to string", Apply: migrateNumericField("act")},
+	{EntityType: EntityTypePlotPoint, Description: "numeric act to string", Apply: migrateNumericField("act")},
+	{EntityType: EntityTypeScene, Description: "string sequence to integer", Apply: migrateStringSequence},
+	{EntityType: EntityTypePlotPoint, Description: "string sequence to integer", Apply: migrateStringSequence},
+	{EntityType: EntityTypeScene, Description: "single character ID to list", Apply: migrateSingleID("characters")},
+	{EntityType: EntityTypeScene, Description: "single theme ID to list", Apply: migrateSingleID("themes")},
+	{EntityType: EntityTypePlotPoint, Description: "single character ID to list", Apply: migrateSingleID("characters")},
+	{EntityType: EntityTypePlotPoint, Description: "single theme ID to list", Apply: migrateSingleID("themes")},
+	{EntityType: EntityTypeArc, Description: "single character ID to list", Apply: migrateSingleID("characters")},
+}
+
+// MigrateEntityData upgrades an entity's decoded data from older shapes to the
+// current schema in place and reports whether anything changed
+func MigrateEntityData(entityType EntityType, data map[string]any) bool {
+	changed := false
+	for _, migration := range DataMigrations {
+		if migration.EntityType == entityType && migration.Apply(data) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// migrateNumericField rewrites a number stored in a string field as its decimal text
+func migrateNumericField(field string) func(map[string]any) bool {
+	return func(data map[string]any) bool {
+		number, ok := data[field].(float64)
+		if !ok {
+			return false
+		}
+		data[field] = strconv.FormatFloat(number, 'f', -1, 64)
+		return true
+	}
+}
+
+// migrateStringSequence rewrites a sequence stored as whole-number text as a number,
+// leaving text that is not a number for validation to report
+func migrateStringSequence(data map[string]any) bool {
+	text, ok := data["sequence"].(string)
+	if !ok {
+		return false
+	}
+	sequence, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return false
+	}
+	data["sequence"] = float64(sequence)
+	return true
+}
+
+// migrateSingleID wraps a lone entity ID stored in a list field in a list
+func migrateSingleID(field string) func(map[string]any) bool {
+	return func(data map[string]any) bool {
+		id, ok := data[field].(string)
+		if !ok {
+			return false
+		}
+		if id == "" {
+			data[field] = []any{}
+		} else {
+			data[field] = []any{id}
+		}
+		return true
+	}
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateEntityData(t *testing.T) {
+	tests := []struct {
+		name       string
+		entityType EntityType
+		data       string
+		want       string
+		changed    bool
+	}{
+		{"legacy scene", EntityTypeScene, `{"title": "Opening", "act": 1, "sequence": "3", "characters": "elena", "themes": ""}`, `{"title": "Opening", "act": "1", "sequence": 3, "characters": ["elena"], "themes": []}`, true},
+		{"legacy plot point", EntityTypePlotPoint, `{"name": "Twist", "act": 2.5, "themes": "betrayal"}`, `{"name": "Twist", "act": "2.5", "themes": ["betrayal"]}`, true},
+		{"current scene", EntityTypeScene, `{"title": "Opening", "act": "1", "sequence": 3, "characters": ["elena"]}`, `{"title": "Opening", "act": "1", "sequence": 3, "characters": ["elena"]}`, false},
+		{"unparseable sequence is left for validation", EntityTypeScene, `{"sequence": "first"}`, `{"sequence": "first"}`, false},
+		{"other entity types are untouched", EntityTypeCharacter, `{"name": "Elena", "act": 1}`, `{"name": "Elena", "act": 1}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data, want map[string]any
+			if err := json.Unmarshal([]byte(tt.data), &data); err != nil {
+				t.Fatalf("Invalid test data: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatalf("Invalid test data: %v", err)
+			}
+
+			if changed := MigrateEntityData(tt.entityType, data); changed != tt.changed {
+				t.Errorf("Expected changed %v, got %v", tt.changed, changed)
+			}
+			if !reflect.DeepEqual(data, want) {
+				t.Errorf("Expected %v, got %v", want, data)
+			}
+			if tt.changed {
+				if MigrateEntityData(tt.entityType, data) {
+					t.Error("Expected a second migration to change nothing")
+				}
+				if fieldErrors := ValidateEntityFields(tt.entityType, data); len(fieldErrors) != 0 {
+					t.Errorf("Expected migrated data to validate, got %v", fieldErrors)
+				}
+			}
+		})
+	}
+}
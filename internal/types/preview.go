@@ -0,0 +1,28 @@
+package types
+
+import "unicode/utf8"
+
+// previewEllipsis marks a preview that was cut short
+const previewEllipsis = "…"
+
+// Preview shortens text to at most maxLen runes for display, cutting on a rune
+// boundary and ending with an ellipsis when anything was dropped. The ellipsis counts
+// towards maxLen; a maxLen of zero or less gives an empty preview.
+func Preview(text string, maxLen int) string {
+	if maxLen <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(text) <= maxLen {
+		return text
+	}
+
+	// Keep maxLen-1 runes, leaving room for the ellipsis
+	kept := 0
+	for i := range text {
+		if kept == maxLen-1 {
+			return text[:i] + previewEllipsis
+		}
+		kept++
+	}
+	return text
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPreview(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxLen int
+		want   string
+	}{
+		{"short text is unchanged", "Elena", 10, "Elena"},
+		{"exact fit is unchanged", "Elena", 5, "Elena"},
+		{"ascii is cut with an ellipsis", "Elena Stormwind", 8, "Elena S…"},
+		{"accents stay whole", "Café société élégante", 7, "Café s…"},
+		{"cjk stays whole", "龍の城の物語", 4, "龍の城…"},
+		{"emoji stay whole", "🏰🐉⚔️🔥", 2, "🏰…"},
+		{"one rune leaves only the ellipsis", "Elena", 1, "…"},
+		{"zero gives nothing", "Elena", 0, ""},
+		{"negative gives nothing", "Elena", -3, ""},
+		{"empty text", "", 5, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Preview(tt.text, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("Preview(%q, %d) = %q, want %q", tt.text, tt.maxLen, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Preview(%q, %d) = %q is not valid UTF-8", tt.text, tt.maxLen, got)
+			}
+			if tt.maxLen > 0 && utf8.RuneCountInString(got) > tt.maxLen {
+				t.Errorf("Preview(%q, %d) has %d runes", tt.text, tt.maxLen, utf8.RuneCountInString(got))
+			}
+		})
+	}
+
+	// Every cut point of a multibyte string yields valid text of the requested length
+	text := strings.Repeat("naïve 東京 🌙 ", 4)
+	for maxLen := 1; maxLen < utf8.RuneCountInString(text); maxLen++ {
+		got := Preview(text, maxLen)
+		if !utf8.ValidString(got) || utf8.RuneCountInString(got) != maxLen || !strings.HasSuffix(got, "…") {
+			t.Errorf("Preview at %d runes gave %q", maxLen, got)
+		}
+	}
+}
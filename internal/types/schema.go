@@ -0,0 +1,244 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// entityDataTypes maps entity types to the typed structure their data follows
+var entityDataTypes = map[EntityType]reflect.Type{
+	EntityTypeScene:     reflect.TypeOf(SceneData{}),
+	EntityTypeCharacter: reflect.TypeOf(CharacterData{}),
+	EntityTypeLocation:  reflect.TypeOf(LocationData{}),
+	EntityTypeTheme:     reflect.TypeOf(ThemeData{}),
+	EntityTypePlotPoint: reflect.TypeOf(PlotPointData{}),
+	EntityTypeArc:       reflect.TypeOf(ArcData{}),
+}
+
+// FieldError describes one field of an entity payload that does not match its schema.
+// Field is a dotted path such as "sequence" or "voice_characteristics.tone", with
+// array elements as "themes[0]".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// EntitySchema generates the JSON Schema for an entity type's data from its typed
+// structure. Fields outside the structure, such as the entity's name, are allowed.
+func EntitySchema(entityType EntityType) (map[string]any, error) {
+	dataType, exists := entityDataTypes[entityType]
+	if !exists {
+		return nil, fmt.Errorf("no schema for entity type %q", entityType)
+	}
+
+	schema := schemaFor(dataType)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = string(entityType)
+	schema["additionalProperties"] = true
+	return schema, nil
+}
+
+// ValidateEntityFields checks an entity field payload against the entity type's
+// generated schema and returns every mismatching field, sorted by path. Entity
+// types without a schema accept any fields.
+func ValidateEntityFields(entityType EntityType, fields map[string]any) []FieldError {
+	schema, err := EntitySchema(entityType)
+	if err != nil {
+		return nil
+	}
+
+	var fieldErrors []FieldError
+	validateProperties(schema, fields, "", &fieldErrors)
+	sort.Slice(fieldErrors, func(i, j int) bool { return fieldErrors[i].Field < fieldErrors[j].Field })
+	return fieldErrors
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor builds the JSON Schema for a Go type as encoding/json would encode it
+func schemaFor(t reflect.Type) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return map[string]any{"type": "object"}
+		}
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]any)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := jsonFieldName(field)
+			if name == "" {
+				continue
+			}
+			properties[name] = schemaFor(field.Type)
+		}
+		return map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	default:
+		return map[string]any{}
+	}
+}
+
+// jsonFieldName returns the name encoding/json uses for a struct field, or "" when
+// the field is not encoded
+func jsonFieldName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// validateValue checks a decoded JSON value against a schema generated by schemaFor
+func validateValue(schema map[string]any, value any, path string, fieldErrors *[]FieldError) {
+	if value == nil {
+		return
+	}
+
+	expected, _ := schema["type"].(string)
+	switch expected {
+	case "string":
+		if _, ok := value.(string); !ok {
+			addTypeError(fieldErrors, path, expected, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			addTypeError(fieldErrors, path, expected, value)
+		}
+	case "integer":
+		if !isInteger(value) {
+			addTypeError(fieldErrors, path, expected, value)
+		}
+	case "number":
+		if _, ok := toFloat(value); !ok {
+			addTypeError(fieldErrors, path, expected, value)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			addTypeError(fieldErrors, path, expected, value)
+			return
+		}
+		itemSchema, _ := schema["items"].(map[string]any)
+		for i, item := range items {
+			validateValue(itemSchema, item, fmt.Sprintf("%s[%d]", path, i), fieldErrors)
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			addTypeError(fieldErrors, path, expected, value)
+			return
+		}
+		validateProperties(schema, object, path, fieldErrors)
+	}
+}
+
+// validateProperties checks each member of an object against the object schema's
+// properties, or against additionalProperties for members it does not declare
+func validateProperties(schema map[string]any, object map[string]any, path string, fieldErrors *[]FieldError) {
+	properties, _ := schema["properties"].(map[string]any)
+	for name, value := range object {
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		if propertySchema, declared := properties[name].(map[string]any); declared {
+			validateValue(propertySchema, value, fieldPath, fieldErrors)
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*fieldErrors = append(*fieldErrors, FieldError{Field: fieldPath, Message: "unknown field"})
+			}
+		case map[string]any:
+			validateValue(additional, value, fieldPath, fieldErrors)
+		}
+	}
+}
+
+func addTypeError(fieldErrors *[]FieldError, path, expected string, value any) {
+	*fieldErrors = append(*fieldErrors, FieldError{
+		Field:   path,
+		Message: fmt.Sprintf("expected %s, got %s", expected, jsonTypeName(value)),
+	})
+}
+
+// jsonTypeName names the JSON type of a value decoded by encoding/json or built in Go
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	if isInteger(value) {
+		return "integer"
+	}
+	if _, ok := toFloat(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func isInteger(value any) bool {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	case float64:
+		return v == float64(int64(v))
+	case float32:
+		return v == float32(int64(v))
+	}
+	return false
+}
+
+func toFloat(value any) (float64, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
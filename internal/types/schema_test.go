@@ -0,0 +1,69 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEntitySchema(t *testing.T) {
+	schema, err := EntitySchema(EntityTypeScene)
+	if err != nil {
+		t.Fatalf("EntitySchema failed: %v", err)
+	}
+
+	properties := schema["properties"].(map[string]any)
+	if sequence := properties["sequence"].(map[string]any); sequence["type"] != "integer" {
+		t.Errorf("Expected sequence to be an integer, got %v", sequence)
+	}
+	if themes := properties["themes"].(map[string]any); themes["type"] != "array" {
+		t.Errorf("Expected themes to be an array, got %v", themes)
+	}
+
+	if _, err := json.Marshal(schema); err != nil {
+		t.Errorf("Expected the schema to marshal, got %v", err)
+	}
+	if _, err := EntitySchema("Unknown"); err == nil {
+		t.Error("Expected an error for an entity type without a schema")
+	}
+}
+
+func TestValidateEntityFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		entityType EntityType
+		fields     string
+		wantFields []string
+	}{
+		{"matching scene", EntityTypeScene, `{"name": "Opening", "sequence": 3, "themes": ["hope"]}`, nil},
+		{"wrong type for sequence", EntityTypeScene, `{"sequence": "first"}`, []string{"sequence"}},
+		{"fractional sequence", EntityTypeScene, `{"sequence": 1.5}`, []string{"sequence"}},
+		{"wrong array item", EntityTypeScene, `{"themes": ["hope", 7]}`, []string{"themes[1]"}},
+		{"nested field", EntityTypeCharacter, `{"voice_characteristics": {"tone": 3, "accent": "x"}}`, []string{"voice_characteristics.accent", "voice_characteristics.tone"}},
+		{"number accepts integers", EntityTypeTheme, `{"relevance": 1}`, nil},
+		{"matching plot point", EntityTypePlotPoint, `{"tension_level": 0.8, "sets_up": ["reveal"], "pays_off": ["omen"]}`, nil},
+		{"wrong type for tension", EntityTypePlotPoint, `{"tension_level": "high", "pays_off": "omen"}`, []string{"pays_off", "tension_level"}},
+		{"matching arc", EntityTypeArc, `{"character_id": "elena", "starting_state": "naive", "milestones": [{"description": "Leaves home", "sequence": 1}]}`, nil},
+		{"wrong milestone field", EntityTypeArc, `{"milestones": [{"description": "Leaves home", "sequence": "first"}]}`, []string{"milestones[0].sequence"}},
+		{"null values are skipped", EntityTypeScene, `{"sequence": null}`, nil},
+		{"type without schema", "Unknown", `{"sequence": "first"}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields map[string]any
+			if err := json.Unmarshal([]byte(tt.fields), &fields); err != nil {
+				t.Fatalf("Invalid test fields: %v", err)
+			}
+
+			fieldErrors := ValidateEntityFields(tt.entityType, fields)
+			if len(fieldErrors) != len(tt.wantFields) {
+				t.Fatalf("Expected errors for %v, got %v", tt.wantFields, fieldErrors)
+			}
+			for i, field := range tt.wantFields {
+				if fieldErrors[i].Field != field {
+					t.Errorf("Expected error %d for %s, got %v", i, field, fieldErrors[i])
+				}
+			}
+		})
+	}
+}
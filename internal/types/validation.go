@@ -0,0 +1,100 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// annotationSchemas maps annotation types to the typed structure their metadata must match.
+// Annotation types without an entry accept any metadata.
+var annotationSchemas = map[AnnotationType]func() any{
+	AnnotationEmotionalAnalysis: func() any { return &EmotionalAnalysisData{} },
+	AnnotationThematicScore:     func() any { return &ThematicScoreData{} },
+	AnnotationContinuityCheck:   func() any { return &ContinuityCheckData{} },
+}
+
+// ValidateAnnotationMetadata checks that metadata matches the typed schema for the
+// annotation type: every key must be a known field and every value must have the
+// field's type. Empty metadata is always valid.
+func ValidateAnnotationMetadata(annotationType AnnotationType, metadata json.RawMessage) error {
+	newSchema, exists := annotationSchemas[annotationType]
+	if !exists {
+		return nil
+	}
+
+	trimmed := bytes.TrimSpace(metadata)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(newSchema()); err != nil {
+		return fmt.Errorf("metadata does not match %s schema: %w", annotationType, err)
+	}
+
+	return nil
+}
+
+// RelationshipEndpoints lists the entity types a relationship type may connect
+type RelationshipEndpoints struct {
+	From []EntityType
+	To   []EntityType
+}
+
+// relationshipEndpoints maps relationship types to their allowed endpoint types.
+// Relationship types without an entry may connect any entities.
+var relationshipEndpoints = map[RelationshipType]RelationshipEndpoints{
+	RelationshipContains: {
+		From: []EntityType{EntityTypeArc, EntityTypeScene},
+		To:   []EntityType{EntityTypeScene, EntityTypePlotPoint},
+	},
+	RelationshipAdvances: {
+		From: []EntityType{EntityTypeScene, EntityTypePlotPoint},
+		To:   []EntityType{EntityTypePlotPoint, EntityTypeArc, EntityTypeTheme},
+	},
+	RelationshipFeatures: {
+		From: []EntityType{EntityTypeScene, EntityTypePlotPoint, EntityTypeArc},
+		To:   []EntityType{EntityTypeCharacter, EntityTypeLocation, EntityTypeTheme},
+	},
+	RelationshipOccursAt: {
+		From: []EntityType{EntityTypeScene, EntityTypePlotPoint, EntityTypeCharacter},
+		To:   []EntityType{EntityTypeLocation},
+	},
+	RelationshipPrecedes: {
+		From: []EntityType{EntityTypeScene, EntityTypePlotPoint},
+		To:   []EntityType{EntityTypeScene, EntityTypePlotPoint},
+	},
+	RelationshipFollows: {
+		From: []EntityType{EntityTypeScene, EntityTypePlotPoint},
+		To:   []EntityType{EntityTypeScene, EntityTypePlotPoint},
+	},
+}
+
+// ValidateRelationshipEndpoints checks that a relationship type may run from an
+// entity of type from to an entity of type to
+func ValidateRelationshipEndpoints(relationshipType RelationshipType, from, to EntityType) error {
+	endpoints, exists := relationshipEndpoints[relationshipType]
+	if !exists {
+		return nil
+	}
+
+	if !containsEntityType(endpoints.From, from) {
+		return fmt.Errorf("%s relationships cannot start at a %s (allowed: %v)", relationshipType, from, endpoints.From)
+	}
+	if !containsEntityType(endpoints.To, to) {
+		return fmt.Errorf("%s relationships cannot end at a %s (allowed: %v)", relationshipType, to, endpoints.To)
+	}
+
+	return nil
+}
+
+func containsEntityType(entityTypes []EntityType, entityType EntityType) bool {
+	for _, candidate := range entityTypes {
+		if candidate == entityType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateAnnotationMetadata(t *testing.T) {
+	tests := []struct {
+		name           string
+		annotationType AnnotationType
+		metadata       string
+		wantErr        bool
+	}{
+		{"matching emotional analysis", AnnotationEmotionalAnalysis, `{"sentiment": 0.4, "emotions": {"fear": 0.8}, "emotional_arc": "rising"}`, false},
+		{"thematic shape on emotional analysis", AnnotationEmotionalAnalysis, `{"relevance_score": 0.9, "theme_alignment": {"redemption": 0.7}}`, true},
+		{"wrong value type", AnnotationThematicScore, `{"relevance_score": "high"}`, true},
+		{"matching continuity check", AnnotationContinuityCheck, `{"is_consistent": false, "violations": [{"type": "timeline", "description": "x", "severity": "high"}]}`, false},
+		{"empty metadata", AnnotationContinuityCheck, ``, false},
+		{"null metadata", AnnotationEmotionalAnalysis, `null`, false},
+		{"type without schema", AnnotationStructuralNote, `{"anything": true}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAnnotationMetadata(tt.annotationType, json.RawMessage(tt.metadata))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAnnotationMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRelationshipEndpoints(t *testing.T) {
+	tests := []struct {
+		name             string
+		relationshipType RelationshipType
+		from, to         EntityType
+		wantErr          bool
+	}{
+		{"scene features character", RelationshipFeatures, EntityTypeScene, EntityTypeCharacter, false},
+		{"character features scene", RelationshipFeatures, EntityTypeCharacter, EntityTypeScene, true},
+		{"scene occurs at location", RelationshipOccursAt, EntityTypeScene, EntityTypeLocation, false},
+		{"scene occurs at character", RelationshipOccursAt, EntityTypeScene, EntityTypeCharacter, true},
+		{"unregistered type", RelationshipType("allies_with"), EntityTypeCharacter, EntityTypeTheme, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRelationshipEndpoints(tt.relationshipType, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRelationshipEndpoints() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
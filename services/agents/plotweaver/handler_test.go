@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	eventsv1 "github.com/barrynorthern/libretto/gen/go/libretto/events/v1"
+	"github.com/barrynorthern/libretto/internal/publishertest"
+	"github.com/google/uuid"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -37,3 +39,39 @@ func TestPushHandlerAcceptsValidEvent(t *testing.T) {
 	}
 }
 
+func TestPushHandlerPublishesSceneProposal(t *testing.T) {
+	fake := &publishertest.FakePublisher{}
+	prev := plotPublisher
+	plotPublisher = fake
+	defer func() { plotPublisher = prev }()
+
+	corr := uuid.NewString()
+	ev := &eventsv1.Event{
+		Envelope: &eventsv1.Envelope{EventName: "DirectiveIssued", EventVersion: "1.0.0", EventId: uuid.NewString(), CorrelationId: corr, CausationId: uuid.NewString(), IdempotencyKey: uuid.NewString(), Producer: "api", TenantId: "dev", OccurredAt: timestamppb.Now()},
+		Payload:  &eventsv1.Event_DirectiveIssued{DirectiveIssued: &eventsv1.DirectiveIssued{Text: "raise the stakes"}},
+	}
+	b, _ := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(ev)
+	body := map[string]any{"message": map[string]any{"data": base64.StdEncoding.EncodeToString(b), "attributes": map[string]string{}, "messageId": "1"}, "subscription": "devpush"}
+	raw, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/push", bytes.NewReader(raw))
+	pushHandler(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	msgs := fake.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 published message, got %d", len(msgs))
+	}
+	var out eventsv1.Event
+	if err := protojson.Unmarshal(msgs[0].Data, &out); err != nil {
+		t.Fatalf("published data is not an event: %v", err)
+	}
+	if got := out.GetEnvelope().GetEventName(); got != "SceneProposalReady" {
+		t.Fatalf("expected SceneProposalReady, got %q", got)
+	}
+	if got := out.GetEnvelope().GetCorrelationId(); got != corr {
+		t.Fatalf("expected correlation id to carry over, got %q", got)
+	}
+}
@@ -14,9 +14,8 @@ var (
 
 func main() {
 	// Publisher selection
-	var sel string
-	plotPublisher, sel = publisher.Select()
-	log.Printf("plotweaver publisher=%s", sel)
+	plotPublisher = publisher.Select()
+	log.Printf("plotweaver publisher=%s", plotPublisher.Name())
 
 	http.HandleFunc("/", handler)
 	http.HandleFunc("/push", pushHandler)
@@ -8,10 +8,14 @@ import (
 // Publisher publishes events (dev or real). For now, just logs distinctively per implementation.
 type Publisher interface {
 	Publish(ctx context.Context, topic string, data []byte) error
+	// Name identifies the implementation for logging (e.g. "nop", "pubsub").
+	Name() string
 }
 
 type NopPublisher struct{}
 
+func (NopPublisher) Name() string { return "nop" }
+
 func (NopPublisher) Publish(ctx context.Context, topic string, data []byte) error {
 	fmt.Printf("publish to %s: %d bytes\n", topic, len(data))
 	return nil
@@ -19,6 +23,8 @@ func (NopPublisher) Publish(ctx context.Context, topic string, data []byte) erro
 
 type PubSubPublisher struct{}
 
+func (PubSubPublisher) Name() string { return "pubsub" }
+
 func (PubSubPublisher) Publish(ctx context.Context, topic string, data []byte) error {
 	fmt.Printf("[pubsub] publish to %s: %d bytes\n", topic, len(data))
 	return nil
@@ -26,8 +32,9 @@ func (PubSubPublisher) Publish(ctx context.Context, topic string, data []byte) e
 
 type DevPushPublisher struct{}
 
+func (DevPushPublisher) Name() string { return "devpush" }
+
 func (DevPushPublisher) Publish(ctx context.Context, topic string, data []byte) error {
 	fmt.Printf("[devpush] publish to %s: %d bytes\n", topic, len(data))
 	return nil
 }
-
@@ -1,20 +1,22 @@
 package publisher
 
 import (
-	"fmt"
+	"log"
 	"os"
 )
 
-func Select() (Publisher, string) {
-	switch os.Getenv("PUBLISHER") {
+// Select returns a Publisher based on the PUBLISHER env var (nop, devpush, pubsub).
+// Unknown values fall back to nop.
+func Select() Publisher {
+	switch v := os.Getenv("PUBLISHER"); v {
 	case "pubsub":
-		return PubSubPublisher{}, "pubsub"
+		return PubSubPublisher{}
 	case "devpush":
-		return DevPushPublisher{}, "devpush"
+		return DevPushPublisher{}
 	case "nop", "":
-		return NopPublisher{}, "nop"
+		return NopPublisher{}
 	default:
-		return NopPublisher{}, fmt.Sprintf("unknown:%s", os.Getenv("PUBLISHER"))
+		log.Printf("unknown PUBLISHER %q, falling back to nop", v)
+		return NopPublisher{}
 	}
 }
-
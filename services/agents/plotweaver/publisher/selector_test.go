@@ -0,0 +1,22 @@
+package publisher
+
+import "testing"
+
+func TestSelectNamesPerEnv(t *testing.T) {
+	cases := []struct {
+		publisher string
+		want      string
+	}{
+		{"", "nop"},
+		{"nop", "nop"},
+		{"pubsub", "pubsub"},
+		{"devpush", "devpush"},
+		{"bogus", "nop"},
+	}
+	for _, tc := range cases {
+		t.Setenv("PUBLISHER", tc.publisher)
+		if got := Select().Name(); got != tc.want {
+			t.Errorf("PUBLISHER=%q: got %q want %q", tc.publisher, got, tc.want)
+		}
+	}
+}
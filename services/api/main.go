@@ -39,14 +39,7 @@ func main() {
 	mux := healthMux()
 	pub := publisher.Select()
 	// Log which publisher we selected for visibility during manual tests
-	switch pub.(type) {
-	case publisher.PubSubPublisher:
-		log.Printf("publisher=pubsub topic=%s", topic)
-	case publisher.DevPushPublisher:
-		log.Printf("publisher=devpush topic=%s", topic)
-	default:
-		log.Printf("publisher=nop topic=%s", topic)
-	}
+	log.Printf("publisher=%s topic=%s", pub.Name(), topic)
 	svc := &apiserver.BatonServer{Pub: pub, Topic: topic, Producer: producer}
 	mux.Handle(batonv1connect.NewBatonServiceHandler(svc))
 
@@ -28,6 +28,8 @@ type devPushEnvelope struct {
 	Subscription string `json:"subscription"`
 }
 
+func (DevPushPublisher) Name() string { return "devpush" }
+
 func (d DevPushPublisher) client() *http.Client {
 	if d.Client != nil {
 		return d.Client
@@ -8,13 +8,16 @@ import (
 // Publisher publishes events to a bus. In MVP this will be Pub/Sub.
 type Publisher interface {
 	Publish(ctx context.Context, topic string, data []byte) error
+	// Name identifies the implementation for logging (e.g. "nop", "pubsub").
+	Name() string
 }
 
 // NopPublisher is used in local tests.
 type NopPublisher struct{}
 
+func (NopPublisher) Name() string { return "nop" }
+
 func (NopPublisher) Publish(ctx context.Context, topic string, data []byte) error {
 	fmt.Printf("publish to %s: %d bytes\n", topic, len(data))
 	return nil
 }
-
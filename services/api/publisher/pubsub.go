@@ -9,8 +9,9 @@ import (
 // For now, it just logs distinctively to differentiate from NOP.
 type PubSubPublisher struct{}
 
+func (PubSubPublisher) Name() string { return "pubsub" }
+
 func (PubSubPublisher) Publish(ctx context.Context, topic string, data []byte) error {
 	fmt.Printf("[pubsub] publish to %s: %d bytes\n", topic, len(data))
 	return nil
 }
-
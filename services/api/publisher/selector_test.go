@@ -3,14 +3,9 @@ package publisher
 import (
 	"context"
 	"testing"
-)
-
-type capturePublisher struct{ called bool }
 
-func (c *capturePublisher) Publish(ctx context.Context, topic string, data []byte) error {
-	c.called = true
-	return nil
-}
+	"github.com/barrynorthern/libretto/internal/publishertest"
+)
 
 func TestSelectDefaultsToNop(t *testing.T) {
 	t.Setenv("PUBSUB_ENABLED", "")
@@ -28,12 +23,36 @@ func TestSelectPubSubWhenEnabled(t *testing.T) {
 	}
 }
 
+func TestSelectNamesPerEnv(t *testing.T) {
+	cases := []struct {
+		publisher string
+		want      string
+	}{
+		{"", "nop"},
+		{"nop", "nop"},
+		{"pubsub", "pubsub"},
+		{"devpush", "devpush"},
+		{"bogus", "nop"},
+	}
+	for _, tc := range cases {
+		t.Setenv("PUBSUB_ENABLED", "")
+		t.Setenv("PUBLISHER", tc.publisher)
+		if got := Select().Name(); got != tc.want {
+			t.Errorf("PUBLISHER=%q: got %q want %q", tc.publisher, got, tc.want)
+		}
+	}
+}
+
 func TestSmoke(t *testing.T) {
-	p := &capturePublisher{}
+	p := &publishertest.FakePublisher{}
 	if err := Smoke(context.Background(), p); err != nil {
 		t.Fatalf("smoke err: %v", err)
 	}
-	if !p.called {
-		t.Fatalf("expected publish to be called")
+	msgs := p.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 captured message, got %d", len(msgs))
+	}
+	if msgs[0].Topic != "libretto.dev.smoke" || string(msgs[0].Data) != "ok" {
+		t.Fatalf("unexpected captured message: %+v", msgs[0])
 	}
 }
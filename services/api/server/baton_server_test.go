@@ -1,10 +1,14 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
+	"connectrpc.com/connect"
+	batonv1 "github.com/barrynorthern/libretto/gen/go/libretto/baton/v1"
 	eventsv1 "github.com/barrynorthern/libretto/gen/go/libretto/events/v1"
+	"github.com/barrynorthern/libretto/internal/publishertest"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -26,3 +30,33 @@ func TestEventMarshallingOneofDirectiveIssued(t *testing.T) {
 	}
 }
 
+func TestIssueDirectivePublishesEnvelope(t *testing.T) {
+	fake := &publishertest.FakePublisher{}
+	s := &BatonServer{Pub: fake, Topic: "libretto.test.directive", Producer: "api"}
+	req := connect.NewRequest(&batonv1.IssueDirectiveRequest{Text: "raise the stakes", Act: "II", Target: "scene"})
+	res, err := s.IssueDirective(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	msgs := fake.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 published message, got %d", len(msgs))
+	}
+	if msgs[0].Topic != "libretto.test.directive" {
+		t.Fatalf("unexpected topic %q", msgs[0].Topic)
+	}
+	var ev eventsv1.Event
+	if err := protojson.Unmarshal(msgs[0].Data, &ev); err != nil {
+		t.Fatalf("published data is not an event: %v", err)
+	}
+	if got := ev.GetEnvelope().GetEventName(); got != "DirectiveIssued" {
+		t.Fatalf("expected DirectiveIssued, got %q", got)
+	}
+	if got := ev.GetEnvelope().GetCorrelationId(); got != res.Msg.GetCorrelationId() {
+		t.Fatalf("correlation id mismatch: published %q returned %q", got, res.Msg.GetCorrelationId())
+	}
+	if got := ev.GetDirectiveIssued().GetText(); got != "raise the stakes" {
+		t.Fatalf("unexpected directive text %q", got)
+	}
+}
@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"connectrpc.com/connect"
@@ -50,7 +52,7 @@ func (s *GraphWriteServer) Apply(ctx context.Context, req *connect.Request[graph
 		Deltas:          deltas,
 	})
 	if err != nil {
-		return nil, connect.NewError(connect.CodeInternal, err)
+		return nil, serviceError(err)
 	}
 
 	res := connect.NewResponse(&graphv1.ApplyResponse{
@@ -59,3 +61,132 @@ func (s *GraphWriteServer) Apply(ctx context.Context, req *connect.Request[graph
 	})
 	return res, nil
 }
+
+func (s *GraphWriteServer) SearchEntities(ctx context.Context, req *connect.Request[graphv1.SearchEntitiesRequest]) (*connect.Response[graphv1.SearchEntitiesResponse], error) {
+	if req.Msg.GetVersionId() == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("version_id is required"))
+	}
+
+	entities, err := s.service.SearchEntities(ctx, req.Msg.GetVersionId(), req.Msg.GetQuery())
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	pbEntities := make([]*graphv1.Entity, 0, len(entities))
+	for _, entity := range entities {
+		pbEntity, err := toProtoEntity(entity)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		pbEntities = append(pbEntities, pbEntity)
+	}
+
+	return connect.NewResponse(&graphv1.SearchEntitiesResponse{Entities: pbEntities}), nil
+}
+
+func (s *GraphWriteServer) ImportEntity(ctx context.Context, req *connect.Request[graphv1.ImportEntityRequest]) (*connect.Response[graphv1.ImportEntityResponse], error) {
+	if req.Msg.GetTargetVersionId() == "" || req.Msg.GetSourceProjectId() == "" || req.Msg.GetEntityId() == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("target_version_id, source_project_id and entity_id are required"))
+	}
+
+	importEntity := s.service.ImportEntity
+	if req.Msg.GetIncludeRelationships() {
+		importEntity = s.service.ImportEntityWithRelationships
+	}
+	entity, err := importEntity(ctx, req.Msg.GetTargetVersionId(), req.Msg.GetSourceProjectId(), req.Msg.GetEntityId())
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	pbEntity, err := toProtoEntity(entity)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	return connect.NewResponse(&graphv1.ImportEntityResponse{Entity: pbEntity}), nil
+}
+
+// ImportEntities imports the entities in one pass; an ID missing from the source
+// project fails the call without importing any of them. With include_relationships,
+// each entity's relationships to the others and to the target's entities follow.
+func (s *GraphWriteServer) ImportEntities(ctx context.Context, req *connect.Request[graphv1.ImportEntitiesRequest]) (*connect.Response[graphv1.ImportEntitiesResponse], error) {
+	if req.Msg.GetTargetVersionId() == "" || req.Msg.GetSourceProjectId() == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("target_version_id and source_project_id are required"))
+	}
+	if len(req.Msg.GetEntityIds()) == 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("no entity_ids provided"))
+	}
+
+	entities, err := s.service.ImportEntities(ctx, req.Msg.GetTargetVersionId(), req.Msg.GetSourceProjectId(), req.Msg.GetEntityIds())
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	if req.Msg.GetIncludeRelationships() {
+		// Every entity is in the target now, so linking each one brings over the
+		// relationships among them whatever their order
+		for _, logicalID := range req.Msg.GetEntityIds() {
+			if _, err := s.service.ImportEntityWithRelationships(ctx, req.Msg.GetTargetVersionId(), req.Msg.GetSourceProjectId(), logicalID); err != nil {
+				return nil, serviceError(err)
+			}
+		}
+	}
+
+	pbEntities := make([]*graphv1.Entity, 0, len(entities))
+	for _, entity := range entities {
+		pbEntity, err := toProtoEntity(entity)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		pbEntities = append(pbEntities, pbEntity)
+	}
+
+	return connect.NewResponse(&graphv1.ImportEntitiesResponse{Entities: pbEntities}), nil
+}
+
+// serviceError maps the typed graphwrite errors to connect codes, as the dashboard
+// maps them to HTTP statuses; anything else is internal
+func serviceError(err error) *connect.Error {
+	switch {
+	case errors.Is(err, graphwrite.ErrVersionNotFound),
+		errors.Is(err, graphwrite.ErrEntityNotFound),
+		errors.Is(err, graphwrite.ErrNoWorkingSet):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, graphwrite.ErrNoDeltas),
+		errors.Is(err, graphwrite.ErrUnknownOperation),
+		errors.Is(err, graphwrite.ErrSelfRelationship),
+		errors.Is(err, graphwrite.ErrCrossVersionRelationship),
+		errors.Is(err, graphwrite.ErrInvalidRelationshipEndpoints),
+		errors.Is(err, graphwrite.ErrInvalidEntityData):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, graphwrite.ErrConcurrentModification):
+		return connect.NewError(connect.CodeAborted, err)
+	case errors.Is(err, graphwrite.ErrProjectNotOwned):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	}
+	return connect.NewError(connect.CodeInternal, err)
+}
+
+// toProtoEntity converts a service entity to its protobuf message, JSON-encoding
+// non-string field values
+func toProtoEntity(entity *graphwrite.Entity) (*graphv1.Entity, error) {
+	fields := make(map[string]string, len(entity.Data))
+	for k, v := range entity.Data {
+		if str, ok := v.(string); ok {
+			fields[k] = str
+			continue
+		}
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field %s: %w", k, err)
+		}
+		fields[k] = string(encoded)
+	}
+
+	return &graphv1.Entity{
+		Id:         entity.ID,
+		VersionId:  entity.VersionID,
+		EntityType: entity.EntityType,
+		Name:       entity.Name,
+		Fields:     fields,
+	}, nil
+}
@@ -242,4 +242,214 @@ func TestGraphWriteServer_Apply_InvalidRequest_Integration(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for non-existent parent version, got nil")
 	}
-}
\ No newline at end of file
+}
+func TestGraphWriteServer_SearchEntities_Integration(t *testing.T) {
+	server, database, _, versionID := setupIntegrationTest(t)
+	defer database.Close()
+
+	ctx := context.Background()
+
+	scene := func(id, name, summary, mood string) *graphv1.Delta {
+		return &graphv1.Delta{
+			Op:         "create",
+			EntityType: "Scene",
+			EntityId:   id,
+			Fields:     map[string]string{"name": name, "summary": summary, "mood": mood},
+		}
+	}
+	applied, err := server.Apply(ctx, connect.NewRequest(&graphv1.ApplyRequest{
+		ParentVersionId: versionID,
+		Deltas: []*graphv1.Delta{
+			scene("storm", "The Storm", "Elena is caught in a storm at sea", "tense"),
+			scene("harbor", "Harbor Dawn", "Elena reaches the harbor", "hopeful"),
+			scene("market", "Market Day", "Marcus haggles for supplies", "tense"),
+		},
+	}))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	searchVersionID := applied.Msg.GetGraphVersionId()
+
+	search := func(query string) map[string]*graphv1.Entity {
+		t.Helper()
+		response, err := server.SearchEntities(ctx, connect.NewRequest(&graphv1.SearchEntitiesRequest{
+			VersionId: searchVersionID,
+			Query:     query,
+		}))
+		if err != nil {
+			t.Fatalf("SearchEntities(%q) failed: %v", query, err)
+		}
+		found := make(map[string]*graphv1.Entity)
+		for _, entity := range response.Msg.GetEntities() {
+			found[entity.GetId()] = entity
+		}
+		return found
+	}
+
+	found := search("elena")
+	if len(found) != 2 || found["storm"] == nil || found["harbor"] == nil {
+		t.Errorf("Expected storm and harbor for 'elena', got %v", found)
+	}
+	if storm := found["storm"]; storm != nil && (storm.GetName() != "The Storm" || storm.GetEntityType() != "Scene" || storm.GetFields()["mood"] != "tense") {
+		t.Errorf("Unexpected entity message: %v", storm)
+	}
+
+	found = search("mood:tense elena")
+	if len(found) != 1 || found["storm"] == nil {
+		t.Errorf("Expected only storm for 'mood:tense elena', got %v", found)
+	}
+
+	if found = search("dragon"); len(found) != 0 {
+		t.Errorf("Expected no matches for 'dragon', got %v", found)
+	}
+
+	// A version is required
+	_, err = server.SearchEntities(ctx, connect.NewRequest(&graphv1.SearchEntitiesRequest{Query: "elena"}))
+	if connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Errorf("Expected InvalidArgument without a version, got %v", err)
+	}
+}
+
+func TestGraphWriteServer_ImportEntities_Integration(t *testing.T) {
+	server, database, sourceProjectID, sourceVersionID := setupIntegrationTest(t)
+	defer database.Close()
+
+	ctx := context.Background()
+
+	applied, err := server.Apply(ctx, connect.NewRequest(&graphv1.ApplyRequest{
+		ParentVersionId: sourceVersionID,
+		Deltas: []*graphv1.Delta{
+			{Op: "create", EntityType: "Character", EntityId: "elena", Fields: map[string]string{"name": "Elena", "role": "protagonist"}},
+			{Op: "create", EntityType: "Character", EntityId: "marcus", Fields: map[string]string{"name": "Marcus"}},
+		},
+	}))
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if err := database.Queries().SetWorkingSet(ctx, db.SetWorkingSetParams{ID: applied.Msg.GetGraphVersionId(), ProjectID: sourceProjectID}); err != nil {
+		t.Fatalf("Failed to set working set: %v", err)
+	}
+
+	// A second book in the series
+	targetProjectID := uuid.New().String()
+	if _, err := database.Queries().CreateProject(ctx, db.CreateProjectParams{ID: targetProjectID, Name: "Book Two"}); err != nil {
+		t.Fatalf("Failed to create target project: %v", err)
+	}
+	targetVersionID := uuid.New().String()
+	if _, err := database.Queries().CreateGraphVersion(ctx, db.CreateGraphVersionParams{ID: targetVersionID, ProjectID: targetProjectID, IsWorkingSet: true}); err != nil {
+		t.Fatalf("Failed to create target version: %v", err)
+	}
+
+	imported, err := server.ImportEntity(ctx, connect.NewRequest(&graphv1.ImportEntityRequest{
+		TargetVersionId: targetVersionID,
+		SourceProjectId: sourceProjectID,
+		EntityId:        "elena",
+	}))
+	if err != nil {
+		t.Fatalf("ImportEntity failed: %v", err)
+	}
+	elena := imported.Msg.GetEntity()
+	if elena.GetId() != "elena" || elena.GetVersionId() != targetVersionID || elena.GetFields()["role"] != "protagonist" {
+		t.Errorf("Unexpected imported entity: %v", elena)
+	}
+
+	batch, err := server.ImportEntities(ctx, connect.NewRequest(&graphv1.ImportEntitiesRequest{
+		TargetVersionId: targetVersionID,
+		SourceProjectId: sourceProjectID,
+		EntityIds:       []string{"elena", "marcus"},
+	}))
+	if err != nil {
+		t.Fatalf("ImportEntities failed: %v", err)
+	}
+	if len(batch.Msg.GetEntities()) != 2 || batch.Msg.GetEntities()[1].GetId() != "marcus" {
+		t.Errorf("Expected elena and marcus, got %v", batch.Msg.GetEntities())
+	}
+
+	// The imported characters keep their identity across both books
+	shared, err := graphwrite.NewService(database).ListSharedEntities(ctx)
+	if err != nil {
+		t.Fatalf("ListSharedEntities failed: %v", err)
+	}
+	projectCounts := make(map[string]int)
+	for _, entity := range shared {
+		projectCounts[entity.LogicalID] = entity.ProjectCount
+	}
+	if projectCounts["elena"] != 2 || projectCounts["marcus"] != 2 {
+		t.Errorf("Expected elena and marcus to be shared across both projects, got %v", projectCounts)
+	}
+
+	_, err = server.ImportEntities(ctx, connect.NewRequest(&graphv1.ImportEntitiesRequest{
+		TargetVersionId: targetVersionID,
+		SourceProjectId: sourceProjectID,
+	}))
+	if connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Errorf("Expected InvalidArgument without entity IDs, got %v", err)
+	}
+}
+
+func TestGraphWriteServer_ImportEntities_IncludeRelationships_Integration(t *testing.T) {
+	server, database, sourceProjectID, _ := setupIntegrationTest(t)
+	defer database.Close()
+
+	ctx := context.Background()
+	service := graphwrite.NewService(database)
+
+	if _, err := service.ApplyToProject(ctx, sourceProjectID, []*graphwrite.Delta{
+		{Operation: "create", EntityType: "Character", EntityID: "elena", Fields: map[string]any{"name": "Elena"}},
+		{
+			Operation:  "create",
+			EntityType: "Character",
+			EntityID:   "marcus",
+			Fields:     map[string]any{"name": "Marcus"},
+			Relationships: []*graphwrite.RelationshipDelta{
+				{Operation: "create", FromEntityID: "elena", ToEntityID: "marcus", RelationshipType: "allies_with", Properties: map[string]any{}},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("ApplyToProject failed: %v", err)
+	}
+
+	_, targetVersionID, err := service.CreateProject(ctx, db.CreateProjectParams{Name: "Book Two"}, "")
+	if err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+
+	if _, err := server.ImportEntities(ctx, connect.NewRequest(&graphv1.ImportEntitiesRequest{
+		TargetVersionId:      targetVersionID,
+		SourceProjectId:      sourceProjectID,
+		EntityIds:            []string{"elena", "marcus"},
+		IncludeRelationships: true,
+	})); err != nil {
+		t.Fatalf("ImportEntities failed: %v", err)
+	}
+
+	allies, err := service.GetNeighborsInVersion(ctx, targetVersionID, "elena", "allies_with")
+	if err != nil {
+		t.Fatalf("GetNeighborsInVersion failed: %v", err)
+	}
+	if len(allies) != 1 || allies[0].ID != "marcus" {
+		t.Errorf("Expected the alliance to be imported with the characters, got %v", allies)
+	}
+
+	// Typed service errors keep their meaning over the wire
+	_, err = server.ImportEntity(ctx, connect.NewRequest(&graphv1.ImportEntityRequest{
+		TargetVersionId: targetVersionID,
+		SourceProjectId: sourceProjectID,
+		EntityId:        "missing",
+	}))
+	if connect.CodeOf(err) != connect.CodeNotFound {
+		t.Errorf("Expected NotFound for a missing entity, got %v", err)
+	}
+
+	if err := service.SetProjectOwner(ctx, sourceProjectID, "alice"); err != nil {
+		t.Fatalf("SetProjectOwner failed: %v", err)
+	}
+	_, err = server.ImportEntity(graphwrite.WithOwner(ctx, "bob"), connect.NewRequest(&graphv1.ImportEntityRequest{
+		TargetVersionId: targetVersionID,
+		SourceProjectId: sourceProjectID,
+		EntityId:        "elena",
+	}))
+	if connect.CodeOf(err) != connect.CodePermissionDenied {
+		t.Errorf("Expected PermissionDenied importing from another owner's project, got %v", err)
+	}
+}
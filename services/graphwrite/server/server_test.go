@@ -3,10 +3,13 @@ package server
 import (
 	"context"
 	"testing"
+	"time"
 
 	"connectrpc.com/connect"
 	graphv1 "github.com/barrynorthern/libretto/gen/go/libretto/graph/v1"
+	"github.com/barrynorthern/libretto/internal/db"
 	"github.com/barrynorthern/libretto/internal/graphwrite"
+	"github.com/barrynorthern/libretto/internal/types"
 )
 
 func TestApplyRejectsEmptyDeltas(t *testing.T) {
@@ -37,6 +40,14 @@ func (m *mockGraphWriteService) Apply(ctx context.Context, req *graphwrite.Apply
 	}, m.err
 }
 
+func (m *mockGraphWriteService) ApplyAndAdvance(ctx context.Context, req *graphwrite.ApplyRequest) (*graphwrite.ApplyResponse, error) {
+	return m.Apply(ctx, req)
+}
+
+func (m *mockGraphWriteService) Validate(ctx context.Context, req *graphwrite.ApplyRequest) (*graphwrite.ValidationReport, error) {
+	return &graphwrite.ValidationReport{}, nil
+}
+
 func (m *mockGraphWriteService) GetVersion(ctx context.Context, versionID string) (*graphwrite.GraphVersion, error) {
 	return nil, m.err
 }
@@ -57,6 +68,10 @@ func (m *mockGraphWriteService) ImportEntity(ctx context.Context, targetVersionI
 	return nil, m.err
 }
 
+func (m *mockGraphWriteService) CheckImportDuplicate(ctx context.Context, targetVersionID string, sourceProjectID string, entityLogicalID string) (*graphwrite.ImportWarning, error) {
+	return nil, m.err
+}
+
 func (m *mockGraphWriteService) GetEntityHistory(ctx context.Context, entityLogicalID string) ([]*graphwrite.EntityVersion, error) {
 	return nil, m.err
 }
@@ -65,6 +80,254 @@ func (m *mockGraphWriteService) ListSharedEntities(ctx context.Context) ([]*grap
 	return nil, m.err
 }
 
+func (m *mockGraphWriteService) GetCharacterProfile(ctx context.Context, versionID, logicalID string) (*types.CharacterData, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) CompareCharacters(ctx context.Context, versionID, idA, idB string) (*graphwrite.CharacterComparison, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) VersionsContaining(ctx context.Context, logicalID string) ([]*graphwrite.GraphVersion, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) CreateAnnotation(ctx context.Context, versionID string, annotation *graphwrite.Annotation) (*graphwrite.Annotation, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) FlattenProject(ctx context.Context, projectID string) (string, error) {
+	return "", m.err
+}
+
+func (m *mockGraphWriteService) AnnotationsForVersion(ctx context.Context, versionID string) ([]graphwrite.Annotation, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) GetProjectOverview(ctx context.Context, projectID string) (*graphwrite.ProjectOverview, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) UpdateProjectMetadata(ctx context.Context, projectID string, metadata graphwrite.ProjectMetadata) (*graphwrite.Project, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) DeletionImpact(ctx context.Context, projectID string) (*graphwrite.DeletionImpact, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) EmotionalArc(ctx context.Context, versionID string) ([]graphwrite.ArcPoint, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) SearchEntities(ctx context.Context, versionID string, query string) ([]*graphwrite.Entity, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) RestoreEntity(ctx context.Context, versionID, logicalID string) (*graphwrite.ApplyResponse, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) ListProjectsWithStats(ctx context.Context) ([]graphwrite.ProjectSummaryStats, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) EntitiesAsOf(ctx context.Context, projectID string, t time.Time) ([]*graphwrite.Entity, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) RelationshipsAmong(ctx context.Context, versionID string, logicalIDs []string) ([]*graphwrite.Relationship, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) GetProjectSettings(ctx context.Context, projectID string) (*graphwrite.ProjectSettings, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) UpdateProjectSettings(ctx context.Context, projectID string, settings graphwrite.ProjectSettings) (*graphwrite.ProjectSettings, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) TagVersion(ctx context.Context, versionID string, tag string) error {
+	return nil
+}
+
+func (m *mockGraphWriteService) CompareToTag(ctx context.Context, projectID, logicalID, tag string) (*graphwrite.EntityChange, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) RenameProject(ctx context.Context, projectID string, newName string) error {
+	return nil
+}
+
+func (m *mockGraphWriteService) ExportGraphML(ctx context.Context, versionID string) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) CompletenessReport(ctx context.Context, versionID string) (*graphwrite.Completeness, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) FindOrphanAnnotations(ctx context.Context) ([]graphwrite.Annotation, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) PruneOrphanAnnotations(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (m *mockGraphWriteService) ListRelationshipTypes(ctx context.Context, versionID string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) ExtractMentions(ctx context.Context, versionID, sceneLogicalID string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) LinkMentions(ctx context.Context, versionID, sceneLogicalID string) (*graphwrite.ApplyResponse, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) ListEntitiesPage(ctx context.Context, versionID string, filter graphwrite.EntityFilter, opts graphwrite.ListOptions) (*graphwrite.ListResult[*graphwrite.Entity], error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) ListRelationships(ctx context.Context, versionID string, opts graphwrite.ListOptions) (*graphwrite.ListResult[*graphwrite.Relationship], error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) ListRelationshipsOfType(ctx context.Context, versionID string, relationshipType string, opts graphwrite.ListOptions) (*graphwrite.ListResult[*graphwrite.Relationship], error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) GetEntityHistoryPage(ctx context.Context, entityLogicalID string, opts graphwrite.ListOptions) (*graphwrite.ListResult[*graphwrite.EntityVersion], error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) ListSharedEntitiesPage(ctx context.Context, opts graphwrite.ListOptions) (*graphwrite.ListResult[*graphwrite.SharedEntity], error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) SaveLayout(ctx context.Context, versionID string, positions map[string]graphwrite.NodePosition) error {
+	return nil
+}
+
+func (m *mockGraphWriteService) GetLayout(ctx context.Context, versionID string) (map[string]graphwrite.NodePosition, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) PropagateThemes(ctx context.Context, versionID string) (map[string][]string, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) SetProjectOwner(ctx context.Context, projectID string, ownerID string) error {
+	return nil
+}
+
+func (m *mockGraphWriteService) Diff(ctx context.Context, fromVersionID, toVersionID string) (*graphwrite.GraphDiff, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) FieldHistory(ctx context.Context, projectID, logicalID, field string) ([]graphwrite.FieldChange, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) Revert(ctx context.Context, projectID, targetVersionID string) (*graphwrite.GraphVersion, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) RepairIntegrity(ctx context.Context, opts graphwrite.RepairOptions) (*graphwrite.IntegrityReport, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) ValidateSeries(ctx context.Context, seriesProjectIDs []string) ([]graphwrite.SeriesIssue, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) Merge(ctx context.Context, baseVersionID, leftVersionID, rightVersionID string) (*graphwrite.MergeResult, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) GetEntityHistoryInProject(ctx context.Context, projectID, logicalEntityID string) ([]*graphwrite.EntityVersion, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) FirstAppearance(ctx context.Context, versionID, characterLogicalID string) (*graphwrite.Entity, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) ApplyToProject(ctx context.Context, projectID string, deltas []*graphwrite.Delta) (*graphwrite.ApplyResponse, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) AnnotateRelationship(ctx context.Context, versionID string, annotation *graphwrite.RelationshipAnnotation) (*graphwrite.RelationshipAnnotation, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) RelationshipAnnotationsForVersion(ctx context.Context, versionID string) ([]graphwrite.RelationshipAnnotation, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) ExportCharacterDossier(ctx context.Context, logicalID string) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) ApplyWithDiff(ctx context.Context, req *graphwrite.ApplyRequest) (*graphwrite.ApplyResponse, *graphwrite.GraphDiff, error) {
+	return nil, nil, nil
+}
+
+func (m *mockGraphWriteService) GetEntities(ctx context.Context, versionID string, logicalIDs []string) ([]*graphwrite.Entity, error) {
+	return nil, nil
+}
+
+func (m *mockGraphWriteService) ImportEntities(ctx context.Context, targetVersionID, sourceProjectID string, logicalIDs []string) ([]*graphwrite.Entity, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) BuildSceneContext(ctx context.Context, versionID, sceneLogicalID string) (*graphwrite.SceneContext, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) ImportEntityWithRelationships(ctx context.Context, targetVersionID, sourceProjectID, logicalID string) (*graphwrite.Entity, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) StoreEmbedding(ctx context.Context, versionID, logicalID string, vector []float32) error {
+	return m.err
+}
+
+func (m *mockGraphWriteService) FindSimilarEntities(ctx context.Context, versionID, logicalID string, k int) ([]*graphwrite.Entity, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) GetVersionTree(ctx context.Context, projectID string) (*graphwrite.VersionTree, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) CreateProject(ctx context.Context, params db.CreateProjectParams, initialVersionName string) (string, string, error) {
+	return "", "", m.err
+}
+
+func (m *mockGraphWriteService) TraceThread(ctx context.Context, versionID, startLogicalID string, relTypes []string, maxDepth int) (*graphwrite.Thread, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) GetProject(ctx context.Context, projectID string) (*graphwrite.Project, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) VersionRange(ctx context.Context, fromVersionID, toVersionID string) ([]*graphwrite.GraphVersion, error) {
+	return nil, m.err
+}
+
+func (m *mockGraphWriteService) UniqueProjectName(ctx context.Context, name string) (string, error) {
+	return name, nil
+}
+
+func (m *mockGraphWriteService) RelationshipTrends(ctx context.Context, projectID string) (map[string][]int, error) {
+	return nil, m.err
+}
+
 func TestApplySuccess(t *testing.T) {
 	s := NewGraphWriteServer(&mockGraphWriteService{version: "01JF00", count: 2})
 	req := connect.NewRequest(&graphv1.ApplyRequest{ParentVersionId: "01JROOT", Deltas: []*graphv1.Delta{{Op: "create"}, {Op: "create"}}})